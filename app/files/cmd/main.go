@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -9,12 +10,32 @@ import (
 	"grpc-todolist-disk/conf"
 	pb "grpc-todolist-disk/idl/pb/files"
 	"grpc-todolist-disk/utils/discovery"
+	"grpc-todolist-disk/utils/kafka_mq"
+	"grpc-todolist-disk/utils/outbox"
 	"net"
 )
 
 func main() {
 	conf.InitConfig()
 	dao.InitDB()
+	ctx, cancel := context.WithCancel(context.Background())
+	// 发送 outbox 中的消息。使用进程内的消息队列时由 cmd/dev 进程发送，本进程发送的消息没有消费者
+	publisher := kafka_mq.NewPublisher("")
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		if !kafka_mq.InProcess() {
+			outbox.NewRelay(dao.DB, publisher).Run(ctx)
+		}
+	}()
+	// 上传后的恶意文件扫描
+	if err := service.StartScanner(ctx); err != nil {
+		panic(err)
+	}
+	// 客户端直传到本地存储
+	if err := service.StartUploadServer(ctx); err != nil {
+		panic(err)
+	}
 	// etcd 地址
 	etcdAddress := []string{conf.Conf.Etcd.Endpoints[0]}
 	// 注册服务
@@ -24,13 +45,11 @@ func main() {
 	}
 	etcdRegister := discovery.NewRegister(etcdAddress, logger)
 	grpcAddress := conf.Conf.Services["files"].Addr[0]
-	defer etcdRegister.Stop()
 	filesNode := discovery.Server{
 		Name: conf.Conf.Services["files"].Name,
 		Addr: grpcAddress,
 	}
 	server := grpc.NewServer()
-	defer server.Stop()
 	// 绑定service
 	pb.RegisterFilesServiceServer(server, service.GetFilesSrv())
 	lis, err := net.Listen("tcp", grpcAddress)
@@ -44,7 +63,26 @@ func main() {
 		zap.String("address", grpcAddress),
 		zap.String("service", "files"),
 	)
-	if err := server.Serve(lis); err != nil {
-		panic(err)
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			panic(err)
+		}
+	}()
+
+	// 收到退出信号后注销服务，等待处理中的请求完成
+	sig := discovery.WaitSignal()
+	logger.Info("shutting down gRPC server", zap.String("signal", sig.String()))
+	discovery.Shutdown(etcdRegister, server, discovery.ShutdownTimeout)
+	// 等待进行中的直传完成，再停止扫描和清理
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), discovery.ShutdownTimeout)
+	service.ShutdownUploadServer(shutdownCtx)
+	cancelShutdown()
+	// 尚未发送的消息留在 outbox 中，下次启动或由其他实例发送
+	cancel()
+	<-relayDone
+	publisher.Close()
+	if sqlDB, err := dao.DB.DB(); err == nil {
+		sqlDB.Close()
 	}
+	logger.Info("gRPC server exited", zap.String("service", "files"))
 }
@@ -0,0 +1,172 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+)
+
+// ErrPermissionDenied 当前用户对资源没有足够的权限
+var ErrPermissionDenied = errors.New("无权限操作该资源")
+
+// ErrFileNotFound、ErrFolderNotFound 文件或文件夹不存在，均包装 gorm.ErrRecordNotFound
+var (
+	ErrFileNotFound   = fmt.Errorf("文件不存在: %w", gorm.ErrRecordNotFound)
+	ErrFolderNotFound = fmt.Errorf("文件夹不存在: %w", gorm.ErrRecordNotFound)
+)
+
+// maxFolderDepth 文件夹最大嵌套层数，创建和移动文件夹时校验；
+// 向上查找祖先文件夹时也以此为上限，防止脏数据形成环
+const maxFolderDepth = 64
+
+// ErrFolderTooDeep 创建或移动后文件夹嵌套层数超过 maxFolderDepth
+var ErrFolderTooDeep = errors.New("文件夹层级过深")
+
+type AclDao struct {
+	*gorm.DB
+}
+
+func NewAclDao() *AclDao {
+	return &AclDao{
+		NewDBClient(),
+	}
+}
+
+// GroupIDsOfUser 查询用户所在的全部用户组
+func (dao *AclDao) GroupIDsOfUser(userID uint) (ids []uint, err error) {
+	err = dao.DB.Model(&model.GroupMember{}).Where("user_id = ?", userID).Pluck("group_id", &ids).Error
+	return
+}
+
+// folderChain 返回 folderID 及其所有祖先文件夹（由近及远）
+func (dao *AclDao) folderChain(folderID uint) ([]*model.Folder, error) {
+	var chain []*model.Folder
+	for depth := 0; folderID != 0 && depth < maxFolderDepth; depth++ {
+		var folder model.Folder
+		if err := dao.DB.Model(&model.Folder{}).Where("id = ?", folderID).First(&folder).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				break
+			}
+			return nil, err
+		}
+		chain = append(chain, &folder)
+		folderID = folder.ParentID
+	}
+	return chain, nil
+}
+
+// grantedLevel 计算用户在一组资源上通过共享获得的最高角色等级
+func (dao *AclDao) grantedLevel(userID uint, fileID uint, folderIDs []uint) (int, error) {
+	groupIDs, err := dao.GroupIDsOfUser(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	grantee := dao.DB.Where("grantee_type = ? AND grantee_id = ?", model.GranteeTypeUser, userID)
+	if len(groupIDs) > 0 {
+		grantee = grantee.Or("grantee_type = ? AND grantee_id IN ?", model.GranteeTypeGroup, groupIDs)
+	}
+	resource := dao.DB.Where("resource_type = ? AND resource_id = ?", model.ResourceTypeFile, fileID)
+	if len(folderIDs) > 0 {
+		resource = resource.Or("resource_type = ? AND resource_id IN ?", model.ResourceTypeFolder, folderIDs)
+	}
+
+	var roles []string
+	err = dao.DB.Model(&model.FileShare{}).Where(grantee).Where(resource).Pluck("role", &roles).Error
+	if err != nil {
+		return 0, err
+	}
+	level := 0
+	for _, role := range roles {
+		if l := model.RoleLevel(role); l > level {
+			level = l
+		}
+	}
+	return level, nil
+}
+
+// rootOwner 返回文件夹链最顶层文件夹的所有者，链为空时返回 fallback
+func rootOwner(chain []*model.Folder, fallback uint) uint {
+	if len(chain) == 0 {
+		return fallback
+	}
+	return chain[len(chain)-1].UserID
+}
+
+// FileOwner 返回文件的所有者：位于文件夹中时为根文件夹的所有者，否则为上传者。
+// 他人在共享文件夹中上传的文件属于共享文件夹的所有者
+func (dao *AclDao) FileOwner(file *model.Files) (uint, error) {
+	chain, err := dao.folderChain(file.FolderID)
+	if err != nil {
+		return 0, err
+	}
+	return rootOwner(chain, file.UserID), nil
+}
+
+// FolderOwner 返回文件夹所在目录树的所有者，folderID 为 0 时返回 fallback
+func (dao *AclDao) FolderOwner(folderID, fallback uint) (uint, error) {
+	chain, err := dao.folderChain(folderID)
+	if err != nil {
+		return 0, err
+	}
+	return rootOwner(chain, fallback), nil
+}
+
+// CheckFile 校验用户对文件是否至少拥有 role 权限：所有者（见 FileOwner）拥有全部权限，
+// 其他用户需要文件本身或其任意祖先文件夹上的共享授权
+func (dao *AclDao) CheckFile(userID uint, file *model.Files, role string) error {
+	chain, err := dao.folderChain(file.FolderID)
+	if err != nil {
+		return err
+	}
+	if rootOwner(chain, file.UserID) == userID {
+		return nil
+	}
+	folderIDs := make([]uint, 0, len(chain))
+	for _, folder := range chain {
+		folderIDs = append(folderIDs, folder.ID)
+	}
+	level, err := dao.grantedLevel(userID, file.ID, folderIDs)
+	if err != nil {
+		return err
+	}
+	if level < model.RoleLevel(role) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// CheckFolderWritable 校验用户是否可以向文件夹写入（至少 editor），folderID 为 0 表示自己的根目录
+func (dao *AclDao) CheckFolderWritable(userID uint, folderID uint) error {
+	if folderID == 0 {
+		return nil
+	}
+	return dao.CheckFolder(userID, folderID, model.RoleEditor)
+}
+
+// CheckFolder 校验用户对文件夹是否至少拥有 role 权限，规则与 CheckFile 相同
+func (dao *AclDao) CheckFolder(userID uint, folderID uint, role string) error {
+	chain, err := dao.folderChain(folderID)
+	if err != nil {
+		return err
+	}
+	if len(chain) == 0 {
+		return ErrFolderNotFound
+	}
+	if rootOwner(chain, 0) == userID {
+		return nil
+	}
+	folderIDs := make([]uint, 0, len(chain))
+	for _, folder := range chain {
+		folderIDs = append(folderIDs, folder.ID)
+	}
+	level, err := dao.grantedLevel(userID, 0, folderIDs)
+	if err != nil {
+		return err
+	}
+	if level < model.RoleLevel(role) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	"sort"
+)
+
+type ChangeDao struct {
+	*gorm.DB
+}
+
+func NewChangeDao() *ChangeDao {
+	return &ChangeDao{
+		NewDBClient(),
+	}
+}
+
+// audience 返回能看到某个位置上资源的用户：目录树的所有者（folderID 为 0 时为 owner），
+// 以及目录链上各文件夹（和 fileID 对应的文件）的被授权用户、被授权用户组的成员，按 ID 升序
+func audience(tx *gorm.DB, folderID, owner, fileID uint) ([]uint, error) {
+	chain, err := (&AclDao{tx}).folderChain(folderID)
+	if err != nil {
+		return nil, err
+	}
+	users := map[uint]bool{rootOwner(chain, owner): true}
+
+	folderIDs := make([]uint, 0, len(chain))
+	for _, folder := range chain {
+		folderIDs = append(folderIDs, folder.ID)
+	}
+	if len(folderIDs) > 0 || fileID != 0 {
+		resource := tx.Where("resource_type = ? AND resource_id = ?", model.ResourceTypeFile, fileID)
+		if len(folderIDs) > 0 {
+			resource = resource.Or("resource_type = ? AND resource_id IN ?", model.ResourceTypeFolder, folderIDs)
+		}
+		var shares []*model.FileShare
+		if err = tx.Where(resource).Find(&shares).Error; err != nil {
+			return nil, err
+		}
+		var groupIDs []uint
+		for _, share := range shares {
+			switch share.GranteeType {
+			case model.GranteeTypeUser:
+				users[share.GranteeID] = true
+			case model.GranteeTypeGroup:
+				groupIDs = append(groupIDs, share.GranteeID)
+			}
+		}
+		if len(groupIDs) > 0 {
+			var members []uint
+			if err = tx.Model(&model.GroupMember{}).Where("group_id IN ?", groupIDs).Pluck("user_id", &members).Error; err != nil {
+				return nil, err
+			}
+			for _, id := range members {
+				users[id] = true
+			}
+		}
+	}
+
+	ids := make([]uint, 0, len(users))
+	for id := range users {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func fileAudience(tx *gorm.DB, file *model.Files) ([]uint, error) {
+	return audience(tx, file.FolderID, file.UserID, file.ID)
+}
+
+func folderAudience(tx *gorm.DB, folder *model.Folder) ([]uint, error) {
+	return audience(tx, folder.ID, folder.UserID, 0)
+}
+
+// recordChange 在事务中为 users 中的每个用户追加一条变更记录。before 为移动前能看到资源的用户，
+// 非移动操作传 nil；移动后看不到资源的用户记为删除，移动后才看到的用户记为创建。
+// 用户按 ID 升序处理，序号行通过 upsert 自增并被行锁锁住，同一用户的变更在提交顺序上与序号一致
+func recordChange(tx *gorm.DB, change *model.Change, users, before []uint) error {
+	actions := make(map[uint]string, len(users)+len(before))
+	for _, id := range users {
+		actions[id] = change.Action
+	}
+	if before != nil {
+		visible := make(map[uint]bool, len(before))
+		for _, id := range before {
+			visible[id] = true
+			if _, ok := actions[id]; !ok {
+				actions[id] = model.ChangeDelete
+			}
+		}
+		for _, id := range users {
+			if !visible[id] {
+				actions[id] = model.ChangeCreate
+			}
+		}
+	}
+	ids := make([]uint, 0, len(actions))
+	for id := range actions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		entry := *change
+		entry.UserID, entry.Action = id, actions[id]
+		seq := model.ChangeSeq{UserID: id, Seq: 1}
+		if err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]interface{}{"seq": gorm.Expr("seq + 1")}),
+		}).Create(&seq).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.ChangeSeq{}).Where("user_id = ?", id).Select("seq").Scan(&entry.Seq).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fileChange(action string, file *model.Files) *model.Change {
+	return &model.Change{
+		Action:       action,
+		ResourceType: model.ResourceTypeFile,
+		ResourceID:   file.ID,
+		ParentID:     file.FolderID,
+		Name:         file.FileName,
+		Size:         file.FileSize,
+	}
+}
+
+func folderChange(action string, folder *model.Folder) *model.Change {
+	return &model.Change{
+		Action:       action,
+		ResourceType: model.ResourceTypeFolder,
+		ResourceID:   folder.ID,
+		ParentID:     folder.ParentID,
+		Name:         folder.Name,
+	}
+}
+
+// recordFileChange 为能看到文件的用户写入变更，删除时需要在删除记录和授权之前调用
+func recordFileChange(tx *gorm.DB, action string, file *model.Files, before []uint) error {
+	users, err := fileAudience(tx, file)
+	if err != nil {
+		return err
+	}
+	return recordChange(tx, fileChange(action, file), users, before)
+}
+
+// recordFolderChange 为能看到文件夹的用户写入变更，删除时需要在删除记录和授权之前调用
+func recordFolderChange(tx *gorm.DB, action string, folder *model.Folder, before []uint) error {
+	users, err := folderAudience(tx, folder)
+	if err != nil {
+		return err
+	}
+	return recordChange(tx, folderChange(action, folder), users, before)
+}
+
+// resourceAudience 查询共享资源当前能看到它的用户，以及对应的变更模板
+func resourceAudience(tx *gorm.DB, resourceType string, resourceID uint) (*model.Change, []uint, error) {
+	switch resourceType {
+	case model.ResourceTypeFile:
+		var file model.Files
+		if err := tx.Where("id = ?", resourceID).First(&file).Error; err != nil {
+			return nil, nil, err
+		}
+		users, err := fileAudience(tx, &file)
+		return fileChange("", &file), users, err
+	default:
+		var folder model.Folder
+		if err := tx.Where("id = ?", resourceID).First(&folder).Error; err != nil {
+			return nil, nil, err
+		}
+		users, err := folderAudience(tx, &folder)
+		return folderChange("", &folder), users, err
+	}
+}
+
+// recordShareChange 授权变化后，新获得访问权限的用户记为创建，失去访问权限的用户记为删除
+func recordShareChange(tx *gorm.DB, resourceType string, resourceID uint, before []uint) error {
+	change, users, err := resourceAudience(tx, resourceType, resourceID)
+	if err != nil {
+		return err
+	}
+	visible := make(map[uint]bool, len(before))
+	for _, id := range before {
+		visible[id] = true
+	}
+	var added, removed []uint
+	for _, id := range users {
+		if !visible[id] {
+			added = append(added, id)
+		}
+		delete(visible, id)
+	}
+	for _, id := range before {
+		if visible[id] {
+			removed = append(removed, id)
+		}
+	}
+	created, deleted := *change, *change
+	created.Action, deleted.Action = model.ChangeCreate, model.ChangeDelete
+	if err = recordChange(tx, &created, added, nil); err != nil {
+		return err
+	}
+	return recordChange(tx, &deleted, removed, nil)
+}
+
+// createFile 创建文件记录并写入变更日志
+func createFile(db *gorm.DB, file *model.Files) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(file).Error; err != nil {
+			return err
+		}
+		return recordFileChange(tx, model.ChangeCreate, file, nil)
+	})
+}
+
+// updateFile 更新文件记录并写入变更日志，移动时按移动前后能看到文件的用户分别记录
+func updateFile(db *gorm.DB, fileID uint, action string, updates map[string]interface{}) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var file model.Files
+		if err := tx.Where("id = ?", fileID).First(&file).Error; err != nil {
+			return err
+		}
+		var before []uint
+		if action == model.ChangeMove {
+			var err error
+			if before, err = fileAudience(tx, &file); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&model.Files{}).Where("id = ?", fileID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", fileID).First(&file).Error; err != nil {
+			return err
+		}
+		return recordFileChange(tx, action, &file, before)
+	})
+}
+
+// ListChanges 查询用户变更日志中游标之后的变更，按序号升序
+func (dao *ChangeDao) ListChanges(userID uint, cursor uint64, limit int) (changes []*model.Change, err error) {
+	err = dao.DB.Model(&model.Change{}).Where("user_id = ? AND seq > ?", userID, cursor).
+		Order("seq").Limit(limit).Find(&changes).Error
+	return
+}
+
+// LatestSeq 用户当前最新的变更序号，没有变更时为 0
+func (dao *ChangeDao) LatestSeq(userID uint) (seq uint64, err error) {
+	err = dao.DB.Model(&model.ChangeSeq{}).Where("user_id = ?", userID).Select("seq").Scan(&seq).Error
+	return
+}
@@ -0,0 +1,137 @@
+package dao
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	"grpc-todolist-disk/utils/storage"
+	"sort"
+)
+
+type ChunkDao struct {
+	*gorm.DB
+}
+
+func NewChunkDao() *ChunkDao {
+	return &ChunkDao{
+		NewDBClient(),
+	}
+}
+
+// chunkCounts 统计清单中每个分块出现的次数，按哈希排序以保证加锁顺序一致、避免死锁
+func chunkCounts(m *storage.Manifest) ([]string, map[string]*model.Chunk) {
+	chunks := make(map[string]*model.Chunk, len(m.Chunks))
+	for _, ref := range m.Chunks {
+		if c, ok := chunks[ref.Hash]; ok {
+			c.RefCount++
+			continue
+		}
+		chunks[ref.Hash] = &model.Chunk{Hash: ref.Hash, Size: ref.Size, RefCount: 1}
+	}
+	hashes := make([]string, 0, len(chunks))
+	for hash := range chunks {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+	return hashes, chunks
+}
+
+// Acquire 增加清单中各分块的引用计数（不存在时创建），需要在写入分块文件之前调用
+func (dao *ChunkDao) Acquire(m *storage.Manifest) error {
+	hashes, chunks := chunkCounts(m)
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		for _, hash := range hashes {
+			c := chunks[hash]
+			if err := tx.Clauses(clause.OnConflict{
+				DoUpdates: clause.Assignments(map[string]interface{}{"ref_count": gorm.Expr("ref_count + ?", c.RefCount)}),
+			}).Create(c).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Release 减少清单中各分块的引用计数，计数归零的分块在持有行锁时调用 remove 删除，
+// 保证与并发的 Acquire 不会交错
+func (dao *ChunkDao) Release(m *storage.Manifest, remove func(hash string) error) error {
+	hashes, chunks := chunkCounts(m)
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		for _, hash := range hashes {
+			var c model.Chunk
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("hash = ?", hash).First(&c).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			c.RefCount -= chunks[hash].RefCount
+			if c.RefCount > 0 {
+				if err = tx.Model(&c).Update("ref_count", c.RefCount).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err = tx.Delete(&c).Error; err != nil {
+				return err
+			}
+			if err = remove(hash); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// StorageStats 存储统计
+type StorageStats struct {
+	LogicalBytes  int64 // 所有本地文件（含秒传记录和历史版本）的大小之和
+	PhysicalBytes int64 // 实际占用：分块总大小 + 未分块的原始对象大小
+	ChunkBytes    int64
+	ChunkCount    int64
+	ChunkedFiles  int64
+	RawBytes      int64 // 未分块对象在磁盘上的大小（压缩后）
+}
+
+// Stats 统计本地存储的逻辑大小与物理占用
+func (dao *ChunkDao) Stats() (*StorageStats, error) {
+	stats := &StorageStats{}
+	row := struct {
+		Count int64
+		Bytes int64
+	}{}
+	if err := dao.DB.Model(&model.Chunk{}).Select("COUNT(*) AS count, COALESCE(SUM(size), 0) AS bytes").Scan(&row).Error; err != nil {
+		return nil, err
+	}
+	stats.ChunkCount, stats.ChunkBytes = row.Count, row.Bytes
+
+	var logical, versionLogical, raw, versionRaw int64
+	local := "bucket <> 'qiniu'"
+	if err := dao.DB.Model(&model.Files{}).Where(local).Select("COALESCE(SUM(file_size), 0)").Scan(&logical).Error; err != nil {
+		return nil, err
+	}
+	if err := dao.DB.Model(&model.FileVersion{}).Select("COALESCE(SUM(file_size), 0)").Scan(&versionLogical).Error; err != nil {
+		return nil, err
+	}
+	// 秒传记录不占用物理空间；压缩过的对象按压缩后的大小计算
+	notShared := "file_hash NOT LIKE 'shared_%'"
+	storedSize := "COALESCE(SUM(CASE WHEN stored_size > 0 THEN stored_size ELSE file_size END), 0)"
+	if err := dao.DB.Model(&model.Files{}).Where(local).Where(notShared).Where("layout = ?", storage.LayoutRaw).
+		Select(storedSize).Scan(&raw).Error; err != nil {
+		return nil, err
+	}
+	if err := dao.DB.Model(&model.FileVersion{}).Where(notShared).Where("layout = ?", storage.LayoutRaw).
+		Select(storedSize).Scan(&versionRaw).Error; err != nil {
+		return nil, err
+	}
+	if err := dao.DB.Model(&model.Files{}).Where(local).Where(notShared).Where("layout = ?", storage.LayoutChunked).
+		Count(&stats.ChunkedFiles).Error; err != nil {
+		return nil, err
+	}
+	stats.LogicalBytes = logical + versionLogical
+	stats.RawBytes = raw + versionRaw
+	stats.PhysicalBytes = stats.ChunkBytes + stats.RawBytes
+	return stats, nil
+}
@@ -0,0 +1,32 @@
+package dao
+
+import (
+	"strings"
+	"testing"
+
+	"grpc-todolist-disk/utils/storage"
+)
+
+// TestChunkCounts 同一清单中重复出现的分块按出现次数增减引用计数，按哈希排序加锁
+func TestChunkCounts(t *testing.T) {
+	a, b, c := strings.Repeat("a", 64), strings.Repeat("b", 64), strings.Repeat("c", 64)
+	m := &storage.Manifest{Chunks: []storage.ChunkRef{
+		{Hash: c, Size: 3}, {Hash: a, Size: 1}, {Hash: c, Size: 3}, {Hash: b, Size: 2}, {Hash: c, Size: 3},
+	}}
+	hashes, chunks := chunkCounts(m)
+	if strings.Join(hashes, ",") != strings.Join([]string{a, b, c}, ",") {
+		t.Fatalf("hashes %v not sorted or not unique", hashes)
+	}
+	for hash, want := range map[string]int64{a: 1, b: 1, c: 3} {
+		if chunks[hash].RefCount != want {
+			t.Errorf("chunk %s ref count %d, want %d", hash[:1], chunks[hash].RefCount, want)
+		}
+	}
+	if chunks[c].Size != 3 || chunks[c].Hash != c {
+		t.Fatalf("chunk %+v", chunks[c])
+	}
+
+	if hashes, chunks = chunkCounts(&storage.Manifest{}); len(hashes) != 0 || len(chunks) != 0 {
+		t.Fatalf("empty manifest: %v %v", hashes, chunks)
+	}
+}
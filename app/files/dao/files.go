@@ -6,6 +6,7 @@ import (
 	"gorm.io/gorm"
 	"grpc-todolist-disk/app/files/internal/repository/model"
 	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/storage"
 	"strings"
 	"time"
 )
@@ -20,40 +21,55 @@ func NewFilesDao() *FilesDao {
 	}
 }
 
-func (dao *FilesDao) CreateFile(req *pb.FileUploadRequest) (*model.Files, error) {
+// CreateFile 创建文件记录，info 为物理对象在本地磁盘上的保存方式
+func (dao *FilesDao) CreateFile(req *pb.FileUploadRequest, info storage.Info) (*model.Files, error) {
 	file := &model.Files{
 		Model:      gorm.Model{},
 		UserID:     uint(req.UserID),
+		FolderID:   uint(req.FolderID),
 		FileName:   req.Filename,
 		FileSize:   req.FileSize,
 		Bucket:     "local",
 		ObjectName: req.ObjectName,
 		FileHash:   req.FileHash,
+		Layout:     info.Layout,
+		Codec:      info.Codec,
+		StoredSize: info.StoredSize,
+		ScanStatus: InitialScanStatus(),
 	}
-	if err := dao.DB.Model(&model.Files{}).Create(&file).Error; err != nil {
+	if err := createFile(dao.DB, file); err != nil {
 		return nil, err
 	}
 	return file, nil
 }
 
-func (dao *FilesDao) CreateBigFile(req *pb.BigFileUploadRequest) (*model.Files, error) {
+func (dao *FilesDao) CreateBigFile(req *pb.BigFileUploadRequest, info storage.Info) (*model.Files, error) {
 	file := &model.Files{
 		Model:      gorm.Model{},
 		UserID:     uint(req.UserID),
+		FolderID:   uint(req.FolderID),
 		FileName:   req.Filename,
 		FileSize:   req.FileSize,
 		Bucket:     "local",
 		ObjectName: req.ObjectName,
 		FileHash:   req.FileHash,
+		Layout:     info.Layout,
+		Codec:      info.Codec,
+		StoredSize: info.StoredSize,
+		ScanStatus: InitialScanStatus(),
 	}
-	if err := dao.DB.Model(&model.Files{}).Create(&file).Error; err != nil {
+	if err := createFile(dao.DB, file); err != nil {
 		return nil, err
 	}
 	return file, nil
 }
 
+// ListFiles 列出文件：未指定文件夹时列出用户自己的全部文件，指定文件夹时列出该文件夹下的文件（权限由调用方校验）
 func (dao *FilesDao) ListFiles(req *pb.FileListRequest) (f []*model.Files, total int64, err error) {
 	query := dao.DB.Model(&model.Files{}).Where("user_id = ?", req.UserID)
+	if req.FolderID != 0 {
+		query = dao.DB.Model(&model.Files{}).Where("folder_id = ?", req.FolderID)
+	}
 	err = query.Count(&total).Error
 	if err != nil {
 		return
@@ -62,12 +78,68 @@ func (dao *FilesDao) ListFiles(req *pb.FileListRequest) (f []*model.Files, total
 	return
 }
 
+// DeleteFile 删除文件记录（权限由调用方通过 GetAccessibleFile 校验）
 func (dao *FilesDao) DeleteFile(req *pb.FileDeleteRequest) error {
-	return dao.DB.Model(&model.Files{}).Where("id = ? AND user_id = ?", req.FileID, req.UserID).Delete(&model.Files{}).Error
+	file, err := dao.GetFileByID(uint(req.FileID))
+	if err != nil {
+		return err
+	}
+	return deleteFile(dao.DB, file)
+}
+
+// deleteFile 删除文件记录、该文件的共享授权和历史版本，并写入变更日志
+func deleteFile(db *gorm.DB, file *model.Files) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// 先按删除前的可见范围写入变更
+		if err := recordFileChange(tx, model.ChangeDelete, file, nil); err != nil {
+			return err
+		}
+		if err := tx.Where("resource_type = ? AND resource_id = ?", model.ResourceTypeFile, file.ID).
+			Delete(&model.FileShare{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(file).Error; err != nil {
+			return err
+		}
+		return tx.Where("file_id = ?", file.ID).Delete(&model.FileVersion{}).Error
+	})
+}
+
+// GetAccessibleFile 查询文件并校验用户至少拥有 role 权限（所有者或共享授权），
+// 文件不存在返回 ErrFileNotFound，权限不足返回 ErrPermissionDenied
+func (dao *FilesDao) GetAccessibleFile(uID, fID uint, role string) (*model.Files, error) {
+	file, err := dao.GetFileByID(fID)
+	if err != nil {
+		return nil, err
+	}
+	if err = NewAclDao().CheckFile(uID, file, role); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// RenameFile 修改文件名
+func (dao *FilesDao) RenameFile(fID uint, name string) error {
+	return updateFile(dao.DB, fID, model.ChangeRename, map[string]interface{}{"file_name": name})
+}
+
+// MoveFile 移动文件到指定文件夹并修改文件名
+func (dao *FilesDao) MoveFile(fID, folderID uint, name string) error {
+	return updateFile(dao.DB, fID, model.ChangeMove, map[string]interface{}{"folder_id": folderID, "file_name": name})
 }
 
-func (dao *FilesDao) GetFileByUIDAndFID(uID, fID uint) (f *model.Files, err error) {
-	err = dao.DB.Model(&model.Files{}).Where("id = ? AND user_id = ?", fID, uID).First(&f).Error
+// CountObjectRefs 统计仍然引用某个物理对象的记录数（包括秒传生成的 shared_ 记录和历史版本），excludeFileID 为即将删除的记录
+func (dao *FilesDao) CountObjectRefs(objectName string, excludeFileID uint) (count int64, err error) {
+	err = dao.DB.Model(&model.Files{}).
+		Where("id <> ? AND (object_name = ? OR (file_hash LIKE 'shared_%' AND object_name LIKE ?))",
+			excludeFileID, objectName, "%_"+objectName).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return
+	}
+	err = dao.DB.Model(&model.FileVersion{}).
+		Where("object_name = ? OR (file_hash LIKE 'shared_%' AND object_name LIKE ?)", objectName, "%_"+objectName).
+		Count(&count).Error
 	return
 }
 
@@ -129,27 +201,35 @@ func (dao *FilesDao) FindGlobalByHash(fileHash string) (*model.Files, error) {
 	return &file, err
 }
 
-// CreateUserFileFromExisting 为用户创建基于已存在文件的新记录
-func (dao *FilesDao) CreateUserFileFromExisting(userID uint64, filename string, existingFile *model.Files) (*model.Files, error) {
-	// 为了避免 ObjectName 重复，我们在原有基础上添加用户ID和时间戳
-	// 但在实际应用中，我们知道这指向的是同一个物理文件
-	timestamp := time.Now().UnixMilli()
-	uniqueObjectName := fmt.Sprintf("shared_%d_%d_%s", userID, timestamp, existingFile.ObjectName)
-
-	// 为了避免 FileHash 重复（空字符串冲突），生成一个唯一的标识
-	// 格式：shared_用户ID_时间戳，这样确保每个用户的秒传记录都有唯一的hash标识
-	uniqueFileHash := fmt.Sprintf("shared_%d_%d", userID, timestamp)
+// CreateUserFileFromExistingInFolder 为用户在指定文件夹下创建基于已存在文件的新记录
+func (dao *FilesDao) CreateUserFileFromExistingInFolder(userID, folderID uint64, filename string, existingFile *model.Files) (*model.Files, error) {
+	// 已经是秒传记录时指向其原始对象，避免 shared_ 前缀层层嵌套
+	existingFile = &model.Files{
+		FileSize:   existingFile.FileSize,
+		Bucket:     existingFile.Bucket,
+		ObjectName: PhysicalObjectName(existingFile),
+		Layout:     existingFile.Layout,
+		Codec:      existingFile.Codec,
+		StoredSize: existingFile.StoredSize,
+		ScanStatus: existingFile.ScanStatus,
+	}
+	uniqueObjectName, uniqueFileHash := SharedNames(userID, existingFile.ObjectName)
 
 	userFile := &model.Files{
 		UserID:     uint(userID),
+		FolderID:   uint(folderID),
 		FileName:   filename,
 		FileSize:   existingFile.FileSize,
 		Bucket:     existingFile.Bucket,
 		ObjectName: uniqueObjectName, // 使用唯一的对象名
 		FileHash:   uniqueFileHash,   // 使用唯一的哈希标识
+		Layout:     existingFile.Layout,
+		Codec:      existingFile.Codec,
+		StoredSize: existingFile.StoredSize,
+		ScanStatus: existingFile.ScanStatus, // 同一物理对象共用扫描结果
 	}
 
-	if err := dao.DB.Create(&userFile).Error; err != nil {
+	if err := createFile(dao.DB, userFile); err != nil {
 		return nil, err
 	}
 	return userFile, nil
@@ -160,14 +240,16 @@ func (dao *FilesDao) CreateQiniuFile(req *pb.FileUploadRequest) (*model.Files, e
 	file := &model.Files{
 		Model:      gorm.Model{},
 		UserID:     uint(req.UserID),
+		FolderID:   uint(req.FolderID),
 		FileName:   req.Filename,
 		FileSize:   req.FileSize,
 		Bucket:     "qiniu",
 		ObjectName: req.ObjectName,
 		FileHash:   req.FileHash,
+		ScanStatus: InitialScanStatus(),
 	}
 
-	if err := dao.DB.Model(&model.Files{}).Create(&file).Error; err != nil {
+	if err := createFile(dao.DB, file); err != nil {
 		return nil, err
 	}
 	return file, nil
@@ -178,14 +260,16 @@ func (dao *FilesDao) CreateQiniuBigFile(req *pb.BigFileUploadRequest) (*model.Fi
 	file := &model.Files{
 		Model:      gorm.Model{},
 		UserID:     uint(req.UserID),
+		FolderID:   uint(req.FolderID),
 		FileName:   req.Filename,
 		FileSize:   req.FileSize,
 		Bucket:     "qiniu",
 		ObjectName: req.ObjectName,
 		FileHash:   req.FileHash,
+		ScanStatus: InitialScanStatus(),
 	}
 
-	if err := dao.DB.Model(&model.Files{}).Create(&file).Error; err != nil {
+	if err := createFile(dao.DB, file); err != nil {
 		return nil, err
 	}
 	return file, nil
@@ -227,15 +311,34 @@ func (dao *FilesDao) GlobalFileSearch(fileName string, page, pageSize uint32, bu
 func (dao *FilesDao) GetFileByID(fileID uint) (*model.Files, error) {
 	var file model.Files
 	err := dao.DB.Model(&model.Files{}).Where("id = ?", fileID).First(&file).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = ErrFileNotFound
+	}
 	return &file, err
 }
 
-// DeleteQiniuFile 删除七牛云文件记录
-func (dao *FilesDao) DeleteQiniuFile(userID, fileID uint) (*model.Files, error) {
+// FindByObjectName 按对象名查找记录，不存在时返回 nil
+func (dao *FilesDao) FindByObjectName(objectName string) (*model.Files, error) {
 	var file model.Files
+	err := dao.DB.Model(&model.Files{}).Where("object_name = ?", objectName).First(&file).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &file, err
+}
 
-	// 先查找文件
-	err := dao.DB.Model(&model.Files{}).Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error
+// SumUploadedSince 统计用户从 since 开始上传的文件总大小（包括之后删除的文件）
+func (dao *FilesDao) SumUploadedSince(userID uint, since time.Time) (total int64, err error) {
+	err = dao.DB.Unscoped().Model(&model.Files{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Select("COALESCE(SUM(file_size), 0)").Scan(&total).Error
+	return
+}
+
+// DeleteQiniuFile 删除七牛云文件记录
+func (dao *FilesDao) DeleteQiniuFile(userID, fileID uint) (*model.Files, error) {
+	// 先查找文件并校验删除权限
+	file, err := dao.GetAccessibleFile(userID, fileID, model.RoleEditor)
 	if err != nil {
 		return nil, err
 	}
@@ -246,26 +349,36 @@ func (dao *FilesDao) DeleteQiniuFile(userID, fileID uint) (*model.Files, error)
 	}
 
 	// 删除数据库记录
-	err = dao.DB.Delete(&file).Error
+	err = deleteFile(dao.DB, file)
 	if err != nil {
 		return nil, err
 	}
 
-	return &file, nil
+	return file, nil
 }
 
-// FindSameHashFiles 查找相同哈希的其他文件（用于判断是否需要删除物理文件）
-func (dao *FilesDao) FindSameHashFiles(fileHash string, excludeFileID uint) ([]*model.Files, error) {
-	var files []*model.Files
-
-	// 如果是秒传文件（FileHash以shared_开头），不需要删除物理文件
-	if strings.HasPrefix(fileHash, "shared_") {
-		return files, nil
-	}
+// SharedNames 生成指向已有物理对象的秒传记录所用的 ObjectName 和 FileHash
+func SharedNames(userID uint64, physicalObject string) (objectName, fileHash string) {
+	// 为了避免 ObjectName 重复，我们在原有基础上添加用户ID和时间戳
+	// 但在实际应用中，我们知道这指向的是同一个物理文件
+	timestamp := time.Now().UnixMilli()
+	objectName = fmt.Sprintf("shared_%d_%d_%s", userID, timestamp, physicalObject)
 
-	// 查找相同真实哈希的其他文件
-	err := dao.DB.Model(&model.Files{}).Where("file_hash = ? AND id != ? AND file_hash NOT LIKE 'shared_%'",
-		fileHash, excludeFileID).Find(&files).Error
+	// 为了避免 FileHash 重复（空字符串冲突），生成一个唯一的标识
+	// 格式：shared_用户ID_时间戳，这样确保每个用户的秒传记录都有唯一的hash标识
+	fileHash = fmt.Sprintf("shared_%d_%d", userID, timestamp)
+	return
+}
 
-	return files, err
+// PhysicalObjectName 返回记录实际指向的存储对象名：
+// 秒传记录的 ObjectName 格式为 shared_用户ID_时间戳_原始ObjectName，需要去掉前缀
+func PhysicalObjectName(file *model.Files) string {
+	if !strings.HasPrefix(file.FileHash, "shared_") || !strings.HasPrefix(file.ObjectName, "shared_") {
+		return file.ObjectName
+	}
+	parts := strings.SplitN(file.ObjectName, "_", 4)
+	if len(parts) != 4 {
+		return file.ObjectName // 降级处理
+	}
+	return parts[3]
 }
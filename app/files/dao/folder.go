@@ -0,0 +1,196 @@
+package dao
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	pb "grpc-todolist-disk/idl/pb/files"
+)
+
+type FolderDao struct {
+	*gorm.DB
+}
+
+func NewFolderDao() *FolderDao {
+	return &FolderDao{
+		NewDBClient(),
+	}
+}
+
+// CreateFolder 创建文件夹，在他人共享的文件夹下创建时所有者仍为创建者
+func (dao *FolderDao) CreateFolder(req *pb.FolderCreateRequest) (*model.Folder, error) {
+	folder := &model.Folder{
+		UserID:   uint(req.UserID),
+		ParentID: uint(req.ParentID),
+		Name:     req.Name,
+	}
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(folder).Error; err != nil {
+			return err
+		}
+		return recordFolderChange(tx, model.ChangeCreate, folder, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+func (dao *FolderDao) GetFolderByID(folderID uint) (*model.Folder, error) {
+	var folder model.Folder
+	err := dao.DB.Model(&model.Folder{}).Where("id = ?", folderID).First(&folder).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = ErrFolderNotFound
+	}
+	return &folder, err
+}
+
+// ListChildren 列出文件夹下的子文件夹和文件，folderID 为 0 时列出用户自己的根目录
+func (dao *FolderDao) ListChildren(userID, folderID uint) (folders []*model.Folder, files []*model.Files, err error) {
+	folderQuery := dao.DB.Model(&model.Folder{}).Where("parent_id = ?", folderID)
+	fileQuery := dao.DB.Model(&model.Files{}).Where("folder_id = ?", folderID)
+	if folderID == 0 {
+		folderQuery = folderQuery.Where("user_id = ?", userID)
+		fileQuery = fileQuery.Where("user_id = ?", userID)
+	}
+	if err = folderQuery.Order("name").Find(&folders).Error; err != nil {
+		return
+	}
+	err = fileQuery.Order("updated_at DESC").Find(&files).Error
+	return
+}
+
+// MoveFolder 移动/重命名文件夹
+func (dao *FolderDao) MoveFolder(folderID, parentID uint, name string) error {
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		var folder model.Folder
+		if err := tx.Where("id = ?", folderID).First(&folder).Error; err != nil {
+			return err
+		}
+		action := model.ChangeRename
+		var before []uint
+		if folder.ParentID != parentID {
+			action = model.ChangeMove
+			var err error
+			if before, err = folderAudience(tx, &folder); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&folder).Updates(map[string]interface{}{"parent_id": parentID, "name": name}).Error; err != nil {
+			return err
+		}
+		folder.ParentID, folder.Name = parentID, name
+		return recordFolderChange(tx, action, &folder, before)
+	})
+}
+
+// IsDescendant 判断 folderID 是否为 ancestorID 本身或其子孙文件夹
+func (dao *FolderDao) IsDescendant(folderID, ancestorID uint) (bool, error) {
+	chain, err := NewAclDao().folderChain(folderID)
+	if err != nil {
+		return false, err
+	}
+	for _, folder := range chain {
+		if folder.ID == ancestorID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CheckDepth 校验把高度为 height 的文件夹（新建文件夹为 1）放到 parentID 下后层数不超过上限
+func (dao *FolderDao) CheckDepth(parentID uint, height int) error {
+	chain, err := NewAclDao().folderChain(parentID)
+	if err != nil {
+		return err
+	}
+	if len(chain)+height > maxFolderDepth {
+		return ErrFolderTooDeep
+	}
+	return nil
+}
+
+// SubtreeIDs 返回 folderID 及其全部子孙文件夹的 ID
+func (dao *FolderDao) SubtreeIDs(folderID uint) ([]uint, error) {
+	ids, _, err := dao.subtree(folderID)
+	return ids, err
+}
+
+// SubtreeHeight 返回以 folderID 为根的目录树的层数
+func (dao *FolderDao) SubtreeHeight(folderID uint) (int, error) {
+	_, height, err := dao.subtree(folderID)
+	return height, err
+}
+
+// subtree 逐层遍历直到没有子文件夹，不受层数上限约束（历史数据可能超过上限），
+// 已访问过的文件夹不再展开，避免脏数据形成环时死循环
+func (dao *FolderDao) subtree(folderID uint) (ids []uint, height int, err error) {
+	visited := map[uint]bool{folderID: true}
+	ids = []uint{folderID}
+	frontier := []uint{folderID}
+	for len(frontier) > 0 {
+		height++
+		var children []uint
+		if err = dao.DB.Model(&model.Folder{}).Where("parent_id IN ?", frontier).Pluck("id", &children).Error; err != nil {
+			return nil, 0, err
+		}
+		frontier = frontier[:0]
+		for _, id := range children {
+			if !visited[id] {
+				visited[id] = true
+				ids = append(ids, id)
+				frontier = append(frontier, id)
+			}
+		}
+	}
+	return ids, height, nil
+}
+
+// ListFilesInFolders 列出若干文件夹中的全部文件
+func (dao *FolderDao) ListFilesInFolders(folderIDs []uint) (files []*model.Files, err error) {
+	err = dao.DB.Model(&model.Files{}).Where("folder_id IN ?", folderIDs).Find(&files).Error
+	return
+}
+
+// DeleteSubtree 删除文件夹及其中文件的记录，同时清理这些资源上的共享授权，并为每个资源写入删除变更
+func (dao *FolderDao) DeleteSubtree(folderIDs []uint, files []*model.Files) error {
+	fileIDs := make([]uint, 0, len(files))
+	for _, file := range files {
+		fileIDs = append(fileIDs, file.ID)
+	}
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		var folders []*model.Folder
+		if err := tx.Where("id IN ?", folderIDs).Find(&folders).Error; err != nil {
+			return err
+		}
+		// 先按删除前的可见范围写入变更，再删除授权和记录
+		for _, file := range files {
+			if err := recordFileChange(tx, model.ChangeDelete, file, nil); err != nil {
+				return err
+			}
+		}
+		for _, folder := range folders {
+			if err := recordFolderChange(tx, model.ChangeDelete, folder, nil); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("resource_type = ? AND resource_id IN ?", model.ResourceTypeFolder, folderIDs).
+			Delete(&model.FileShare{}).Error; err != nil {
+			return err
+		}
+		if len(fileIDs) > 0 {
+			if err := tx.Where("resource_type = ? AND resource_id IN ?", model.ResourceTypeFile, fileIDs).
+				Delete(&model.FileShare{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", fileIDs).Delete(&model.Files{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("file_id IN ?", fileIDs).Delete(&model.FileVersion{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("id IN ?", folderIDs).Delete(&model.Folder{}).Error
+	})
+}
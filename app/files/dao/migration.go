@@ -2,6 +2,7 @@ package dao
 
 import (
 	"grpc-todolist-disk/app/files/internal/repository/model"
+	"grpc-todolist-disk/utils/outbox"
 	"log"
 )
 
@@ -10,6 +11,15 @@ func migration() {
 	err := DB.Set("gorm:table_options", "charset=utf8mb4").
 		AutoMigrate(
 			&model.Files{},
+			&model.FileVersion{},
+			&model.Chunk{},
+			&model.Folder{},
+			&model.FileShare{},
+			&model.Group{},
+			&model.GroupMember{},
+			&model.Change{},
+			&model.ChangeSeq{},
+			&outbox.Message{},
 		)
 	if err != nil {
 		log.Println("register table failed")
@@ -0,0 +1,29 @@
+package dao
+
+import (
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	"grpc-todolist-disk/conf"
+)
+
+// InitialScanStatus 新写入的物理对象（本地或七牛云）的扫描状态，未开启扫描时为空
+func InitialScanStatus() string {
+	if conf.ScanEnabled() {
+		return model.ScanPending
+	}
+	return ""
+}
+
+// FindUnscanned 按 ID 顺序查找需要扫描的文件：待扫描、扫描失败以及开启扫描之前上传的文件
+func (dao *FilesDao) FindUnscanned(afterID uint, limit int) (files []*model.Files, err error) {
+	err = dao.DB.Model(&model.Files{}).
+		Where("id > ? AND scan_status IN ?", afterID, []string{"", model.ScanPending, model.ScanError}).
+		Order("id").Limit(limit).Find(&files).Error
+	return
+}
+
+// SetScanStatus 更新引用同一物理对象的所有记录（包括秒传记录）的扫描状态，不修改更新时间
+func (dao *FilesDao) SetScanStatus(objectName, status string) error {
+	return dao.DB.Model(&model.Files{}).
+		Where("object_name = ? OR (file_hash LIKE 'shared_%' AND object_name LIKE ?)", objectName, "%_"+objectName).
+		UpdateColumn("scan_status", status).Error
+}
@@ -0,0 +1,167 @@
+package dao
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	pb "grpc-todolist-disk/idl/pb/files"
+)
+
+type ShareDao struct {
+	*gorm.DB
+}
+
+func NewShareDao() *ShareDao {
+	return &ShareDao{
+		NewDBClient(),
+	}
+}
+
+// CreateShare 新增授权，同一资源对同一对象重复授权时更新角色；
+// 新获得访问权限的用户会在变更日志中看到该资源的创建
+func (dao *ShareDao) CreateShare(req *pb.ShareGrantRequest) (*model.FileShare, error) {
+	var share model.FileShare
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.FileShare{}).
+			Where("resource_type = ? AND resource_id = ? AND grantee_type = ? AND grantee_id = ?",
+				req.ResourceType, req.ResourceID, req.GranteeType, req.GranteeID).
+			First(&share).Error
+		if err == nil {
+			share.Role = req.Role
+			return tx.Save(&share).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		_, before, err := resourceAudience(tx, req.ResourceType, uint(req.ResourceID))
+		if err != nil {
+			return err
+		}
+		share = model.FileShare{
+			OwnerID:      uint(req.UserID),
+			ResourceType: req.ResourceType,
+			ResourceID:   uint(req.ResourceID),
+			GranteeType:  req.GranteeType,
+			GranteeID:    uint(req.GranteeID),
+			Role:         req.Role,
+		}
+		if err = tx.Model(&model.FileShare{}).Create(&share).Error; err != nil {
+			return err
+		}
+		return recordShareChange(tx, share.ResourceType, share.ResourceID, before)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+func (dao *ShareDao) GetShareByID(shareID uint) (*model.FileShare, error) {
+	var share model.FileShare
+	err := dao.DB.Model(&model.FileShare{}).Where("id = ?", shareID).First(&share).Error
+	return &share, err
+}
+
+// DeleteShare 删除授权，失去访问权限的用户会在变更日志中看到该资源的删除
+func (dao *ShareDao) DeleteShare(shareID uint) error {
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		var share model.FileShare
+		if err := tx.Where("id = ?", shareID).First(&share).Error; err != nil {
+			return err
+		}
+		_, before, err := resourceAudience(tx, share.ResourceType, share.ResourceID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err = tx.Delete(&share).Error; err != nil {
+			return err
+		}
+		if before == nil {
+			// 资源已不存在，无需记录变更
+			return nil
+		}
+		return recordShareChange(tx, share.ResourceType, share.ResourceID, before)
+	})
+}
+
+// ListByResource 列出某个资源上的全部授权
+func (dao *ShareDao) ListByResource(resourceType string, resourceID uint, page, pageSize int) (s []*model.FileShare, total int64, err error) {
+	query := dao.DB.Model(&model.FileShare{}).Where("resource_type = ? AND resource_id = ?", resourceType, resourceID)
+	if err = query.Count(&total).Error; err != nil {
+		return
+	}
+	err = query.Offset((page - 1) * pageSize).Limit(pageSize).Order("id DESC").Find(&s).Error
+	return
+}
+
+// ListSharedWith 列出直接授予用户或其所在用户组的授权（"共享给我的"）
+func (dao *ShareDao) ListSharedWith(userID uint, groupIDs []uint, page, pageSize int) (s []*model.FileShare, total int64, err error) {
+	grantee := dao.DB.Where("grantee_type = ? AND grantee_id = ?", model.GranteeTypeUser, userID)
+	if len(groupIDs) > 0 {
+		grantee = grantee.Or("grantee_type = ? AND grantee_id IN ?", model.GranteeTypeGroup, groupIDs)
+	}
+	// 不展示自己共享给自己所在用户组的资源
+	query := dao.DB.Model(&model.FileShare{}).Where(grantee).Where("owner_id <> ?", userID)
+	if err = query.Count(&total).Error; err != nil {
+		return
+	}
+	err = query.Offset((page - 1) * pageSize).Limit(pageSize).Order("id DESC").Find(&s).Error
+	return
+}
+
+// ResourceName 查询授权资源的名称，资源已被删除时返回空字符串
+func (dao *ShareDao) ResourceName(resourceType string, resourceID uint) string {
+	var names []string
+	switch resourceType {
+	case model.ResourceTypeFile:
+		dao.DB.Model(&model.Files{}).Where("id = ?", resourceID).Limit(1).Pluck("file_name", &names)
+	case model.ResourceTypeFolder:
+		dao.DB.Model(&model.Folder{}).Where("id = ?", resourceID).Limit(1).Pluck("name", &names)
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+func (dao *ShareDao) CreateGroup(req *pb.GroupCreateRequest) (*model.Group, error) {
+	group := &model.Group{
+		OwnerID: uint(req.UserID),
+		Name:    req.Name,
+	}
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&group).Error; err != nil {
+			return err
+		}
+		// 创建者默认是组成员
+		return tx.Create(&model.GroupMember{GroupID: group.ID, UserID: group.OwnerID}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (dao *ShareDao) GetGroupByID(groupID uint) (*model.Group, error) {
+	var group model.Group
+	err := dao.DB.Model(&model.Group{}).Where("id = ?", groupID).First(&group).Error
+	return &group, err
+}
+
+// AddGroupMember 添加组成员，已是成员时忽略
+func (dao *ShareDao) AddGroupMember(groupID, userID uint) error {
+	var count int64
+	if err := dao.DB.Model(&model.GroupMember{}).Where("group_id = ? AND user_id = ?", groupID, userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return dao.DB.Create(&model.GroupMember{GroupID: groupID, UserID: userID}).Error
+}
+
+// RemoveGroupMember 移除组成员（物理删除，便于之后重新加入）
+func (dao *ShareDao) RemoveGroupMember(groupID, userID uint) error {
+	return dao.DB.Unscoped().Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&model.GroupMember{}).Error
+}
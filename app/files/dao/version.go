@@ -0,0 +1,74 @@
+package dao
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	"grpc-todolist-disk/utils/storage"
+)
+
+// ErrVersionConflict 文件在计算增量之后已被修改
+var ErrVersionConflict = errors.New("文件版本已变化，请重新获取签名")
+
+// CurrentVersion 兼容迁移前的记录（版本号为 0 视为 1）
+func CurrentVersion(file *model.Files) uint {
+	if file.Version == 0 {
+		return 1
+	}
+	return file.Version
+}
+
+// ReplaceContent 将文件内容替换为新对象：旧内容保存为历史版本，版本号加一并写入变更日志。
+// baseVersion 与当前版本不一致时返回 ErrVersionConflict，scanStatus 为新内容的扫描状态
+func (dao *FilesDao) ReplaceContent(file *model.Files, baseVersion uint, objectName, fileHash string, fileSize int64, info storage.Info, scanStatus string) error {
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model.FileVersion{
+			FileID:     file.ID,
+			Version:    CurrentVersion(file),
+			ObjectName: file.ObjectName,
+			FileHash:   file.FileHash,
+			FileSize:   file.FileSize,
+			Layout:     file.Layout,
+			Codec:      file.Codec,
+			StoredSize: file.StoredSize,
+		}).Error; err != nil {
+			return err
+		}
+		// 旧的真实哈希让给历史版本，避免与 FileHash 唯一索引冲突
+		versions := []uint{baseVersion}
+		if baseVersion == 1 {
+			versions = append(versions, 0)
+		}
+		result := tx.Model(&model.Files{}).
+			Where("id = ? AND version IN ?", file.ID, versions).
+			Updates(map[string]interface{}{
+				"object_name": objectName,
+				"file_hash":   fileHash,
+				"file_size":   fileSize,
+				"version":     baseVersion + 1,
+				"layout":      info.Layout,
+				"codec":       info.Codec,
+				"stored_size": info.StoredSize,
+				"scan_status": scanStatus,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		file.ObjectName, file.FileHash, file.FileSize, file.Version = objectName, fileHash, fileSize, baseVersion+1
+		file.Layout, file.Codec, file.StoredSize = info.Layout, info.Codec, info.StoredSize
+		file.ScanStatus = scanStatus
+		return recordFileChange(tx, model.ChangeUpdate, file, nil)
+	})
+}
+
+// ListVersions 查询若干文件的全部历史版本
+func (dao *FilesDao) ListVersions(fileIDs []uint) (versions []*model.FileVersion, err error) {
+	if len(fileIDs) == 0 {
+		return
+	}
+	err = dao.DB.Model(&model.FileVersion{}).Where("file_id IN ?", fileIDs).Find(&versions).Error
+	return
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/segmentio/kafka-go"
 	"grpc-todolist-disk/app/files/dao"
 	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/storage"
 	"log"
 	"os"
 	"path/filepath"
@@ -17,7 +18,8 @@ type AsyncFileUploadMsg struct {
 	FileSize   int64  `json:"file_size"`
 	FileHash   string `json:"file_hash"`
 	ObjectName string `json:"object_name"`
-	Content    []byte `json:"content"` // 小文件内容
+	Content    []byte `json:"content"`   // 旧版本网关直接携带文件内容
+	TempPath   string `json:"temp_path"` // 网关写入的暂存路径
 }
 
 // HandleAsyncFileUpload 异步启动上传文件的消费者
@@ -29,11 +31,23 @@ func HandleAsyncFileUpload(msg *kafka.Message) error {
 
 	log.Println("开始异步处理文件：", m.Filename)
 
+	// 消息重复消费时记录已存在，不再重复写入
+	if file, err := dao.NewFilesDao().FindByObjectName(m.ObjectName); err != nil {
+		return fmt.Errorf("查询文件记录失败: %w", err)
+	} else if file != nil {
+		log.Println("文件已处理: ", m.Filename)
+		return nil
+	}
+
 	savePath := filepath.Join("stores/uploaded_files", m.ObjectName)
 	if err := os.MkdirAll(filepath.Dir(savePath), os.ModePerm); err != nil {
 		return fmt.Errorf("创建目录失败: %w", err)
 	}
-	if err := os.WriteFile(savePath, m.Content, 0644); err != nil {
+	if m.TempPath != "" {
+		if err := os.Rename(m.TempPath, savePath); err != nil {
+			return fmt.Errorf("移动文件失败: %w", err)
+		}
+	} else if err := os.WriteFile(savePath, m.Content, 0644); err != nil {
 		return fmt.Errorf("写入文件失败: %w", err)
 	}
 
@@ -44,7 +58,7 @@ func HandleAsyncFileUpload(msg *kafka.Message) error {
 		FileSize:   m.FileSize,
 		ObjectName: m.ObjectName,
 		FileHash:   m.FileHash,
-	}); err != nil {
+	}, storage.Info{}); err != nil {
 		return fmt.Errorf("数据库写入失败: %w", err)
 	}
 
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update" // 内容变化（例如增量同步生成新版本）
+	ChangeRename = "rename"
+	ChangeMove   = "move"
+	ChangeDelete = "delete"
+)
+
+// Change 变更日志，为能看到资源的每个用户（目录树所有者和被授权用户）各记录一条，
+// UserID 为日志所属用户，Seq 在同一用户内严格递增，作为同步客户端的游标
+type Change struct {
+	ID           uint   `gorm:"primarykey"`
+	UserID       uint   `gorm:"uniqueIndex:idx_change_user_seq"`
+	Seq          uint64 `gorm:"uniqueIndex:idx_change_user_seq"`
+	Action       string `gorm:"type:varchar(16)"`
+	ResourceType string `gorm:"type:varchar(16)"`
+	ResourceID   uint
+	ParentID     uint   // 变更后所在的文件夹
+	Name         string `gorm:"type:varchar(255)"`
+	Size         int64
+	CreatedAt    time.Time
+}
+
+// ChangeSeq 每个用户当前的变更序号
+type ChangeSeq struct {
+	UserID uint `gorm:"primarykey;autoIncrement:false"`
+	Seq    uint64
+}
@@ -1,13 +1,51 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 扫描状态，空表示未扫描（开启扫描之前上传的文件）
+const (
+	ScanPending  = "pending"
+	ScanClean    = "clean"
+	ScanInfected = "infected" // 本地文件已移入隔离目录，七牛云文件只拒绝下载
+	ScanError    = "error"    // 扫描失败，稍后重试
+)
 
 type Files struct {
 	gorm.Model
 	UserID     uint   `gorm:"index"`
+	FolderID   uint   `gorm:"index"` // 所在文件夹，0 表示根目录
 	FileName   string `gorm:"type:varchar(255)"`
 	FileSize   int64
 	Bucket     string `gorm:"type:varchar(64)"`              // 存储桶名称（如 MinIO 的 bucket）
 	ObjectName string `gorm:"type:varchar(255);unique"`      // 存储对象名（唯一标识）
 	FileHash   string `gorm:"type:varchar(255);uniqueIndex"` // 计算出来的哈希值（防止重复上传）
+	Version    uint   `gorm:"default:1"`                     // 内容版本号，增量同步生成新版本时递增
+	Layout     string `gorm:"type:varchar(16)"`              // 存储布局，空为普通文件，chunked 为分块清单
+	Codec      string `gorm:"type:varchar(16)"`              // 压缩格式，空为未压缩，zstd 为可随机访问的 zstd
+	StoredSize int64  // 压缩后的大小，0 表示未记录（与 FileSize 相同）
+	ScanStatus string `gorm:"type:varchar(16);index"` // 恶意文件扫描状态
+}
+
+// FileVersion 文件的历史版本，保存被替换下来的内容
+type FileVersion struct {
+	gorm.Model
+	FileID     uint `gorm:"index"`
+	Version    uint
+	ObjectName string `gorm:"type:varchar(255)"`
+	FileHash   string `gorm:"type:varchar(255)"`
+	FileSize   int64
+	Layout     string `gorm:"type:varchar(16)"`
+	Codec      string `gorm:"type:varchar(16)"`
+	StoredSize int64
+}
+
+// Chunk 内容定义分块，相同内容只存一份，RefCount 为引用该分块的清单数量（同一清单内重复出现按次数计）
+type Chunk struct {
+	Hash      string `gorm:"type:varchar(64);primarykey"`
+	Size      int64
+	RefCount  int64
+	CreatedAt time.Time
 }
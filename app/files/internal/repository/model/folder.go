@@ -0,0 +1,11 @@
+package model
+
+import "gorm.io/gorm"
+
+// Folder 文件夹，ParentID 为 0 表示位于根目录
+type Folder struct {
+	gorm.Model
+	UserID   uint   `gorm:"index"`
+	ParentID uint   `gorm:"index"`
+	Name     string `gorm:"type:varchar(255)"`
+}
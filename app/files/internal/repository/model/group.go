@@ -0,0 +1,17 @@
+package model
+
+import "gorm.io/gorm"
+
+// Group 用户组，可作为共享对象
+type Group struct {
+	gorm.Model
+	OwnerID uint   `gorm:"index"`
+	Name    string `gorm:"type:varchar(100)"`
+}
+
+// GroupMember 用户组成员
+type GroupMember struct {
+	gorm.Model
+	GroupID uint `gorm:"uniqueIndex:idx_group_member"`
+	UserID  uint `gorm:"uniqueIndex:idx_group_member"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import "gorm.io/gorm"
+
+const (
+	ResourceTypeFile   = "file"
+	ResourceTypeFolder = "folder"
+
+	GranteeTypeUser  = "user"
+	GranteeTypeGroup = "group"
+
+	RoleViewer = "viewer" // 可查看、下载
+	RoleEditor = "editor" // 可上传、重命名、删除
+	RoleOwner  = "owner"  // 可再次共享、撤销授权
+)
+
+// roleLevels 角色等级，数值越大权限越高
+var roleLevels = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// RoleLevel 返回角色等级，未知角色返回 0
+func RoleLevel(role string) int {
+	return roleLevels[role]
+}
+
+// FileShare 文件/文件夹的共享授权记录，文件夹上的授权对其下所有子文件夹和文件生效
+type FileShare struct {
+	gorm.Model
+	OwnerID      uint   `gorm:"index"` // 发起共享的用户
+	ResourceType string `gorm:"type:varchar(16);index:idx_share_resource"`
+	ResourceID   uint   `gorm:"index:idx_share_resource"`
+	GranteeType  string `gorm:"type:varchar(16);index:idx_share_grantee"`
+	GranteeID    uint   `gorm:"index:idx_share_grantee"`
+	Role         string `gorm:"type:varchar(16)"`
+}
@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"path/filepath"
+	"regexp"
+)
+
+var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// Clean 生成对象名用的文件名：去掉路径，只保留数字、字母、下划线、点、破折号（与网关的规则一致）
+func Clean(filename string) string {
+	safe := unsafeChars.ReplaceAllString(filepath.Base(filename), "_")
+	if len(safe) > 128 {
+		safe = safe[:128]
+	}
+	return safe
+}
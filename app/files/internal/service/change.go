@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"grpc-todolist-disk/app/files/dao"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+	"time"
+)
+
+// watchInterval WatchChanges 轮询变更日志的间隔
+const watchInterval = time.Second
+
+// listChanges 查询游标之后的一批变更
+func listChanges(req *pb.ChangeListRequest) (*pb.ChangeListResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	// 多查一条用于判断是否还有更多
+	changes, err := dao.NewChangeDao().ListChanges(uint(req.UserID), req.Cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ChangeListResponse{Code: e.SUCCESS, Cursor: req.Cursor}
+	if len(changes) > limit {
+		changes = changes[:limit]
+		resp.HasMore = true
+	}
+	for _, change := range changes {
+		resp.Changes = append(resp.Changes, &pb.ChangeModel{
+			Seq:          change.Seq,
+			Action:       change.Action,
+			ResourceType: change.ResourceType,
+			ResourceID:   uint64(change.ResourceID),
+			ParentID:     uint64(change.ParentID),
+			Name:         change.Name,
+			Size:         change.Size,
+			CreatedAt:    change.CreatedAt.Unix(),
+		})
+		resp.Cursor = change.Seq
+	}
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// ListChanges 增量拉取变更日志
+func (*FilesSrv) ListChanges(ctx context.Context, req *pb.ChangeListRequest) (resp *pb.ChangeListResponse, err error) {
+	resp, err = listChanges(req)
+	if err != nil {
+		return &pb.ChangeListResponse{Code: e.ERROR, Msg: "查询变更失败: " + err.Error(), Cursor: req.Cursor}, nil
+	}
+	return resp, nil
+}
+
+// WatchChanges 持续推送游标之后的变更，直到客户端断开
+func (*FilesSrv) WatchChanges(req *pb.ChangeListRequest, stream pb.FilesService_WatchChangesServer) error {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		resp, err := listChanges(req)
+		if err != nil {
+			return stream.Send(&pb.ChangeListResponse{Code: e.ERROR, Msg: "查询变更失败: " + err.Error(), Cursor: req.Cursor})
+		}
+		if len(resp.Changes) > 0 {
+			if err = stream.Send(resp); err != nil {
+				return err
+			}
+			req.Cursor = resp.Cursor
+			if resp.HasMore {
+				continue
+			}
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"grpc-todolist-disk/app/files/dao"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	"grpc-todolist-disk/app/files/internal/repository/utils"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/delta"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/policy"
+	"grpc-todolist-disk/utils/storage"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// signatureBatch 每条签名消息携带的块数
+const signatureBatch = 1024
+
+// DeltaSignature 返回文件当前版本的块签名（分批流式返回），供客户端计算增量
+func (*FilesSrv) DeltaSignature(req *pb.DeltaSignatureRequest, stream pb.FilesService_DeltaSignatureServer) error {
+	fail := func(code int64, msg string) error {
+		return stream.Send(&pb.DeltaSignatureResponse{Code: code, Msg: msg, FileID: req.FileID})
+	}
+	file, err := dao.NewFilesDao().GetAccessibleFile(uint(req.UserID), uint(req.FileID), model.RoleViewer)
+	if err != nil {
+		return fail(aclErrCode(err))
+	}
+	if file.Bucket == "qiniu" {
+		return fail(e.ERROR, "七牛云文件不支持增量同步")
+	}
+	if code := scanErrCode(file); code != e.SUCCESS {
+		return fail(code, e.GetMsg(int(code)))
+	}
+	blockSize, err := delta.ValidBlockSize(int(req.BlockSize))
+	if err != nil {
+		return fail(e.InvalidParams, err.Error())
+	}
+	base, err := storage.OpenContent(filepath.Join("stores/uploaded_files", dao.PhysicalObjectName(file)), file.Layout, file.Codec)
+	if err != nil {
+		return fail(e.ERROR, "打开文件失败: "+err.Error())
+	}
+	defer base.Close()
+
+	resp := &pb.DeltaSignatureResponse{
+		Code:      e.SUCCESS,
+		Msg:       e.GetMsg(e.SUCCESS),
+		FileID:    uint64(file.ID),
+		Version:   uint64(dao.CurrentVersion(file)),
+		FileSize:  file.FileSize,
+		BlockSize: int32(blockSize),
+	}
+	hashes := sha256.New()
+	err = delta.Sign(io.TeeReader(base, hashes), blockSize, func(sig delta.Signature) error {
+		resp.Blocks = append(resp.Blocks, &pb.BlockSignature{
+			Index:  sig.Index,
+			Weak:   sig.Weak,
+			Strong: sig.Strong,
+			Length: int32(sig.Length),
+		})
+		if len(resp.Blocks) < signatureBatch {
+			return nil
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+		resp.Blocks = nil
+		return nil
+	})
+	if err != nil {
+		return fail(e.ERROR, "计算签名失败: "+err.Error())
+	}
+	// 最后一批带上整个文件的 SHA-256，客户端可以据此判断内容是否变化
+	resp.FileHash = hex.EncodeToString(hashes.Sum(nil))
+	return stream.Send(resp)
+}
+
+// DeltaUpload 接收增量操作，基于当前版本重建新内容，校验 SHA-256 后保存为新版本
+func (*FilesSrv) DeltaUpload(stream pb.FilesService_DeltaUploadServer) error {
+	fail := func(code int64, msg string) error {
+		return stream.SendAndClose(&pb.BigFileUploadResponse{Code: code, Msg: msg})
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return fail(e.ERROR, "接收上传流失败: "+err.Error())
+	}
+	filesDao := dao.NewFilesDao()
+	file, err := filesDao.GetAccessibleFile(uint(first.UserID), uint(first.FileID), model.RoleEditor)
+	if err != nil {
+		return fail(aclErrCode(err))
+	}
+	if file.Bucket == "qiniu" {
+		return fail(e.ERROR, "七牛云文件不支持增量同步")
+	}
+	// 感染的文件已被隔离，不能作为增量的基础
+	if file.ScanStatus == model.ScanInfected {
+		return fail(e.ErrorFileInfected, e.GetMsg(e.ErrorFileInfected))
+	}
+	if uint64(dao.CurrentVersion(file)) != first.BaseVersion {
+		return fail(e.ERROR, dao.ErrVersionConflict.Error())
+	}
+	blockSize, err := delta.ValidBlockSize(int(first.BlockSize))
+	if err != nil {
+		return fail(e.InvalidParams, err.Error())
+	}
+
+	base, err := storage.OpenContent(filepath.Join("stores/uploaded_files", dao.PhysicalObjectName(file)), file.Layout, file.Codec)
+	if err != nil {
+		return fail(e.ERROR, "打开文件失败: "+err.Error())
+	}
+	defer base.Close()
+
+	// 新版本先写入临时目录
+	newVersion := first.BaseVersion + 1
+	objectName := fmt.Sprintf("%d/%d_v%d%s", file.UserID, time.Now().UnixMilli(), newVersion, filepath.Ext(file.FileName))
+	tempPath := filepath.Join("stores/uploaded_temp", objectName)
+	if err = os.MkdirAll(filepath.Dir(tempPath), os.ModePerm); err != nil {
+		return fail(e.ERROR, "创建目录失败: "+err.Error())
+	}
+	out, err := os.Create(tempPath)
+	if err != nil {
+		return fail(e.ERROR, "创建文件失败: "+err.Error())
+	}
+	hashes := sha256.New()
+	counter := &countWriter{}
+	w := io.MultiWriter(out, hashes, counter)
+
+	for req := first; ; {
+		for _, op := range req.Ops {
+			err = delta.Apply(base, base.Size(), blockSize, delta.Op{
+				BlockIndex: op.BlockIndex,
+				BlockCount: op.BlockCount,
+				Data:       op.Data,
+			}, w)
+			if err != nil {
+				out.Close()
+				utils.SafeRemove(tempPath)
+				return fail(e.InvalidParams, "应用增量失败: "+err.Error())
+			}
+		}
+		if rej := policy.Default().CheckSize(policy.RouteDeltaUpload, first.UserID, counter.n); rej != nil {
+			out.Close()
+			utils.SafeRemove(tempPath)
+			resp := &pb.BigFileUploadResponse{}
+			resp.Code, resp.Msg, resp.Rejection = rejectionPb(rej)
+			return stream.SendAndClose(resp)
+		}
+		req, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			out.Close()
+			utils.SafeRemove(tempPath)
+			return fail(e.ERROR, "接收上传流失败: "+err.Error())
+		}
+	}
+	if err = out.Close(); err != nil {
+		utils.SafeRemove(tempPath)
+		return fail(e.ERROR, "文件关闭失败: "+err.Error())
+	}
+
+	fileHash := hex.EncodeToString(hashes.Sum(nil))
+	if first.ExpectedHash != "" && first.ExpectedHash != fileHash {
+		utils.SafeRemove(tempPath)
+		return fail(e.ERROR, "重建后的文件哈希不一致")
+	}
+
+	// 新内容已存在时直接指向已有对象，与秒传一致
+	exist, err := filesDao.FindGlobalByHash(fileHash)
+	if err != nil {
+		utils.SafeRemove(tempPath)
+		return fail(e.ERROR, "检查文件 Hash 失败: "+err.Error())
+	}
+	if exist != nil && exist.ID == file.ID {
+		utils.SafeRemove(tempPath)
+		return stream.SendAndClose(&pb.BigFileUploadResponse{
+			Code:      e.SUCCESS,
+			Msg:       "文件内容未变化",
+			FileID:    uint64(file.ID),
+			ObjectUrl: filepath.Join("stores/uploaded_files", dao.PhysicalObjectName(file)),
+		})
+	}
+	finalPath := filepath.Join("stores/uploaded_files", objectName)
+	var info storage.Info
+	scanStatus := dao.InitialScanStatus()
+	if exist != nil {
+		utils.SafeRemove(tempPath)
+		physical := dao.PhysicalObjectName(exist)
+		finalPath = filepath.Join("stores/uploaded_files", physical)
+		objectName, fileHash = dao.SharedNames(uint64(file.UserID), physical)
+		info = storage.Info{Layout: exist.Layout, Codec: exist.Codec, StoredSize: exist.StoredSize}
+		scanStatus = exist.ScanStatus
+	} else if info, err = storeObject(tempPath, finalPath, file.FileName); err != nil {
+		utils.SafeRemove(tempPath)
+		return fail(e.ERROR, "移动文件失败: "+err.Error())
+	}
+
+	if err = filesDao.ReplaceContent(file, uint(first.BaseVersion), objectName, fileHash, counter.n, info, scanStatus); err != nil {
+		if exist == nil {
+			if err := releaseObject(finalPath, info.Layout); err != nil {
+				zap.L().Warn("清理新版本文件失败", zap.String("path", finalPath), zap.Error(err))
+			}
+		}
+		if errors.Is(err, dao.ErrVersionConflict) {
+			return fail(e.ERROR, err.Error())
+		}
+		return fail(e.ERROR, "保存新版本失败: "+err.Error())
+	}
+	enqueueScan(file)
+
+	zap.L().Info("Delta upload", zap.Uint64("user_id", first.UserID), zap.Uint64("file_id", first.FileID),
+		zap.Uint64("version", newVersion), zap.Int64("size", counter.n))
+	return stream.SendAndClose(&pb.BigFileUploadResponse{
+		Code:      e.SUCCESS,
+		Msg:       e.GetMsg(e.SUCCESS),
+		FileID:    uint64(file.ID),
+		ObjectUrl: finalPath,
+	})
+}
+
+// countWriter 统计写入的字节数
+type countWriter struct {
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
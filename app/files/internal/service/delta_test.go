@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"grpc-todolist-disk/app/files/dao"
+	"grpc-todolist-disk/conf"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/delta"
+	"grpc-todolist-disk/utils/e"
+)
+
+// fakeDeltaStream 依次返回 reqs，SendAndClose 的响应保存在 resp 中
+type fakeDeltaStream struct {
+	grpc.ServerStream
+	reqs []*pb.DeltaUploadRequest
+	resp *pb.BigFileUploadResponse
+}
+
+func (s *fakeDeltaStream) Recv() (*pb.DeltaUploadRequest, error) {
+	if len(s.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return req, nil
+}
+
+func (s *fakeDeltaStream) SendAndClose(resp *pb.BigFileUploadResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func (s *fakeDeltaStream) Context() context.Context { return context.Background() }
+
+// deltaOps 计算把 base 变为 target 的增量操作
+func deltaOps(t *testing.T, base, target []byte, blockSize int) []*pb.DeltaOp {
+	t.Helper()
+	var sigs []delta.Signature
+	if err := delta.Sign(bytes.NewReader(base), blockSize, func(s delta.Signature) error {
+		sigs = append(sigs, s)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var ops []*pb.DeltaOp
+	if err := delta.Diff(sigs, blockSize, bytes.NewReader(target), func(op delta.Op) error {
+		ops = append(ops, &pb.DeltaOp{BlockIndex: op.BlockIndex, BlockCount: op.BlockCount, Data: op.Data})
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return ops
+}
+
+// TestDeltaUpload 基础版本过期时拒绝；增量上传后旧内容保存为历史版本，当前内容为重建后的内容
+func TestDeltaUpload(t *testing.T) {
+	testDB(t)
+	chdirTemp(t)
+	withConf(t, &conf.Config{})
+
+	srv := GetFilesSrv()
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	userID := uint64(suffix%1_000_000_000 + 1_000_000)
+	const blockSize = delta.MinBlockSize
+
+	v1 := bytes.Repeat([]byte(fmt.Sprintf("delta test %d\n", suffix)), 4*blockSize/16)
+	v2 := append(append([]byte{}, v1[:blockSize]...), append([]byte("inserted"), v1[blockSize:]...)...)
+	objectName := fmt.Sprintf("delta-test/%d-a.txt", suffix)
+	writeObject(t, objectName, string(v1))
+	up, err := srv.FileUpload(ctx, &pb.FileUploadRequest{
+		UserID: userID, Filename: "a.txt", FileSize: int64(len(v1)), ObjectName: objectName,
+		FileHash: fmt.Sprintf("delta-test-%d", suffix),
+	})
+	if err != nil || up.Code != e.SUCCESS {
+		t.Fatalf("upload: resp=%v err=%v", up, err)
+	}
+
+	upload := func(baseVersion uint64) *pb.BigFileUploadResponse {
+		stream := &fakeDeltaStream{reqs: []*pb.DeltaUploadRequest{{
+			UserID: userID, FileID: up.FileID, BaseVersion: baseVersion, BlockSize: blockSize,
+			Ops: deltaOps(t, v1, v2, blockSize),
+		}}}
+		if err := srv.DeltaUpload(stream); err != nil {
+			t.Fatal(err)
+		}
+		return stream.resp
+	}
+
+	// 客户端基于过期的版本计算增量
+	if resp := upload(2); resp.Code == e.SUCCESS || resp.Msg != dao.ErrVersionConflict.Error() {
+		t.Fatalf("stale base version: %v", resp)
+	}
+	file, err := dao.NewFilesDao().GetFileByID(uint(up.FileID))
+	if err != nil || dao.CurrentVersion(file) != 1 || file.ObjectName != objectName {
+		t.Fatalf("file changed by a rejected upload: %+v err %v", file, err)
+	}
+
+	if resp := upload(1); resp.Code != e.SUCCESS {
+		t.Fatalf("delta upload: %v", resp)
+	}
+	if file, err = dao.NewFilesDao().GetFileByID(uint(up.FileID)); err != nil {
+		t.Fatal(err)
+	}
+	if dao.CurrentVersion(file) != 2 || file.FileSize != int64(len(v2)) {
+		t.Fatalf("after delta upload: %+v", file)
+	}
+	data, err := os.ReadFile(filepath.Join("stores/uploaded_files", dao.PhysicalObjectName(file)))
+	if err != nil || !bytes.Equal(data, v2) {
+		t.Fatalf("new content %d bytes err %v, want %d bytes", len(data), err, len(v2))
+	}
+
+	versions, err := dao.NewFilesDao().ListVersions([]uint{file.ID})
+	if err != nil || len(versions) != 1 {
+		t.Fatalf("versions %v err %v", versions, err)
+	}
+	if v := versions[0]; v.Version != 1 || v.ObjectName != objectName || v.FileSize != int64(len(v1)) {
+		t.Fatalf("previous version %+v", v)
+	}
+	if data, err = os.ReadFile(filepath.Join("stores/uploaded_files", objectName)); err != nil || !bytes.Equal(data, v1) {
+		t.Fatalf("previous content %d bytes err %v", len(data), err)
+	}
+}
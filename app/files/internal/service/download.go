@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"grpc-todolist-disk/app/files/dao"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	"grpc-todolist-disk/conf"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/signurl"
+	"time"
+)
+
+const (
+	defaultURLExpire    = 3600
+	defaultURLMaxExpire = 7 * 24 * 3600
+)
+
+// urlExpire 按配置限制有效期
+func urlExpire(c *conf.Download, expires int64) int64 {
+	def, limit := int64(defaultURLExpire), int64(defaultURLMaxExpire)
+	if c.Expire > 0 {
+		def = int64(c.Expire)
+	}
+	if c.MaxExpire > 0 {
+		limit = int64(c.MaxExpire)
+	}
+	if expires <= 0 {
+		expires = def
+	}
+	return min(expires, limit)
+}
+
+// PresignDownload 为有读取权限的文件签发有时效的下载链接，本地和七牛云文件都通过网关的签名路由访问
+func (*FilesSrv) PresignDownload(ctx context.Context, req *pb.PresignDownloadRequest) (resp *pb.PresignDownloadResponse, err error) {
+	resp = new(pb.PresignDownloadResponse)
+	resp.Code = e.SUCCESS
+	c := conf.Conf.Download
+	if c == nil || c.SignSecret == "" {
+		resp.Code = e.ERROR
+		resp.Msg = "未配置下载签名密钥"
+		return resp, nil
+	}
+	file, err := dao.NewFilesDao().GetAccessibleFile(uint(req.UserID), uint(req.FileID), model.RoleViewer)
+	if err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	if code := scanErrCode(file); code != e.SUCCESS {
+		resp.Code, resp.Msg = code, e.GetMsg(int(code))
+		return resp, nil
+	}
+	resp.ExpiresAt = time.Now().Unix() + urlExpire(c, req.Expires)
+	resp.Url = signurl.URL([]byte(c.SignSecret), c.BaseURL, signurl.Params{
+		FileID:     uint64(file.ID),
+		UserID:     req.UserID,
+		Expires:    resp.ExpiresAt,
+		IP:         req.BindIP,
+		Attachment: req.Attachment,
+	})
+	resp.Filename = file.FileName
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
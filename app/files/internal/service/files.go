@@ -12,12 +12,13 @@ import (
 	"grpc-todolist-disk/app/files/internal/repository/utils"
 	pb "grpc-todolist-disk/idl/pb/files"
 	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/policy"
 	"grpc-todolist-disk/utils/qiniu"
+	"grpc-todolist-disk/utils/storage"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 )
 
@@ -40,12 +41,38 @@ func (*FilesSrv) FileUpload(ctx context.Context, req *pb.FileUploadRequest) (res
 	resp = new(pb.FileUploadResponse)
 	resp.Code = e.SUCCESS
 	resp.ObjectUrl = filepath.Join("stores/uploaded_files", req.ObjectName)
-	file, err := dao.NewFilesDao().CreateFile(req)
+	if err = checkFolderWritable(req.UserID, req.FolderID); err != nil {
+		// 网关已写入文件，拒绝时同样需要清理
+		utils.SafeRemove(resp.ObjectUrl)
+		resp.ObjectUrl = ""
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	if rej := checkUploadedFile(policy.RouteFileUpload, req.UserID, req.Filename, resp.ObjectUrl); rej != nil {
+		utils.SafeRemove(resp.ObjectUrl)
+		resp.ObjectUrl = ""
+		resp.Code, resp.Msg, resp.Rejection = rejectionPb(rej)
+		return resp, nil
+	}
+	// 网关已将文件写入正式路径，需要压缩或分块时原地转换
+	info, err := storeObject(resp.ObjectUrl, resp.ObjectUrl, req.Filename)
 	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "保存文件失败: " + err.Error()
+		return resp, nil
+	}
+	file, err := dao.NewFilesDao().CreateFile(req, info)
+	if err != nil {
+		if info.Layout == storage.LayoutChunked {
+			if err := releaseObject(resp.ObjectUrl, info.Layout); err != nil {
+				zap.L().Warn("清理分块文件失败", zap.String("path", resp.ObjectUrl), zap.Error(err))
+			}
+		}
 		resp.Code = e.ERROR
 		resp.Msg = e.GetMsg(int(resp.Code))
 		return
 	}
+	enqueueScan(file)
 	resp.FileID = uint64(file.ID)
 	resp.Msg = e.GetMsg(int(resp.Code))
 	return
@@ -60,6 +87,16 @@ func (*FilesSrv) BigFileUpload(stream pb.FilesService_BigFileUploadServer) error
 		out        *os.File
 		hashes     = sha256.New() // 创建 Hash 实例
 	)
+	// 被上传策略拒绝时清理已写入的临时文件
+	reject := func(rej *policy.Rejection) error {
+		if out != nil {
+			out.Close()
+			utils.SafeRemove(objectPath)
+		}
+		resp := &pb.BigFileUploadResponse{}
+		resp.Code, resp.Msg, resp.Rejection = rejectionPb(rej)
+		return stream.SendAndClose(resp)
+	}
 
 	for {
 		req, err := stream.Recv()
@@ -76,6 +113,22 @@ func (*FilesSrv) BigFileUpload(stream pb.FilesService_BigFileUploadServer) error
 		if firstReq == nil {
 			firstReq = req
 
+			// 上传到共享文件夹需要编辑权限
+			if err = checkFolderWritable(req.UserID, req.FolderID); err != nil {
+				code, msg := aclErrCode(err)
+				return stream.SendAndClose(&pb.BigFileUploadResponse{
+					Code: code,
+					Msg:  msg,
+				})
+			}
+			// 首个分片携带的 FileSize 为客户端声明的大小，0 表示未知
+			if rej := checkUpload(policy.RouteBigFileUpload, req.UserID, req.Filename, declaredSize(req)); rej != nil {
+				return reject(rej)
+			}
+			if rej := policy.Default().CheckContent(req.Content); rej != nil {
+				return reject(rej)
+			}
+
 			// 写入临时路径
 			objectPath = filepath.Join("stores/uploaded_temp", req.ObjectName)
 			if err = os.MkdirAll(filepath.Dir(objectPath), os.ModePerm); err != nil {
@@ -112,6 +165,9 @@ func (*FilesSrv) BigFileUpload(stream pb.FilesService_BigFileUploadServer) error
 			})
 		}
 		totalSize += int64(n)
+		if rej := policy.Default().CheckSize(policy.RouteBigFileUpload, firstReq.UserID, totalSize); rej != nil {
+			return reject(rej)
+		}
 
 		if req.IsLast {
 			// 最后一块后立即关闭文件
@@ -133,6 +189,11 @@ func (*FilesSrv) BigFileUpload(stream pb.FilesService_BigFileUploadServer) error
 		})
 	}
 
+	// 按实际大小校验当日上传量
+	if rej := checkUpload(policy.RouteBigFileUpload, firstReq.UserID, firstReq.Filename, totalSize); rej != nil {
+		return reject(rej)
+	}
+
 	// 计算最终 Hash 值
 	fileHash := hex.EncodeToString(hashes.Sum(nil))
 	firstReq.FileHash = fileHash
@@ -148,25 +209,37 @@ func (*FilesSrv) BigFileUpload(stream pb.FilesService_BigFileUploadServer) error
 			Msg:  "检查文件 Hash 失败: " + err.Error(),
 		})
 	}
+	if exist == nil {
+		// 其他用户已上传过相同内容时同样走秒传，避免 FileHash 唯一索引冲突
+		if exist, err = dao.NewFilesDao().FindGlobalByHash(firstReq.FileHash); err != nil {
+			return stream.SendAndClose(&pb.BigFileUploadResponse{
+				Code: e.ERROR,
+				Msg:  "检查文件 Hash 失败: " + err.Error(),
+			})
+		}
+	}
 	if exist != nil {
 		utils.SafeRemove(objectPath) // 删除临时文件（忽略错误）
+		if exist, err = placeInFolder(firstReq.UserID, firstReq.FolderID, firstReq.Filename, exist); err != nil {
+			return stream.SendAndClose(&pb.BigFileUploadResponse{
+				Code: e.ERROR,
+				Msg:  "创建秒传记录失败: " + err.Error(),
+			})
+		}
 		return stream.SendAndClose(&pb.BigFileUploadResponse{
 			Code:      e.SUCCESS,
 			Msg:       "秒传成功，文件已存在",
 			FileID:    uint64(exist.ID),
-			ObjectUrl: filepath.Join("stores/uploaded_files", exist.ObjectName),
+			ObjectUrl: filepath.Join("stores/uploaded_files", dao.PhysicalObjectName(exist)),
 		})
 	}
 
 	// 将文件移到正式目录
+	// 按配置压缩、分块后保存到正式目录
 	finalPath := filepath.Join("stores/uploaded_files", firstReq.ObjectName)
-	if err = os.MkdirAll(filepath.Dir(finalPath), os.ModePerm); err != nil {
-		return stream.SendAndClose(&pb.BigFileUploadResponse{
-			Code: e.ERROR,
-			Msg:  "创建目标目录失败: " + err.Error(),
-		})
-	}
-	if err = os.Rename(objectPath, finalPath); err != nil {
+	info, err := storeObject(objectPath, finalPath, firstReq.Filename)
+	if err != nil {
+		utils.SafeRemove(objectPath)
 		return stream.SendAndClose(&pb.BigFileUploadResponse{
 			Code: e.ERROR,
 			Msg:  "移动文件失败: " + err.Error(),
@@ -176,14 +249,18 @@ func (*FilesSrv) BigFileUpload(stream pb.FilesService_BigFileUploadServer) error
 	// 数据库保存记录
 	//log.Println("收到上传总大小：", totalSize)
 	firstReq.FileSize = totalSize
-	file, err := dao.NewFilesDao().CreateBigFile(firstReq)
+	file, err := dao.NewFilesDao().CreateBigFile(firstReq, info)
 	if err != nil {
-		utils.SafeRemove(finalPath) // 删除已经移动过去的正式文件
+		// 删除已经移动过去的正式文件
+		if err := releaseObject(finalPath, info.Layout); err != nil {
+			zap.L().Warn("清理正式文件失败", zap.String("path", finalPath), zap.Error(err))
+		}
 		return stream.SendAndClose(&pb.BigFileUploadResponse{
 			Code: e.ERROR,
 			Msg:  e.GetMsg(e.ERROR),
 		})
 	}
+	enqueueScan(file)
 
 	return stream.SendAndClose(&pb.BigFileUploadResponse{
 		Code:      e.SUCCESS,
@@ -206,6 +283,13 @@ func (*FilesSrv) FileList(ctx context.Context, req *pb.FileListRequest) (resp *p
 	if req.PageSize > 100 {
 		req.PageSize = 100
 	}
+	if req.FolderID != 0 {
+		if err = dao.NewAclDao().CheckFolder(uint(req.UserID), uint(req.FolderID), model.RoleViewer); err != nil {
+			code, msg := aclErrCode(err)
+			resp.Code, resp.Msg = int32(code), msg
+			return resp, nil
+		}
+	}
 	files, total, err := dao.NewFilesDao().ListFiles(req)
 	if err != nil {
 		resp.Code = e.ERROR
@@ -221,6 +305,9 @@ func (*FilesSrv) FileList(ctx context.Context, req *pb.FileListRequest) (resp *p
 			FileSize:   file.FileSize,
 			Bucket:     file.Bucket,
 			ObjectName: file.ObjectName,
+			FolderID:   uint64(file.FolderID),
+			UpdatedAt:  file.UpdatedAt.Unix(),
+			ScanStatus: file.ScanStatus,
 		})
 	}
 	resp.Msg = e.GetMsg(int(resp.Code))
@@ -232,22 +319,16 @@ func (*FilesSrv) FileDelete(ctx context.Context, req *pb.FileDeleteRequest) (res
 	resp = new(pb.FileCommonResponse)
 	resp.Code = e.SUCCESS
 
-	file, err := dao.NewFilesDao().GetFileByUIDAndFID(uint(req.UserID), uint(req.FileID))
+	file, err := dao.NewFilesDao().GetAccessibleFile(uint(req.UserID), uint(req.FileID), model.RoleEditor)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			resp.Code = e.ERROR
-			resp.Msg = "文件不存在"
-			return resp, nil
-		}
-		resp.Code = e.ERROR
-		resp.Msg = "查询文件信息失败"
-		return
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
 	}
-	objectPath := filepath.Join("stores/uploaded_files", file.ObjectName)
-	if err = os.Remove(objectPath); err != nil && !os.IsNotExist(err) {
+	versions, err := dao.NewFilesDao().ListVersions([]uint{file.ID})
+	if err != nil {
 		resp.Code = e.ERROR
-		resp.Msg = "删除本地文件失败: " + err.Error()
-		return
+		resp.Msg = "查询历史版本失败: " + err.Error()
+		return resp, nil
 	}
 
 	err = dao.NewFilesDao().DeleteFile(req)
@@ -256,6 +337,10 @@ func (*FilesSrv) FileDelete(ctx context.Context, req *pb.FileDeleteRequest) (res
 		resp.Msg = e.GetMsg(int(resp.Code))
 		return
 	}
+	// 仍有其他记录（包括秒传记录）引用同一物理文件时只删除记录
+	objectPath := filepath.Join("stores/uploaded_files", dao.PhysicalObjectName(file))
+	removeObjectIfUnused(file)
+	removeVersionObjects(versions)
 	resp.Msg = e.GetMsg(e.SUCCESS)
 
 	zap.L().Info("Delete file", zap.Uint64("user_id", req.UserID), zap.Uint64("file_id", req.FileID), zap.String("path", objectPath))
@@ -267,35 +352,55 @@ func (*FilesSrv) FileDownload(ctx context.Context, req *pb.FileDownloadRequest)
 	resp = new(pb.FileDownloadResponse)
 	resp.Code = e.SUCCESS
 
-	file, err := dao.NewFilesDao().GetFileByUIDAndFID(uint(req.UserID), uint(req.FileID))
+	file, err := dao.NewFilesDao().GetAccessibleFile(uint(req.UserID), uint(req.FileID), model.RoleViewer)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			resp.Code = e.ERROR
-			resp.Msg = "文件不存在"
-			return resp, nil
-		}
-		resp.Code = e.ERROR
-		resp.Msg = "查询文件信息失败"
-		return
+		code, msg := aclErrCode(err)
+		resp.Code, resp.Msg = int32(code), msg
+		return resp, nil
+	}
+	if code := scanErrCode(file); code != e.SUCCESS {
+		resp.Code, resp.Msg = int32(code), e.GetMsg(int(code))
+		return resp, nil
 	}
-	resp.DownloadUrl = filepath.Join("stores/uploaded_files", file.ObjectName)
 	resp.Filename = file.FileName
+	resp.Bucket = file.Bucket
+	if file.Bucket == "qiniu" {
+		resp.DownloadUrl = qiniu.NewQiniuClient().DownloadURL(dao.PhysicalObjectName(file))
+		resp.Msg = e.GetMsg(int(resp.Code))
+		return
+	}
+	resp.DownloadUrl = filepath.Join("stores/uploaded_files", dao.PhysicalObjectName(file))
+	resp.Layout = file.Layout
+	resp.Codec = file.Codec
 	resp.Msg = e.GetMsg(int(resp.Code))
 	return
 }
 
-// CheckFileExists 秒传哈希检测
+// CheckFileExists 秒传哈希检测，指定了目标文件夹时先校验写入权限。
+// 命中的记录不在目标文件夹时在目标文件夹下创建秒传记录，返回该记录
 func (*FilesSrv) CheckFileExists(ctx context.Context, req *pb.CheckFileRequest) (*pb.CheckFileResponse, error) {
+	if err := checkFolderWritable(req.UserID, req.FolderID); err != nil {
+		code, msg := aclErrCode(err)
+		return &pb.CheckFileResponse{Code: code, Msg: msg}, nil
+	}
 	file, err := dao.NewFilesDao().FindByHash(req)
 	if err != nil {
 		return nil, err
 	}
 	if file == nil {
-		return &pb.CheckFileResponse{Exists: false}, nil
+		return &pb.CheckFileResponse{Code: e.SUCCESS, Exists: false}, nil
+	}
+	filename := req.Filename
+	if filename == "" {
+		filename = file.FileName
+	}
+	if file, err = placeInFolder(req.UserID, req.FolderID, filename, file); err != nil {
+		return nil, err
 	}
 	return &pb.CheckFileResponse{
+		Code:      e.SUCCESS,
 		FileID:    uint64(file.ID),
-		ObjectUrl: filepath.Join("stores/uploaded_files", file.ObjectName),
+		ObjectUrl: filepath.Join("stores/uploaded_files", dao.PhysicalObjectName(file)),
 		Exists:    true,
 	}, nil
 }
@@ -304,6 +409,15 @@ func (*FilesSrv) CheckFileExists(ctx context.Context, req *pb.CheckFileRequest)
 func (*FilesSrv) QiniuFileUpload(ctx context.Context, req *pb.FileUploadRequest) (resp *pb.FileUploadResponse, err error) {
 	resp = new(pb.FileUploadResponse)
 	resp.Code = e.SUCCESS
+	if err = checkFolderWritable(req.UserID, req.FolderID); err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	// 网关会先调用一次只做秒传检查，此时同样校验策略，避免先传到七牛云再被拒绝
+	if rej := checkUpload(policy.RouteQiniuFileUpload, req.UserID, req.Filename, req.FileSize); rej != nil {
+		resp.Code, resp.Msg, resp.Rejection = rejectionPb(rej)
+		return resp, nil
+	}
 
 	// 检查是否已存在相同文件（秒传）
 	if req.FileHash != "" {
@@ -315,24 +429,14 @@ func (*FilesSrv) QiniuFileUpload(ctx context.Context, req *pb.FileUploadRequest)
 			return resp, nil
 		}
 		if userFile != nil {
-			// 用户已有该文件（可能是真实记录或秒传记录），直接返回
-			var objectUrl string
-			if userFile.FileHash == req.FileHash {
-				// 真实记录，直接使用其 ObjectName
-				objectUrl = userFile.ObjectName
-			} else {
-				// 秒传记录，需要找到原始文件的 ObjectName
-				originalFile, err := dao.NewFilesDao().FindGlobalByHash(req.FileHash)
-				if err != nil {
-					resp.Code = e.ERROR
-					resp.Msg = "查找原始文件失败: " + err.Error()
-					return resp, nil
-				}
-				objectUrl = originalFile.ObjectName
+			// 用户已有该文件（可能是真实记录或秒传记录），不在目标位置时在目标位置创建秒传记录
+			if userFile, err = placeInFolder(req.UserID, req.FolderID, req.Filename, userFile); err != nil {
+				resp.Code = e.ERROR
+				resp.Msg = "创建用户文件记录失败: " + err.Error()
+				return resp, nil
 			}
-
 			resp.FileID = uint64(userFile.ID)
-			resp.ObjectUrl = objectUrl
+			resp.ObjectUrl = qiniuURL(qiniu.NewQiniuClient(), userFile)
 			resp.Msg = "秒传成功，文件已存在"
 			return resp, nil
 		}
@@ -345,8 +449,8 @@ func (*FilesSrv) QiniuFileUpload(ctx context.Context, req *pb.FileUploadRequest)
 			return resp, nil
 		}
 		if globalFile != nil {
-			// 全局存在相同文件，为当前用户创建新记录
-			newUserFile, err := dao.NewFilesDao().CreateUserFileFromExisting(req.UserID, req.Filename, globalFile)
+			// 全局存在相同文件，为当前用户在目标文件夹下创建新记录
+			newUserFile, err := dao.NewFilesDao().CreateUserFileFromExistingInFolder(req.UserID, req.FolderID, req.Filename, globalFile)
 			if err != nil {
 				resp.Code = e.ERROR
 				resp.Msg = "创建用户文件记录失败: " + err.Error()
@@ -354,7 +458,7 @@ func (*FilesSrv) QiniuFileUpload(ctx context.Context, req *pb.FileUploadRequest)
 			}
 
 			resp.FileID = uint64(newUserFile.ID)
-			resp.ObjectUrl = globalFile.ObjectName
+			resp.ObjectUrl = qiniuURL(qiniu.NewQiniuClient(), newUserFile)
 			resp.Msg = "秒传成功，文件已存在"
 			return resp, nil
 		}
@@ -375,20 +479,33 @@ func (*FilesSrv) QiniuFileUpload(ctx context.Context, req *pb.FileUploadRequest)
 		return
 	}
 
+	enqueueScan(file)
 	resp.FileID = uint64(file.ID)
-	resp.ObjectUrl = req.ObjectName // 返回七牛云的完整URL
+	resp.ObjectUrl = qiniuURL(qiniu.NewQiniuClient(), file) // 私有空间返回带签名的地址，扫描通过前为空
 	resp.Msg = e.GetMsg(int(resp.Code))
 	return
 }
 
-// QiniuBigFileUpload 七牛云流式上传
+// QiniuBigFileUpload 七牛云流式上传：分片边接收边写入临时文件并计算 Hash，
+// 秒传未命中时再从临时文件分片上传到七牛云，内存占用与文件大小无关
 func (*FilesSrv) QiniuBigFileUpload(stream pb.FilesService_QiniuBigFileUploadServer) error {
 	var (
 		firstReq  *pb.BigFileUploadRequest
 		totalSize int64
 		hashes    = sha256.New()
-		chunks    [][]byte // 存储所有分片数据
+		out       *os.File
 	)
+	defer func() {
+		if out != nil {
+			out.Close()
+			utils.SafeRemove(out.Name())
+		}
+	}()
+	reject := func(rej *policy.Rejection) error {
+		resp := &pb.BigFileUploadResponse{}
+		resp.Code, resp.Msg, resp.Rejection = rejectionPb(rej)
+		return stream.SendAndClose(resp)
+	}
 
 	// 接收所有分片数据
 	for {
@@ -405,19 +522,48 @@ func (*FilesSrv) QiniuBigFileUpload(stream pb.FilesService_QiniuBigFileUploadSer
 
 		if firstReq == nil {
 			firstReq = req
+
+			// 上传到共享文件夹需要编辑权限
+			if err = checkFolderWritable(req.UserID, req.FolderID); err != nil {
+				code, msg := aclErrCode(err)
+				return stream.SendAndClose(&pb.BigFileUploadResponse{
+					Code: code,
+					Msg:  msg,
+				})
+			}
+			if rej := checkUpload(policy.RouteQiniuBigFileUpload, req.UserID, req.Filename, declaredSize(req)); rej != nil {
+				return reject(rej)
+			}
+			if rej := policy.Default().CheckContent(req.Content); rej != nil {
+				return reject(rej)
+			}
+
+			if err = os.MkdirAll("stores/uploaded_temp", os.ModePerm); err != nil {
+				return stream.SendAndClose(&pb.BigFileUploadResponse{
+					Code: e.ERROR,
+					Msg:  "创建目录失败: " + err.Error(),
+				})
+			}
+			if out, err = os.CreateTemp("stores/uploaded_temp", "qiniu-*"); err != nil {
+				return stream.SendAndClose(&pb.BigFileUploadResponse{
+					Code: e.ERROR,
+					Msg:  "创建临时文件失败: " + err.Error(),
+				})
+			}
 		}
 
-		// 计算 Hash
-		if _, err = hashes.Write(req.Content); err != nil {
+		// 写入临时文件并计算 Hash
+		n, err := io.MultiWriter(out, hashes).Write(req.Content)
+		if err != nil {
 			return stream.SendAndClose(&pb.BigFileUploadResponse{
 				Code: e.ERROR,
-				Msg:  "计算 Hash 错误: " + err.Error(),
+				Msg:  "文件写入失败: " + err.Error(),
 			})
 		}
-
-		// 存储分片数据
-		chunks = append(chunks, req.Content)
-		totalSize += int64(len(req.Content))
+		totalSize += int64(n)
+		if rej := policy.Default().CheckSize(policy.RouteQiniuBigFileUpload, firstReq.UserID, totalSize); rej != nil {
+			return reject(rej)
+		}
 
 		if req.IsLast {
 			break
@@ -431,6 +577,10 @@ func (*FilesSrv) QiniuBigFileUpload(stream pb.FilesService_QiniuBigFileUploadSer
 		})
 	}
 
+	if rej := checkUpload(policy.RouteQiniuBigFileUpload, firstReq.UserID, firstReq.Filename, totalSize); rej != nil {
+		return reject(rej)
+	}
+
 	// 计算文件 Hash
 	fileHash := hex.EncodeToString(hashes.Sum(nil))
 	firstReq.FileHash = fileHash
@@ -445,28 +595,18 @@ func (*FilesSrv) QiniuBigFileUpload(stream pb.FilesService_QiniuBigFileUploadSer
 		})
 	}
 	if userFile != nil {
-		// 用户已有该文件（可能是真实记录或秒传记录），直接返回
-		var objectUrl string
-		if userFile.FileHash == firstReq.FileHash {
-			// 真实记录，直接使用其 ObjectName
-			objectUrl = userFile.ObjectName
-		} else {
-			// 秒传记录，需要找到原始文件的 ObjectName
-			originalFile, err := dao.NewFilesDao().FindGlobalByHash(firstReq.FileHash)
-			if err != nil {
-				return stream.SendAndClose(&pb.BigFileUploadResponse{
-					Code: e.ERROR,
-					Msg:  "查找原始文件失败: " + err.Error(),
-				})
-			}
-			objectUrl = originalFile.ObjectName
+		// 用户已有该文件（可能是真实记录或秒传记录），不在目标位置时在目标位置创建秒传记录
+		if userFile, err = placeInFolder(firstReq.UserID, firstReq.FolderID, firstReq.Filename, userFile); err != nil {
+			return stream.SendAndClose(&pb.BigFileUploadResponse{
+				Code: e.ERROR,
+				Msg:  "创建用户文件记录失败: " + err.Error(),
+			})
 		}
-
 		return stream.SendAndClose(&pb.BigFileUploadResponse{
 			Code:      e.SUCCESS,
 			Msg:       "秒传成功，文件已存在",
 			FileID:    uint64(userFile.ID),
-			ObjectUrl: objectUrl,
+			ObjectUrl: qiniuURL(qiniu.NewQiniuClient(), userFile),
 		})
 	}
 
@@ -479,8 +619,8 @@ func (*FilesSrv) QiniuBigFileUpload(stream pb.FilesService_QiniuBigFileUploadSer
 		})
 	}
 	if globalFile != nil {
-		// 全局存在相同文件，为当前用户创建新记录
-		newUserFile, err := dao.NewFilesDao().CreateUserFileFromExisting(firstReq.UserID, firstReq.Filename, globalFile)
+		// 全局存在相同文件，为当前用户在目标文件夹下创建新记录
+		newUserFile, err := dao.NewFilesDao().CreateUserFileFromExistingInFolder(firstReq.UserID, firstReq.FolderID, firstReq.Filename, globalFile)
 		if err != nil {
 			return stream.SendAndClose(&pb.BigFileUploadResponse{
 				Code: e.ERROR,
@@ -492,22 +632,16 @@ func (*FilesSrv) QiniuBigFileUpload(stream pb.FilesService_QiniuBigFileUploadSer
 			Code:      e.SUCCESS,
 			Msg:       "秒传成功，文件已存在",
 			FileID:    uint64(newUserFile.ID),
-			ObjectUrl: globalFile.ObjectName,
+			ObjectUrl: qiniuURL(qiniu.NewQiniuClient(), newUserFile),
 		})
 	}
 
-	// 合并所有分片数据
-	var allData []byte
-	for _, chunk := range chunks {
-		allData = append(allData, chunk...)
-	}
-
 	// 生成七牛云对象名
 	objectName := qiniu.GenerateObjectName(firstReq.UserID, firstReq.Filename)
 
-	// 上传到七牛云
+	// 从临时文件分片上传到七牛云
 	qiniuClient := qiniu.NewQiniuClient()
-	qiniuURL, err := qiniuClient.UploadStreamFromBytes(objectName, allData)
+	key, err := qiniuClient.UploadStream(objectName, out, totalSize)
 	if err != nil {
 		return stream.SendAndClose(&pb.BigFileUploadResponse{
 			Code: e.ERROR,
@@ -517,7 +651,7 @@ func (*FilesSrv) QiniuBigFileUpload(stream pb.FilesService_QiniuBigFileUploadSer
 
 	// 保存到数据库
 	firstReq.FileSize = totalSize
-	firstReq.ObjectName = qiniuURL  // 使用七牛云返回的完整URL
+	firstReq.ObjectName = key // 只保存 key，下载地址在请求时生成
 	file, err := dao.NewFilesDao().CreateQiniuBigFile(firstReq)
 	if err != nil {
 		return stream.SendAndClose(&pb.BigFileUploadResponse{
@@ -526,38 +660,25 @@ func (*FilesSrv) QiniuBigFileUpload(stream pb.FilesService_QiniuBigFileUploadSer
 		})
 	}
 
+	enqueueScan(file)
 	return stream.SendAndClose(&pb.BigFileUploadResponse{
 		Code:      e.SUCCESS,
 		Msg:       "上传成功",
 		FileID:    uint64(file.ID),
-		ObjectUrl: qiniuURL,
+		ObjectUrl: qiniuURL(qiniuClient, file),
 	})
 }
 
-// QiniuFileDownload 七牛云文件下载（支持跨用户下载）
+// QiniuFileDownload 七牛云文件下载，需要是文件所有者或被共享
 func (*FilesSrv) QiniuFileDownload(ctx context.Context, req *pb.FileDownloadRequest) (resp *pb.FileDownloadResponse, err error) {
 	resp = new(pb.FileDownloadResponse)
 	resp.Code = e.SUCCESS
 
-	// 支持跨用户下载：如果传入了UserID，则按用户查找；否则全局查找
-	var file *model.Files
-	if req.UserID != 0 {
-		// 按用户查找（原有逻辑）
-		file, err = dao.NewFilesDao().GetFileByUIDAndFID(uint(req.UserID), uint(req.FileID))
-	} else {
-		// 全局查找（新增逻辑）
-		file, err = dao.NewFilesDao().GetFileByID(uint(req.FileID))
-	}
-
+	file, err := dao.NewFilesDao().GetAccessibleFile(uint(req.UserID), uint(req.FileID), model.RoleViewer)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			resp.Code = e.ERROR
-			resp.Msg = "文件不存在"
-			return resp, nil
-		}
-		resp.Code = e.ERROR
-		resp.Msg = "查询文件信息失败"
-		return
+		code, msg := aclErrCode(err)
+		resp.Code, resp.Msg = int32(code), msg
+		return resp, nil
 	}
 
 	// 检查是否为七牛云文件
@@ -566,44 +687,13 @@ func (*FilesSrv) QiniuFileDownload(ctx context.Context, req *pb.FileDownloadRequ
 		resp.Msg = "该文件不是七牛云存储文件"
 		return resp, nil
 	}
-
-	// 处理下载URL
-	var downloadUrl string
-	if strings.HasPrefix(file.FileHash, "shared_") {
-		// 这是秒传文件，需要找到原始文件的URL
-		// ObjectName 格式：shared_用户ID_时间戳_原始URL
-		// 我们需要找到第三个下划线后的内容
-		objectName := file.ObjectName
-
-		// 找到 "shared_" 前缀后的内容
-		if strings.HasPrefix(objectName, "shared_") {
-			// 移除 "shared_" 前缀
-			remaining := objectName[7:] // 去掉 "shared_"
-
-			// 找到用户ID后的第一个下划线
-			firstUnderscoreIndex := strings.Index(remaining, "_")
-			if firstUnderscoreIndex != -1 {
-				// 找到时间戳后的第二个下划线
-				afterFirstUnderscore := remaining[firstUnderscoreIndex+1:]
-				secondUnderscoreIndex := strings.Index(afterFirstUnderscore, "_")
-				if secondUnderscoreIndex != -1 {
-					// 提取原始URL（第二个下划线之后的所有内容）
-					downloadUrl = afterFirstUnderscore[secondUnderscoreIndex+1:]
-				} else {
-					downloadUrl = file.ObjectName // 降级处理
-				}
-			} else {
-				downloadUrl = file.ObjectName // 降级处理
-			}
-		} else {
-			downloadUrl = file.ObjectName // 降级处理
-		}
-	} else {
-		// 这是原始文件，直接使用 ObjectName
-		downloadUrl = file.ObjectName
+	if code := scanErrCode(file); code != e.SUCCESS {
+		resp.Code, resp.Msg = int32(code), e.GetMsg(int(code))
+		return resp, nil
 	}
 
-	resp.DownloadUrl = downloadUrl
+	// 秒传记录指向原始对象，私有空间的地址带有效期签名
+	resp.DownloadUrl = qiniu.NewQiniuClient().DownloadURL(dao.PhysicalObjectName(file))
 	resp.Filename = file.FileName
 	resp.Msg = e.GetMsg(int(resp.Code))
 	return
@@ -637,31 +727,14 @@ func (*FilesSrv) GlobalFileSearch(ctx context.Context, req *pb.GlobalFileSearchR
 
 	// 转换为响应格式
 	var fileInfos []*pb.GlobalFileInfo
+	qiniuClient := qiniu.NewQiniuClient()
 	for _, file := range files {
-		// 处理下载URL（与下载接口逻辑一致）
-		var downloadUrl string
-		if strings.HasPrefix(file.FileHash, "shared_") {
-			// 秒传文件，提取原始URL
-			objectName := file.ObjectName
-			if strings.HasPrefix(objectName, "shared_") {
-				remaining := objectName[7:]
-				firstUnderscoreIndex := strings.Index(remaining, "_")
-				if firstUnderscoreIndex != -1 {
-					afterFirstUnderscore := remaining[firstUnderscoreIndex+1:]
-					secondUnderscoreIndex := strings.Index(afterFirstUnderscore, "_")
-					if secondUnderscoreIndex != -1 {
-						downloadUrl = afterFirstUnderscore[secondUnderscoreIndex+1:]
-					} else {
-						downloadUrl = file.ObjectName
-					}
-				} else {
-					downloadUrl = file.ObjectName
-				}
-			} else {
-				downloadUrl = file.ObjectName
-			}
-		} else {
-			downloadUrl = file.ObjectName
+		// 处理下载URL（与下载接口逻辑一致），未通过扫描的文件不返回地址
+		downloadUrl := dao.PhysicalObjectName(file)
+		if scanErrCode(file) != e.SUCCESS {
+			downloadUrl = ""
+		} else if file.Bucket == "qiniu" {
+			downloadUrl = qiniuClient.DownloadURL(downloadUrl)
 		}
 
 		fileInfo := &pb.GlobalFileInfo{
@@ -694,9 +767,8 @@ func (*FilesSrv) QiniuFileDelete(ctx context.Context, req *pb.FileDeleteRequest)
 	// 删除数据库记录
 	deletedFile, err := dao.NewFilesDao().DeleteQiniuFile(uint(req.UserID), uint(req.FileID))
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			resp.Code = e.ERROR
-			resp.Msg = "文件不存在或无权限删除"
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, dao.ErrPermissionDenied) {
+			resp.Code, resp.Msg = aclErrCode(err)
 			return resp, nil
 		}
 		resp.Code = e.ERROR
@@ -704,52 +776,9 @@ func (*FilesSrv) QiniuFileDelete(ctx context.Context, req *pb.FileDeleteRequest)
 		return resp, nil
 	}
 
-	// 检查是否需要删除七牛云上的物理文件
-	shouldDeletePhysical := false
-
-	// 如果是原始文件（不是秒传文件），需要检查是否还有其他用户在使用
-	if !strings.HasPrefix(deletedFile.FileHash, "shared_") {
-		// 查找相同哈希的其他文件
-		sameHashFiles, err := dao.NewFilesDao().FindSameHashFiles(deletedFile.FileHash, deletedFile.ID)
-		if err != nil {
-			// 查询失败，为了安全起见，不删除物理文件
-			log.Printf("查询相同哈希文件失败: %v", err)
-		} else if len(sameHashFiles) == 0 {
-			// 没有其他文件使用相同哈希，可以删除物理文件
-			shouldDeletePhysical = true
-		}
-	}
-
-	// 删除七牛云上的物理文件
-	if shouldDeletePhysical {
-		// 从ObjectName中提取七牛云的key
-		objectKey := extractQiniuKey(deletedFile.ObjectName)
-		if objectKey != "" {
-			qiniuClient := qiniu.NewQiniuClient()
-			if deleteErr := qiniuClient.DeleteFile(objectKey); deleteErr != nil {
-				// 物理文件删除失败，记录日志但不影响响应
-				log.Printf("删除七牛云物理文件失败: %v, key: %s", deleteErr, objectKey)
-			} else {
-				log.Printf("成功删除七牛云物理文件: %s", objectKey)
-			}
-		}
-	}
+	// 仍有其他记录（包括其他用户的秒传记录）引用同一对象时只删除记录
+	removeObjectIfUnused(deletedFile)
 
 	resp.Msg = e.GetMsg(int(resp.Code))
 	return resp, nil
 }
-
-// extractQiniuKey 从完整URL中提取七牛云的key
-func extractQiniuKey(objectName string) string {
-	// 如果是完整URL，提取路径部分作为key
-	if strings.HasPrefix(objectName, "http://") || strings.HasPrefix(objectName, "https://") {
-		// 找到域名后的第一个斜杠
-		parts := strings.SplitN(objectName, "/", 4)
-		if len(parts) >= 4 {
-			return parts[3] // 返回路径部分
-		}
-	}
-
-	// 如果不是完整URL，直接返回
-	return objectName
-}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"grpc-todolist-disk/app/files/dao"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+)
+
+// TestCheckFileExistsPlacesInFolder 秒传命中其他文件夹中的记录时在目标文件夹下创建记录，已在目标位置时直接返回
+func TestCheckFileExistsPlacesInFolder(t *testing.T) {
+	testDB(t)
+	chdirTemp(t)
+
+	srv := GetFilesSrv()
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	userID := uint64(suffix%1_000_000_000 + 1_000_000)
+	hash := fmt.Sprintf("check-test-%d", suffix)
+
+	objectName := fmt.Sprintf("check-test/%d-a.txt", suffix)
+	writeObject(t, objectName, "hello")
+	up, err := srv.FileUpload(ctx, &pb.FileUploadRequest{
+		UserID: userID, Filename: "a.txt", FileSize: 5, ObjectName: objectName, FileHash: hash,
+	})
+	if err != nil || up.Code != e.SUCCESS {
+		t.Fatalf("upload: resp=%v err=%v", up, err)
+	}
+	folder, err := srv.FolderCreate(ctx, &pb.FolderCreateRequest{UserID: userID, Name: "docs"})
+	if err != nil || folder.Code != e.SUCCESS {
+		t.Fatalf("create folder: resp=%v err=%v", folder, err)
+	}
+
+	check := func(folderID uint64, filename string) *pb.CheckFileResponse {
+		resp, err := srv.CheckFileExists(ctx, &pb.CheckFileRequest{FileHash: hash, UserID: userID, FolderID: folderID, Filename: filename})
+		if err != nil || resp.Code != e.SUCCESS || !resp.Exists {
+			t.Fatalf("check %d/%s: resp=%v err=%v", folderID, filename, resp, err)
+		}
+		return resp
+	}
+	if resp := check(0, "a.txt"); resp.FileID != up.FileID {
+		t.Fatalf("same place returned %d, want %d", resp.FileID, up.FileID)
+	}
+
+	resp := check(folder.FolderID, "b.txt")
+	if resp.FileID == up.FileID {
+		t.Fatal("hit in another folder should create a record in the target folder")
+	}
+	file, err := dao.NewFilesDao().GetFileByID(uint(resp.FileID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uint64(file.FolderID) != folder.FolderID || file.FileName != "b.txt" || dao.PhysicalObjectName(file) != objectName {
+		t.Fatalf("placed record %+v", file)
+	}
+}
+
+// TestFileDeleteRemovesShares 删除文件时同时删除该文件的共享授权
+func TestFileDeleteRemovesShares(t *testing.T) {
+	testDB(t)
+	chdirTemp(t)
+
+	srv := GetFilesSrv()
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	owner := uint64(suffix%1_000_000_000 + 1_000_000)
+
+	objectName := fmt.Sprintf("share-test/%d-a.txt", suffix)
+	writeObject(t, objectName, "hello")
+	up, err := srv.FileUpload(ctx, &pb.FileUploadRequest{
+		UserID: owner, Filename: "a.txt", FileSize: 5, ObjectName: objectName, FileHash: fmt.Sprintf("share-test-%d", suffix),
+	})
+	if err != nil || up.Code != e.SUCCESS {
+		t.Fatalf("upload: resp=%v err=%v", up, err)
+	}
+	grant, err := srv.ShareGrant(ctx, &pb.ShareGrantRequest{
+		UserID: owner, ResourceType: model.ResourceTypeFile, ResourceID: up.FileID,
+		GranteeType: model.GranteeTypeUser, GranteeID: owner + 1, Role: model.RoleViewer,
+	})
+	if err != nil || grant.Code != e.SUCCESS {
+		t.Fatalf("grant: resp=%v err=%v", grant, err)
+	}
+
+	del, err := srv.FileDelete(ctx, &pb.FileDeleteRequest{UserID: owner, FileID: up.FileID})
+	if err != nil || del.Code != e.SUCCESS {
+		t.Fatalf("delete: resp=%v err=%v", del, err)
+	}
+	if _, total, err := dao.NewShareDao().ListByResource(model.ResourceTypeFile, uint(up.FileID), 1, 10); err != nil || total != 0 {
+		t.Fatalf("shares left after delete: %d err %v", total, err)
+	}
+}
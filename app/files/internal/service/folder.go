@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"grpc-todolist-disk/app/files/dao"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/qiniu"
+	"strings"
+)
+
+// validName 文件名/文件夹名不能为空且不能包含路径分隔符
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/\\")
+}
+
+// FolderList 列出文件夹下的子文件夹和文件，folder_id 为 0 时列出自己的根目录
+func (*FilesSrv) FolderList(ctx context.Context, req *pb.FolderListRequest) (resp *pb.FolderListResponse, err error) {
+	resp = new(pb.FolderListResponse)
+	resp.Code = e.SUCCESS
+	if req.FolderID != 0 {
+		if err = dao.NewAclDao().CheckFolder(uint(req.UserID), uint(req.FolderID), model.RoleViewer); err != nil {
+			resp.Code, resp.Msg = aclErrCode(err)
+			return resp, nil
+		}
+	}
+	folders, files, err := dao.NewFolderDao().ListChildren(uint(req.UserID), uint(req.FolderID))
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "查询文件夹失败: " + err.Error()
+		return resp, nil
+	}
+	for _, folder := range folders {
+		resp.Folders = append(resp.Folders, &pb.FolderModel{
+			FolderID:  uint64(folder.ID),
+			UserID:    uint64(folder.UserID),
+			ParentID:  uint64(folder.ParentID),
+			Name:      folder.Name,
+			UpdatedAt: folder.UpdatedAt.Unix(),
+		})
+	}
+	for _, file := range files {
+		resp.Files = append(resp.Files, &pb.FileModel{
+			FileID:     uint64(file.ID),
+			UserID:     uint64(file.UserID),
+			FileName:   file.FileName,
+			FileSize:   file.FileSize,
+			Bucket:     file.Bucket,
+			ObjectName: file.ObjectName,
+			FolderID:   uint64(file.FolderID),
+			UpdatedAt:  file.UpdatedAt.Unix(),
+			ScanStatus: file.ScanStatus,
+		})
+	}
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// FileMove 移动文件（可同时重命名），需要文件和目标文件夹的编辑权限，
+// 移出共享目录树时还需要是文件的所有者
+func (*FilesSrv) FileMove(ctx context.Context, req *pb.FileMoveRequest) (resp *pb.FileCommonResponse, err error) {
+	resp = new(pb.FileCommonResponse)
+	resp.Code = e.SUCCESS
+	file, err := dao.NewFilesDao().GetAccessibleFile(uint(req.UserID), uint(req.FileID), model.RoleEditor)
+	if err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	name := strings.TrimSpace(req.NewName)
+	if name == "" {
+		name = file.FileName
+	}
+	if !validName(name) {
+		resp.Code = e.InvalidParams
+		resp.Msg = "文件名不合法"
+		return resp, nil
+	}
+	if err = checkFolderWritable(req.UserID, req.FolderID); err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	owner, err := dao.NewAclDao().FileOwner(file)
+	if err == nil {
+		err = checkMoveOwner(req.UserID, owner, req.FolderID)
+	}
+	if err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	if err = dao.NewFilesDao().MoveFile(file.ID, uint(req.FolderID), name); err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "移动文件失败: " + err.Error()
+		return resp, nil
+	}
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// FolderMove 移动/重命名文件夹，不允许移动到自身或其子文件夹下，移出共享目录树时需要是所有者
+func (*FilesSrv) FolderMove(ctx context.Context, req *pb.FolderMoveRequest) (resp *pb.FileCommonResponse, err error) {
+	resp = new(pb.FileCommonResponse)
+	resp.Code = e.SUCCESS
+	folderDao := dao.NewFolderDao()
+	if err = dao.NewAclDao().CheckFolder(uint(req.UserID), uint(req.FolderID), model.RoleEditor); err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	folder, err := folderDao.GetFolderByID(uint(req.FolderID))
+	if err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	name := strings.TrimSpace(req.NewName)
+	if name == "" {
+		name = folder.Name
+	}
+	if !validName(name) {
+		resp.Code = e.InvalidParams
+		resp.Msg = "文件夹名称不合法"
+		return resp, nil
+	}
+	if err = checkFolderWritable(req.UserID, req.ParentID); err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	owner, err := dao.NewAclDao().FolderOwner(folder.ID, 0)
+	if err == nil {
+		err = checkMoveOwner(req.UserID, owner, req.ParentID)
+	}
+	if err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	if req.ParentID != 0 {
+		loop, err := folderDao.IsDescendant(uint(req.ParentID), folder.ID)
+		if err != nil {
+			resp.Code = e.ERROR
+			resp.Msg = "查询文件夹失败: " + err.Error()
+			return resp, nil
+		}
+		if loop {
+			resp.Code = e.InvalidParams
+			resp.Msg = "不能将文件夹移动到自身或其子文件夹下"
+			return resp, nil
+		}
+	}
+	if folder.ParentID != uint(req.ParentID) {
+		height, err := folderDao.SubtreeHeight(folder.ID)
+		if err == nil {
+			err = folderDao.CheckDepth(uint(req.ParentID), height)
+		}
+		if err != nil {
+			resp.Code, resp.Msg = aclErrCode(err)
+			return resp, nil
+		}
+	}
+	if err = folderDao.MoveFolder(folder.ID, uint(req.ParentID), name); err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "移动文件夹失败: " + err.Error()
+		return resp, nil
+	}
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// FolderDelete 递归删除文件夹，物理文件只在没有其他记录引用时才删除
+func (*FilesSrv) FolderDelete(ctx context.Context, req *pb.FolderDeleteRequest) (resp *pb.FileCommonResponse, err error) {
+	resp = new(pb.FileCommonResponse)
+	resp.Code = e.SUCCESS
+	folderDao := dao.NewFolderDao()
+	if err = dao.NewAclDao().CheckFolder(uint(req.UserID), uint(req.FolderID), model.RoleEditor); err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	folderIDs, err := folderDao.SubtreeIDs(uint(req.FolderID))
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "查询文件夹失败: " + err.Error()
+		return resp, nil
+	}
+	files, err := folderDao.ListFilesInFolders(folderIDs)
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "查询文件失败: " + err.Error()
+		return resp, nil
+	}
+	fileIDs := make([]uint, 0, len(files))
+	for _, file := range files {
+		fileIDs = append(fileIDs, file.ID)
+	}
+	versions, err := dao.NewFilesDao().ListVersions(fileIDs)
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "查询历史版本失败: " + err.Error()
+		return resp, nil
+	}
+	if err = folderDao.DeleteSubtree(folderIDs, files); err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "删除文件夹失败: " + err.Error()
+		return resp, nil
+	}
+
+	// 记录已删除，剩余引用数为 0 的物理文件可以清理
+	for _, file := range files {
+		removeObjectIfUnused(file)
+	}
+	removeVersionObjects(versions)
+	resp.Msg = e.GetMsg(int(resp.Code))
+
+	zap.L().Info("Delete folder", zap.Uint64("user_id", req.UserID), zap.Uint64("folder_id", req.FolderID),
+		zap.Int("folders", len(folderIDs)), zap.Int("files", len(files)))
+	return resp, nil
+}
+
+// removeObjectIfUnused 在记录删除后清理不再被引用的物理文件，失败只记录日志
+func removeObjectIfUnused(file *model.Files) {
+	objectName := dao.PhysicalObjectName(file)
+	refs, err := dao.NewFilesDao().CountObjectRefs(objectName, file.ID)
+	if err != nil || refs > 0 {
+		return
+	}
+	switch file.Bucket {
+	case "qiniu":
+		if key := qiniu.Key(objectName); key != "" {
+			if err = qiniu.NewQiniuClient().DeleteFile(key); err != nil {
+				zap.L().Warn("删除七牛云物理文件失败", zap.String("key", key), zap.Error(err))
+			}
+		}
+	default:
+		if err = releaseObject(localObjectPath(objectName), file.Layout); err != nil {
+			zap.L().Warn("删除本地文件失败", zap.String("object", objectName), zap.Error(err))
+		}
+	}
+}
+
+// removeVersionObjects 清理已删除文件的历史版本对象（历史版本只存在于本地存储）
+func removeVersionObjects(versions []*model.FileVersion) {
+	for _, version := range versions {
+		removeObjectIfUnused(&model.Files{
+			Bucket:     "local",
+			ObjectName: version.ObjectName,
+			FileHash:   version.FileHash,
+			Layout:     version.Layout,
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"go.uber.org/zap"
+	"grpc-todolist-disk/app/files/dao"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/policy"
+	"io"
+	"os"
+	"time"
+)
+
+// checkUpload 按上传策略校验文件名、大小和当日上传量，size < 0 表示大小未知（只校验文件名）
+func checkUpload(route string, userID uint64, filename string, size int64) *policy.Rejection {
+	engine := policy.Default()
+	if rej := engine.CheckFile(route, userID, filename, size); rej != nil {
+		return rej
+	}
+	if size < 0 || engine.DailyVolume(userID) <= 0 {
+		return nil
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	used, err := dao.NewFilesDao().SumUploadedSince(uint(userID), today)
+	if err != nil {
+		// 统计失败时不阻塞上传，后续写库失败会再返回错误
+		zap.L().Warn("统计当日上传量失败", zap.Uint64("user_id", userID), zap.Error(err))
+		return nil
+	}
+	return engine.CheckDailyVolume(userID, used, size)
+}
+
+// checkUploadedFile 校验已经落盘的文件：大小、当日上传量和按内容识别的类型
+func checkUploadedFile(route string, userID uint64, filename, path string) *policy.Rejection {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil
+	}
+	if rej := checkUpload(route, userID, filename, info.Size()); rej != nil {
+		return rej
+	}
+	head := make([]byte, policy.SniffLen)
+	n, _ := io.ReadFull(f, head)
+	return policy.Default().CheckContent(head[:n])
+}
+
+// declaredSize 流式上传首个分片中声明的文件大小，未声明时返回 -1
+func declaredSize(req *pb.BigFileUploadRequest) int64 {
+	if req.FileSize > 0 {
+		return req.FileSize
+	}
+	return -1
+}
+
+// rejectionPb 转换为响应中的拒绝原因
+func rejectionPb(rej *policy.Rejection) (int64, string, *pb.PolicyRejection) {
+	return e.ErrorUploadPolicy, rej.Reason, &pb.PolicyRejection{
+		Rule:   rej.Rule,
+		Reason: rej.Reason,
+		Limit:  rej.Limit,
+		Actual: rej.Actual,
+	}
+}
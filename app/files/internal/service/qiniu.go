@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"grpc-todolist-disk/app/files/dao"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/policy"
+	"grpc-todolist-disk/utils/qiniu"
+	"net/url"
+	"strconv"
+)
+
+// QiniuUploadToken 签发客户端直传七牛云的凭证，上传完成后由七牛云回调网关创建文件记录
+func (*FilesSrv) QiniuUploadToken(ctx context.Context, req *pb.QiniuUploadTokenRequest) (resp *pb.QiniuUploadTokenResponse, err error) {
+	resp = new(pb.QiniuUploadTokenResponse)
+	resp.Code = e.SUCCESS
+	client := qiniu.NewQiniuClient()
+	if !client.CallbackEnabled() {
+		resp.Code = e.ERROR
+		resp.Msg = "未配置七牛云直传回调地址"
+		return resp, nil
+	}
+	if err = checkFolderWritable(req.UserID, req.FolderID); err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	if rej := checkUpload(policy.RouteQiniuDirectUpload, req.UserID, req.Filename, req.FileSize); rej != nil {
+		resp.Code, resp.Msg, resp.Rejection = rejectionPb(rej)
+		return resp, nil
+	}
+	if resp.UploadUrl, err = client.UploadHost(); err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "获取七牛云上传地址失败: " + err.Error()
+		return resp, nil
+	}
+
+	// 大小上限写入凭证，超出时七牛云直接拒绝；用户、文件夹和文件名随回调返回，客户端无法修改
+	maxSize, _ := policy.Default().MaxSize(policy.RouteQiniuDirectUpload, req.UserID)
+	resp.Key = qiniu.GenerateObjectName(req.UserID, req.Filename)
+	token, deadline := client.CallbackUploadToken(resp.Key, maxSize, url.Values{
+		"uid":    {strconv.FormatUint(req.UserID, 10)},
+		"folder": {strconv.FormatUint(req.FolderID, 10)},
+		"fname":  {req.Filename},
+	})
+	resp.Token = token
+	resp.ExpiresAt = deadline.Unix()
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// QiniuUploadCallback 处理直传完成的回调（网关已校验七牛云签名）。七牛云会重试失败的回调，重复回调返回已有记录
+func (*FilesSrv) QiniuUploadCallback(ctx context.Context, req *pb.QiniuUploadCallbackRequest) (resp *pb.FileUploadResponse, err error) {
+	resp = new(pb.FileUploadResponse)
+	resp.Code = e.SUCCESS
+	client := qiniu.NewQiniuClient()
+
+	existing, err := dao.NewFilesDao().FindByObjectName(req.Key)
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "查询文件记录失败: " + err.Error()
+		return resp, nil
+	}
+	if existing != nil {
+		resp.FileID = uint64(existing.ID)
+		resp.ObjectUrl = qiniuURL(client, existing)
+		resp.Msg = e.GetMsg(int(resp.Code))
+		return resp, nil
+	}
+
+	// 签发凭证之后权限或用量可能已经变化，不能入库的对象直接删除
+	if err = checkFolderWritable(req.UserID, req.FolderID); err != nil {
+		discardQiniuObject(client, req.Key)
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	if rej := checkUpload(policy.RouteQiniuDirectUpload, req.UserID, req.Filename, req.FileSize); rej != nil {
+		discardQiniuObject(client, req.Key)
+		resp.Code, resp.Msg, resp.Rejection = rejectionPb(rej)
+		return resp, nil
+	}
+
+	// 直传时服务端拿不到内容，用七牛云的 etag 去重
+	fileHash := "qetag_" + req.Hash
+	globalFile, err := dao.NewFilesDao().FindGlobalByHash(fileHash)
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "检查全局文件 Hash 失败: " + err.Error()
+		return resp, nil
+	}
+	if globalFile != nil {
+		// 已有相同内容，创建秒传记录并删除重复上传的对象
+		userFile, err := dao.NewFilesDao().CreateUserFileFromExistingInFolder(req.UserID, req.FolderID, req.Filename, globalFile)
+		if err != nil {
+			resp.Code = e.ERROR
+			resp.Msg = "创建用户文件记录失败: " + err.Error()
+			return resp, nil
+		}
+		discardQiniuObject(client, req.Key)
+		resp.FileID = uint64(userFile.ID)
+		resp.ObjectUrl = qiniuURL(client, userFile)
+		resp.Msg = "秒传成功，文件已存在"
+		return resp, nil
+	}
+
+	file, err := dao.NewFilesDao().CreateQiniuFile(&pb.FileUploadRequest{
+		UserID:     req.UserID,
+		Filename:   req.Filename,
+		FileSize:   req.FileSize,
+		ObjectName: req.Key,
+		FileHash:   fileHash,
+		FolderID:   req.FolderID,
+	})
+	if err != nil {
+		discardQiniuObject(client, req.Key)
+		resp.Code = e.ERROR
+		resp.Msg = "数据库保存失败: " + err.Error()
+		return resp, nil
+	}
+	enqueueScan(file)
+	resp.FileID = uint64(file.ID)
+	resp.ObjectUrl = qiniuURL(client, file)
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// discardQiniuObject 删除不会入库的直传对象，失败只记录日志
+func discardQiniuObject(client *qiniu.QiniuClient, key string) {
+	if err := client.DeleteFile(key); err != nil {
+		zap.L().Warn("删除七牛云直传对象失败", zap.String("key", key), zap.Error(err))
+	}
+}
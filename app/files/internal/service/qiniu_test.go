@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"grpc-todolist-disk/conf"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/qiniu/qiniutest"
+)
+
+// TestQiniuCallbackAndDelete 直传回调入库、重复回调、相同内容秒传，以及删除时按引用计数清理七牛云对象
+func TestQiniuCallbackAndDelete(t *testing.T) {
+	testDB(t)
+	const bucket = "test-bucket"
+	server := qiniutest.New("ak", "sk")
+	defer server.Close()
+	withConf(t, &conf.Config{Qiniu: &conf.Qiniu{
+		AccessKey: "ak", SecretKey: "sk", Bucket: bucket,
+		Domain: server.URL, UpHost: server.URL, RsHost: server.URL,
+	}})
+
+	srv := GetFilesSrv()
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	owner := uint64(suffix%1_000_000_000 + 1_000_000)
+	other := owner + 1
+	data := []byte(fmt.Sprintf("qiniu test %d", suffix))
+	hash := qiniutest.Etag(data)
+
+	callback := func(userID uint64, key string) *pb.FileUploadResponse {
+		resp, err := srv.QiniuUploadCallback(ctx, &pb.QiniuUploadCallbackRequest{
+			Key: key, Hash: hash, FileSize: int64(len(data)), UserID: userID, Filename: "a.txt",
+		})
+		if err != nil || resp.Code != e.SUCCESS {
+			t.Fatalf("callback %s: resp=%v err=%v", key, resp, err)
+		}
+		return resp
+	}
+
+	key := fmt.Sprintf("uploads/%d/%d-a.txt", owner, suffix)
+	server.Put(bucket, key, data)
+	first := callback(owner, key)
+	if first.ObjectUrl == "" {
+		t.Fatal("callback should return a download url")
+	}
+	// 七牛云重试回调时返回已有记录
+	if again := callback(owner, key); again.FileID != first.FileID {
+		t.Fatalf("retried callback created file %d, want %d", again.FileID, first.FileID)
+	}
+
+	// 其他用户直传相同内容：创建秒传记录并删除重复的对象
+	dupKey := fmt.Sprintf("uploads/%d/%d-a.txt", other, suffix)
+	server.Put(bucket, dupKey, data)
+	shared := callback(other, dupKey)
+	if shared.FileID == first.FileID {
+		t.Fatal("instant upload should create a new record")
+	}
+	if server.Object(bucket, dupKey) != nil {
+		t.Fatal("duplicate object not discarded")
+	}
+
+	del := func(userID, fileID uint64) {
+		resp, err := srv.QiniuFileDelete(ctx, &pb.FileDeleteRequest{UserID: userID, FileID: fileID})
+		if err != nil || resp.Code != e.SUCCESS {
+			t.Fatalf("delete %d: resp=%v err=%v", fileID, resp, err)
+		}
+	}
+	// 删除原始记录后秒传记录仍引用该对象，不能删除
+	del(owner, first.FileID)
+	if server.Object(bucket, key) == nil {
+		t.Fatal("object referenced by an instant-upload record was deleted")
+	}
+	resp, err := srv.QiniuFileDownload(ctx, &pb.FileDownloadRequest{UserID: other, FileID: shared.FileID})
+	if err != nil || resp.Code != e.SUCCESS {
+		t.Fatalf("download shared record: resp=%v err=%v", resp, err)
+	}
+	// 最后一条引用删除后清理对象
+	del(other, shared.FileID)
+	if server.Object(bucket, key) != nil {
+		t.Fatal("unreferenced object not deleted")
+	}
+}
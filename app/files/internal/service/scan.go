@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"grpc-todolist-disk/app/files/dao"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	"grpc-todolist-disk/conf"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/qiniu"
+	"grpc-todolist-disk/utils/scanner"
+	"grpc-todolist-disk/utils/storage"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	scanQueueSize = 1024
+	scanSweepPage = 100
+)
+
+var (
+	scanQueue chan uint
+	scanning  sync.Map // 已在队列中的文件 ID，避免重复扫描
+)
+
+// StartScanner 开启扫描时启动扫描协程，并定期补扫待扫描、扫描失败和开启扫描之前上传的文件
+func StartScanner(ctx context.Context) error {
+	if !conf.ScanEnabled() {
+		return nil
+	}
+	c := conf.Conf.Scan
+	s, err := scanner.New(c)
+	if err != nil {
+		return err
+	}
+	timeout := time.Duration(c.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	interval := time.Duration(c.SweepInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	scanQueue = make(chan uint, scanQueueSize)
+	for i := 0; i < max(c.Workers, 1); i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-scanQueue:
+					scanFile(ctx, s, id, timeout)
+					scanning.Delete(id)
+				}
+			}
+		}()
+	}
+	go sweepUnscanned(ctx, interval)
+	return nil
+}
+
+// enqueueScan 上传完成后投递扫描任务，队列已满时留给定期补扫
+func enqueueScan(file *model.Files) {
+	if scanQueue == nil || file.ScanStatus != model.ScanPending {
+		return
+	}
+	if _, loaded := scanning.LoadOrStore(file.ID, struct{}{}); loaded {
+		return
+	}
+	select {
+	case scanQueue <- file.ID:
+	default:
+		scanning.Delete(file.ID)
+	}
+}
+
+func sweepUnscanned(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		var after uint
+		for {
+			files, err := dao.NewFilesDao().FindUnscanned(after, scanSweepPage)
+			if err != nil {
+				zap.L().Warn("查询待扫描文件失败", zap.Error(err))
+				break
+			}
+			for _, file := range files {
+				after = file.ID
+				if _, loaded := scanning.LoadOrStore(file.ID, struct{}{}); loaded {
+					continue
+				}
+				select {
+				case scanQueue <- file.ID:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if len(files) < scanSweepPage {
+				break
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// scanFile 扫描文件指向的物理对象，结果写入引用该对象的所有记录
+func scanFile(ctx context.Context, s scanner.Scanner, fileID uint, timeout time.Duration) {
+	file, err := dao.NewFilesDao().GetFileByID(fileID)
+	if err != nil || file.ScanStatus == model.ScanClean || file.ScanStatus == model.ScanInfected {
+		return // 已删除或已有结果
+	}
+	objectName := dao.PhysicalObjectName(file)
+	log := zap.L().With(zap.Uint("file_id", file.ID), zap.String("object", objectName))
+
+	status := model.ScanClean
+	result, err := scanObject(ctx, s, file, objectName, timeout)
+	switch {
+	case err != nil:
+		status = model.ScanError
+		log.Warn("文件扫描失败", zap.Error(err))
+	case result.Infected:
+		status = model.ScanInfected
+		log.Warn("发现恶意文件", zap.String("signature", result.Signature))
+	}
+	// 先更新状态再隔离，隔离失败时文件也已经不能下载。七牛云对象不隔离，只通过状态拒绝下载
+	if err = dao.NewFilesDao().SetScanStatus(objectName, status); err != nil {
+		log.Warn("更新扫描状态失败", zap.Error(err))
+		return
+	}
+	if status == model.ScanInfected && file.Bucket != "qiniu" {
+		if err = quarantine(objectName); err != nil {
+			log.Warn("隔离文件失败", zap.Error(err))
+		}
+	}
+}
+
+// scanObject 读取物理对象内容并扫描，七牛云对象通过私有下载地址读取
+func scanObject(ctx context.Context, s scanner.Scanner, file *model.Files, objectName string, timeout time.Duration) (*scanner.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var (
+		obj io.ReadCloser
+		err error
+	)
+	if file.Bucket == "qiniu" {
+		obj, err = qiniu.NewQiniuClient().Open(ctx, objectName)
+	} else {
+		obj, err = storage.OpenContent(filepath.Join("stores/uploaded_files", objectName), file.Layout, file.Codec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	return s.Scan(ctx, obj)
+}
+
+func quarantinePath(objectName string) string {
+	dir := "stores/quarantine"
+	if conf.Conf != nil && conf.Conf.Scan != nil && conf.Conf.Scan.QuarantinePath != "" {
+		dir = conf.Conf.Scan.QuarantinePath
+	}
+	return filepath.Join(dir, objectName)
+}
+
+// quarantine 将物理对象移入隔离目录，分块布局只移动清单，分块仍保留引用直到文件被删除
+func quarantine(objectName string) error {
+	dst := quarantinePath(objectName)
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join("stores/uploaded_files", objectName), dst)
+}
+
+// localObjectPath 返回物理对象在本地的路径，被隔离的对象位于隔离目录
+func localObjectPath(objectName string) string {
+	path := filepath.Join("stores/uploaded_files", objectName)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if _, err = os.Stat(quarantinePath(objectName)); err == nil {
+			return quarantinePath(objectName)
+		}
+	}
+	return path
+}
+
+// scanErrCode 开启扫描时只有扫描通过的文件（本地和七牛云）可以下载或读取内容
+func scanErrCode(file *model.Files) int64 {
+	if !conf.ScanEnabled() {
+		return e.SUCCESS
+	}
+	switch file.ScanStatus {
+	case model.ScanClean:
+		return e.SUCCESS
+	case model.ScanInfected:
+		return e.ErrorFileInfected
+	default:
+		return e.ErrorFileNotScanned
+	}
+}
+
+// qiniuURL 返回七牛云文件的下载地址，开启扫描且文件未通过扫描时不返回地址
+func qiniuURL(client *qiniu.QiniuClient, file *model.Files) string {
+	if scanErrCode(file) != e.SUCCESS {
+		return ""
+	}
+	return client.DownloadURL(dao.PhysicalObjectName(file))
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"grpc-todolist-disk/app/files/dao"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	"grpc-todolist-disk/conf"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/qiniu/qiniutest"
+	"grpc-todolist-disk/utils/scanner"
+)
+
+// withConf 在测试期间替换全局配置
+func withConf(t *testing.T, c *conf.Config) {
+	t.Helper()
+	old := conf.Conf
+	conf.Conf = c
+	t.Cleanup(func() { conf.Conf = old })
+}
+
+// chdirTemp 切换到临时目录，stores/ 下的文件都写在临时目录中
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// testDB 连接 FILES_TEST_DSN 指定的 MySQL 测试库（会自动建表），未设置时跳过
+func testDB(t *testing.T) {
+	t.Helper()
+	dsn := os.Getenv("FILES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("未设置 FILES_TEST_DSN，跳过需要 MySQL 的测试")
+	}
+	if err := dao.Database(dsn); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeObject 模拟网关把上传内容写入正式目录
+func writeObject(t *testing.T, objectName, content string) {
+	t.Helper()
+	path := filepath.Join("stores/uploaded_files", objectName)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func scanConf() *conf.Config {
+	return &conf.Config{Scan: &conf.Scan{Enabled: true, Scanner: "fake"}}
+}
+
+func TestScanObjectLocal(t *testing.T) {
+	chdirTemp(t)
+	writeObject(t, "clean.txt", "hello")
+	writeObject(t, "eicar.txt", "prefix "+scanner.EICAR+" suffix")
+
+	s := scanner.NewFake()
+	result, err := scanObject(context.Background(), s, &model.Files{}, "clean.txt", time.Second)
+	if err != nil || result.Infected {
+		t.Fatalf("clean.txt: result=%+v err=%v", result, err)
+	}
+	result, err = scanObject(context.Background(), s, &model.Files{}, "eicar.txt", time.Second)
+	if err != nil || !result.Infected || result.Signature != "Eicar-Test-Signature" {
+		t.Fatalf("eicar.txt: result=%+v err=%v", result, err)
+	}
+}
+
+func TestScanObjectQiniu(t *testing.T) {
+	server := qiniutest.New("ak", "sk")
+	defer server.Close()
+	server.Private = true
+	server.Put("bucket", "uploads/1/eicar.txt", []byte(scanner.EICAR))
+	withConf(t, &conf.Config{Qiniu: &conf.Qiniu{
+		AccessKey: "ak", SecretKey: "sk", Bucket: "bucket", Domain: server.URL, Private: true,
+	}})
+
+	file := &model.Files{Bucket: "qiniu", ObjectName: "uploads/1/eicar.txt"}
+	result, err := scanObject(context.Background(), scanner.NewFake(), file, file.ObjectName, time.Second)
+	if err != nil || !result.Infected {
+		t.Fatalf("qiniu object: result=%+v err=%v", result, err)
+	}
+	// 对象不存在时是扫描失败而不是通过
+	if _, err = scanObject(context.Background(), scanner.NewFake(), file, "uploads/1/missing", time.Second); err == nil {
+		t.Fatal("missing qiniu object should fail to scan")
+	}
+}
+
+func TestScanErrCode(t *testing.T) {
+	withConf(t, scanConf())
+	cases := []struct {
+		file *model.Files
+		want int64
+	}{
+		{&model.Files{Bucket: "local", ScanStatus: model.ScanClean}, e.SUCCESS},
+		{&model.Files{Bucket: "local", ScanStatus: model.ScanPending}, e.ErrorFileNotScanned},
+		{&model.Files{Bucket: "local", ScanStatus: ""}, e.ErrorFileNotScanned},
+		{&model.Files{Bucket: "local", ScanStatus: model.ScanInfected}, e.ErrorFileInfected},
+		{&model.Files{Bucket: "qiniu", ScanStatus: model.ScanPending}, e.ErrorFileNotScanned},
+		{&model.Files{Bucket: "qiniu", ScanStatus: model.ScanInfected}, e.ErrorFileInfected},
+		{&model.Files{Bucket: "qiniu", ScanStatus: model.ScanClean}, e.SUCCESS},
+	}
+	for _, c := range cases {
+		if got := scanErrCode(c.file); got != c.want {
+			t.Errorf("%s/%q: got %d, want %d", c.file.Bucket, c.file.ScanStatus, got, c.want)
+		}
+	}
+
+	withConf(t, &conf.Config{})
+	if got := scanErrCode(&model.Files{ScanStatus: model.ScanPending}); got != e.SUCCESS {
+		t.Errorf("scan disabled: got %d", got)
+	}
+}
+
+// TestUploadScanDownload 上传 → 待扫描（拒绝下载）→ 扫描发现病毒并隔离 → 拒绝下载
+func TestUploadScanDownload(t *testing.T) {
+	testDB(t)
+	chdirTemp(t)
+	withConf(t, scanConf())
+
+	srv := GetFilesSrv()
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	userID := uint64(suffix%1_000_000_000 + 1_000_000)
+
+	upload := func(name, content string) uint64 {
+		objectName := fmt.Sprintf("scan-test/%d-%s", suffix, name)
+		writeObject(t, objectName, content)
+		resp, err := srv.FileUpload(ctx, &pb.FileUploadRequest{
+			UserID:     userID,
+			Filename:   name,
+			FileSize:   int64(len(content)),
+			ObjectName: objectName,
+			FileHash:   fmt.Sprintf("scan-test-%d-%s", suffix, name),
+		})
+		if err != nil || resp.Code != e.SUCCESS {
+			t.Fatalf("upload %s: resp=%v err=%v", name, resp, err)
+		}
+		return resp.FileID
+	}
+	download := func(fileID uint64) int32 {
+		resp, err := srv.FileDownload(ctx, &pb.FileDownloadRequest{UserID: userID, FileID: fileID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Code
+	}
+
+	infectedID := upload("eicar.txt", scanner.EICAR)
+	cleanID := upload("hello.txt", "hello")
+
+	file, err := dao.NewFilesDao().GetFileByID(uint(infectedID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.ScanStatus != model.ScanPending {
+		t.Fatalf("new upload scan status = %q, want pending", file.ScanStatus)
+	}
+	if code := download(infectedID); code != e.ErrorFileNotScanned {
+		t.Fatalf("download before scan: code %d", code)
+	}
+
+	s := scanner.NewFake()
+	scanFile(ctx, s, uint(infectedID), time.Second)
+	scanFile(ctx, s, uint(cleanID), time.Second)
+
+	if file, err = dao.NewFilesDao().GetFileByID(uint(infectedID)); err != nil {
+		t.Fatal(err)
+	}
+	if file.ScanStatus != model.ScanInfected {
+		t.Fatalf("scan status = %q, want infected", file.ScanStatus)
+	}
+	if _, err = os.Stat(quarantinePath(file.ObjectName)); err != nil {
+		t.Fatalf("infected object not quarantined: %v", err)
+	}
+	if code := download(infectedID); code != e.ErrorFileInfected {
+		t.Fatalf("download infected file: code %d", code)
+	}
+	if code := download(cleanID); code != e.SUCCESS {
+		t.Fatalf("download clean file: code %d", code)
+	}
+}
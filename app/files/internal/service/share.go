@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"grpc-todolist-disk/app/files/dao"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+	"strings"
+)
+
+// aclErrCode 将权限校验的错误转换为响应码和提示信息
+func aclErrCode(err error) (int64, string) {
+	switch {
+	case errors.Is(err, dao.ErrFolderNotFound):
+		return e.ErrorFolderNotExist, e.GetMsg(e.ErrorFolderNotExist)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return e.ErrorFileNotExist, e.GetMsg(e.ErrorFileNotExist)
+	case errors.Is(err, dao.ErrPermissionDenied):
+		return e.ErrorFilePermission, e.GetMsg(e.ErrorFilePermission)
+	case errors.Is(err, dao.ErrFolderTooDeep):
+		return e.InvalidParams, err.Error()
+	default:
+		return e.ERROR, "查询文件信息失败"
+	}
+}
+
+// checkFolderWritable 校验用户是否可以向文件夹写入，folderID 为 0 表示自己的根目录
+func checkFolderWritable(userID, folderID uint64) error {
+	return dao.NewAclDao().CheckFolderWritable(uint(userID), uint(folderID))
+}
+
+// checkMoveOwner 移动到其他所有者的目录树会转移所有权，要求操作者是源资源的所有者，
+// 防止编辑者把共享目录中的内容移到自己的目录下据为己有
+func checkMoveOwner(userID uint64, srcOwner uint, dstFolderID uint64) error {
+	dstOwner, err := dao.NewAclDao().FolderOwner(uint(dstFolderID), uint(userID))
+	if err != nil {
+		return err
+	}
+	if dstOwner != srcOwner && srcOwner != uint(userID) {
+		return dao.ErrPermissionDenied
+	}
+	return nil
+}
+
+// checkResource 校验用户对文件或文件夹的权限
+func checkResource(userID uint64, resourceType string, resourceID uint64, role string) error {
+	switch resourceType {
+	case model.ResourceTypeFile:
+		_, err := dao.NewFilesDao().GetAccessibleFile(uint(userID), uint(resourceID), role)
+		return err
+	case model.ResourceTypeFolder:
+		return dao.NewAclDao().CheckFolder(uint(userID), uint(resourceID), role)
+	default:
+		return errors.New("未知的资源类型")
+	}
+}
+
+func pageParams(page, pageSize int32) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return int(page), int(pageSize)
+}
+
+// FolderCreate 创建文件夹
+func (*FilesSrv) FolderCreate(ctx context.Context, req *pb.FolderCreateRequest) (resp *pb.FolderCreateResponse, err error) {
+	resp = new(pb.FolderCreateResponse)
+	resp.Code = e.SUCCESS
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || strings.ContainsAny(req.Name, "/\\") {
+		resp.Code = e.InvalidParams
+		resp.Msg = "文件夹名称不合法"
+		return resp, nil
+	}
+	if err = checkFolderWritable(req.UserID, req.ParentID); err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	if err = dao.NewFolderDao().CheckDepth(uint(req.ParentID), 1); err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	folder, err := dao.NewFolderDao().CreateFolder(req)
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "创建文件夹失败: " + err.Error()
+		return resp, nil
+	}
+	resp.FolderID = uint64(folder.ID)
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// FileRename 文件重命名，需要编辑权限
+func (*FilesSrv) FileRename(ctx context.Context, req *pb.FileRenameRequest) (resp *pb.FileCommonResponse, err error) {
+	resp = new(pb.FileCommonResponse)
+	resp.Code = e.SUCCESS
+	req.NewName = strings.TrimSpace(req.NewName)
+	if req.NewName == "" || strings.ContainsAny(req.NewName, "/\\") {
+		resp.Code = e.InvalidParams
+		resp.Msg = "文件名不合法"
+		return resp, nil
+	}
+	file, err := dao.NewFilesDao().GetAccessibleFile(uint(req.UserID), uint(req.FileID), model.RoleEditor)
+	if err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	if err = dao.NewFilesDao().RenameFile(file.ID, req.NewName); err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "重命名失败: " + err.Error()
+		return resp, nil
+	}
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// ShareGrant 将文件或文件夹共享给用户/用户组，需要 owner 权限
+func (*FilesSrv) ShareGrant(ctx context.Context, req *pb.ShareGrantRequest) (resp *pb.ShareGrantResponse, err error) {
+	resp = new(pb.ShareGrantResponse)
+	resp.Code = e.SUCCESS
+
+	if model.RoleLevel(req.Role) == 0 ||
+		(req.GranteeType != model.GranteeTypeUser && req.GranteeType != model.GranteeTypeGroup) ||
+		req.GranteeID == 0 {
+		resp.Code = e.InvalidParams
+		resp.Msg = e.GetMsg(e.InvalidParams)
+		return resp, nil
+	}
+	if req.GranteeType == model.GranteeTypeUser && req.GranteeID == req.UserID {
+		resp.Code = e.InvalidParams
+		resp.Msg = "不能共享给自己"
+		return resp, nil
+	}
+	if err = checkResource(req.UserID, req.ResourceType, req.ResourceID, model.RoleOwner); err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	if req.GranteeType == model.GranteeTypeGroup {
+		if _, err = dao.NewShareDao().GetGroupByID(uint(req.GranteeID)); err != nil {
+			resp.Code = e.ERROR
+			resp.Msg = "用户组不存在"
+			return resp, nil
+		}
+	}
+
+	share, err := dao.NewShareDao().CreateShare(req)
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "共享失败: " + err.Error()
+		return resp, nil
+	}
+	resp.ShareID = uint64(share.ID)
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// ShareRevoke 撤销授权：授权发起人或资源的 owner 可以撤销
+func (*FilesSrv) ShareRevoke(ctx context.Context, req *pb.ShareRevokeRequest) (resp *pb.FileCommonResponse, err error) {
+	resp = new(pb.FileCommonResponse)
+	resp.Code = e.SUCCESS
+
+	share, err := dao.NewShareDao().GetShareByID(uint(req.ShareID))
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "授权记录不存在"
+		return resp, nil
+	}
+	if share.OwnerID != uint(req.UserID) {
+		if err = checkResource(req.UserID, share.ResourceType, uint64(share.ResourceID), model.RoleOwner); err != nil {
+			resp.Code, resp.Msg = aclErrCode(err)
+			return resp, nil
+		}
+	}
+	if err = dao.NewShareDao().DeleteShare(share.ID); err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "撤销授权失败: " + err.Error()
+		return resp, nil
+	}
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// ShareList 查看某个资源上的授权列表，需要 owner 权限
+func (*FilesSrv) ShareList(ctx context.Context, req *pb.ShareListRequest) (resp *pb.ShareListResponse, err error) {
+	resp = new(pb.ShareListResponse)
+	resp.Code = e.SUCCESS
+
+	if err = checkResource(req.UserID, req.ResourceType, req.ResourceID, model.RoleOwner); err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	page, pageSize := pageParams(req.Page, req.PageSize)
+	shares, total, err := dao.NewShareDao().ListByResource(req.ResourceType, uint(req.ResourceID), page, pageSize)
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "查询授权失败: " + err.Error()
+		return resp, nil
+	}
+	resp.Shares = buildShareModels(shares)
+	resp.Total = total
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// SharedWithMe 共享给我的文件和文件夹（包括通过用户组获得的）
+func (*FilesSrv) SharedWithMe(ctx context.Context, req *pb.ShareListRequest) (resp *pb.ShareListResponse, err error) {
+	resp = new(pb.ShareListResponse)
+	resp.Code = e.SUCCESS
+
+	groupIDs, err := dao.NewAclDao().GroupIDsOfUser(uint(req.UserID))
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "查询用户组失败: " + err.Error()
+		return resp, nil
+	}
+	page, pageSize := pageParams(req.Page, req.PageSize)
+	shares, total, err := dao.NewShareDao().ListSharedWith(uint(req.UserID), groupIDs, page, pageSize)
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "查询共享失败: " + err.Error()
+		return resp, nil
+	}
+	resp.Shares = buildShareModels(shares)
+	resp.Total = total
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+func buildShareModels(shares []*model.FileShare) []*pb.ShareModel {
+	shareDao := dao.NewShareDao()
+	res := make([]*pb.ShareModel, 0, len(shares))
+	for _, share := range shares {
+		res = append(res, &pb.ShareModel{
+			ShareID:      uint64(share.ID),
+			OwnerID:      uint64(share.OwnerID),
+			ResourceType: share.ResourceType,
+			ResourceID:   uint64(share.ResourceID),
+			ResourceName: shareDao.ResourceName(share.ResourceType, share.ResourceID),
+			GranteeType:  share.GranteeType,
+			GranteeID:    uint64(share.GranteeID),
+			Role:         share.Role,
+			CreatedAt:    share.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return res
+}
+
+// GroupCreate 创建用户组，创建者自动成为成员
+func (*FilesSrv) GroupCreate(ctx context.Context, req *pb.GroupCreateRequest) (resp *pb.GroupCreateResponse, err error) {
+	resp = new(pb.GroupCreateResponse)
+	resp.Code = e.SUCCESS
+	if strings.TrimSpace(req.Name) == "" {
+		resp.Code = e.InvalidParams
+		resp.Msg = "用户组名称不能为空"
+		return resp, nil
+	}
+	group, err := dao.NewShareDao().CreateGroup(req)
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "创建用户组失败: " + err.Error()
+		return resp, nil
+	}
+	resp.GroupID = uint64(group.ID)
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// GroupAddMember 添加组成员，仅组的创建者可操作
+func (*FilesSrv) GroupAddMember(ctx context.Context, req *pb.GroupMemberRequest) (resp *pb.FileCommonResponse, err error) {
+	resp = new(pb.FileCommonResponse)
+	resp.Code = e.SUCCESS
+	group, err := dao.NewShareDao().GetGroupByID(uint(req.GroupID))
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "用户组不存在"
+		return resp, nil
+	}
+	if group.OwnerID != uint(req.UserID) {
+		resp.Code = e.ErrorFilePermission
+		resp.Msg = "只有用户组创建者可以添加成员"
+		return resp, nil
+	}
+	if err = dao.NewShareDao().AddGroupMember(group.ID, uint(req.MemberID)); err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "添加成员失败: " + err.Error()
+		return resp, nil
+	}
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// GroupRemoveMember 移除组成员，组的创建者可移除任何人，成员可以退出
+func (*FilesSrv) GroupRemoveMember(ctx context.Context, req *pb.GroupMemberRequest) (resp *pb.FileCommonResponse, err error) {
+	resp = new(pb.FileCommonResponse)
+	resp.Code = e.SUCCESS
+	group, err := dao.NewShareDao().GetGroupByID(uint(req.GroupID))
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "用户组不存在"
+		return resp, nil
+	}
+	if group.OwnerID != uint(req.UserID) && req.MemberID != req.UserID {
+		resp.Code = e.ErrorFilePermission
+		resp.Msg = "只有用户组创建者可以移除成员"
+		return resp, nil
+	}
+	if err = dao.NewShareDao().RemoveGroupMember(group.ID, uint(req.MemberID)); err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "移除成员失败: " + err.Error()
+		return resp, nil
+	}
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"grpc-todolist-disk/app/files/dao"
+	"grpc-todolist-disk/app/files/internal/repository/utils"
+	"grpc-todolist-disk/conf"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/storage"
+	"os"
+	"path/filepath"
+)
+
+// chunkingConf 返回分块配置，未配置时使用默认值
+func chunkingConf() (enabled bool, avgSize int, minFileSize int64) {
+	avgSize, minFileSize = storage.DefaultChunkAvgSize, 4*storage.DefaultChunkAvgSize
+	if conf.Conf == nil || conf.Conf.Storage == nil {
+		return false, avgSize, minFileSize
+	}
+	s := conf.Conf.Storage
+	if s.ChunkAvgSize > 0 {
+		avgSize = s.ChunkAvgSize
+	}
+	if s.ChunkMinFileSize > 0 {
+		minFileSize = s.ChunkMinFileSize
+	}
+	return s.Chunking, avgSize, minFileSize
+}
+
+// compressionConf 返回压缩配置，未配置时不压缩
+func compressionConf() (enabled bool, minSize int64, types []string) {
+	if conf.Conf == nil || conf.Conf.Storage == nil {
+		return false, 0, nil
+	}
+	s := conf.Conf.Storage
+	return s.Compression, s.CompressMinSize, s.CompressTypes
+}
+
+// compressObject 按文件类型和大小决定是否压缩，压缩收益不足 10% 时保留原文件。
+// 压缩后的内容原地替换 path，返回压缩格式和压缩后的大小
+func compressObject(path, filename string, size int64) (string, int64, error) {
+	enabled, minSize, types := compressionConf()
+	if !enabled || size < minSize || !storage.Compressible(filename, types) {
+		return storage.CodecNone, size, nil
+	}
+	zstPath := path + ".zst"
+	n, err := storage.CompressFile(path, zstPath, storage.DefaultFrameSize)
+	if err != nil {
+		return "", 0, err
+	}
+	if n >= size-size/10 {
+		utils.SafeRemove(zstPath)
+		return storage.CodecNone, size, nil
+	}
+	if err = os.Rename(zstPath, path); err != nil {
+		utils.SafeRemove(zstPath)
+		return "", 0, err
+	}
+	return storage.CodecZstd, n, nil
+}
+
+// storeObject 将临时文件保存到正式路径，返回对象的保存方式。
+// 文本类文件先压缩；开启分块时大文件再按内容切分，分块只保存一份，正式路径上只写入清单
+func storeObject(tempPath, finalPath, filename string) (storage.Info, error) {
+	var info storage.Info
+	if err := os.MkdirAll(filepath.Dir(finalPath), os.ModePerm); err != nil {
+		return info, err
+	}
+	stat, err := os.Stat(tempPath)
+	if err != nil {
+		return info, err
+	}
+	if info.Codec, info.StoredSize, err = compressObject(tempPath, filename, stat.Size()); err != nil {
+		return info, err
+	}
+
+	enabled, avgSize, minFileSize := chunkingConf()
+	if !enabled || info.StoredSize < minFileSize {
+		info.Layout = storage.LayoutRaw
+		if tempPath == finalPath {
+			return info, nil
+		}
+		return info, os.Rename(tempPath, finalPath)
+	}
+
+	chunker := storage.NewChunker(avgSize)
+	m, err := storage.BuildManifest(tempPath, chunker)
+	if err != nil {
+		return info, err
+	}
+	// 先增加引用计数再写分块，避免并发删除刚写入的分块
+	chunkDao := dao.NewChunkDao()
+	if err = chunkDao.Acquire(m); err != nil {
+		return info, err
+	}
+	if err = storage.WriteChunks(tempPath, m, chunker); err == nil {
+		err = storage.WriteManifest(finalPath, m)
+	}
+	if err != nil {
+		if releaseErr := chunkDao.Release(m, storage.RemoveChunk); releaseErr != nil {
+			zap.L().Warn("释放分块引用失败", zap.String("path", finalPath), zap.Error(releaseErr))
+		}
+		return info, err
+	}
+	// 原地转换时 tempPath 已被清单覆盖
+	if tempPath != finalPath {
+		utils.SafeRemove(tempPath)
+	}
+	info.Layout = storage.LayoutChunked
+	return info, nil
+}
+
+// releaseObject 删除正式路径上的对象，分块布局同时释放分块引用
+func releaseObject(path, layout string) error {
+	if layout == storage.LayoutChunked {
+		m, err := storage.ReadManifest(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if m != nil {
+			if err = dao.NewChunkDao().Release(m, storage.RemoveChunk); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// StorageStats 存储统计，返回逻辑大小、物理占用和去重比（仅管理员）
+func (*FilesSrv) StorageStats(ctx context.Context, req *pb.StorageStatsRequest) (resp *pb.StorageStatsResponse, err error) {
+	resp = new(pb.StorageStatsResponse)
+	resp.Code = e.SUCCESS
+	if !conf.IsAdmin(uint(req.UserID)) {
+		resp.Code = e.ErrorNotAdmin
+		resp.Msg = e.GetMsg(e.ErrorNotAdmin)
+		return resp, nil
+	}
+	stats, err := dao.NewChunkDao().Stats()
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "统计存储失败: " + err.Error()
+		return resp, nil
+	}
+	resp.LogicalBytes = stats.LogicalBytes
+	resp.PhysicalBytes = stats.PhysicalBytes
+	resp.ChunkBytes = stats.ChunkBytes
+	resp.ChunkCount = stats.ChunkCount
+	resp.ChunkedFiles = stats.ChunkedFiles
+	resp.RawBytes = stats.RawBytes
+	if stats.PhysicalBytes > 0 {
+		resp.DedupRatio = float64(stats.LogicalBytes) / float64(stats.PhysicalBytes)
+	}
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
@@ -0,0 +1,466 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"grpc-todolist-disk/app/files/dao"
+	"grpc-todolist-disk/app/files/internal/repository/model"
+	"grpc-todolist-disk/app/files/internal/repository/utils"
+	"grpc-todolist-disk/conf"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/policy"
+	"grpc-todolist-disk/utils/qiniu"
+	"grpc-todolist-disk/utils/redis_cache"
+	"grpc-todolist-disk/utils/signurl"
+	"grpc-todolist-disk/utils/throttle"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDirectExpire = 3600
+	directTempDir       = "stores/uploaded_temp/direct"
+)
+
+// directSlots 直传不经过网关，在这里按用户等级限制并发上传数。
+// gRPC 接口（包括流式上传下载）只由网关调用，网关已占用名额，这里不再重复计数
+var directSlots *throttle.Slots
+
+// directServer 接收本地直传的 HTTP 服务，未启动时为 nil
+var directServer *http.Server
+
+// directConf 返回直传配置和凭证有效期，未配置密钥时返回 nil
+func directConf() (*conf.DirectUpload, time.Duration) {
+	c := conf.Conf.Direct
+	if c == nil || c.SignSecret == "" {
+		return nil, 0
+	}
+	expire := c.Expire
+	if expire <= 0 {
+		expire = defaultDirectExpire
+	}
+	return c, time.Duration(expire) * time.Second
+}
+
+// PresignUpload 签发直传地址：本地存储直接 PUT 到 files 服务，七牛云使用限定 key 和大小的上传凭证。
+// 声明的哈希未经验证，不能用于秒传，确认上传时按实际内容去重
+func (*FilesSrv) PresignUpload(ctx context.Context, req *pb.PresignUploadRequest) (resp *pb.PresignUploadResponse, err error) {
+	resp = new(pb.PresignUploadResponse)
+	resp.Code = e.SUCCESS
+	c, expire := directConf()
+	if c == nil {
+		resp.Code = e.ERROR
+		resp.Msg = "未配置直传签名密钥"
+		return resp, nil
+	}
+	if req.Bucket == "" {
+		req.Bucket = "local"
+	}
+	if req.Bucket != "local" && req.Bucket != "qiniu" {
+		resp.Code = e.InvalidParams
+		resp.Msg = "不支持的存储: " + req.Bucket
+		return resp, nil
+	}
+	if req.FileHash == "" || req.FileSize < 0 {
+		resp.Code = e.InvalidParams
+		resp.Msg = "需要提供文件大小和哈希"
+		return resp, nil
+	}
+	if req.Bucket == "local" && c.Addr == "" {
+		resp.Code = e.ERROR
+		resp.Msg = "未开启本地直传"
+		return resp, nil
+	}
+	if err = checkFolderWritable(req.UserID, req.FolderID); err != nil {
+		resp.Code, resp.Msg = aclErrCode(err)
+		return resp, nil
+	}
+	route := directRoute(req.Bucket)
+	if rej := checkUpload(route, req.UserID, req.Filename, req.FileSize); rej != nil {
+		resp.Code, resp.Msg, resp.Rejection = rejectionPb(rej)
+		return resp, nil
+	}
+
+	u := signurl.Upload{
+		UserID:   req.UserID,
+		FolderID: req.FolderID,
+		Filename: req.Filename,
+		Size:     req.FileSize,
+		Hash:     req.FileHash,
+		Bucket:   req.Bucket,
+		Expires:  time.Now().Add(expire).Unix(),
+	}
+	switch req.Bucket {
+	case "qiniu":
+		client := qiniu.NewQiniuClient()
+		if resp.Url, err = client.UploadHost(); err != nil {
+			resp.Code = e.ERROR
+			resp.Msg = "获取七牛云上传地址失败: " + err.Error()
+			return resp, nil
+		}
+		u.Key = qiniu.GenerateObjectName(req.UserID, req.Filename)
+		// 大小上限为 0 表示不限制，空文件按 1 字节限制，确认时再校验实际大小
+		resp.Token, _ = client.ClientUploadToken(u.Key, max(req.FileSize, 1))
+		resp.Key = u.Key
+		resp.Method = http.MethodPost
+		resp.UploadID = signurl.SignUpload([]byte(c.SignSecret), u)
+	default:
+		u.Key = fmt.Sprintf("%d/%d_%s", req.UserID, time.Now().UnixMilli(), utils.Clean(req.Filename))
+		resp.UploadID = signurl.SignUpload([]byte(c.SignSecret), u)
+		resp.Method = http.MethodPut
+		resp.Url = strings.TrimRight(c.BaseURL, "/") + signurl.UploadPath + resp.UploadID
+	}
+	resp.ExpiresAt = u.Expires
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// ConfirmUpload 客户端上传完成后确认：校验存储的对象与签发时声明的大小和哈希一致后创建文件记录（相同内容已存在时创建秒传记录），不一致时删除对象
+func (*FilesSrv) ConfirmUpload(ctx context.Context, req *pb.ConfirmUploadRequest) (resp *pb.FileUploadResponse, err error) {
+	resp = new(pb.FileUploadResponse)
+	resp.Code = e.SUCCESS
+	c, _ := directConf()
+	if c == nil {
+		resp.Code = e.ERROR
+		resp.Msg = "未配置直传签名密钥"
+		return resp, nil
+	}
+	// 凭证过期前已经上传的对象仍然可以确认
+	u, err := signurl.VerifyUpload([]byte(c.SignSecret), req.UploadID, time.Now(), true)
+	if err != nil {
+		resp.Code, resp.Msg = e.InvalidParams, err.Error()
+		return resp, nil
+	}
+	if u.UserID != req.UserID {
+		resp.Code, resp.Msg = e.ErrorFilePermission, e.GetMsg(e.ErrorFilePermission)
+		return resp, nil
+	}
+	// 重复确认返回已有记录
+	file, err := dao.NewFilesDao().FindByObjectName(u.Key)
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = "查询文件记录失败: " + err.Error()
+		return resp, nil
+	}
+	if file == nil {
+		if u.Bucket == "qiniu" {
+			file, resp.Code, resp.Msg, resp.Rejection = confirmQiniu(u)
+		} else {
+			file, resp.Code, resp.Msg, resp.Rejection = confirmLocal(u)
+		}
+		if resp.Code != e.SUCCESS {
+			return resp, nil
+		}
+	}
+
+	resp.FileID = uint64(file.ID)
+	if file.Bucket == "qiniu" {
+		resp.ObjectUrl = qiniuURL(qiniu.NewQiniuClient(), file)
+	} else {
+		resp.ObjectUrl = filepath.Join("stores/uploaded_files", dao.PhysicalObjectName(file))
+	}
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// confirmLocal 校验本地临时文件并保存到正式目录
+func confirmLocal(u *signurl.Upload) (*model.Files, int64, string, *pb.PolicyRejection) {
+	tempPath := filepath.Join(directTempDir, u.Key)
+	size, hash, err := hashFile(tempPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, e.ERROR, "上传的文件不存在或已过期清理", nil
+	}
+	if err != nil {
+		return nil, e.ERROR, "读取上传的文件失败: " + err.Error(), nil
+	}
+	if size != u.Size || hash != u.Hash {
+		utils.SafeRemove(tempPath)
+		return nil, e.ErrorUploadMismatch, e.GetMsg(e.ErrorUploadMismatch), nil
+	}
+	// 签发之后权限或用量可能已经变化，按实际内容再校验一次
+	if err = checkFolderWritable(u.UserID, u.FolderID); err != nil {
+		utils.SafeRemove(tempPath)
+		code, msg := aclErrCode(err)
+		return nil, code, msg, nil
+	}
+	if rej := checkUploadedFile(policy.RouteDirectUpload, u.UserID, u.Filename, tempPath); rej != nil {
+		utils.SafeRemove(tempPath)
+		code, msg, pbRej := rejectionPb(rej)
+		return nil, code, msg, pbRej
+	}
+	// 签发之后其他人可能上传了相同内容
+	exist, err := instantUpload(u.UserID, u.FolderID, u.Filename, u.Hash)
+	if err != nil {
+		return nil, e.ERROR, "检查文件 Hash 失败: " + err.Error(), nil
+	}
+	if exist != nil {
+		utils.SafeRemove(tempPath)
+		return exist, e.SUCCESS, "", nil
+	}
+
+	finalPath := filepath.Join("stores/uploaded_files", u.Key)
+	info, err := storeObject(tempPath, finalPath, u.Filename)
+	if err != nil {
+		utils.SafeRemove(tempPath)
+		return nil, e.ERROR, "移动文件失败: " + err.Error(), nil
+	}
+	file, err := dao.NewFilesDao().CreateBigFile(&pb.BigFileUploadRequest{
+		UserID:     u.UserID,
+		FolderID:   u.FolderID,
+		Filename:   u.Filename,
+		FileSize:   u.Size,
+		FileHash:   u.Hash,
+		ObjectName: u.Key,
+	}, info)
+	if err != nil {
+		if err := releaseObject(finalPath, info.Layout); err != nil {
+			zap.L().Warn("清理正式文件失败", zap.String("path", finalPath), zap.Error(err))
+		}
+		return nil, e.ERROR, e.GetMsg(e.ERROR), nil
+	}
+	enqueueScan(file)
+	return file, e.SUCCESS, "", nil
+}
+
+// confirmQiniu 按七牛云返回的大小和 etag 校验对象
+func confirmQiniu(u *signurl.Upload) (*model.Files, int64, string, *pb.PolicyRejection) {
+	client := qiniu.NewQiniuClient()
+	info, err := client.Stat(u.Key)
+	if errors.Is(err, qiniu.ErrNotFound) {
+		return nil, e.ERROR, "上传的文件不存在", nil
+	}
+	if err != nil {
+		return nil, e.ERROR, "查询七牛云文件失败: " + err.Error(), nil
+	}
+	if info.Size != u.Size || info.Hash != u.Hash {
+		discardQiniuObject(client, u.Key)
+		return nil, e.ErrorUploadMismatch, e.GetMsg(e.ErrorUploadMismatch), nil
+	}
+	if err = checkFolderWritable(u.UserID, u.FolderID); err != nil {
+		discardQiniuObject(client, u.Key)
+		code, msg := aclErrCode(err)
+		return nil, code, msg, nil
+	}
+	if rej := checkUpload(policy.RouteQiniuDirectUpload, u.UserID, u.Filename, info.Size); rej != nil {
+		discardQiniuObject(client, u.Key)
+		code, msg, pbRej := rejectionPb(rej)
+		return nil, code, msg, pbRej
+	}
+	fileHash := storedHash("qiniu", u.Hash)
+	exist, err := instantUpload(u.UserID, u.FolderID, u.Filename, fileHash)
+	if err != nil {
+		return nil, e.ERROR, "检查文件 Hash 失败: " + err.Error(), nil
+	}
+	if exist != nil {
+		discardQiniuObject(client, u.Key)
+		return exist, e.SUCCESS, "", nil
+	}
+	file, err := dao.NewFilesDao().CreateQiniuFile(&pb.FileUploadRequest{
+		UserID:     u.UserID,
+		Filename:   u.Filename,
+		FileSize:   u.Size,
+		ObjectName: u.Key,
+		FileHash:   fileHash,
+		FolderID:   u.FolderID,
+	})
+	if err != nil {
+		discardQiniuObject(client, u.Key)
+		return nil, e.ERROR, "数据库保存失败: " + err.Error(), nil
+	}
+	enqueueScan(file)
+	return file, e.SUCCESS, "", nil
+}
+
+// instantUpload 已有相同内容时返回目标位置上的记录（不在目标位置时创建秒传记录），没有时返回 nil
+func instantUpload(userID, folderID uint64, filename, fileHash string) (*model.Files, error) {
+	exist, err := dao.NewFilesDao().FindByHash(&pb.CheckFileRequest{FileHash: fileHash, UserID: userID})
+	if err == nil && exist == nil {
+		exist, err = dao.NewFilesDao().FindGlobalByHash(fileHash)
+	}
+	if err != nil || exist == nil {
+		return nil, err
+	}
+	return placeInFolder(userID, folderID, filename, exist)
+}
+
+// placeInFolder 秒传命中的记录不在目标位置时（其他文件夹、其他文件名或其他用户），在目标位置创建一条秒传记录
+func placeInFolder(userID, folderID uint64, filename string, exist *model.Files) (*model.Files, error) {
+	if exist.UserID == uint(userID) && exist.FolderID == uint(folderID) && exist.FileName == filename {
+		return exist, nil
+	}
+	return dao.NewFilesDao().CreateUserFileFromExistingInFolder(userID, folderID, filename, exist)
+}
+
+// storedHash 七牛云直传的文件以 etag 去重，加前缀与本地文件的 SHA256 区分
+func storedHash(bucket, hash string) string {
+	if bucket == "qiniu" {
+		return "qetag_" + hash
+	}
+	return hash
+}
+
+func directRoute(bucket string) string {
+	if bucket == "qiniu" {
+		return policy.RouteQiniuDirectUpload
+	}
+	return policy.RouteDirectUpload
+}
+
+func hashFile(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// StartUploadServer 配置了 directUpload.addr 时启动接收本地直传的 HTTP 服务，并定期清理过期未确认的临时文件
+func StartUploadServer(ctx context.Context) error {
+	c, expire := directConf()
+	if c == nil || c.Addr == "" {
+		return nil
+	}
+	directSlots = throttle.NewSlots(redis_cache.ConnectRedis())
+	mux := http.NewServeMux()
+	mux.HandleFunc(signurl.UploadPath, handleDirectUpload)
+	server := &http.Server{Addr: c.Addr, Handler: mux}
+	directServer = server
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			zap.L().Error("直传服务退出", zap.Error(err))
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(expire)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				server.Close()
+				return
+			case <-ticker.C:
+				sweepDirectTemp(2 * expire)
+			}
+		}
+	}()
+	return nil
+}
+
+// ShutdownUploadServer 停止接收新的直传，等待进行中的上传完成，ctx 结束后强制关闭
+func ShutdownUploadServer(ctx context.Context) {
+	if directServer == nil {
+		return
+	}
+	if err := directServer.Shutdown(ctx); err != nil {
+		zap.L().Warn("直传服务未能在超时前关闭", zap.Error(err))
+		directServer.Close()
+	}
+}
+
+// handleDirectUpload PUT /upload/<凭证>，请求体为文件内容。凭证即授权，不需要登录
+func handleDirectUpload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Access-Control-Allow-Methods", "PUT")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPut {
+		writeUploadResult(w, http.StatusMethodNotAllowed, "只支持 PUT")
+		return
+	}
+	c, _ := directConf()
+	if c == nil {
+		writeUploadResult(w, http.StatusNotFound, "未开启直传")
+		return
+	}
+	token := strings.TrimPrefix(r.URL.Path, signurl.UploadPath)
+	u, err := signurl.VerifyUpload([]byte(c.SignSecret), token, time.Now(), false)
+	if err != nil || u.Bucket != "local" {
+		writeUploadResult(w, http.StatusForbidden, "上传凭证无效或已过期")
+		return
+	}
+	if r.ContentLength > u.Size {
+		writeUploadResult(w, http.StatusRequestEntityTooLarge, "超过声明的文件大小")
+		return
+	}
+
+	// 与网关的上传共用并发名额和带宽上限
+	release, err := directSlots.Acquire(r.Context(), uint(u.UserID), throttle.Upload)
+	if err != nil {
+		w.Header().Set("Retry-After", strconv.Itoa(int(throttle.RetryAfter.Seconds())))
+		writeUploadResult(w, http.StatusTooManyRequests, e.GetMsg(e.ErrorTooManyTransfers))
+		return
+	}
+	defer release()
+
+	path := filepath.Join(directTempDir, u.Key)
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		writeUploadResult(w, http.StatusInternalServerError, "创建目录失败")
+		return
+	}
+	// 同一凭证只能上传一次，上一次完成后直接确认即可
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		writeUploadResult(w, http.StatusConflict, "已经上传过，请确认上传")
+		return
+	}
+	if err != nil {
+		writeUploadResult(w, http.StatusInternalServerError, "创建文件失败")
+		return
+	}
+	body := throttle.Reader(r.Context(), r.Body, throttle.For(uint(u.UserID), throttle.Upload))
+	n, err := io.Copy(out, io.LimitReader(body, u.Size+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	switch {
+	case err != nil:
+		utils.SafeRemove(path)
+		writeUploadResult(w, http.StatusBadRequest, "接收文件失败")
+	case n > u.Size:
+		utils.SafeRemove(path)
+		writeUploadResult(w, http.StatusRequestEntityTooLarge, "超过声明的文件大小")
+	default:
+		writeUploadResult(w, http.StatusOK, "上传完成，请确认上传")
+	}
+}
+
+func writeUploadResult(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": status, "msg": msg})
+}
+
+// sweepDirectTemp 删除超过 maxAge 仍未确认的临时文件
+func sweepDirectTemp(maxAge time.Duration) {
+	deadline := time.Now().Add(-maxAge)
+	filepath.WalkDir(directTempDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().Before(deadline) {
+			utils.SafeRemove(path)
+		}
+		return nil
+	})
+}
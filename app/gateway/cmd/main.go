@@ -3,8 +3,10 @@ package main
 import (
 	"context"
 	"errors"
+	"grpc-todolist-disk/app/gateway/middleware"
 	"grpc-todolist-disk/app/gateway/router"
 	"grpc-todolist-disk/app/gateway/rpc"
+	"grpc-todolist-disk/app/gateway/utils/cache"
 	"grpc-todolist-disk/app/gateway/utils/mq"
 	"grpc-todolist-disk/conf"
 	"log"
@@ -19,16 +21,12 @@ func main() {
 	conf.InitConfig()
 	mq.Init()
 	rpc.Init()
+	cache.Init()
+	// 并发传输数记录在 redis 中，多个网关实例共享
+	middleware.InitTransfer(cache.RDB)
 
 	// 创建 Gin 路由和 HTTP Server 实例
-	r := router.NewRouter()
-	server := &http.Server{
-		Addr:           conf.Conf.Server.Port,
-		Handler:        r,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
-		MaxHeaderBytes: 1 << 20,
-	}
+	server := router.NewServer()
 
 	// 启动 HTTP 监听（子协程）
 	go func() {
@@ -52,6 +50,11 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	// 请求处理完后再关闭生产者，发送缓冲中的消息
+	if err := mq.KfWriter.Close(); err != nil {
+		log.Printf("Failed to close kafka writer: %v", err)
+	}
+	cache.RDB.Close()
 
 	log.Println("Server exiting")
 }
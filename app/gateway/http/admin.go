@@ -0,0 +1,28 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"grpc-todolist-disk/app/gateway/rpc"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/ctl"
+	"net/http"
+)
+
+// StorageStats 存储统计：逻辑大小、物理占用和去重比（仅管理员）
+func StorageStats(ctx *gin.Context) {
+	var req pb.StorageStatsRequest
+	user, err := ctl.GetUserInfo(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "获取用户信息错误"))
+		return
+	}
+	req.UserID = uint64(user.ID)
+
+	r, err := rpc.StorageStats(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "StorageStats RPC服务调用错误"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, r))
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"grpc-todolist-disk/app/gateway/rpc"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/ctl"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxWatchTimeout 长轮询最长等待时间
+const maxWatchTimeout = 60 * time.Second
+
+// ListChanges 增量拉取变更日志：GET /changes?cursor=0&limit=100
+func ListChanges(ctx *gin.Context) {
+	var req pb.ChangeListRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ctl.RespError(ctx, err, "参数绑定错误"))
+		return
+	}
+
+	user, err := ctl.GetUserInfo(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "获取用户信息错误"))
+		return
+	}
+	req.UserID = uint64(user.ID)
+
+	r, err := rpc.ListChanges(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "ListChanges RPC服务调用错误"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, r))
+}
+
+// WatchChanges 长轮询变更日志：GET /changes/watch?cursor=10&timeout=30，超时返回空列表
+func WatchChanges(ctx *gin.Context) {
+	var req pb.ChangeListRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ctl.RespError(ctx, err, "参数绑定错误"))
+		return
+	}
+
+	user, err := ctl.GetUserInfo(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "获取用户信息错误"))
+		return
+	}
+	req.UserID = uint64(user.ID)
+
+	timeout := 30 * time.Second
+	if seconds, err := strconv.Atoi(ctx.Query("timeout")); err == nil && seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+	if timeout > maxWatchTimeout {
+		timeout = maxWatchTimeout
+	}
+	// 网关默认的写超时较短，长轮询需要单独延长
+	_ = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Now().Add(timeout + 10*time.Second))
+
+	r, err := rpc.WaitChanges(ctx.Request.Context(), &req, timeout)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "WatchChanges RPC服务调用错误"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, r))
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"grpc-todolist-disk/app/gateway/rpc"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/ctl"
+	"net/http"
+)
+
+// DeltaSignature 获取文件当前版本的块签名
+func DeltaSignature(ctx *gin.Context) {
+	var req pb.DeltaSignatureRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ctl.RespError(ctx, err, "参数绑定错误"))
+		return
+	}
+
+	user, err := ctl.GetUserInfo(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "获取用户信息错误"))
+		return
+	}
+	req.UserID = uint64(user.ID)
+
+	r, err := rpc.DeltaSignature(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "DeltaSignature RPC服务调用错误"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, r))
+}
+
+// DeltaUpload 上传增量操作（JSON，data 字段为 base64），服务端重建后保存为新版本
+func DeltaUpload(ctx *gin.Context) {
+	var req pb.DeltaUploadRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ctl.RespError(ctx, err, "参数绑定错误"))
+		return
+	}
+
+	user, err := ctl.GetUserInfo(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "获取用户信息错误"))
+		return
+	}
+	req.UserID = uint64(user.ID)
+
+	r, err := rpc.DeltaUpload(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "DeltaUpload RPC服务调用错误"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, r))
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"grpc-todolist-disk/app/gateway/middleware"
+	"grpc-todolist-disk/app/gateway/rpc"
+	"grpc-todolist-disk/conf"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/ctl"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/signurl"
+	"grpc-todolist-disk/utils/throttle"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// PresignDownload 签发有时效的下载链接
+func PresignDownload(ctx *gin.Context) {
+	var req pb.PresignDownloadRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ctl.RespError(ctx, err, "参数绑定错误"))
+		return
+	}
+
+	user, err := ctl.GetUserInfo(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "获取用户信息错误"))
+		return
+	}
+	req.UserID = uint64(user.ID)
+	// bind_ip=self 绑定当前请求的 IP
+	if req.BindIP == "self" {
+		req.BindIP = ctx.ClientIP()
+	}
+
+	r, err := rpc.PresignDownload(ctx, &req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "PresignDownload RPC服务调用错误"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, r))
+}
+
+// SignedDownload 通过签名链接下载，不需要登录；按签发链接的用户重新校验权限，撤销共享后链接随之失效
+func SignedDownload(ctx *gin.Context) {
+	var secret []byte
+	if conf.Conf.Download != nil {
+		secret = []byte(conf.Conf.Download.SignSecret)
+	}
+	p, err := signurl.Verify(secret, ctx.Param("file_id"), ctx.Request.URL.Query(), ctx.ClientIP(), time.Now())
+	if err != nil {
+		status := http.StatusForbidden
+		if errors.Is(err, signurl.ErrMalformed) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, ctl.RespError(ctx, err, "下载链接无效", e.InvalidParams))
+		return
+	}
+
+	// 按签发链接的用户限制并发和带宽，HEAD 请求不传输内容
+	if ctx.Request.Method == http.MethodGet {
+		release, ok := middleware.BeginTransfer(ctx, uint(p.UserID), throttle.Download)
+		if !ok {
+			return
+		}
+		defer release()
+	}
+
+	r, err := rpc.FileDownload(ctx, &pb.FileDownloadRequest{UserID: p.UserID, FileID: p.FileID})
+	if r != nil && scanBlocked(ctx, r.Code, r.Msg) {
+		return
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		if r != nil {
+			status = http.StatusNotFound
+		}
+		ctx.JSON(status, ctl.RespError(ctx, err, "文件不存在或已无权访问"))
+		return
+	}
+	// 按链接的剩余有效期允许浏览器缓存
+	ctx.Header("Cache-Control", "private, max-age="+maxAge(p.Expires))
+	disposition := "inline"
+	if p.Attachment {
+		disposition = "attachment"
+	}
+	serveDownload(ctx, r, disposition)
+}
+
+func maxAge(expires int64) string {
+	return strconv.FormatInt(max(expires-time.Now().Unix(), 0), 10)
+}
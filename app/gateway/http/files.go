@@ -3,20 +3,27 @@ package http
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"grpc-todolist-disk/app/gateway/rpc"
 	"grpc-todolist-disk/app/gateway/utils"
+	"grpc-todolist-disk/app/gateway/utils/cache"
 	"grpc-todolist-disk/app/gateway/utils/mq"
 	pb "grpc-todolist-disk/idl/pb/files"
 	"grpc-todolist-disk/utils/ctl"
 	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/policy"
 	"grpc-todolist-disk/utils/qiniu"
+	"grpc-todolist-disk/utils/storage"
+	"grpc-todolist-disk/utils/uploadjob"
 	"io"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -48,22 +55,6 @@ func FileUpload(ctx *gin.Context) {
 	}
 	files := form.File["file"]
 	for _, file := range files {
-		if file.Size > 10*1024*1024 { // 文件大小超过10MB
-			ctx.JSON(400, gin.H{
-				"msg":  "文件过大",
-				"data": "文件大小超过10MB",
-				"code": "400",
-			})
-			return
-		}
-		if file.Filename == "" {
-			ctx.JSON(400, gin.H{
-				"msg":  "上传文件名不能为空",
-				"code": "400",
-			})
-			return
-		}
-
 		// 计算文件 hash
 		src, err := file.Open()
 		if err != nil {
@@ -75,6 +66,9 @@ func FileUpload(ctx *gin.Context) {
 			return
 		}
 		defer src.Close()
+		if !checkUploadPolicy(ctx, policy.RouteFileUpload, req.UserID, file, src) {
+			return
+		}
 
 		h := sha256.New()
 		if _, err := io.Copy(h, src); err != nil {
@@ -91,13 +85,11 @@ func FileUpload(ctx *gin.Context) {
 		exist, err := rpc.CheckFileExists(ctx, &pb.CheckFileRequest{
 			FileHash: req.FileHash,
 			UserID:   req.UserID,
+			FolderID: req.FolderID,
+			Filename: file.Filename,
 		})
 		if err != nil {
-			ctx.JSON(500, gin.H{
-				"msg":  "数据库查询失败",
-				"data": err.Error(),
-				"code": "500",
-			})
+			ctx.JSON(uploadErrStatus(exist), ctl.RespError(ctx, err, "CheckFileExists RPC服务调用错误"))
 			return
 		}
 		if exist.Exists {
@@ -142,6 +134,9 @@ func FileUpload(ctx *gin.Context) {
 	}
 
 	r, err := rpc.FileUpload(ctx, &req)
+	if r != nil && rejectPb(ctx, r.Rejection) {
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "FileUpload RPC服务调用错误"))
 		return
@@ -164,11 +159,20 @@ func BigFileUpload(ctx *gin.Context) {
 		return
 	}
 	defer file.Close()
+	if !checkUploadPolicy(ctx, policy.RouteBigFileUpload, uint64(user.ID), header, file) {
+		return
+	}
 
-	res, err := rpc.BigFileUpload(ctx.Request.Context(), ctx.Request.Body, &rpc.UploadMeta{
+	folderID, _ := strconv.ParseUint(ctx.PostForm("folder_id"), 10, 64)
+	res, err := rpc.BigFileUpload(ctx.Request.Context(), file, &rpc.UploadMeta{
 		UserID:   uint64(user.ID),
 		FileName: header.Filename,
+		FileSize: header.Size,
+		FolderID: folderID,
 	})
+	if res != nil && rejectPb(ctx, res.Rejection) {
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "上传失败"))
 		return
@@ -238,12 +242,71 @@ func FileDownload(ctx *gin.Context) {
 	req.UserID = uint64(user.ID)
 
 	r, err := rpc.FileDownload(ctx, &req)
+	if r != nil && scanBlocked(ctx, r.Code, r.Msg) {
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "FileDownload RPC服务调用错误"))
 		return
 	}
-	//ctx.File(r.DownloadUrl)	// 不强制下载，可以只做预览
-	ctx.FileAttachment(r.DownloadUrl, r.Filename) // 强制下载
+	serveDownload(ctx, r, "attachment")
+}
+
+// serveDownload 返回文件内容，disposition 为 attachment（下载）或 inline（预览、嵌入页面）；七牛云文件重定向到七牛云地址
+func serveDownload(ctx *gin.Context, r *pb.FileDownloadResponse, disposition string) {
+	if r.Bucket == "qiniu" {
+		ctx.Redirect(http.StatusFound, r.DownloadUrl)
+		return
+	}
+	if r.Layout == storage.LayoutRaw && r.Codec == storage.CodecNone && disposition == "attachment" {
+		//ctx.File(r.DownloadUrl)	// 不强制下载，可以只做预览
+		ctx.FileAttachment(r.DownloadUrl, r.Filename) // 强制下载
+		return
+	}
+	// 分块存储的文件按清单拼接后返回，支持 Range
+	obj, err := storage.Open(r.DownloadUrl, r.Layout)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "打开文件失败"))
+		return
+	}
+	defer obj.Close()
+	ctx.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": r.Filename}))
+	if r.Codec == storage.CodecNone {
+		http.ServeContent(ctx.Writer, ctx.Request, r.Filename, time.Time{}, obj)
+		return
+	}
+
+	// 压缩存储的文件：客户端支持该编码且不是 Range 请求时直接返回压缩后的内容，否则边读边解压
+	ctx.Header("Vary", "Accept-Encoding")
+	if ctx.GetHeader("Range") == "" && acceptsEncoding(ctx.GetHeader("Accept-Encoding"), r.Codec) {
+		contentType := mime.TypeByExtension(filepath.Ext(r.Filename))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		ctx.Header("Content-Type", contentType)
+		ctx.Header("Content-Encoding", r.Codec)
+		http.ServeContent(ctx.Writer, ctx.Request, "", time.Time{}, obj)
+		return
+	}
+	content, err := storage.Decode(obj, r.Codec)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "解压文件失败"))
+		return
+	}
+	http.ServeContent(ctx.Writer, ctx.Request, r.Filename, time.Time{}, content)
+}
+
+// acceptsEncoding 判断 Accept-Encoding 中是否接受指定编码（q=0 表示不接受）
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+		q := strings.ReplaceAll(params, " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
 }
 
 // AsyncFileUpload 异步上传（表单）
@@ -271,23 +334,8 @@ func AsyncFileUpload(ctx *gin.Context) {
 		return
 	}
 	files := form.File["file"]
+	var jobs []*uploadjob.Job
 	for _, file := range files {
-		if file.Size > 10*1024*1024 { // 文件大小超过10MB
-			ctx.JSON(400, gin.H{
-				"msg":  "文件过大",
-				"data": "文件大小超过10MB",
-				"code": "400",
-			})
-			return
-		}
-		if file.Filename == "" {
-			ctx.JSON(400, gin.H{
-				"msg":  "上传文件名不能为空",
-				"code": "400",
-			})
-			return
-		}
-
 		src, err := file.Open()
 		if err != nil {
 			ctx.JSON(200, gin.H{
@@ -298,35 +346,42 @@ func AsyncFileUpload(ctx *gin.Context) {
 			return
 		}
 		defer src.Close()
+		if !checkUploadPolicy(ctx, policy.RouteAsyncFileUpload, req.UserID, file, src) {
+			return
+		}
+
+		// 生成目标 ObjectName
+		req.FileSize = file.Size
+		req.ObjectName = fmt.Sprintf("%d/%d_%s", req.UserID, time.Now().UnixMilli(), utils.Clean(file.Filename))
+		req.Filename = file.Filename // 文件名里的中文会被”_“代替
 
-		fileBytes, err := io.ReadAll(src)
+		// 写入暂存目录，同时计算 hash，kafka 消息中只传暂存路径
+		tempPath, hash, err := stageAsyncUpload(src, req.ObjectName)
 		if err != nil {
-			ctx.JSON(200, gin.H{
-				"msg":  "读取文件失败",
+			ctx.JSON(500, gin.H{
+				"msg":  "暂存文件失败",
 				"data": err.Error(),
-				"code": "400",
+				"code": "500",
 			})
 			return
 		}
-
-		// 计算文件 hash
-		hash := utils.Sha256Hash(fileBytes)
 		req.FileHash = hash
 		// 检查数据库
+		// 检查目标文件夹的写入权限和秒传
 		exist, err := rpc.CheckFileExists(ctx, &pb.CheckFileRequest{
 			FileHash: req.FileHash,
 			UserID:   req.UserID,
+			FolderID: req.FolderID,
+			Filename: file.Filename,
 		})
 		if err != nil {
-			ctx.JSON(500, gin.H{
-				"msg":  "数据库查询失败",
-				"data": err.Error(),
-				"code": "500",
-			})
+			os.Remove(tempPath)
+			ctx.JSON(uploadErrStatus(exist), ctl.RespError(ctx, err, "CheckFileExists RPC服务调用错误"))
 			return
 		}
 		if exist.Exists {
 			// 命中，秒传
+			os.Remove(tempPath)
 			ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, &pb.FileUploadResponse{
 				Code:      e.SUCCESS,
 				Msg:       "秒传成功，文件已存在",
@@ -336,23 +391,34 @@ func AsyncFileUpload(ctx *gin.Context) {
 			return
 		}
 
-		// 生成目标 ObjectName
-		req.FileSize = file.Size
-		req.ObjectName = fmt.Sprintf("%d/%d_%s", req.UserID, time.Now().UnixMilli(), utils.Clean(file.Filename))
-		req.Filename = file.Filename // 文件名里的中文会被”_“代替
+		// 记录任务状态，客户端通过 upload_job 查询
+		job := &uploadjob.Job{ID: uploadjob.NewID(), UserID: req.UserID, Filename: file.Filename}
+		if err = uploadjob.Create(ctx, cache.RDB, job); err != nil {
+			os.Remove(tempPath)
+			ctx.JSON(500, gin.H{
+				"msg":  "创建上传任务失败",
+				"data": err.Error(),
+				"code": "500",
+			})
+			return
+		}
 
 		// 构建 Kafka 消息体，发送到异步上传消费者
 		msg := &mq.AsyncFileUploadMsg{
+			JobID:      job.ID,
 			UserID:     req.UserID,
 			Filename:   file.Filename,
 			FileSize:   file.Size,
 			FileHash:   hash,
 			ObjectName: req.ObjectName,
-			Content:    fileBytes,
+			TempPath:   tempPath,
+			FolderID:   req.FolderID,
 		}
 
 		// 发送 kafka 异步任务
 		if err = mq.SendFileUploadTask(msg); err != nil {
+			os.Remove(tempPath)
+			uploadjob.Delete(ctx, cache.RDB, job.ID)
 			ctx.JSON(500, gin.H{
 				"msg":  "异步任务发送失败",
 				"data": err.Error(),
@@ -360,14 +426,38 @@ func AsyncFileUpload(ctx *gin.Context) {
 			})
 			return
 		}
+		jobs = append(jobs, job)
 	}
 
 	// 异步处理响应
 	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, gin.H{
-		"msg": "文件上传任务已提交",
+		"msg":  "文件上传任务已提交",
+		"jobs": jobs,
 	}))
 }
 
+// stageAsyncUpload 把上传的文件写入暂存目录，返回暂存路径和 SHA256
+func stageAsyncUpload(src io.Reader, objectName string) (string, string, error) {
+	tempPath := filepath.Join(uploadjob.StagingDir, objectName)
+	if err := os.MkdirAll(filepath.Dir(tempPath), os.ModePerm); err != nil {
+		return "", "", err
+	}
+	out, err := os.Create(tempPath)
+	if err != nil {
+		return "", "", err
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, h), src)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return "", "", err
+	}
+	return tempPath, hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // QiniuFileUpload 七牛云表单上传
 func QiniuFileUpload(ctx *gin.Context) {
 	var req pb.FileUploadRequest
@@ -395,22 +485,6 @@ func QiniuFileUpload(ctx *gin.Context) {
 
 	files := form.File["file"]
 	for _, file := range files {
-		if file.Size > 10*1024*1024 { // 文件大小超过10MB
-			ctx.JSON(400, gin.H{
-				"msg":  "文件过大",
-				"data": "文件大小超过10MB",
-				"code": "400",
-			})
-			return
-		}
-		if file.Filename == "" {
-			ctx.JSON(400, gin.H{
-				"msg":  "上传文件名不能为空",
-				"code": "400",
-			})
-			return
-		}
-
 		src, err := file.Open()
 		if err != nil {
 			ctx.JSON(200, gin.H{
@@ -421,8 +495,12 @@ func QiniuFileUpload(ctx *gin.Context) {
 			return
 		}
 		defer src.Close()
+		if !checkUploadPolicy(ctx, policy.RouteQiniuFileUpload, req.UserID, file, src) {
+			return
+		}
 
-		fileBytes, err := io.ReadAll(src)
+		// 流式计算文件 hash，不把文件读入内存
+		hash, err := utils.Sha256Reader(src)
 		if err != nil {
 			ctx.JSON(200, gin.H{
 				"msg":  "读取文件失败",
@@ -431,9 +509,6 @@ func QiniuFileUpload(ctx *gin.Context) {
 			})
 			return
 		}
-
-		// 计算文件 hash
-		hash := utils.Sha256Hash(fileBytes)
 		req.FileHash = hash
 		req.FileSize = file.Size
 		req.Filename = file.Filename
@@ -452,12 +527,24 @@ func QiniuFileUpload(ctx *gin.Context) {
 			ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, userFileResp))
 			return
 		}
+		// 超过当日上传量等服务端策略
+		if userFileResp != nil && rejectPb(ctx, userFileResp.Rejection) {
+			return
+		}
 		// 如果返回错误且消息是"需要先上传文件到七牛云"，说明需要真正上传
 
 		// 如果不是秒传情况，则上传到七牛云
 		qiniuClient := qiniu.NewQiniuClient()
 		objectName := qiniu.GenerateObjectName(req.UserID, req.Filename)
-		qiniuURL, err := qiniuClient.UploadFile(objectName, fileBytes)
+		if _, err = src.Seek(0, io.SeekStart); err != nil {
+			ctx.JSON(500, gin.H{
+				"msg":  "读取文件失败",
+				"data": err.Error(),
+				"code": "500",
+			})
+			return
+		}
+		key, err := qiniuClient.UploadStream(objectName, src, file.Size)
 		if err != nil {
 			ctx.JSON(500, gin.H{
 				"msg":  "七牛云上传失败",
@@ -468,8 +555,11 @@ func QiniuFileUpload(ctx *gin.Context) {
 		}
 
 		// 保存文件信息到数据库
-		req.ObjectName = qiniuURL // 存储七牛云返回的完整访问URL
+		req.ObjectName = key // 只保存 key，下载地址由文件服务按空间类型生成
 		r, err := rpc.QiniuFileUpload(ctx, &req)
+		if r != nil && rejectPb(ctx, r.Rejection) {
+			return
+		}
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "QiniuFileUpload RPC服务调用错误"))
 			return
@@ -495,11 +585,20 @@ func QiniuBigFileUpload(ctx *gin.Context) {
 		return
 	}
 	defer file.Close()
+	if !checkUploadPolicy(ctx, policy.RouteQiniuBigFileUpload, uint64(user.ID), header, file) {
+		return
+	}
 
+	folderID, _ := strconv.ParseUint(ctx.PostForm("folder_id"), 10, 64)
 	res, err := rpc.QiniuBigFileUpload(ctx.Request.Context(), file, &rpc.UploadMeta{
 		UserID:   uint64(user.ID),
 		FileName: header.Filename,
+		FileSize: header.Size,
+		FolderID: folderID,
 	})
+	if res != nil && rejectPb(ctx, res.Rejection) {
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "七牛云上传失败"))
 		return
@@ -508,7 +607,7 @@ func QiniuBigFileUpload(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, res))
 }
 
-// QiniuFileDownload 七牛云文件下载（支持跨用户下载）
+// QiniuFileDownload 七牛云文件下载，他人的文件需要被共享后才能下载
 func QiniuFileDownload(ctx *gin.Context) {
 	var req pb.FileDownloadRequest
 	if err := ctx.ShouldBind(&req); err != nil {
@@ -523,21 +622,13 @@ func QiniuFileDownload(ctx *gin.Context) {
 		return
 	}
 
-	// 检查是否指定了 user_id 参数，如果没有则支持跨用户下载
-	userIDParam := ctx.Query("user_id")
-	if userIDParam != "" {
-		// 如果指定了user_id，则按指定用户查找
-		if userID, parseErr := strconv.ParseUint(userIDParam, 10, 64); parseErr == nil {
-			req.UserID = userID
-		} else {
-			req.UserID = uint64(user.ID) // 解析失败则使用当前用户
-		}
-	} else {
-		// 如果没有指定user_id，则支持跨用户下载（设置为0表示全局查找）
-		req.UserID = 0
-	}
+	// 始终以当前登录用户校验权限，不信任请求中的 user_id
+	req.UserID = uint64(user.ID)
 
 	r, err := rpc.QiniuFileDownload(ctx, &req)
+	if r != nil && scanBlocked(ctx, r.Code, r.Msg) {
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "QiniuFileDownload RPC服务调用错误"))
 		return
@@ -594,3 +685,17 @@ func QiniuFileDelete(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, r))
 }
+
+// scanBlocked 文件未通过恶意文件扫描时写入响应并返回 true
+func scanBlocked(ctx *gin.Context, code int32, msg string) bool {
+	switch code {
+	case e.ErrorFileNotScanned:
+		ctx.Header("Retry-After", "30")
+		ctx.JSON(http.StatusConflict, ctl.RespError(ctx, errors.New(msg), "文件尚未完成扫描", int(code)))
+	case e.ErrorFileInfected:
+		ctx.JSON(http.StatusForbidden, ctl.RespError(ctx, errors.New(msg), "文件已被隔离", int(code)))
+	default:
+		return false
+	}
+	return true
+}
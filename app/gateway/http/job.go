@@ -0,0 +1,112 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"grpc-todolist-disk/app/gateway/utils/cache"
+	"grpc-todolist-disk/utils/ctl"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/uploadjob"
+	"net/http"
+	"time"
+)
+
+const (
+	// jobEventWriteTimeout 推送单个事件的写超时，代替网关默认的写超时
+	jobEventWriteTimeout = 30 * time.Second
+	// jobEventKeepalive 没有事件时发送注释行，避免连接被代理断开
+	jobEventKeepalive = 15 * time.Second
+)
+
+// UploadJobStatus 查询异步上传任务的状态，完成后返回文件 ID
+func UploadJobStatus(ctx *gin.Context) {
+	user, err := ctl.GetUserInfo(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "获取用户信息错误"))
+		return
+	}
+	job, err := uploadjob.Get(ctx, cache.RDB, ctx.Param("id"))
+	// 其他用户的任务按不存在处理
+	if errors.Is(err, uploadjob.ErrNotFound) || (err == nil && job.UserID != uint64(user.ID)) {
+		ctx.JSON(http.StatusNotFound, ctl.RespError(ctx, uploadjob.ErrNotFound, "上传任务不存在", e.InvalidParams))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "查询上传任务失败"))
+		return
+	}
+	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, job))
+}
+
+// UploadJobEvents 以 SSE 推送当前用户任务的状态变化；指定 job_id 时只推送该任务，先发送当前状态，完成或失败后结束
+func UploadJobEvents(ctx *gin.Context) {
+	user, err := ctl.GetUserInfo(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "获取用户信息错误"))
+		return
+	}
+	userID := uint64(user.ID)
+	jobID := ctx.Query("job_id")
+
+	// 先订阅再查询当前状态，避免漏掉中间的变化
+	sub := uploadjob.Subscribe(ctx.Request.Context(), cache.RDB, userID)
+	defer sub.Close()
+	if _, err = sub.Receive(ctx.Request.Context()); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "订阅任务状态失败"))
+		return
+	}
+	var current *uploadjob.Job
+	if jobID != "" {
+		current, err = uploadjob.Get(ctx, cache.RDB, jobID)
+		if errors.Is(err, uploadjob.ErrNotFound) || (err == nil && current.UserID != userID) {
+			ctx.JSON(http.StatusNotFound, ctl.RespError(ctx, uploadjob.ErrNotFound, "上传任务不存在", e.InvalidParams))
+			return
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "查询上传任务失败"))
+			return
+		}
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	rc := http.NewResponseController(ctx.Writer)
+	send := func(job *uploadjob.Job) bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(jobEventWriteTimeout))
+		ctx.SSEvent("job", job)
+		ctx.Writer.Flush()
+		return jobID != "" && (job.State == uploadjob.StateDone || job.State == uploadjob.StateFailed)
+	}
+	if current != nil && send(current) {
+		return
+	}
+
+	keepalive := time.NewTicker(jobEventKeepalive)
+	defer keepalive.Stop()
+	events := sub.Channel()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-keepalive.C:
+			_ = rc.SetWriteDeadline(time.Now().Add(jobEventWriteTimeout))
+			if _, err = ctx.Writer.WriteString(": keepalive\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		case msg, ok := <-events:
+			if !ok {
+				return
+			}
+			var job uploadjob.Job
+			if json.Unmarshal([]byte(msg.Payload), &job) != nil || (jobID != "" && job.ID != jobID) {
+				continue
+			}
+			if send(&job) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/ctl"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/policy"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+// checkUploadPolicy 在读取文件内容前按上传策略校验文件名、大小和文件头，未通过时直接写入响应
+func checkUploadPolicy(ctx *gin.Context, route string, userID uint64, header *multipart.FileHeader, src multipart.File) bool {
+	engine := policy.Default()
+	if rej := engine.CheckFile(route, userID, header.Filename, header.Size); rej != nil {
+		rejectUpload(ctx, rej)
+		return false
+	}
+	head := make([]byte, policy.SniffLen)
+	n, err := src.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		ctx.JSON(http.StatusBadRequest, ctl.RespError(ctx, err, "读取文件失败"))
+		return false
+	}
+	if rej := engine.CheckContent(head[:n]); rej != nil {
+		rejectUpload(ctx, rej)
+		return false
+	}
+	return true
+}
+
+// rejectUpload 返回结构化的拒绝原因
+func rejectUpload(ctx *gin.Context, rej *policy.Rejection) {
+	ctx.JSON(rejectionStatus(rej.Rule), &ctl.Response{
+		Status: e.ErrorUploadPolicy,
+		Data:   rej,
+		Msg:    e.GetMsg(e.ErrorUploadPolicy),
+		Error:  rej.Reason,
+	})
+}
+
+// rejectPb files 服务返回拒绝原因时写入响应并返回 true
+func rejectPb(ctx *gin.Context, rej *pb.PolicyRejection) bool {
+	if rej == nil {
+		return false
+	}
+	rejectUpload(ctx, &policy.Rejection{
+		Rule:   rej.Rule,
+		Reason: rej.Reason,
+		Limit:  rej.Limit,
+		Actual: rej.Actual,
+	})
+	return true
+}
+
+func rejectionStatus(rule string) int {
+	switch rule {
+	case policy.RuleRouteSize, policy.RuleTierSize:
+		return http.StatusRequestEntityTooLarge
+	case policy.RuleExtension, policy.RuleMimeType:
+		return http.StatusUnsupportedMediaType
+	case policy.RuleDailyVolume:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusBadRequest
+	}
+}
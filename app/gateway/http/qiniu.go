@@ -0,0 +1,73 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"grpc-todolist-disk/app/gateway/rpc"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/ctl"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/qiniu"
+	"net/http"
+)
+
+// QiniuUploadToken 签发客户端直传七牛云的凭证
+func QiniuUploadToken(ctx *gin.Context) {
+	var req pb.QiniuUploadTokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ctl.RespError(ctx, err, "参数绑定错误"))
+		return
+	}
+
+	user, err := ctl.GetUserInfo(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "获取用户信息错误"))
+		return
+	}
+	req.UserID = uint64(user.ID)
+
+	r, err := rpc.QiniuUploadToken(ctx, &req)
+	if r != nil && rejectPb(ctx, r.Rejection) {
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "QiniuUploadToken RPC服务调用错误"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, r))
+}
+
+// QiniuUploadCallback 七牛云直传完成后的回调，不需要登录，以七牛云的签名为凭证。
+// 响应内容会被七牛云原样返回给上传的客户端，非 200 时客户端的上传请求失败
+func QiniuUploadCallback(ctx *gin.Context) {
+	ok, err := qiniu.NewQiniuClient().VerifyCallback(ctx.Request)
+	if err != nil || !ok {
+		if err == nil {
+			err = errors.New("回调签名无效")
+		}
+		ctx.JSON(http.StatusUnauthorized, ctl.RespError(ctx, err, "回调签名无效", e.InvalidParams))
+		return
+	}
+
+	var req pb.QiniuUploadCallbackRequest
+	if err = ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ctl.RespError(ctx, err, "参数绑定错误"))
+		return
+	}
+
+	r, err := rpc.QiniuUploadCallback(ctx, &req)
+	if r != nil && rejectPb(ctx, r.Rejection) {
+		return
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		if r != nil && r.Code == e.ErrorFilePermission {
+			status = http.StatusForbidden
+		}
+		ctx.JSON(status, ctl.RespError(ctx, err, "QiniuUploadCallback RPC服务调用错误"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, r))
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"grpc-todolist-disk/app/gateway/rpc"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/ctl"
+	"grpc-todolist-disk/utils/e"
+	"net/http"
+)
+
+// PresignUpload 签发直传地址，客户端把文件直接上传到 files 服务或七牛云
+func PresignUpload(ctx *gin.Context) {
+	var req pb.PresignUploadRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ctl.RespError(ctx, err, "参数绑定错误"))
+		return
+	}
+
+	user, err := ctl.GetUserInfo(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "获取用户信息错误"))
+		return
+	}
+	req.UserID = uint64(user.ID)
+
+	r, err := rpc.PresignUpload(ctx, &req)
+	if r != nil && rejectPb(ctx, r.Rejection) {
+		return
+	}
+	if err != nil {
+		ctx.JSON(uploadErrStatus(r), ctl.RespError(ctx, err, "PresignUpload RPC服务调用错误"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, r))
+}
+
+// ConfirmUpload 直传完成后确认，校验通过后创建文件记录
+func ConfirmUpload(ctx *gin.Context) {
+	var req pb.ConfirmUploadRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ctl.RespError(ctx, err, "参数绑定错误"))
+		return
+	}
+
+	user, err := ctl.GetUserInfo(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ctl.RespError(ctx, err, "获取用户信息错误"))
+		return
+	}
+	req.UserID = uint64(user.ID)
+
+	r, err := rpc.ConfirmUpload(ctx, &req)
+	if r != nil && rejectPb(ctx, r.Rejection) {
+		return
+	}
+	if err != nil {
+		ctx.JSON(uploadErrStatus(r), ctl.RespError(ctx, err, "ConfirmUpload RPC服务调用错误"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ctl.RespSuccess(ctx, r))
+}
+
+// uploadErrStatus 按 files 服务的错误码选择 HTTP 状态码
+func uploadErrStatus(r interface{ GetCode() int64 }) int {
+	if r == nil {
+		return http.StatusInternalServerError
+	}
+	switch r.GetCode() {
+	case e.InvalidParams:
+		return http.StatusBadRequest
+	case e.ErrorFilePermission:
+		return http.StatusForbidden
+	case e.ErrorUploadMismatch:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"grpc-todolist-disk/utils/ctl"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/throttle"
+)
+
+// transferIdleTimeout 传输按单次读写设置超时，代替 http.Server 对整个请求的读写超时，
+// 不限速的用户传输大文件也不会被整体超时中断
+const transferIdleTimeout = 30 * time.Second
+
+var transferSlots *throttle.Slots
+
+// InitTransfer 设置记录并发传输的 redis，未设置时只限速不限制并发
+func InitTransfer(rdb *redis.Client) {
+	transferSlots = throttle.NewSlots(rdb)
+}
+
+// Transfer 限制登录用户的上传或下载：超过并发数返回 429，请求体或响应按用户等级的带宽限速
+func Transfer(dir throttle.Direction) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := ctl.GetUserInfo(c.Request.Context())
+		if err != nil {
+			c.Next()
+			return
+		}
+		release, ok := BeginTransfer(c, user.ID, dir)
+		if !ok {
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
+
+// BeginTransfer 为 userID 开始一次传输，用于不经过 JWT 的接口（如签名下载链接）。
+// 名额已满时返回 429 并中止请求；否则替换请求体或响应（每次读写延长超时，有带宽限制时限速），
+// 调用方在传输结束后调用 release
+func BeginTransfer(c *gin.Context, userID uint, dir throttle.Direction) (release func(), ok bool) {
+	release, err := transferSlots.Acquire(c.Request.Context(), userID, dir)
+	if errors.Is(err, throttle.ErrTooManyTransfers) {
+		c.Header("Retry-After", strconv.Itoa(int(throttle.RetryAfter.Seconds())))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, ctl.RespError(c, err, "同时进行的传输过多", e.ErrorTooManyTransfers))
+		return nil, false
+	}
+	bucket := throttle.For(userID, dir) // 不限速时为 nil，Reader/Writer 原样返回
+	rc := http.NewResponseController(c.Writer)
+	if dir == throttle.Upload {
+		c.Request.Body = &limitedBody{
+			ReadCloser: c.Request.Body,
+			r:          throttle.Reader(c.Request.Context(), c.Request.Body, bucket),
+			rc:         rc,
+		}
+	} else {
+		c.Writer = &limitedWriter{
+			ResponseWriter: c.Writer,
+			w:              throttle.Writer(c.Request.Context(), c.Writer, bucket),
+			rc:             rc,
+		}
+	}
+	return release, true
+}
+
+// TransferHandler 用于不经过 Gin 的处理器（WebDAV），GET 按下载、PUT 按上传限制，需要放在认证之后
+func TransferHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var dir throttle.Direction
+		switch r.Method {
+		case http.MethodGet:
+			dir = throttle.Download
+		case http.MethodPut:
+			dir = throttle.Upload
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, err := ctl.GetUserInfo(r.Context())
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		release, err := transferSlots.Acquire(r.Context(), user.ID, dir)
+		if errors.Is(err, throttle.ErrTooManyTransfers) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(throttle.RetryAfter.Seconds())))
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		defer release()
+
+		bucket := throttle.For(user.ID, dir)
+		rc := http.NewResponseController(w)
+		if dir == throttle.Upload {
+			r.Body = &limitedBody{ReadCloser: r.Body, r: throttle.Reader(r.Context(), r.Body, bucket), rc: rc}
+		} else {
+			w = &limitedResponse{ResponseWriter: w, w: throttle.Writer(r.Context(), w, bucket), rc: rc}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitedBody 读取请求体（有带宽限制时限速），每次读取前延长读超时；
+// 写超时从读完请求头开始计算，同时延长，上传结束后才能写回响应
+type limitedBody struct {
+	io.ReadCloser
+	r  io.Reader
+	rc *http.ResponseController
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	deadline := time.Now().Add(transferIdleTimeout)
+	b.rc.SetReadDeadline(deadline)
+	b.rc.SetWriteDeadline(deadline)
+	return b.r.Read(p)
+}
+
+// limitedWriter 写入 Gin 的响应（有带宽限制时限速），每次写入前延长写超时。只暴露 gin.ResponseWriter 的方法，
+// http.ServeContent 不会绕过限速走 ReadFrom
+type limitedWriter struct {
+	gin.ResponseWriter
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	w.rc.SetWriteDeadline(time.Now().Add(transferIdleTimeout))
+	return w.w.Write(p)
+}
+
+func (w *limitedWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *limitedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// limitedResponse 与 limitedWriter 相同，用于 net/http 的处理器
+type limitedResponse struct {
+	http.ResponseWriter
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (w *limitedResponse) Write(p []byte) (int, error) {
+	w.rc.SetWriteDeadline(time.Now().Add(transferIdleTimeout))
+	return w.w.Write(p)
+}
+
+func (w *limitedResponse) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"grpc-todolist-disk/utils/throttle"
+)
+
+// 不限速的用户传输时间超过 http.Server 的读写超时也不会被中断
+func TestUnlimitedTransferOutlivesServerTimeouts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const serverTimeout = 200 * time.Millisecond
+	const chunks = 6
+	step := serverTimeout / 2 // 总耗时约为超时的 3 倍
+
+	r := gin.New()
+	r.GET("/download", func(c *gin.Context) {
+		release, ok := BeginTransfer(c, 1, throttle.Download)
+		if !ok {
+			return
+		}
+		defer release()
+		for i := 0; i < chunks; i++ {
+			time.Sleep(step)
+			c.Writer.WriteString("x")
+			c.Writer.Flush()
+		}
+	})
+	r.PUT("/upload", func(c *gin.Context) {
+		release, ok := BeginTransfer(c, 1, throttle.Upload)
+		if !ok {
+			return
+		}
+		defer release()
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, "%d", len(data))
+	})
+
+	server := httptest.NewUnstartedServer(r)
+	server.Config.ReadTimeout = serverTimeout
+	server.Config.WriteTimeout = serverTimeout
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != strings.Repeat("x", chunks) {
+		t.Fatalf("download: body %q err %v", body, err)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		for i := 0; i < chunks; i++ {
+			time.Sleep(step)
+			pw.Write([]byte("y"))
+		}
+		pw.Close()
+	}()
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"/upload", pr)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "6" {
+		t.Fatalf("upload: %d %q", resp.StatusCode, body)
+	}
+}
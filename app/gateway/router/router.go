@@ -8,6 +8,7 @@ import (
 	"grpc-todolist-disk/app/gateway/http"
 	"grpc-todolist-disk/app/gateway/middleware"
 	"grpc-todolist-disk/utils/logger"
+	"grpc-todolist-disk/utils/throttle"
 )
 
 func NewRouter() *gin.Engine {
@@ -30,6 +31,11 @@ func NewRouter() *gin.Engine {
 		// 用户服务
 		v1.POST("/user/register", http.UserRegister)
 		v1.POST("/user/login", http.UserLogin)
+		// 签名下载链接，签名即凭证
+		v1.GET("dl/:file_id", http.SignedDownload)
+		v1.HEAD("dl/:file_id", http.SignedDownload)
+		// 七牛云直传回调，以七牛云的签名为凭证
+		v1.POST("qiniu/callback", http.QiniuUploadCallback)
 
 		// 需要登录保护
 		authed := v1.Group("/")
@@ -39,6 +45,8 @@ func NewRouter() *gin.Engine {
 			authed.PUT("/user/update_password", http.UserChangePassword)
 			authed.POST("/user/logout", http.UserLogout)
 			authed.DELETE("/user/delete", http.UserDelete)
+			authed.POST("/user/app_password", http.UserAppPasswordCreate)
+			authed.DELETE("/user/app_password", http.UserAppPasswordDelete)
 
 			// 任务模块
 			authed.GET("task", http.GetTaskList)
@@ -48,21 +56,52 @@ func NewRouter() *gin.Engine {
 			authed.GET("task/show", http.ShowTask)
 
 			// 文件模块
-			authed.POST("file_upload", http.FileUpload)
-			authed.POST("big_file_upload", http.BigFileUpload)
+			authed.POST("file_upload", middleware.Transfer(throttle.Upload), http.FileUpload)
+			authed.POST("big_file_upload", middleware.Transfer(throttle.Upload), http.BigFileUpload)
 			authed.GET("file_list", http.FileList)
 			authed.DELETE("file_delete", http.FileDelete)
-			authed.GET("file_download", http.FileDownload)
+			authed.GET("file_download", middleware.Transfer(throttle.Download), http.FileDownload)
+			authed.POST("file_presign", http.PresignDownload)
+			// 预签名直传，内容不经过网关
+			authed.POST("upload_presign", http.PresignUpload)
+			authed.POST("upload_confirm", http.ConfirmUpload)
 			// kafka 异步处理
-			authed.POST("upload", http.AsyncFileUpload)
+			authed.POST("upload", middleware.Transfer(throttle.Upload), http.AsyncFileUpload)
+			authed.GET("jobs/events", http.UploadJobEvents)
+			authed.GET("jobs/:id", http.UploadJobStatus)
 
 			// 七牛云
-			authed.POST("qiniu_file_upload", http.QiniuFileUpload)
-			authed.POST("qiniu_big_file_upload", http.QiniuBigFileUpload)
+			authed.POST("qiniu_file_upload", middleware.Transfer(throttle.Upload), http.QiniuFileUpload)
+			authed.POST("qiniu_big_file_upload", middleware.Transfer(throttle.Upload), http.QiniuBigFileUpload)
 			authed.GET("qiniu_file_download", http.QiniuFileDownload)
 			authed.DELETE("qiniu_file_delete", http.QiniuFileDelete)
+			authed.POST("qiniu_upload_token", http.QiniuUploadToken)
 			// 全盘文件搜索
 			authed.GET("global_file_search", http.GlobalFileSearch)
+
+			// 文件夹与共享
+			authed.POST("folder_create", http.FolderCreate)
+			authed.PUT("file_rename", http.FileRename)
+			authed.GET("folder_list", http.FolderList)
+			authed.PUT("file_move", http.FileMove)
+			authed.PUT("folder_move", http.FolderMove)
+			authed.DELETE("folder_delete", http.FolderDelete)
+			authed.POST("share_grant", http.ShareGrant)
+			authed.DELETE("share_revoke", http.ShareRevoke)
+			authed.GET("share_list", http.ShareList)
+			authed.GET("shared_with_me", http.SharedWithMe)
+			authed.POST("group_create", http.GroupCreate)
+			authed.POST("group_member", http.GroupAddMember)
+			authed.DELETE("group_member", http.GroupRemoveMember)
+
+			// 变更日志（同步客户端）
+			authed.GET("changes", http.ListChanges)
+			authed.GET("changes/watch", http.WatchChanges)
+			// 增量同步
+			authed.GET("delta/signature", http.DeltaSignature)
+			authed.POST("delta/upload", middleware.Transfer(throttle.Upload), http.DeltaUpload)
+			// 管理员
+			authed.GET("admin/storage_stats", http.StorageStats)
 		}
 	}
 
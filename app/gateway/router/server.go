@@ -0,0 +1,23 @@
+package router
+
+import (
+	"grpc-todolist-disk/app/gateway/webdav"
+	"grpc-todolist-disk/conf"
+	"net/http"
+	"time"
+)
+
+// NewServer 创建网关的 HTTP Server，需要先初始化 rpc、cache 和 mq
+func NewServer() *http.Server {
+	// WebDAV 不经过 Gin，避免 Cors 中间件直接应答 OPTIONS 以及路由对 PROPFIND 等方法的限制
+	mux := http.NewServeMux()
+	mux.Handle(webdav.Prefix, webdav.NewDefaultHandler())
+	mux.Handle("/", NewRouter())
+	return &http.Server{
+		Addr:           conf.Conf.Server.Port,
+		Handler:        mux,
+		ReadTimeout:    10 * time.Second,
+		WriteTimeout:   10 * time.Second,
+		MaxHeaderBytes: 1 << 20,
+	}
+}
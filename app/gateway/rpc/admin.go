@@ -0,0 +1,22 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+)
+
+// StorageStats 存储统计（仅管理员）
+func StorageStats(ctx context.Context, req *pb.StorageStatsRequest) (resp *pb.StorageStatsResponse, err error) {
+	resp, err = FilesClient.StorageStats(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
@@ -0,0 +1,45 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+	"time"
+)
+
+// ListChanges 增量拉取变更日志
+func ListChanges(ctx context.Context, req *pb.ChangeListRequest) (resp *pb.ChangeListResponse, err error) {
+	resp, err = FilesClient.ListChanges(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// WaitChanges 长轮询：等到游标之后出现新的变更或超时，超时时返回空列表和原游标
+func WaitChanges(ctx context.Context, req *pb.ChangeListRequest, timeout time.Duration) (*pb.ChangeListResponse, error) {
+	watchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stream, err := FilesClient.WatchChanges(watchCtx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		if watchCtx.Err() != nil && ctx.Err() == nil {
+			return &pb.ChangeListResponse{Code: e.SUCCESS, Msg: e.GetMsg(e.SUCCESS), Cursor: req.Cursor}, nil
+		}
+		return nil, err
+	}
+	if resp.Code != e.SUCCESS {
+		return nil, errors.New(resp.Msg)
+	}
+	return resp, nil
+}
@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+	"io"
+)
+
+// deltaBatchBytes 增量上传时每条消息携带的字面数据上限
+const deltaBatchBytes = 1 << 20
+
+// DeltaSignature 获取文件当前版本的全部块签名（合并服务端分批返回的结果）
+func DeltaSignature(ctx context.Context, req *pb.DeltaSignatureRequest) (*pb.DeltaSignatureResponse, error) {
+	stream, err := FilesClient.DeltaSignature(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var resp *pb.DeltaSignatureResponse
+	for {
+		r, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r.Code != e.SUCCESS {
+			return nil, errors.New(r.Msg)
+		}
+		if resp == nil {
+			resp = r
+			continue
+		}
+		resp.Blocks = append(resp.Blocks, r.Blocks...)
+		resp.FileHash = r.FileHash
+	}
+	if resp == nil {
+		return nil, errors.New("签名结果为空")
+	}
+	return resp, nil
+}
+
+// DeltaUpload 将增量操作分批发送给 files 服务
+func DeltaUpload(ctx context.Context, req *pb.DeltaUploadRequest) (*pb.BigFileUploadResponse, error) {
+	stream, err := FilesClient.DeltaUpload(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("初始化上传流失败: %w", err)
+	}
+
+	msg := &pb.DeltaUploadRequest{
+		UserID:       req.UserID,
+		FileID:       req.FileID,
+		BaseVersion:  req.BaseVersion,
+		BlockSize:    req.BlockSize,
+		ExpectedHash: req.ExpectedHash,
+	}
+	size := 0
+	for _, op := range req.Ops {
+		if size > 0 && size+len(op.Data) > deltaBatchBytes {
+			if err = stream.Send(msg); err != nil {
+				return nil, fmt.Errorf("发送增量失败: %w", err)
+			}
+			msg, size = &pb.DeltaUploadRequest{}, 0
+		}
+		msg.Ops = append(msg.Ops, op)
+		size += len(op.Data)
+	}
+	if err = stream.Send(msg); err != nil {
+		return nil, fmt.Errorf("发送增量失败: %w", err)
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, fmt.Errorf("关闭上传流失败: %w", err)
+	}
+	if resp.Code != e.SUCCESS {
+		return nil, errors.New(resp.Msg)
+	}
+	return resp, nil
+}
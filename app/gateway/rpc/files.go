@@ -30,11 +30,17 @@ type UploadMeta struct {
 	UserID   uint64
 	FileName string
 	FileSize int64
+	FolderID uint64
 }
 
 // BigFileUpload 分片上传大文件
 func BigFileUpload(ctx context.Context, reader io.Reader, meta *UploadMeta) (*pb.BigFileUploadResponse, error) {
-	stream, err := FilesClient.BigFileUpload(ctx)
+	return StreamUpload(ctx, FilesClient, reader, meta)
+}
+
+// StreamUpload 通过指定的客户端分片上传，便于 WebDAV 等模块注入自己的客户端
+func StreamUpload(ctx context.Context, client pb.FilesServiceClient, reader io.Reader, meta *UploadMeta) (*pb.BigFileUploadResponse, error) {
+	stream, err := client.BigFileUpload(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("初始化上传流失败: %w", err)
 	}
@@ -64,9 +70,12 @@ func BigFileUpload(ctx context.Context, reader io.Reader, meta *UploadMeta) (*pb
 			ObjectName: objectName,
 			Content:    buf[:n],
 			IsLast:     false,
+			FolderID:   meta.FolderID,
 		}
 
+		// 首个分片携带声明的文件大小，便于服务端提前按上传策略拒绝
 		if first {
+			req.FileSize = meta.FileSize
 			first = false
 		}
 
@@ -76,6 +85,23 @@ func BigFileUpload(ctx context.Context, reader io.Reader, meta *UploadMeta) (*pb
 		}
 
 		if err := stream.Send(req); err != nil {
+			// 服务端提前结束（如被上传策略拒绝）时，通过 CloseAndRecv 取回响应
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("发送分片失败: %w", err)
+		}
+	}
+
+	// 空文件也需要发送一个分片，否则服务端会认为没有上传内容
+	if first {
+		if err := stream.Send(&pb.BigFileUploadRequest{
+			UserID:     meta.UserID,
+			Filename:   meta.FileName,
+			ObjectName: objectName,
+			IsLast:     true,
+			FolderID:   meta.FolderID,
+		}); err != nil && err != io.EOF {
 			return nil, fmt.Errorf("发送分片失败: %w", err)
 		}
 	}
@@ -134,6 +160,10 @@ func CheckFileExists(ctx context.Context, req *pb.CheckFileRequest) (resp *pb.Ch
 		return
 	}
 
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
 	return
 }
 
@@ -178,10 +208,15 @@ func QiniuBigFileUpload(ctx context.Context, reader io.Reader, meta *UploadMeta)
 		if isFirst {
 			req.UserID = meta.UserID
 			req.Filename = meta.FileName
+			req.FolderID = meta.FolderID
+			req.FileSize = meta.FileSize
 			isFirst = false
 		}
 
 		if err := stream.Send(req); err != nil {
+			if err == io.EOF {
+				break
+			}
 			return nil, fmt.Errorf("发送分片失败: %w", err)
 		}
 
@@ -237,3 +272,69 @@ func QiniuFileDelete(ctx context.Context, req *pb.FileDeleteRequest) (resp *pb.F
 	}
 	return
 }
+
+// PresignDownload 签发下载链接
+func PresignDownload(ctx context.Context, req *pb.PresignDownloadRequest) (resp *pb.PresignDownloadResponse, err error) {
+	resp, err = FilesClient.PresignDownload(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+func QiniuUploadToken(ctx context.Context, req *pb.QiniuUploadTokenRequest) (resp *pb.QiniuUploadTokenResponse, err error) {
+	resp, err = FilesClient.QiniuUploadToken(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+func QiniuUploadCallback(ctx context.Context, req *pb.QiniuUploadCallbackRequest) (resp *pb.FileUploadResponse, err error) {
+	resp, err = FilesClient.QiniuUploadCallback(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+func PresignUpload(ctx context.Context, req *pb.PresignUploadRequest) (resp *pb.PresignUploadResponse, err error) {
+	resp, err = FilesClient.PresignUpload(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+func ConfirmUpload(ctx context.Context, req *pb.ConfirmUploadRequest) (resp *pb.FileUploadResponse, err error) {
+	resp, err = FilesClient.ConfirmUpload(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
@@ -0,0 +1,190 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+)
+
+// FolderCreate 创建文件夹
+func FolderCreate(ctx context.Context, req *pb.FolderCreateRequest) (resp *pb.FolderCreateResponse, err error) {
+	resp, err = FilesClient.FolderCreate(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// FileRename 文件重命名
+func FileRename(ctx context.Context, req *pb.FileRenameRequest) (resp *pb.FileCommonResponse, err error) {
+	resp, err = FilesClient.FileRename(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// FolderList 列出文件夹内容
+func FolderList(ctx context.Context, req *pb.FolderListRequest) (resp *pb.FolderListResponse, err error) {
+	resp, err = FilesClient.FolderList(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// FileMove 移动文件
+func FileMove(ctx context.Context, req *pb.FileMoveRequest) (resp *pb.FileCommonResponse, err error) {
+	resp, err = FilesClient.FileMove(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// FolderMove 移动/重命名文件夹
+func FolderMove(ctx context.Context, req *pb.FolderMoveRequest) (resp *pb.FileCommonResponse, err error) {
+	resp, err = FilesClient.FolderMove(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// FolderDelete 递归删除文件夹
+func FolderDelete(ctx context.Context, req *pb.FolderDeleteRequest) (resp *pb.FileCommonResponse, err error) {
+	resp, err = FilesClient.FolderDelete(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// ShareGrant 共享文件或文件夹
+func ShareGrant(ctx context.Context, req *pb.ShareGrantRequest) (resp *pb.ShareGrantResponse, err error) {
+	resp, err = FilesClient.ShareGrant(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// ShareRevoke 撤销共享
+func ShareRevoke(ctx context.Context, req *pb.ShareRevokeRequest) (resp *pb.FileCommonResponse, err error) {
+	resp, err = FilesClient.ShareRevoke(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// ShareList 资源的授权列表
+func ShareList(ctx context.Context, req *pb.ShareListRequest) (resp *pb.ShareListResponse, err error) {
+	resp, err = FilesClient.ShareList(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// SharedWithMe 共享给我的资源
+func SharedWithMe(ctx context.Context, req *pb.ShareListRequest) (resp *pb.ShareListResponse, err error) {
+	resp, err = FilesClient.SharedWithMe(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// GroupCreate 创建用户组
+func GroupCreate(ctx context.Context, req *pb.GroupCreateRequest) (resp *pb.GroupCreateResponse, err error) {
+	resp, err = FilesClient.GroupCreate(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// GroupAddMember 添加组成员
+func GroupAddMember(ctx context.Context, req *pb.GroupMemberRequest) (resp *pb.FileCommonResponse, err error) {
+	resp, err = FilesClient.GroupAddMember(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// GroupRemoveMember 移除组成员
+func GroupRemoveMember(ctx context.Context, req *pb.GroupMemberRequest) (resp *pb.FileCommonResponse, err error) {
+	resp, err = FilesClient.GroupRemoveMember(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
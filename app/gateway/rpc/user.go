@@ -79,3 +79,31 @@ func UserDelete(ctx context.Context, req *pb.UserRequest) (resp *pb.UserCommonRe
 	}
 	return
 }
+
+// UserAppPasswordCreate 创建应用专用密码
+func UserAppPasswordCreate(ctx context.Context, req *pb.AppPasswordRequest) (resp *pb.AppPasswordResponse, err error) {
+	resp, err = UserClient.UserAppPasswordCreate(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
+
+// UserAppPasswordDelete 吊销应用专用密码
+func UserAppPasswordDelete(ctx context.Context, req *pb.AppPasswordRequest) (resp *pb.UserCommonResponse, err error) {
+	resp, err = UserClient.UserAppPasswordDelete(ctx, req)
+	if err != nil {
+		return
+	}
+
+	if resp.Code != e.SUCCESS {
+		err = errors.New(resp.Msg)
+		return
+	}
+	return
+}
@@ -0,0 +1,13 @@
+package cache
+
+import (
+	"github.com/go-redis/redis/v8"
+	"grpc-todolist-disk/utils/redis_cache"
+)
+
+// RDB 网关使用的 redis：并发传输计数、异步上传任务状态
+var RDB *redis.Client
+
+func Init() {
+	RDB = redis_cache.ConnectRedis()
+}
@@ -2,24 +2,27 @@ package mq
 
 import (
 	"context"
-	"encoding/json"
 	"github.com/segmentio/kafka-go"
+	"grpc-todolist-disk/utils/kafka_mq"
 	"log"
+	"time"
 )
 
 // AsyncFileUploadMsg 表示文件异步上传任务的消息结构
 type AsyncFileUploadMsg struct {
+	JobID      string `json:"job_id"`
 	UserID     uint64 `json:"user_id"`
 	Filename   string `json:"filename"`
 	FileSize   int64  `json:"file_size"`
 	FileHash   string `json:"file_hash"`
 	ObjectName string `json:"object_name"`
-	Content    []byte `json:"content"` // 小文件内容
-	TempPath   string `json:"temp_path"`
+	TempPath   string `json:"temp_path"` // 暂存路径，文件内容不经过 kafka
+	FolderID   uint64 `json:"folder_id"` // 目标文件夹，0 表示根目录
 }
 
+// SendFileUploadTask 发送异步上传任务，以任务 ID 作为幂等键
 func SendFileUploadTask(msg *AsyncFileUploadMsg) error {
-	value, err := json.Marshal(msg)
+	value, err := kafka_mq.NewKeyedEnvelope(kafka_mq.JobFileUpload, 1, msg.JobID, msg, time.Time{})
 	if err != nil {
 		log.Printf("Kafka Msg JSON 序列化失败: %v", err)
 		return err
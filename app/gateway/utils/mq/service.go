@@ -1,11 +1,10 @@
 package mq
 
 import (
-	"github.com/segmentio/kafka-go"
 	"grpc-todolist-disk/utils/kafka_mq"
 )
 
-var KfWriter *kafka.Writer
+var KfWriter kafka_mq.Publisher
 
 func Init() {
 	KfWriter = kafka_mq.NewFileKafkaProducer()
@@ -3,6 +3,7 @@ package utils
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"io"
 )
 
 func Sha256Hash(data []byte) string {
@@ -10,3 +11,12 @@ func Sha256Hash(data []byte) string {
 	hasher.Write(data)
 	return hex.EncodeToString(hasher.Sum(nil))
 }
+
+// Sha256Reader 流式计算 r 中剩余内容的哈希
+func Sha256Reader(r io.Reader) (string, error) {
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,103 @@
+package webdav
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"grpc-todolist-disk/utils/ctl"
+	"grpc-todolist-disk/utils/token"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BasicFunc 使用用户名 + 应用专用密码认证，返回用户 ID
+type BasicFunc func(ctx context.Context, username, password string) (uint, error)
+
+// BearerFunc 使用 JWT 认证，返回用户 ID
+type BearerFunc func(tokenStr string) (uint, error)
+
+// Auth WebDAV 认证：客户端一般只支持 Basic，因此使用应用专用密码；也兼容网关签发的 Bearer token
+type Auth struct {
+	Basic  BasicFunc
+	Bearer BearerFunc
+	TTL    time.Duration // Basic 认证结果的缓存时间，避免每个请求都做一次 bcrypt 校验
+
+	mu    sync.Mutex
+	cache map[string]basicEntry
+}
+
+type basicEntry struct {
+	userID  uint
+	expires time.Time
+}
+
+// CheckJWT 默认的 Bearer 校验，与网关 JWT 中间件一致
+func CheckJWT(tokenStr string) (uint, error) {
+	if err := token.CheckRS(tokenStr); err != nil {
+		return 0, err
+	}
+	var claims token.UserClaims
+	if err := token.Rs.Decode(tokenStr, &claims); err != nil {
+		return 0, err
+	}
+	return claims.UserID, nil
+}
+
+func (a *Auth) authenticate(r *http.Request) (uint, error) {
+	authHeader := r.Header.Get("Authorization")
+	if parts := strings.SplitN(authHeader, " ", 2); len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
+		if a.Bearer == nil {
+			return 0, errors.New("不支持 Bearer 认证")
+		}
+		return a.Bearer(parts[1])
+	}
+	username, password, ok := r.BasicAuth()
+	if !ok || a.Basic == nil {
+		return 0, errors.New("缺少认证信息")
+	}
+
+	sum := sha256.Sum256([]byte(username + "\x00" + password))
+	key := hex.EncodeToString(sum[:])
+	a.mu.Lock()
+	entry, hit := a.cache[key]
+	a.mu.Unlock()
+	if hit && time.Now().Before(entry.expires) {
+		return entry.userID, nil
+	}
+
+	userID, err := a.Basic(r.Context(), username, password)
+	if err != nil {
+		return 0, err
+	}
+	if a.TTL > 0 {
+		a.mu.Lock()
+		if a.cache == nil {
+			a.cache = make(map[string]basicEntry)
+		}
+		now := time.Now()
+		for k, v := range a.cache {
+			if now.After(v.expires) {
+				delete(a.cache, k)
+			}
+		}
+		a.cache[key] = basicEntry{userID: userID, expires: now.Add(a.TTL)}
+		a.mu.Unlock()
+	}
+	return userID, nil
+}
+
+// Wrap 认证通过后将用户信息写入 ctx，失败时返回 401 并提示客户端使用 Basic 认证
+func (a *Auth) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := a.authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Basic realm="disk", charset="UTF-8"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctl.NewContext(r.Context(), &ctl.UserInfo{ID: userID})))
+	})
+}
@@ -0,0 +1,524 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/net/webdav"
+	"grpc-todolist-disk/app/gateway/rpc"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/ctl"
+	"grpc-todolist-disk/utils/e"
+	"grpc-todolist-disk/utils/policy"
+	"grpc-todolist-disk/utils/storage"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileSystem 将用户网盘（文件夹 + 文件）映射为 webdav.FileSystem，
+// 所有读写都通过 files 服务完成，当前用户从 ctx 中获取
+type FileSystem struct {
+	Files pb.FilesServiceClient
+}
+
+func NewFileSystem(files pb.FilesServiceClient) *FileSystem {
+	return &FileSystem{Files: files}
+}
+
+// node 路径解析的结果：文件夹或文件
+type node struct {
+	name     string
+	isDir    bool
+	folderID uint64 // 文件夹 ID，根目录为 0
+	parentID uint64 // 所在文件夹 ID
+	file     *pb.FileModel
+	modTime  time.Time
+}
+
+func (n *node) Name() string { return n.name }
+func (n *node) Size() int64 {
+	if n.file != nil {
+		return n.file.FileSize
+	}
+	return 0
+}
+func (n *node) Mode() os.FileMode {
+	if n.isDir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+func (n *node) ModTime() time.Time { return n.modTime }
+func (n *node) IsDir() bool        { return n.isDir }
+func (n *node) Sys() interface{}   { return nil }
+
+// ContentType 按扩展名推断类型，避免 PROPFIND 时为了嗅探类型去下载文件内容
+func (n *node) ContentType(ctx context.Context) (string, error) {
+	if n.isDir {
+		return "", webdav.ErrNotImplemented
+	}
+	if ctype := mime.TypeByExtension(filepath.Ext(n.name)); ctype != "" {
+		return ctype, nil
+	}
+	return "application/octet-stream", nil
+}
+
+// codeErr 将 files 服务的响应码转换为 os 包的错误，便于 webdav 返回正确的状态码
+func codeErr(code int64, msg string) error {
+	switch code {
+	case e.SUCCESS:
+		return nil
+	case e.ErrorFilePermission, e.ErrorUploadPolicy, e.ErrorFileNotScanned, e.ErrorFileInfected:
+		return os.ErrPermission
+	case e.ErrorFileNotExist, e.ErrorFolderNotExist:
+		return os.ErrNotExist
+	}
+	return errors.New(msg)
+}
+
+func userID(ctx context.Context) (uint64, error) {
+	user, err := ctl.GetUserInfo(ctx)
+	if err != nil {
+		return 0, os.ErrPermission
+	}
+	return uint64(user.ID), nil
+}
+
+// splitPath 将 /a/b/c 拆分为 [a b c]
+func splitPath(name string) []string {
+	name = strings.Trim(path.Clean("/"+name), "/")
+	if name == "" {
+		return nil
+	}
+	return strings.Split(name, "/")
+}
+
+// list 列出文件夹内容
+func (fs *FileSystem) list(ctx context.Context, uid, folderID uint64) (*pb.FolderListResponse, error) {
+	resp, err := fs.Files.FolderList(ctx, &pb.FolderListRequest{UserID: uid, FolderID: folderID})
+	if err != nil {
+		return nil, err
+	}
+	if err = codeErr(resp.Code, resp.Msg); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// children 将文件夹内容转换为 node 列表
+func children(resp *pb.FolderListResponse, parentID uint64) []*node {
+	nodes := make([]*node, 0, len(resp.Folders)+len(resp.Files))
+	for _, folder := range resp.Folders {
+		nodes = append(nodes, &node{
+			name:     folder.Name,
+			isDir:    true,
+			folderID: folder.FolderID,
+			parentID: parentID,
+			modTime:  time.Unix(folder.UpdatedAt, 0),
+		})
+	}
+	for _, file := range resp.Files {
+		nodes = append(nodes, &node{
+			name:     file.FileName,
+			parentID: parentID,
+			file:     file,
+			modTime:  time.Unix(file.UpdatedAt, 0),
+		})
+	}
+	return nodes
+}
+
+// resolve 逐级解析路径
+func (fs *FileSystem) resolve(ctx context.Context, name string) (*node, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cur := &node{name: "/", isDir: true}
+	for _, part := range splitPath(name) {
+		if !cur.isDir {
+			return nil, os.ErrNotExist
+		}
+		resp, err := fs.list(ctx, uid, cur.folderID)
+		if err != nil {
+			return nil, err
+		}
+		var next *node
+		for _, child := range children(resp, cur.folderID) {
+			// 同名时优先匹配文件夹
+			if child.name == part && (next == nil || child.isDir) {
+				next = child
+			}
+		}
+		if next == nil {
+			return nil, os.ErrNotExist
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+// resolveParent 解析目标路径的父文件夹，返回父文件夹和文件名
+func (fs *FileSystem) resolveParent(ctx context.Context, name string) (*node, string, error) {
+	parts := splitPath(name)
+	if len(parts) == 0 {
+		return nil, "", os.ErrInvalid
+	}
+	parent, err := fs.resolve(ctx, strings.Join(parts[:len(parts)-1], "/"))
+	if err != nil {
+		return nil, "", err
+	}
+	if !parent.isDir {
+		return nil, "", os.ErrNotExist
+	}
+	return parent, parts[len(parts)-1], nil
+}
+
+func (fs *FileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	uid, err := userID(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err = fs.resolve(ctx, name); err == nil {
+		return os.ErrExist
+	}
+	parent, base, err := fs.resolveParent(ctx, name)
+	if err != nil {
+		return err
+	}
+	resp, err := fs.Files.FolderCreate(ctx, &pb.FolderCreateRequest{UserID: uid, ParentID: parent.folderID, Name: base})
+	if err != nil {
+		return err
+	}
+	return codeErr(resp.Code, resp.Msg)
+}
+
+func (fs *FileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	return fs.resolve(ctx, name)
+}
+
+func (fs *FileSystem) RemoveAll(ctx context.Context, name string) error {
+	uid, err := userID(ctx)
+	if err != nil {
+		return err
+	}
+	n, err := fs.resolve(ctx, name)
+	if err != nil {
+		return err
+	}
+	var resp *pb.FileCommonResponse
+	switch {
+	case n.isDir && n.folderID == 0:
+		return os.ErrPermission // 不允许删除根目录
+	case n.isDir:
+		resp, err = fs.Files.FolderDelete(ctx, &pb.FolderDeleteRequest{UserID: uid, FolderID: n.folderID})
+	default:
+		resp, err = fs.deleteFile(ctx, uid, n.file)
+	}
+	if err != nil {
+		return err
+	}
+	return codeErr(resp.Code, resp.Msg)
+}
+
+func (fs *FileSystem) deleteFile(ctx context.Context, uid uint64, file *pb.FileModel) (*pb.FileCommonResponse, error) {
+	req := &pb.FileDeleteRequest{UserID: uid, FileID: file.FileID}
+	if file.Bucket == "qiniu" {
+		return fs.Files.QiniuFileDelete(ctx, req)
+	}
+	return fs.Files.FileDelete(ctx, req)
+}
+
+func (fs *FileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	uid, err := userID(ctx)
+	if err != nil {
+		return err
+	}
+	n, err := fs.resolve(ctx, oldName)
+	if err != nil {
+		return err
+	}
+	parent, base, err := fs.resolveParent(ctx, newName)
+	if err != nil {
+		return err
+	}
+	var resp *pb.FileCommonResponse
+	if n.isDir {
+		if n.folderID == 0 {
+			return os.ErrPermission
+		}
+		resp, err = fs.Files.FolderMove(ctx, &pb.FolderMoveRequest{
+			UserID: uid, FolderID: n.folderID, ParentID: parent.folderID, NewName: base,
+		})
+	} else {
+		resp, err = fs.Files.FileMove(ctx, &pb.FileMoveRequest{
+			UserID: uid, FileID: n.file.FileID, FolderID: parent.folderID, NewName: base,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return codeErr(resp.Code, resp.Msg)
+}
+
+func (fs *FileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	uid, err := userID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC) != 0 {
+		return fs.openForWrite(ctx, uid, name, flag)
+	}
+	n, err := fs.resolve(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if n.isDir {
+		resp, err := fs.list(ctx, uid, n.folderID)
+		if err != nil {
+			return nil, err
+		}
+		return &dirFile{node: n, entries: children(resp, n.folderID)}, nil
+	}
+	return &readFile{node: n, fs: fs, ctx: ctx, uid: uid}, nil
+}
+
+func (fs *FileSystem) openForWrite(ctx context.Context, uid uint64, name string, flag int) (webdav.File, error) {
+	parent, base, err := fs.resolveParent(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	old, err := fs.resolve(ctx, name)
+	switch {
+	case err == nil && old.isDir:
+		return nil, os.ErrExist
+	case err == nil && flag&os.O_EXCL != 0:
+		return nil, os.ErrExist
+	case err != nil && !os.IsNotExist(err):
+		return nil, err
+	case err != nil && flag&os.O_CREATE == 0:
+		return nil, os.ErrNotExist
+	}
+	if flag&os.O_TRUNC == 0 && old != nil {
+		return nil, os.ErrPermission // 不支持对已有文件做部分写入
+	}
+	// 文件名和扩展名在接收内容前校验，大小和类型由 files 服务在上传时校验
+	if rej := policy.Default().CheckFile(policy.RouteBigFileUpload, uid, base, -1); rej != nil {
+		return nil, os.ErrPermission
+	}
+	tmp, err := os.CreateTemp("", "webdav-put-*")
+	if err != nil {
+		return nil, err
+	}
+	return &writeFile{
+		File:   tmp,
+		fs:     fs,
+		ctx:    ctx,
+		uid:    uid,
+		name:   base,
+		parent: parent.folderID,
+		old:    old,
+	}, nil
+}
+
+// dirFile 打开的文件夹
+type dirFile struct {
+	node    *node
+	entries []*node
+	pos     int
+}
+
+func (d *dirFile) Close() error                                 { return nil }
+func (d *dirFile) Read(p []byte) (int, error)                   { return 0, os.ErrInvalid }
+func (d *dirFile) Write(p []byte) (int, error)                  { return 0, os.ErrInvalid }
+func (d *dirFile) Seek(offset int64, whence int) (int64, error) { return 0, os.ErrInvalid }
+func (d *dirFile) Stat() (os.FileInfo, error)                   { return d.node, nil }
+
+func (d *dirFile) Readdir(count int) ([]os.FileInfo, error) {
+	rest := d.entries[d.pos:]
+	if count > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if count > 0 && count < len(rest) {
+		rest = rest[:count]
+	}
+	d.pos += len(rest)
+	infos := make([]os.FileInfo, 0, len(rest))
+	for _, entry := range rest {
+		infos = append(infos, entry)
+	}
+	return infos, nil
+}
+
+// readFile 只读打开的文件，首次读取时才打开内容：
+// 本地存储直接打开共享磁盘上的文件（分块文件按清单拼接、压缩文件按帧解压），七牛云文件先下载到临时文件以支持 Seek
+type readFile struct {
+	node    *node
+	fs      *FileSystem
+	ctx     context.Context
+	uid     uint64
+	content io.ReadSeekCloser
+	tmp     string // 七牛云文件的临时文件路径
+}
+
+func (f *readFile) open() error {
+	if f.content != nil {
+		return nil
+	}
+	req := &pb.FileDownloadRequest{UserID: f.uid, FileID: f.node.file.FileID}
+	if f.node.file.Bucket == "qiniu" {
+		resp, err := f.fs.Files.QiniuFileDownload(f.ctx, req)
+		if err != nil {
+			return err
+		}
+		if err = codeErr(int64(resp.Code), resp.Msg); err != nil {
+			return err
+		}
+		content, err := download(f.ctx, resp.DownloadUrl)
+		if err != nil {
+			return err
+		}
+		f.content, f.tmp = content, content.Name()
+		return nil
+	}
+	resp, err := f.fs.Files.FileDownload(f.ctx, req)
+	if err != nil {
+		return err
+	}
+	if err = codeErr(int64(resp.Code), resp.Msg); err != nil {
+		return err
+	}
+	f.content, err = storage.OpenContent(resp.DownloadUrl, resp.Layout, resp.Codec)
+	return err
+}
+
+func (f *readFile) Read(p []byte) (int, error) {
+	if err := f.open(); err != nil {
+		return 0, err
+	}
+	return f.content.Read(p)
+}
+
+func (f *readFile) Seek(offset int64, whence int) (int64, error) {
+	// 只查询文件末尾时不需要打开内容（http.ServeContent 用这种方式获取大小）
+	if f.content == nil && offset == 0 && whence == io.SeekEnd {
+		return f.node.Size(), nil
+	}
+	if f.content == nil && offset == 0 && whence == io.SeekStart {
+		return 0, nil
+	}
+	if err := f.open(); err != nil {
+		return 0, err
+	}
+	return f.content.Seek(offset, whence)
+}
+
+func (f *readFile) Close() error {
+	if f.content == nil {
+		return nil
+	}
+	err := f.content.Close()
+	if f.tmp != "" {
+		_ = os.Remove(f.tmp)
+	}
+	return err
+}
+
+func (f *readFile) Readdir(count int) ([]os.FileInfo, error) { return nil, os.ErrInvalid }
+func (f *readFile) Stat() (os.FileInfo, error)               { return f.node, nil }
+func (f *readFile) Write(p []byte) (int, error)              { return 0, os.ErrPermission }
+
+// download 将远程文件下载到临时文件
+func download(ctx context.Context, url string) (*os.File, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载文件失败: %s", resp.Status)
+	}
+	tmp, err := os.CreateTemp("", "webdav-get-*")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(tmp, resp.Body); err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, err
+	}
+	return tmp, nil
+}
+
+// writeFile 写入的内容先落到临时文件，Close 时通过流式上传提交到 files 服务，
+// 覆盖已有文件时在新文件上传成功后再删除旧记录
+type writeFile struct {
+	*os.File
+	fs     *FileSystem
+	ctx    context.Context
+	uid    uint64
+	name   string
+	parent uint64
+	old    *node
+	size   int64
+	closed bool
+}
+
+func (f *writeFile) Write(p []byte) (int, error) {
+	n, err := f.File.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *writeFile) Readdir(count int) ([]os.FileInfo, error) { return nil, os.ErrInvalid }
+
+func (f *writeFile) Stat() (os.FileInfo, error) {
+	return &node{name: f.name, parentID: f.parent, file: &pb.FileModel{FileName: f.name, FileSize: f.size}, modTime: time.Now()}, nil
+}
+
+func (f *writeFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	defer os.Remove(f.File.Name())
+	defer f.File.Close()
+
+	if _, err := f.File.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	resp, err := rpc.StreamUpload(f.ctx, f.fs.Files, f.File, &rpc.UploadMeta{
+		UserID:   f.uid,
+		FileName: f.name,
+		FileSize: f.size,
+		FolderID: f.parent,
+	})
+	if err != nil {
+		return err
+	}
+	if err = codeErr(resp.Code, resp.Msg); err != nil {
+		return err
+	}
+	// 内容未变化时服务端返回的就是原记录
+	if f.old != nil && f.old.file.FileID != resp.FileID {
+		del, err := f.fs.deleteFile(f.ctx, f.uid, f.old.file)
+		if err != nil {
+			return err
+		}
+		return codeErr(del.Code, del.Msg)
+	}
+	return nil
+}
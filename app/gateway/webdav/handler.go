@@ -0,0 +1,56 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"golang.org/x/net/webdav"
+	"grpc-todolist-disk/app/gateway/middleware"
+	"grpc-todolist-disk/app/gateway/rpc"
+	filesPb "grpc-todolist-disk/idl/pb/files"
+	userPb "grpc-todolist-disk/idl/pb/user"
+	"grpc-todolist-disk/utils/e"
+	"net/http"
+	"time"
+)
+
+// Prefix WebDAV 挂载路径
+const Prefix = "/dav/"
+
+// NewHandler 创建 WebDAV 处理器，依赖通过参数注入，测试时可以传入进程内的 files 客户端和认证函数
+func NewHandler(prefix string, files filesPb.FilesServiceClient, auth *Auth) http.Handler {
+	h := &webdav.Handler{
+		Prefix:     prefix[:len(prefix)-1],
+		FileSystem: NewFileSystem(files),
+		LockSystem: webdav.NewMemLS(),
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				zap.L().Warn("webdav", zap.String("method", r.Method), zap.String("path", r.URL.Path), zap.Error(err))
+			}
+		},
+	}
+	// 认证之后按用户等级限制并发和带宽
+	return auth.Wrap(middleware.TransferHandler(h))
+}
+
+// NewDefaultHandler 使用网关的 rpc 客户端：Basic 认证走 user 服务的应用专用密码校验，Bearer 认证校验 JWT
+func NewDefaultHandler() http.Handler {
+	return NewHandler(Prefix, rpc.FilesClient, &Auth{
+		Basic:  checkAppPassword(rpc.UserClient),
+		Bearer: CheckJWT,
+		TTL:    time.Minute,
+	})
+}
+
+func checkAppPassword(client userPb.UserServiceClient) BasicFunc {
+	return func(ctx context.Context, username, password string) (uint, error) {
+		resp, err := client.UserAppPasswordCheck(ctx, &userPb.AppPasswordRequest{Username: username, Password: password})
+		if err != nil {
+			return 0, err
+		}
+		if resp.Code != e.SUCCESS || resp.UserDetail == nil {
+			return 0, errors.New(resp.Msg)
+		}
+		return uint(resp.UserDetail.UserID), nil
+	}
+}
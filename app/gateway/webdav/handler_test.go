@@ -0,0 +1,268 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	pb "grpc-todolist-disk/idl/pb/files"
+	"grpc-todolist-disk/utils/e"
+)
+
+// fakeFiles 进程内的 files 客户端，文件夹和文件记录保存在内存中，文件内容写在临时目录
+type fakeFiles struct {
+	pb.FilesServiceClient // 未实现的方法调用时 panic
+
+	dir     string
+	mu      sync.Mutex
+	nextID  uint64
+	folders map[uint64]*pb.FolderModel
+	files   map[uint64]*pb.FileModel
+}
+
+func newFakeFiles(dir string) *fakeFiles {
+	return &fakeFiles{dir: dir, folders: map[uint64]*pb.FolderModel{}, files: map[uint64]*pb.FileModel{}}
+}
+
+func (f *fakeFiles) id() uint64 {
+	f.nextID++
+	return f.nextID
+}
+
+func (f *fakeFiles) FolderList(ctx context.Context, in *pb.FolderListRequest, opts ...grpc.CallOption) (*pb.FolderListResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.folders[in.FolderID]; in.FolderID != 0 && !ok {
+		return &pb.FolderListResponse{Code: e.ErrorFolderNotExist, Msg: "文件夹不存在"}, nil
+	}
+	resp := &pb.FolderListResponse{Code: e.SUCCESS}
+	for _, folder := range f.folders {
+		if folder.ParentID == in.FolderID {
+			resp.Folders = append(resp.Folders, folder)
+		}
+	}
+	for _, file := range f.files {
+		if file.FolderID == in.FolderID {
+			resp.Files = append(resp.Files, file)
+		}
+	}
+	return resp, nil
+}
+
+func (f *fakeFiles) FolderCreate(ctx context.Context, in *pb.FolderCreateRequest, opts ...grpc.CallOption) (*pb.FolderCreateResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	folder := &pb.FolderModel{FolderID: f.id(), UserID: in.UserID, ParentID: in.ParentID, Name: in.Name}
+	f.folders[folder.FolderID] = folder
+	return &pb.FolderCreateResponse{Code: e.SUCCESS, FolderID: folder.FolderID}, nil
+}
+
+func (f *fakeFiles) FolderMove(ctx context.Context, in *pb.FolderMoveRequest, opts ...grpc.CallOption) (*pb.FileCommonResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	folder, ok := f.folders[in.FolderID]
+	if !ok {
+		return &pb.FileCommonResponse{Code: e.ErrorFolderNotExist, Msg: "文件夹不存在"}, nil
+	}
+	folder.ParentID, folder.Name = in.ParentID, in.NewName
+	return &pb.FileCommonResponse{Code: e.SUCCESS}, nil
+}
+
+func (f *fakeFiles) FolderDelete(ctx context.Context, in *pb.FolderDeleteRequest, opts ...grpc.CallOption) (*pb.FileCommonResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.folders, in.FolderID)
+	return &pb.FileCommonResponse{Code: e.SUCCESS}, nil
+}
+
+func (f *fakeFiles) FileMove(ctx context.Context, in *pb.FileMoveRequest, opts ...grpc.CallOption) (*pb.FileCommonResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, ok := f.files[in.FileID]
+	if !ok {
+		return &pb.FileCommonResponse{Code: e.ErrorFileNotExist, Msg: "文件不存在"}, nil
+	}
+	file.FolderID, file.FileName = in.FolderID, in.NewName
+	return &pb.FileCommonResponse{Code: e.SUCCESS}, nil
+}
+
+func (f *fakeFiles) FileDelete(ctx context.Context, in *pb.FileDeleteRequest, opts ...grpc.CallOption) (*pb.FileCommonResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, ok := f.files[in.FileID]
+	if !ok {
+		return &pb.FileCommonResponse{Code: e.ErrorFileNotExist, Msg: "文件不存在"}, nil
+	}
+	delete(f.files, in.FileID)
+	os.Remove(filepath.Join(f.dir, file.ObjectName))
+	return &pb.FileCommonResponse{Code: e.SUCCESS}, nil
+}
+
+func (f *fakeFiles) FileDownload(ctx context.Context, in *pb.FileDownloadRequest, opts ...grpc.CallOption) (*pb.FileDownloadResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, ok := f.files[in.FileID]
+	if !ok {
+		return &pb.FileDownloadResponse{Code: e.ErrorFileNotExist, Msg: "文件不存在"}, nil
+	}
+	return &pb.FileDownloadResponse{Code: e.SUCCESS, DownloadUrl: filepath.Join(f.dir, file.ObjectName), Filename: file.FileName}, nil
+}
+
+func (f *fakeFiles) BigFileUpload(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[pb.BigFileUploadRequest, pb.BigFileUploadResponse], error) {
+	return &fakeUpload{files: f}, nil
+}
+
+// fakeUpload 接收流式上传的分片，与 files 服务一样在流结束时创建文件记录
+type fakeUpload struct {
+	grpc.ClientStream
+
+	files *fakeFiles
+	meta  *pb.BigFileUploadRequest
+	data  []byte
+}
+
+func (u *fakeUpload) Send(req *pb.BigFileUploadRequest) error {
+	u.meta = req
+	u.data = append(u.data, req.Content...)
+	return nil
+}
+
+func (u *fakeUpload) CloseAndRecv() (*pb.BigFileUploadResponse, error) {
+	if u.meta == nil {
+		return nil, errors.New("没有上传内容")
+	}
+	f := u.files
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file := &pb.FileModel{
+		FileID:     f.id(),
+		UserID:     u.meta.UserID,
+		FileName:   u.meta.Filename,
+		FileSize:   int64(len(u.data)),
+		Bucket:     "local",
+		ObjectName: strings.ReplaceAll(u.meta.ObjectName, "/", "_"),
+		FolderID:   u.meta.FolderID,
+	}
+	if err := os.WriteFile(filepath.Join(f.dir, file.ObjectName), u.data, 0o644); err != nil {
+		return nil, err
+	}
+	f.files[file.FileID] = file
+	return &pb.BigFileUploadResponse{Code: e.SUCCESS, FileID: file.FileID}, nil
+}
+
+func (u *fakeUpload) CloseSend() error { return nil }
+
+// fileIn 按文件名查找记录
+func (f *fakeFiles) fileIn(folderID uint64, name string) *pb.FileModel {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, file := range f.files {
+		if file.FolderID == folderID && file.FileName == name {
+			return file
+		}
+	}
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	files := newFakeFiles(t.TempDir())
+	auth := &Auth{Basic: func(ctx context.Context, username, password string) (uint, error) {
+		if username == "alice" && password == "app-password" {
+			return 7, nil
+		}
+		return 0, errors.New("密码错误")
+	}}
+	server := httptest.NewServer(NewHandler(Prefix, files, auth))
+	defer server.Close()
+
+	do := func(method, path, body string, header map[string]string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.SetBasicAuth("alice", "app-password")
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(data)
+	}
+
+	// 未认证
+	resp, err := http.Get(server.URL + Prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+		t.Fatalf("unauthenticated: %d", resp.StatusCode)
+	}
+
+	if status, _ := do("MKCOL", "/dav/docs", "", nil); status != http.StatusCreated {
+		t.Fatalf("MKCOL: %d", status)
+	}
+	if status, _ := do(http.MethodPut, "/dav/docs/a.txt", "hello webdav", nil); status != http.StatusCreated {
+		t.Fatalf("PUT: %d", status)
+	}
+	file := files.fileIn(1, "a.txt")
+	if file == nil || file.UserID != 7 || file.FileSize != int64(len("hello webdav")) {
+		t.Fatalf("uploaded record %v", file)
+	}
+
+	status, body := do("PROPFIND", "/dav/docs/", "", map[string]string{"Depth": "1"})
+	if status != http.StatusMultiStatus || !strings.Contains(body, "/dav/docs/a.txt") || !strings.Contains(body, "<D:getcontentlength>12</D:getcontentlength>") {
+		t.Fatalf("PROPFIND: %d %s", status, body)
+	}
+
+	if status, body = do(http.MethodGet, "/dav/docs/a.txt", "", nil); status != http.StatusOK || body != "hello webdav" {
+		t.Fatalf("GET: %d %q", status, body)
+	}
+	if status, body = do(http.MethodGet, "/dav/docs/a.txt", "", map[string]string{"Range": "bytes=6-"}); status != http.StatusPartialContent || body != "webdav" {
+		t.Fatalf("GET range: %d %q", status, body)
+	}
+
+	// 覆盖已有文件：上传新记录后删除旧记录
+	if status, _ = do(http.MethodPut, "/dav/docs/a.txt", "v2", nil); status != http.StatusCreated && status != http.StatusNoContent {
+		t.Fatalf("PUT overwrite: %d", status)
+	}
+	if cur := files.fileIn(1, "a.txt"); cur == nil || cur.FileID == file.FileID {
+		t.Fatalf("overwrite record %v", cur)
+	}
+
+	if status, _ = do("MOVE", "/dav/docs/a.txt", "", map[string]string{"Destination": server.URL + "/dav/b.txt"}); status != http.StatusCreated {
+		t.Fatalf("MOVE: %d", status)
+	}
+	if files.fileIn(1, "a.txt") != nil || files.fileIn(0, "b.txt") == nil {
+		t.Fatal("file not moved to root")
+	}
+	if status, body = do(http.MethodGet, "/dav/b.txt", "", nil); status != http.StatusOK || body != "v2" {
+		t.Fatalf("GET moved: %d %q", status, body)
+	}
+
+	if status, _ = do(http.MethodDelete, "/dav/b.txt", "", nil); status != http.StatusNoContent {
+		t.Fatalf("DELETE: %d", status)
+	}
+	if status, _ = do(http.MethodGet, "/dav/b.txt", "", nil); status != http.StatusNotFound {
+		t.Fatalf("GET deleted: %d", status)
+	}
+	if status, _ = do(http.MethodDelete, "/dav/docs", "", nil); status != http.StatusNoContent {
+		t.Fatalf("DELETE folder: %d", status)
+	}
+	if status, _ = do("PROPFIND", "/dav/docs/", "", map[string]string{"Depth": "0"}); status != http.StatusNotFound {
+		t.Fatalf("PROPFIND deleted folder: %d", status)
+	}
+}
@@ -24,13 +24,11 @@ func main() {
 	}
 	etcdRegister := discovery.NewRegister(etcdAddress, logger)
 	grpcAddress := conf.Conf.Services["task"].Addr[0]
-	defer etcdRegister.Stop()
 	taskNode := discovery.Server{
 		Name: conf.Conf.Services["task"].Name,
 		Addr: grpcAddress,
 	}
 	server := grpc.NewServer()
-	defer server.Stop()
 	// 绑定service
 	pb.RegisterTaskServiceServer(server, service.GetTaskSrv())
 	lis, err := net.Listen("tcp", grpcAddress)
@@ -44,7 +42,18 @@ func main() {
 		zap.String("address", grpcAddress),
 		zap.String("service", "task"),
 	)
-	if err := server.Serve(lis); err != nil {
-		panic(err)
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			panic(err)
+		}
+	}()
+
+	// 收到退出信号后注销服务，等待处理中的请求完成
+	sig := discovery.WaitSignal()
+	logger.Info("shutting down gRPC server", zap.String("signal", sig.String()))
+	discovery.Shutdown(etcdRegister, server, discovery.ShutdownTimeout)
+	if sqlDB, err := dao.NewDBClient().DB(); err == nil {
+		sqlDB.Close()
 	}
+	logger.Info("gRPC server exited", zap.String("service", "task"))
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -10,6 +11,8 @@ import (
 	"grpc-todolist-disk/conf"
 	pb "grpc-todolist-disk/idl/pb/user"
 	"grpc-todolist-disk/utils/discovery"
+	"grpc-todolist-disk/utils/kafka_mq"
+	"grpc-todolist-disk/utils/outbox"
 	"net"
 )
 
@@ -17,6 +20,16 @@ func main() {
 	conf.InitConfig()
 	dao.InitDB()
 	cache.Init()
+	// 发送 outbox 中的消息。使用进程内的消息队列时由 cmd/dev 进程发送，本进程发送的消息没有消费者
+	ctx, cancel := context.WithCancel(context.Background())
+	publisher := kafka_mq.NewPublisher("")
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		if !kafka_mq.InProcess() {
+			outbox.NewRelay(dao.NewDBClient(), publisher).Run(ctx)
+		}
+	}()
 	// etcd 地址
 	etcdAddress := []string{conf.Conf.Etcd.Endpoints[0]}
 	// 注册服务
@@ -26,13 +39,11 @@ func main() {
 	}
 	etcdRegister := discovery.NewRegister(etcdAddress, logger)
 	grpcAddress := conf.Conf.Services["user"].Addr[0]
-	defer etcdRegister.Stop()
 	userNode := discovery.Server{
 		Name: conf.Conf.Services["user"].Name,
 		Addr: grpcAddress,
 	}
 	server := grpc.NewServer()
-	defer server.Stop()
 	// 绑定service
 	pb.RegisterUserServiceServer(server, service.GetUserSrv())
 	lis, err := net.Listen("tcp", grpcAddress)
@@ -46,7 +57,23 @@ func main() {
 		zap.String("address", grpcAddress),
 		zap.String("service", "user"),
 	)
-	if err := server.Serve(lis); err != nil {
-		panic(err)
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			panic(err)
+		}
+	}()
+
+	// 收到退出信号后注销服务，等待处理中的请求完成
+	sig := discovery.WaitSignal()
+	logger.Info("shutting down gRPC server", zap.String("signal", sig.String()))
+	discovery.Shutdown(etcdRegister, server, discovery.ShutdownTimeout)
+	// 请求处理完后再停止 relay，尚未发送的消息留在 outbox 中，下次启动或由其他实例发送
+	cancel()
+	<-relayDone
+	publisher.Close()
+	cache.RDB.Close()
+	if sqlDB, err := dao.NewDBClient().DB(); err == nil {
+		sqlDB.Close()
 	}
+	logger.Info("gRPC server exited", zap.String("service", "user"))
 }
@@ -1,30 +1,19 @@
 package cache
 
 import (
-	"context"
-	"encoding/json"
-	"github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
+	"grpc-todolist-disk/conf"
 	mqService "grpc-todolist-disk/kafka_server/service"
-	"log"
+	"grpc-todolist-disk/utils/kafka_mq"
+	"grpc-todolist-disk/utils/outbox"
 	"time"
 )
 
-func SendMsgToMq(name string, time time.Time) {
+// ClearCacheLater 在事务 tx 中写入清除用户缓存的任务，到 time 时由 kafka_server 执行（延时双删的第二次删除）
+func ClearCacheLater(tx *gorm.DB, name string, time time.Time) error {
 	msg := &mqService.Message{
-		Name:      "user:" + name,
+		Name:      name,
 		Timestamp: time.UnixNano(),
 	}
-	// 将消息序列化为JSON
-	jsonBytes, err := json.Marshal(msg)
-	if err != nil {
-		log.Printf("Error marshaling message: %v", err)
-	}
-
-	// 发送消息到Kafka
-	err = KfWriter.WriteMessages(context.Background(), kafka.Message{
-		Value: jsonBytes,
-	})
-	if err != nil {
-		log.Printf("Error sending message: %v", err)
-	}
+	return outbox.AddEnvelope(tx, conf.Conf.Kafka.Topic[0], name, kafka_mq.JobClearCache, 1, msg, time)
 }
@@ -9,18 +9,25 @@ import (
 	"time"
 )
 
-// ClearEntireRedisCache 清除redis缓存
+// ClearEntireRedisCache 清除用户缓存。redis 中还保存着延时队列和上传任务，只删除 user: 开头的键
 func ClearEntireRedisCache() {
 	ctx := context.Background()
 
 	log.Println("清除redis")
 
-	// 使用FlushDB命令清空当前数据库
-	err := RDB.FlushDB(ctx).Err()
-	if err != nil {
+	var cleared int
+	iter := RDB.Scan(ctx, 0, "user:*", 1000).Iterator()
+	for iter.Next(ctx) {
+		if err := RDB.Del(ctx, iter.Val()).Err(); err != nil {
+			log.Printf("Failed to clear redis cache %s: %v", iter.Val(), err)
+			continue
+		}
+		cleared++
+	}
+	if err := iter.Err(); err != nil {
 		log.Printf("Failed to flush redis cache: %v", err)
 	} else {
-		log.Printf("Successfully flush redis cache")
+		log.Printf("Successfully flush redis cache, %d keys", cleared)
 	}
 }
 
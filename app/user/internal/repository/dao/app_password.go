@@ -0,0 +1,56 @@
+package dao
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"grpc-todolist-disk/app/user/internal/repository/model"
+	"strings"
+	"time"
+)
+
+// CreateAppPassword 生成随机的应用专用密码，明文仅在此处返回一次
+func (dao *UserDao) CreateAppPassword(userID uint, name string) (*model.AppPassword, string, error) {
+	buf := make([]byte, 15)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", err
+	}
+	raw := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))
+
+	p := &model.AppPassword{
+		UserID:     userID,
+		Name:       name,
+		LastUsedAt: time.Now(),
+	}
+	if err := p.SetPassword(raw); err != nil {
+		return nil, "", err
+	}
+	if err := dao.DB.Create(&p).Error; err != nil {
+		return nil, "", err
+	}
+	return p, raw, nil
+}
+
+func (dao *UserDao) DeleteAppPassword(userID, id uint) (int64, error) {
+	res := dao.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&model.AppPassword{})
+	return res.RowsAffected, res.Error
+}
+
+// CheckAppPassword 校验用户的任意一个应用专用密码，成功时更新最后使用时间
+func (dao *UserDao) CheckAppPassword(userID uint, password string) (bool, error) {
+	var list []*model.AppPassword
+	if err := dao.DB.Model(&model.AppPassword{}).Where("user_id = ?", userID).Find(&list).Error; err != nil {
+		return false, err
+	}
+	for _, p := range list {
+		if p.CheckPassword(password) {
+			dao.DB.Model(p).Update("last_used_at", time.Now())
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (dao *UserDao) GetUserByUsername(name string) (user *model.User, err error) {
+	err = dao.DB.Model(&model.User{}).Where("username = ?", name).First(&user).Error
+	return
+}
@@ -2,6 +2,7 @@ package dao
 
 import (
 	"grpc-todolist-disk/app/user/internal/repository/model"
+	"grpc-todolist-disk/utils/outbox"
 	"log"
 )
 
@@ -10,6 +11,8 @@ func migration() {
 	err := _db.Set("gorm:table_options", "charset=utf8mb4").
 		AutoMigrate(
 			&model.User{},
+			&model.AppPassword{},
+			&outbox.Message{},
 		)
 	if err != nil {
 		log.Println("register table failed")
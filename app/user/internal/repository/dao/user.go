@@ -5,6 +5,7 @@ import (
 	"grpc-todolist-disk/app/user/internal/repository/cache"
 	"grpc-todolist-disk/app/user/internal/repository/model"
 	pb "grpc-todolist-disk/idl/pb/user"
+	"grpc-todolist-disk/utils/outbox"
 	"log"
 	"time"
 )
@@ -84,14 +85,19 @@ func SetUserStatus(user *model.User, status string) bool {
 func SetUserPassword(user *model.User, newPassword string) bool {
 	db := NewDBClient().Session(&gorm.Session{NewDB: true})
 	_ = user.SetPassword(newPassword)
-	// 方式二：双删，弱一致性
+	// 方式二：双删，弱一致性。第二次删除与修改在同一事务中写入 outbox，进程崩溃也不会丢失
 	go cache.ClearNameRedisCache(user.Username)
-	err := db.Save(&user).Error
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		return cache.ClearCacheLater(tx, user.Username, time.Now().Add(1000*time.Millisecond))
+	})
 	if err != nil {
 		log.Println(err)
 		return false
 	}
-	go cache.SendMsgToMq(user.Username, time.Now().Add(1000*time.Millisecond))
+	outbox.Notify()
 	return true
 }
 
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"time"
+)
+
+// AppPassword 应用专用密码，供 WebDAV 等只支持 Basic 认证的客户端使用，可单独吊销
+type AppPassword struct {
+	gorm.Model
+	UserID     uint      `gorm:"index" json:"user_id"`
+	Name       string    `gorm:"type: varchar(100)" json:"name"` // 备注，如 "办公室电脑"
+	Password   string    `gorm:"type: varchar(100); not null;" json:"-"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// SetPassword 加密密码
+func (p *AppPassword) SetPassword(password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	p.Password = string(hash)
+	return nil
+}
+
+// CheckPassword 校验密码
+func (p *AppPassword) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(p.Password), []byte(password)) == nil
+}
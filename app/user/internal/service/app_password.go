@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"grpc-todolist-disk/app/user/internal/repository/dao"
+	pb "grpc-todolist-disk/idl/pb/user"
+	"grpc-todolist-disk/utils/e"
+	"time"
+)
+
+// UserAppPasswordCreate 创建应用专用密码
+func (u *UserSrv) UserAppPasswordCreate(ctx context.Context, req *pb.AppPasswordRequest) (*pb.AppPasswordResponse, error) {
+	resp := &pb.AppPasswordResponse{}
+	resp.Code = e.SUCCESS
+	p, raw, err := dao.NewUserDao().CreateAppPassword(uint(req.UserID), req.Name)
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = e.GetMsg(int(resp.Code))
+		return resp, nil
+	}
+	resp.AppPasswordID = uint64(p.ID)
+	resp.Password = raw
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// UserAppPasswordDelete 吊销应用专用密码
+func (u *UserSrv) UserAppPasswordDelete(ctx context.Context, req *pb.AppPasswordRequest) (*pb.UserCommonResponse, error) {
+	resp := &pb.UserCommonResponse{}
+	resp.Code = e.SUCCESS
+	n, err := dao.NewUserDao().DeleteAppPassword(uint(req.UserID), uint(req.AppPasswordID))
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = e.GetMsg(int(resp.Code))
+		return resp, nil
+	}
+	if n == 0 {
+		resp.Code = e.ERROR
+		resp.Msg = "应用专用密码不存在"
+		return resp, nil
+	}
+	resp.Msg = e.GetMsg(int(resp.Code))
+	return resp, nil
+}
+
+// UserAppPasswordCheck 使用用户名 + 应用专用密码认证，成功时返回用户信息
+func (u *UserSrv) UserAppPasswordCheck(ctx context.Context, req *pb.AppPasswordRequest) (*pb.UserDetailResponse, error) {
+	resp := &pb.UserDetailResponse{}
+	resp.Code = e.SUCCESS
+	user, err := dao.NewUserDao().GetUserByUsername(req.Username)
+	if err != nil || user == nil {
+		resp.Code = e.ErrorNotExistUser
+		resp.Msg = e.GetMsg(int(resp.Code))
+		return resp, nil
+	}
+	if time.Now().Before(user.LockedUntil) {
+		resp.Code = e.ErrorUserLock
+		resp.Msg = e.GetMsg(int(resp.Code))
+		return resp, nil
+	}
+	ok, err := dao.NewUserDao().CheckAppPassword(user.ID, req.Password)
+	if err != nil {
+		resp.Code = e.ERROR
+		resp.Msg = e.GetMsg(int(resp.Code))
+		return resp, nil
+	}
+	if !ok {
+		resp.Code = e.ErrorUserPassword
+		resp.Msg = e.GetMsg(int(resp.Code))
+		return resp, nil
+	}
+	resp.Msg = e.GetMsg(int(resp.Code))
+	resp.UserDetail = &pb.UserResponse{
+		UserID:   uint64(user.ID),
+		Nickname: user.Nickname,
+		Username: user.Username,
+	}
+	return resp, nil
+}
@@ -0,0 +1,82 @@
+// dev 单机开发模式：网关、kafka_server 的消费者和 outbox relay 在同一进程中运行，
+// 通过进程内的消息队列（kafka.driver: memory）通信，不需要启动 Kafka。
+// user / files 服务仍需单独启动，它们在该模式下不发送 outbox，由本进程发送
+package main
+
+import (
+	"context"
+	"errors"
+	"grpc-todolist-disk/app/files/dao"
+	"grpc-todolist-disk/app/gateway/middleware"
+	"grpc-todolist-disk/app/gateway/router"
+	"grpc-todolist-disk/app/gateway/rpc"
+	"grpc-todolist-disk/app/gateway/utils/cache"
+	"grpc-todolist-disk/app/gateway/utils/mq"
+	"grpc-todolist-disk/conf"
+	"grpc-todolist-disk/kafka_server/service"
+	"grpc-todolist-disk/utils/kafka_mq"
+	"grpc-todolist-disk/utils/outbox"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	conf.InitConfig()
+	// 其他服务读取同一份配置，据此判断是否由本进程发送 outbox
+	if !kafka_mq.InProcess() {
+		log.Fatal("单机开发模式需要在配置中设置 kafka.driver: memory")
+	}
+
+	// kafka_server：任务执行协程和消费者
+	service.Init()
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobsDone := make(chan struct{})
+	go func() {
+		defer close(jobsDone)
+		service.Run(jobsCtx)
+	}()
+
+	// user / files 服务写入 outbox 的消息
+	publisher := kafka_mq.NewPublisher("")
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		outbox.NewRelay(dao.DB, publisher).Run(jobsCtx)
+	}()
+
+	// 网关
+	mq.Init()
+	rpc.Init()
+	cache.Init()
+	middleware.InitTransfer(cache.RDB)
+	server := router.NewServer()
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("HTTP server start failed: %v", err)
+		}
+	}()
+	log.Printf("dev server listen on: %s (in-process message queue)", conf.Conf.Server.Port)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+	log.Println("Shutting down dev server...")
+
+	// 先处理完网关的请求，再停止消费，已发送的任务在退出前处理或放回队列
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server forced to shutdown: %v", err)
+	}
+	mq.KfWriter.Close()
+	stopJobs()
+	<-jobsDone
+	<-relayDone
+	publisher.Close()
+	service.Close()
+	cache.RDB.Close()
+	log.Println("dev server exiting")
+}
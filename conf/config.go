@@ -18,6 +18,14 @@ type Config struct {
 	Token    *Token              `yaml:"token"`
 	Kafka    *Kafka              `yaml:"kafka"`
 	Qiniu    *Qiniu              `yaml:"qiniu"`
+	Storage  *Storage            `yaml:"storage"`
+	Admin    *Admin              `yaml:"admin"`
+	Tiers    *Tiers              `yaml:"tiers"`
+	Policy   *Policy             `yaml:"policy"`
+	Scan     *Scan               `yaml:"scan"`
+	Download *Download           `yaml:"download"`
+	Direct   *DirectUpload       `yaml:"directUpload"`
+	Jobs     *Jobs               `yaml:"jobs"`
 }
 
 type Server struct {
@@ -61,17 +69,171 @@ type Token struct {
 }
 
 type Kafka struct {
+	Driver  string   `yaml:"driver"` // kafka（默认）或 memory：进程内的消息队列，用于单元测试和单机开发（cmd/dev）
 	Topic   []string `yaml:"topic"`
 	Broker  []string `yaml:"broker"`
 	GroupId []string `yaml:"groupID"`
 }
 
+// Jobs kafka_server 的后台任务
+type Jobs struct {
+	Consumers       []*JobConsumer      `yaml:"consumers"` // 消费的主题，新的任务类型可以复用已有主题
+	Types           map[string]*JobType `yaml:"types"`
+	DeadLetterTopic string              `yaml:"deadLetterTopic"` // 重试耗尽或不可重试的任务写入这个主题，为空时保存在 redis 中
+	IdempotencyTTL  int                 `yaml:"idempotencyTTL"`  // 已处理任务的幂等键保留时间（秒），默认 7 天
+	Schedules       []*JobSchedule      `yaml:"schedules"`       // 定时任务
+	Admin           *JobAdmin           `yaml:"admin"`
+	ShutdownTimeout int                 `yaml:"shutdownTimeout"` // 退出时等待执行中任务的最长时间（秒），默认 30
+}
+
+// JobAdmin kafka_server 的管理接口
+type JobAdmin struct {
+	Addr  string `yaml:"addr"`  // 监听地址，为空时不启动
+	Token string `yaml:"token"` // 请求头 Authorization: Bearer <token>，为空时不能访问
+}
+
+// JobSchedule 定时任务，到时间后由一个 kafka_server 实例写入任务队列
+type JobSchedule struct {
+	Name    string `yaml:"name"`
+	Spec    string `yaml:"spec"`    // cron 表达式（分 时 日 月 周），或 @daily、@every 10m 等
+	Job     string `yaml:"job"`     // 任务名
+	Payload string `yaml:"payload"` // 任务内容（JSON），为空时为 {}
+}
+
+type JobConsumer struct {
+	Topic      string `yaml:"topic"`
+	GroupID    string `yaml:"groupID"`
+	DefaultJob string `yaml:"defaultJob"` // 不是任务信封的旧格式消息按这个任务处理
+	Workers    int    `yaml:"workers"`    // 调度协程数，键相同的消息由同一个协程按顺序处理，默认 8
+}
+
+type JobType struct {
+	Concurrency int `yaml:"concurrency"` // 同时执行的数量（每个实例）
+	Timeout     int `yaml:"timeout"`     // 单次执行的超时（秒）
+	MaxAttempts int `yaml:"maxAttempts"` // 最多执行次数，包括第一次
+	BackoffBase int `yaml:"backoffBase"` // 第一次重试的延迟（秒），之后每次翻倍并加入随机抖动
+	BackoffMax  int `yaml:"backoffMax"`  // 重试延迟的上限（秒）
+}
+
 type Qiniu struct {
 	AccessKey string `yaml:"accessKey"`
 	SecretKey string `yaml:"secretKey"`
 	Bucket    string `yaml:"bucket"`
 	Domain    string `yaml:"domain"`
 	Zone      string `yaml:"zone"`
+
+	Private     bool   `yaml:"private"`     // 私有空间，下载地址需要签名
+	UseHTTPS    bool   `yaml:"useHTTPS"`    // 上传、管理和下载地址使用 HTTPS
+	URLExpire   int    `yaml:"urlExpire"`   // 私有空间下载地址的有效期（秒）
+	TokenExpire int    `yaml:"tokenExpire"` // 客户端直传凭证的有效期（秒）
+	CallbackURL string `yaml:"callbackURL"` // 客户端直传完成后七牛云回调的网关地址，为空时不支持直传
+	UpHost      string `yaml:"upHost"`      // 自定义上传地址（私有化部署或本地模拟服务），为空时按区域选择
+	RsHost      string `yaml:"rsHost"`      // 自定义管理接口地址
+}
+
+type Storage struct {
+	Chunking         bool  `yaml:"chunking"`         // 是否开启分块去重
+	ChunkAvgSize     int   `yaml:"chunkAvgSize"`     // 平均分块大小（字节）
+	ChunkMinFileSize int64 `yaml:"chunkMinFileSize"` // 小于该大小的文件不分块
+
+	Compression     bool     `yaml:"compression"`     // 是否对文本类文件做 zstd 压缩
+	CompressMinSize int64    `yaml:"compressMinSize"` // 小于该大小的文件不压缩
+	CompressTypes   []string `yaml:"compressTypes"`   // 需要压缩的 MIME 类型前缀，为空时使用默认列表
+}
+
+type Admin struct {
+	UserIDs []uint `yaml:"userIDs"` // 管理员用户 ID
+}
+
+// Tiers 用户等级，未列出的用户属于 Default 等级
+type Tiers struct {
+	Default string           `yaml:"default"`
+	Levels  map[string]*Tier `yaml:"levels"`
+}
+
+type Tier struct {
+	UserIDs     []uint `yaml:"userIDs"`     // 属于该等级的用户
+	MaxFileSize int64  `yaml:"maxFileSize"` // 单文件大小上限（字节），0 表示不限制
+	DailyVolume int64  `yaml:"dailyVolume"` // 每日上传总量上限（字节），0 表示不限制
+
+	UploadRate   int64 `yaml:"uploadRate"`   // 上传带宽（字节/秒），0 表示不限制
+	DownloadRate int64 `yaml:"downloadRate"` // 下载带宽（字节/秒），0 表示不限制
+	MaxUploads   int   `yaml:"maxUploads"`   // 同时进行的上传数，0 表示不限制
+	MaxDownloads int   `yaml:"maxDownloads"` // 同时进行的下载数，0 表示不限制
+}
+
+// TierOf 返回用户所属的等级，未配置时返回 nil
+func TierOf(userID uint) (string, *Tier) {
+	if Conf == nil || Conf.Tiers == nil {
+		return "", nil
+	}
+	for name, tier := range Conf.Tiers.Levels {
+		for _, id := range tier.UserIDs {
+			if id == userID {
+				return name, tier
+			}
+		}
+	}
+	name := Conf.Tiers.Default
+	return name, Conf.Tiers.Levels[name]
+}
+
+// Policy 上传策略
+type Policy struct {
+	RouteMaxSize      map[string]int64 `yaml:"routeMaxSize"`      // 各上传接口的单文件大小上限（字节）
+	AllowedExtensions []string         `yaml:"allowedExtensions"` // 非空时只允许这些扩展名
+	DeniedExtensions  []string         `yaml:"deniedExtensions"`
+	AllowedMimeTypes  []string         `yaml:"allowedMimeTypes"` // 按内容识别的 MIME 类型前缀，非空时只允许这些类型
+	DeniedMimeTypes   []string         `yaml:"deniedMimeTypes"`
+	MaxFilenameLength int              `yaml:"maxFilenameLength"`
+	DeniedFilenames   []string         `yaml:"deniedFilenames"` // 禁止的文件名（通配符，不区分大小写）
+}
+
+// Scan 上传后的恶意文件扫描
+type Scan struct {
+	Enabled        bool     `yaml:"enabled"`        // 开启后未通过扫描的文件（本地和七牛云）不允许下载
+	Scanner        string   `yaml:"scanner"`        // clamd 或 fake
+	Network        string   `yaml:"network"`        // clamd 连接方式：tcp 或 unix
+	Address        string   `yaml:"address"`        // clamd 地址
+	Timeout        int      `yaml:"timeout"`        // 单个文件的扫描超时（秒）
+	Workers        int      `yaml:"workers"`        // 并发扫描数
+	SweepInterval  int      `yaml:"sweepInterval"`  // 重新扫描待扫描和扫描失败文件的间隔（秒）
+	QuarantinePath string   `yaml:"quarantinePath"` // 感染文件的隔离目录
+	Signatures     []string `yaml:"signatures"`     // fake 扫描器识别的特征串，为空时使用 EICAR 测试串
+}
+
+// ScanEnabled 是否开启了上传扫描
+func ScanEnabled() bool {
+	return Conf != nil && Conf.Scan != nil && Conf.Scan.Enabled
+}
+
+// Download 签名下载链接
+type Download struct {
+	SignSecret string `yaml:"signSecret"` // HMAC 密钥，为空时不能生成签名链接
+	BaseURL    string `yaml:"baseURL"`    // 网关对外地址，如 https://disk.example.com，为空时返回相对路径
+	Expire     int    `yaml:"expire"`     // 默认有效期（秒）
+	MaxExpire  int    `yaml:"maxExpire"`  // 最长有效期（秒）
+}
+
+// DirectUpload 客户端直传：files 服务签发上传地址，内容不经过网关
+type DirectUpload struct {
+	Addr       string `yaml:"addr"`       // files 服务接收直传的 HTTP 监听地址，为空时不能直传到本地存储
+	BaseURL    string `yaml:"baseURL"`    // 客户端访问该地址使用的 URL，如 https://upload.example.com
+	SignSecret string `yaml:"signSecret"` // 上传凭证的 HMAC 密钥，为空时不能直传
+	Expire     int    `yaml:"expire"`     // 上传凭证的有效期（秒）
+}
+
+// IsAdmin 判断用户是否为管理员
+func IsAdmin(userID uint) bool {
+	if Conf == nil || Conf.Admin == nil {
+		return false
+	}
+	for _, id := range Conf.Admin.UserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
 }
 
 func InitConfig() {
@@ -34,7 +34,13 @@ type FileModel struct {
 	// @inject_tag: json:"bucket"
 	Bucket string `protobuf:"bytes,5,opt,name=Bucket,proto3" json:"bucket"` // 存储桶名称（如 MinIO 的 bucket）
 	// @inject_tag: json:"object_name"
-	ObjectName    string `protobuf:"bytes,6,opt,name=ObjectName,proto3" json:"object_name"` // 存储对象名（唯一标识）
+	ObjectName string `protobuf:"bytes,6,opt,name=ObjectName,proto3" json:"object_name"` // 存储对象名（唯一标识）
+	// @inject_tag: json:"folder_id"
+	FolderID uint64 `protobuf:"varint,7,opt,name=FolderID,proto3" json:"folder_id"` // 所在文件夹，0 表示根目录
+	// @inject_tag: json:"updated_at"
+	UpdatedAt int64 `protobuf:"varint,8,opt,name=UpdatedAt,proto3" json:"updated_at"` // 最后修改时间（Unix 秒）
+	// @inject_tag: json:"scan_status"
+	ScanStatus    string `protobuf:"bytes,9,opt,name=ScanStatus,proto3" json:"scan_status"` // 恶意文件扫描状态：pending、clean、infected、error，空表示未扫描
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -111,6 +117,27 @@ func (x *FileModel) GetObjectName() string {
 	return ""
 }
 
+func (x *FileModel) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
+func (x *FileModel) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *FileModel) GetScanStatus() string {
+	if x != nil {
+		return x.ScanStatus
+	}
+	return ""
+}
+
 // 文件上传（表单上传）
 type FileUploadRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -123,7 +150,9 @@ type FileUploadRequest struct {
 	// @inject_tag: json:"object_name"
 	ObjectName string `protobuf:"bytes,4,opt,name=ObjectName,proto3" json:"object_name"`
 	// @inject_tag: json:"file_hash"
-	FileHash      string `protobuf:"bytes,5,opt,name=FileHash,proto3" json:"file_hash"`
+	FileHash string `protobuf:"bytes,5,opt,name=FileHash,proto3" json:"file_hash"`
+	// @inject_tag: json:"folder_id" form:"folder_id"
+	FolderID      uint64 `protobuf:"varint,6,opt,name=FolderID,proto3" json:"folder_id" form:"folder_id"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -193,6 +222,13 @@ func (x *FileUploadRequest) GetFileHash() string {
 	return ""
 }
 
+func (x *FileUploadRequest) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
 type FileUploadResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// @inject_tag: json:"code" form:"code"
@@ -202,7 +238,9 @@ type FileUploadResponse struct {
 	// @inject_tag: json:"object_url"
 	ObjectUrl string `protobuf:"bytes,3,opt,name=ObjectUrl,proto3" json:"object_url"`
 	// @inject_tag: json:"file_id" form:"file_id"
-	FileID        uint64 `protobuf:"varint,4,opt,name=FileID,proto3" json:"file_id" form:"file_id"`
+	FileID uint64 `protobuf:"varint,4,opt,name=FileID,proto3" json:"file_id" form:"file_id"`
+	// @inject_tag: json:"rejection,omitempty"
+	Rejection     *PolicyRejection `protobuf:"bytes,5,opt,name=Rejection,proto3" json:"rejection,omitempty"` // 被上传策略拒绝时的原因
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -265,6 +303,86 @@ func (x *FileUploadResponse) GetFileID() uint64 {
 	return 0
 }
 
+func (x *FileUploadResponse) GetRejection() *PolicyRejection {
+	if x != nil {
+		return x.Rejection
+	}
+	return nil
+}
+
+// 上传策略的拒绝原因
+type PolicyRejection struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"rule"
+	Rule string `protobuf:"bytes,1,opt,name=Rule,proto3" json:"rule"` // route_size, tier_size, daily_volume, extension, mime_type, filename
+	// @inject_tag: json:"reason"
+	Reason string `protobuf:"bytes,2,opt,name=Reason,proto3" json:"reason"`
+	// @inject_tag: json:"limit,omitempty"
+	Limit int64 `protobuf:"varint,3,opt,name=Limit,proto3" json:"limit,omitempty"`
+	// @inject_tag: json:"actual,omitempty"
+	Actual        int64 `protobuf:"varint,4,opt,name=Actual,proto3" json:"actual,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PolicyRejection) Reset() {
+	*x = PolicyRejection{}
+	mi := &file_files_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PolicyRejection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PolicyRejection) ProtoMessage() {}
+
+func (x *PolicyRejection) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PolicyRejection.ProtoReflect.Descriptor instead.
+func (*PolicyRejection) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PolicyRejection) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *PolicyRejection) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *PolicyRejection) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *PolicyRejection) GetActual() int64 {
+	if x != nil {
+		return x.Actual
+	}
+	return 0
+}
+
 // 文件上传（二进制上传）
 type BigFileUploadRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -281,14 +399,16 @@ type BigFileUploadRequest struct {
 	// @inject_tag: json:"is_last" form:"is_last"
 	IsLast bool `protobuf:"varint,6,opt,name=IsLast,proto3" json:"is_last" form:"is_last"` // 是否为最后一个分片
 	// @inject_tag: json:"file_hash"
-	FileHash      string `protobuf:"bytes,7,opt,name=FileHash,proto3" json:"file_hash"`
+	FileHash string `protobuf:"bytes,7,opt,name=FileHash,proto3" json:"file_hash"`
+	// @inject_tag: json:"folder_id" form:"folder_id"
+	FolderID      uint64 `protobuf:"varint,8,opt,name=FolderID,proto3" json:"folder_id" form:"folder_id"` // 目标文件夹，仅首个分片携带
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BigFileUploadRequest) Reset() {
 	*x = BigFileUploadRequest{}
-	mi := &file_files_proto_msgTypes[3]
+	mi := &file_files_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -300,7 +420,7 @@ func (x *BigFileUploadRequest) String() string {
 func (*BigFileUploadRequest) ProtoMessage() {}
 
 func (x *BigFileUploadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_files_proto_msgTypes[3]
+	mi := &file_files_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -313,7 +433,7 @@ func (x *BigFileUploadRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BigFileUploadRequest.ProtoReflect.Descriptor instead.
 func (*BigFileUploadRequest) Descriptor() ([]byte, []int) {
-	return file_files_proto_rawDescGZIP(), []int{3}
+	return file_files_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *BigFileUploadRequest) GetUserID() uint64 {
@@ -365,6 +485,13 @@ func (x *BigFileUploadRequest) GetFileHash() string {
 	return ""
 }
 
+func (x *BigFileUploadRequest) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
 type BigFileUploadResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// @inject_tag: json:"code" form:"code"
@@ -374,14 +501,16 @@ type BigFileUploadResponse struct {
 	// @inject_tag: json:"object_url"
 	ObjectUrl string `protobuf:"bytes,3,opt,name=ObjectUrl,proto3" json:"object_url"`
 	// @inject_tag: json:"file_id" form:"file_id"
-	FileID        uint64 `protobuf:"varint,4,opt,name=FileID,proto3" json:"file_id" form:"file_id"`
+	FileID uint64 `protobuf:"varint,4,opt,name=FileID,proto3" json:"file_id" form:"file_id"`
+	// @inject_tag: json:"rejection,omitempty"
+	Rejection     *PolicyRejection `protobuf:"bytes,5,opt,name=Rejection,proto3" json:"rejection,omitempty"` // 被上传策略拒绝时的原因
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BigFileUploadResponse) Reset() {
 	*x = BigFileUploadResponse{}
-	mi := &file_files_proto_msgTypes[4]
+	mi := &file_files_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -393,7 +522,7 @@ func (x *BigFileUploadResponse) String() string {
 func (*BigFileUploadResponse) ProtoMessage() {}
 
 func (x *BigFileUploadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_files_proto_msgTypes[4]
+	mi := &file_files_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -406,7 +535,7 @@ func (x *BigFileUploadResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BigFileUploadResponse.ProtoReflect.Descriptor instead.
 func (*BigFileUploadResponse) Descriptor() ([]byte, []int) {
-	return file_files_proto_rawDescGZIP(), []int{4}
+	return file_files_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *BigFileUploadResponse) GetCode() int64 {
@@ -437,6 +566,13 @@ func (x *BigFileUploadResponse) GetFileID() uint64 {
 	return 0
 }
 
+func (x *BigFileUploadResponse) GetRejection() *PolicyRejection {
+	if x != nil {
+		return x.Rejection
+	}
+	return nil
+}
+
 // 文件删除
 type FileDeleteRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -450,7 +586,7 @@ type FileDeleteRequest struct {
 
 func (x *FileDeleteRequest) Reset() {
 	*x = FileDeleteRequest{}
-	mi := &file_files_proto_msgTypes[5]
+	mi := &file_files_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -462,7 +598,7 @@ func (x *FileDeleteRequest) String() string {
 func (*FileDeleteRequest) ProtoMessage() {}
 
 func (x *FileDeleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_files_proto_msgTypes[5]
+	mi := &file_files_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -475,7 +611,7 @@ func (x *FileDeleteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileDeleteRequest.ProtoReflect.Descriptor instead.
 func (*FileDeleteRequest) Descriptor() ([]byte, []int) {
-	return file_files_proto_rawDescGZIP(), []int{5}
+	return file_files_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *FileDeleteRequest) GetFileID() uint64 {
@@ -500,14 +636,16 @@ type FileListRequest struct {
 	// @inject_tag: json:"page" form:"page"
 	Page int32 `protobuf:"varint,2,opt,name=Page,proto3" json:"page" form:"page"`
 	// @inject_tag: json:"page_size" form:"page_size"
-	PageSize      int32 `protobuf:"varint,3,opt,name=PageSize,proto3" json:"page_size" form:"page_size"`
+	PageSize int32 `protobuf:"varint,3,opt,name=PageSize,proto3" json:"page_size" form:"page_size"`
+	// @inject_tag: json:"folder_id" form:"folder_id"
+	FolderID      uint64 `protobuf:"varint,4,opt,name=FolderID,proto3" json:"folder_id" form:"folder_id"` // 指定文件夹时列出该文件夹内容（可以是他人共享的文件夹）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *FileListRequest) Reset() {
 	*x = FileListRequest{}
-	mi := &file_files_proto_msgTypes[6]
+	mi := &file_files_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -519,7 +657,7 @@ func (x *FileListRequest) String() string {
 func (*FileListRequest) ProtoMessage() {}
 
 func (x *FileListRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_files_proto_msgTypes[6]
+	mi := &file_files_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -532,7 +670,7 @@ func (x *FileListRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileListRequest.ProtoReflect.Descriptor instead.
 func (*FileListRequest) Descriptor() ([]byte, []int) {
-	return file_files_proto_rawDescGZIP(), []int{6}
+	return file_files_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *FileListRequest) GetUserID() uint64 {
@@ -556,6 +694,13 @@ func (x *FileListRequest) GetPageSize() int32 {
 	return 0
 }
 
+func (x *FileListRequest) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
 type FileListResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// @inject_tag: json:"code"
@@ -572,7 +717,7 @@ type FileListResponse struct {
 
 func (x *FileListResponse) Reset() {
 	*x = FileListResponse{}
-	mi := &file_files_proto_msgTypes[7]
+	mi := &file_files_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -584,7 +729,7 @@ func (x *FileListResponse) String() string {
 func (*FileListResponse) ProtoMessage() {}
 
 func (x *FileListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_files_proto_msgTypes[7]
+	mi := &file_files_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -597,7 +742,7 @@ func (x *FileListResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileListResponse.ProtoReflect.Descriptor instead.
 func (*FileListResponse) Descriptor() ([]byte, []int) {
-	return file_files_proto_rawDescGZIP(), []int{7}
+	return file_files_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *FileListResponse) GetCode() int32 {
@@ -643,7 +788,7 @@ type FileDownloadRequest struct {
 
 func (x *FileDownloadRequest) Reset() {
 	*x = FileDownloadRequest{}
-	mi := &file_files_proto_msgTypes[8]
+	mi := &file_files_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -655,7 +800,7 @@ func (x *FileDownloadRequest) String() string {
 func (*FileDownloadRequest) ProtoMessage() {}
 
 func (x *FileDownloadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_files_proto_msgTypes[8]
+	mi := &file_files_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -668,7 +813,7 @@ func (x *FileDownloadRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileDownloadRequest.ProtoReflect.Descriptor instead.
 func (*FileDownloadRequest) Descriptor() ([]byte, []int) {
-	return file_files_proto_rawDescGZIP(), []int{8}
+	return file_files_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *FileDownloadRequest) GetFileID() uint64 {
@@ -701,14 +846,20 @@ type FileDownloadResponse struct {
 	// @inject_tag: json:"download_url"
 	DownloadUrl string `protobuf:"bytes,3,opt,name=DownloadUrl,proto3" json:"download_url"`
 	// @inject_tag: json:"file_name" form:"file_name"
-	Filename      string `protobuf:"bytes,4,opt,name=Filename,proto3" json:"file_name" form:"file_name"`
+	Filename string `protobuf:"bytes,4,opt,name=Filename,proto3" json:"file_name" form:"file_name"`
+	// @inject_tag: json:"layout"
+	Layout string `protobuf:"bytes,5,opt,name=Layout,proto3" json:"layout"` // 本地文件的存储布局，chunked 表示 DownloadUrl 上是分块清单
+	// @inject_tag: json:"codec"
+	Codec string `protobuf:"bytes,6,opt,name=Codec,proto3" json:"codec"` // 压缩格式，zstd 表示按布局读出的内容需要解压
+	// @inject_tag: json:"bucket"
+	Bucket        string `protobuf:"bytes,7,opt,name=Bucket,proto3" json:"bucket"` // qiniu 表示 DownloadUrl 为七牛云的访问地址
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *FileDownloadResponse) Reset() {
 	*x = FileDownloadResponse{}
-	mi := &file_files_proto_msgTypes[9]
+	mi := &file_files_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -720,7 +871,7 @@ func (x *FileDownloadResponse) String() string {
 func (*FileDownloadResponse) ProtoMessage() {}
 
 func (x *FileDownloadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_files_proto_msgTypes[9]
+	mi := &file_files_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -733,7 +884,7 @@ func (x *FileDownloadResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileDownloadResponse.ProtoReflect.Descriptor instead.
 func (*FileDownloadResponse) Descriptor() ([]byte, []int) {
-	return file_files_proto_rawDescGZIP(), []int{9}
+	return file_files_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *FileDownloadResponse) GetCode() int32 {
@@ -764,6 +915,27 @@ func (x *FileDownloadResponse) GetFilename() string {
 	return ""
 }
 
+func (x *FileDownloadResponse) GetLayout() string {
+	if x != nil {
+		return x.Layout
+	}
+	return ""
+}
+
+func (x *FileDownloadResponse) GetCodec() string {
+	if x != nil {
+		return x.Codec
+	}
+	return ""
+}
+
+func (x *FileDownloadResponse) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
 type FileCommonResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// @inject_tag: json:"code" form:"code"
@@ -776,7 +948,7 @@ type FileCommonResponse struct {
 
 func (x *FileCommonResponse) Reset() {
 	*x = FileCommonResponse{}
-	mi := &file_files_proto_msgTypes[10]
+	mi := &file_files_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -788,7 +960,7 @@ func (x *FileCommonResponse) String() string {
 func (*FileCommonResponse) ProtoMessage() {}
 
 func (x *FileCommonResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_files_proto_msgTypes[10]
+	mi := &file_files_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -801,7 +973,7 @@ func (x *FileCommonResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileCommonResponse.ProtoReflect.Descriptor instead.
 func (*FileCommonResponse) Descriptor() ([]byte, []int) {
-	return file_files_proto_rawDescGZIP(), []int{10}
+	return file_files_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *FileCommonResponse) GetCode() int64 {
@@ -824,14 +996,18 @@ type CheckFileRequest struct {
 	// @inject_tag: json:"file_hash"
 	FileHash string `protobuf:"bytes,1,opt,name=FileHash,proto3" json:"file_hash"`
 	// @inject_tag: json:"user_id" form:"user_id"
-	UserID        uint64 `protobuf:"varint,2,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	UserID uint64 `protobuf:"varint,2,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"folder_id" form:"folder_id"
+	FolderID uint64 `protobuf:"varint,3,opt,name=FolderID,proto3" json:"folder_id" form:"folder_id"` // 上传的目标文件夹，非 0 时先校验写入权限
+	// @inject_tag: json:"filename" form:"filename"
+	Filename      string `protobuf:"bytes,4,opt,name=Filename,proto3" json:"filename" form:"filename"` // 命中时在目标文件夹下使用的文件名，为空时沿用已有记录的文件名
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CheckFileRequest) Reset() {
 	*x = CheckFileRequest{}
-	mi := &file_files_proto_msgTypes[11]
+	mi := &file_files_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -843,7 +1019,7 @@ func (x *CheckFileRequest) String() string {
 func (*CheckFileRequest) ProtoMessage() {}
 
 func (x *CheckFileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_files_proto_msgTypes[11]
+	mi := &file_files_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -856,7 +1032,7 @@ func (x *CheckFileRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckFileRequest.ProtoReflect.Descriptor instead.
 func (*CheckFileRequest) Descriptor() ([]byte, []int) {
-	return file_files_proto_rawDescGZIP(), []int{11}
+	return file_files_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *CheckFileRequest) GetFileHash() string {
@@ -873,6 +1049,20 @@ func (x *CheckFileRequest) GetUserID() uint64 {
 	return 0
 }
 
+func (x *CheckFileRequest) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
+func (x *CheckFileRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
 type CheckFileResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// @inject_tag: json:"file_id" form:"file_id"
@@ -880,14 +1070,18 @@ type CheckFileResponse struct {
 	// @inject_tag: json:"object_url"
 	ObjectUrl string `protobuf:"bytes,2,opt,name=ObjectUrl,proto3" json:"object_url"`
 	// @inject_tag: json:"exists"
-	Exists        bool `protobuf:"varint,3,opt,name=exists,proto3" json:"exists"`
+	Exists bool `protobuf:"varint,3,opt,name=exists,proto3" json:"exists"`
+	// @inject_tag: json:"code"
+	Code int64 `protobuf:"varint,4,opt,name=Code,proto3" json:"code"` // 没有目标文件夹的写入权限时不为 SUCCESS
+	// @inject_tag: json:"msg"
+	Msg           string `protobuf:"bytes,5,opt,name=Msg,proto3" json:"msg"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CheckFileResponse) Reset() {
 	*x = CheckFileResponse{}
-	mi := &file_files_proto_msgTypes[12]
+	mi := &file_files_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -899,7 +1093,7 @@ func (x *CheckFileResponse) String() string {
 func (*CheckFileResponse) ProtoMessage() {}
 
 func (x *CheckFileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_files_proto_msgTypes[12]
+	mi := &file_files_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -912,7 +1106,7 @@ func (x *CheckFileResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckFileResponse.ProtoReflect.Descriptor instead.
 func (*CheckFileResponse) Descriptor() ([]byte, []int) {
-	return file_files_proto_rawDescGZIP(), []int{12}
+	return file_files_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *CheckFileResponse) GetFileID() uint64 {
@@ -936,6 +1130,20 @@ func (x *CheckFileResponse) GetExists() bool {
 	return false
 }
 
+func (x *CheckFileResponse) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *CheckFileResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
 // 全局 file_name 模糊搜索
 type GlobalFileSearchRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -953,7 +1161,7 @@ type GlobalFileSearchRequest struct {
 
 func (x *GlobalFileSearchRequest) Reset() {
 	*x = GlobalFileSearchRequest{}
-	mi := &file_files_proto_msgTypes[13]
+	mi := &file_files_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -965,7 +1173,7 @@ func (x *GlobalFileSearchRequest) String() string {
 func (*GlobalFileSearchRequest) ProtoMessage() {}
 
 func (x *GlobalFileSearchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_files_proto_msgTypes[13]
+	mi := &file_files_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -978,7 +1186,7 @@ func (x *GlobalFileSearchRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GlobalFileSearchRequest.ProtoReflect.Descriptor instead.
 func (*GlobalFileSearchRequest) Descriptor() ([]byte, []int) {
-	return file_files_proto_rawDescGZIP(), []int{13}
+	return file_files_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *GlobalFileSearchRequest) GetFileName() string {
@@ -1029,7 +1237,7 @@ type GlobalFileSearchResponse struct {
 
 func (x *GlobalFileSearchResponse) Reset() {
 	*x = GlobalFileSearchResponse{}
-	mi := &file_files_proto_msgTypes[14]
+	mi := &file_files_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1041,7 +1249,7 @@ func (x *GlobalFileSearchResponse) String() string {
 func (*GlobalFileSearchResponse) ProtoMessage() {}
 
 func (x *GlobalFileSearchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_files_proto_msgTypes[14]
+	mi := &file_files_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1054,7 +1262,7 @@ func (x *GlobalFileSearchResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GlobalFileSearchResponse.ProtoReflect.Descriptor instead.
 func (*GlobalFileSearchResponse) Descriptor() ([]byte, []int) {
-	return file_files_proto_rawDescGZIP(), []int{14}
+	return file_files_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *GlobalFileSearchResponse) GetCode() int64 {
@@ -1125,7 +1333,7 @@ type GlobalFileInfo struct {
 
 func (x *GlobalFileInfo) Reset() {
 	*x = GlobalFileInfo{}
-	mi := &file_files_proto_msgTypes[15]
+	mi := &file_files_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1137,7 +1345,7 @@ func (x *GlobalFileInfo) String() string {
 func (*GlobalFileInfo) ProtoMessage() {}
 
 func (x *GlobalFileInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_files_proto_msgTypes[15]
+	mi := &file_files_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1150,7 +1358,7 @@ func (x *GlobalFileInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GlobalFileInfo.ProtoReflect.Descriptor instead.
 func (*GlobalFileInfo) Descriptor() ([]byte, []int) {
-	return file_files_proto_rawDescGZIP(), []int{15}
+	return file_files_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *GlobalFileInfo) GetFileID() uint64 {
@@ -1216,56 +1424,2834 @@ func (x *GlobalFileInfo) GetUpdatedAt() string {
 	return ""
 }
 
-var File_files_proto protoreflect.FileDescriptor
+// 文件夹
+type FolderModel struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"folder_id"
+	FolderID uint64 `protobuf:"varint,1,opt,name=FolderID,proto3" json:"folder_id"`
+	// @inject_tag: json:"user_id"
+	UserID uint64 `protobuf:"varint,2,opt,name=UserID,proto3" json:"user_id"`
+	// @inject_tag: json:"parent_id"
+	ParentID uint64 `protobuf:"varint,3,opt,name=ParentID,proto3" json:"parent_id"`
+	// @inject_tag: json:"name"
+	Name string `protobuf:"bytes,4,opt,name=Name,proto3" json:"name"`
+	// @inject_tag: json:"updated_at"
+	UpdatedAt     int64 `protobuf:"varint,5,opt,name=UpdatedAt,proto3" json:"updated_at"` // 最后修改时间（Unix 秒）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_files_proto_rawDesc = "" +
-	"\n" +
-	"\vfiles.proto\"\xab\x01\n" +
-	"\tFileModel\x12\x16\n" +
-	"\x06FileID\x18\x01 \x01(\x04R\x06FileID\x12\x16\n" +
-	"\x06UserID\x18\x02 \x01(\x04R\x06UserID\x12\x1a\n" +
-	"\bFileName\x18\x03 \x01(\tR\bFileName\x12\x1a\n" +
-	"\bFileSize\x18\x04 \x01(\x03R\bFileSize\x12\x16\n" +
-	"\x06Bucket\x18\x05 \x01(\tR\x06Bucket\x12\x1e\n" +
-	"\n" +
-	"ObjectName\x18\x06 \x01(\tR\n" +
-	"ObjectName\"\x9f\x01\n" +
-	"\x11FileUploadRequest\x12\x16\n" +
-	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x1a\n" +
-	"\bFilename\x18\x02 \x01(\tR\bFilename\x12\x1a\n" +
-	"\bFileSize\x18\x03 \x01(\x03R\bFileSize\x12\x1e\n" +
-	"\n" +
-	"ObjectName\x18\x04 \x01(\tR\n" +
-	"ObjectName\x12\x1a\n" +
-	"\bFileHash\x18\x05 \x01(\tR\bFileHash\"p\n" +
-	"\x12FileUploadResponse\x12\x12\n" +
-	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
-	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12\x1c\n" +
-	"\tObjectUrl\x18\x03 \x01(\tR\tObjectUrl\x12\x16\n" +
-	"\x06FileID\x18\x04 \x01(\x04R\x06FileID\"\xd4\x01\n" +
-	"\x14BigFileUploadRequest\x12\x16\n" +
-	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x1a\n" +
-	"\bFilename\x18\x02 \x01(\tR\bFilename\x12\x1a\n" +
-	"\bFileSize\x18\x03 \x01(\x03R\bFileSize\x12\x1e\n" +
-	"\n" +
-	"ObjectName\x18\x04 \x01(\tR\n" +
-	"ObjectName\x12\x18\n" +
-	"\aContent\x18\x05 \x01(\fR\aContent\x12\x16\n" +
-	"\x06IsLast\x18\x06 \x01(\bR\x06IsLast\x12\x1a\n" +
-	"\bFileHash\x18\a \x01(\tR\bFileHash\"s\n" +
-	"\x15BigFileUploadResponse\x12\x12\n" +
-	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
-	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12\x1c\n" +
-	"\tObjectUrl\x18\x03 \x01(\tR\tObjectUrl\x12\x16\n" +
-	"\x06FileID\x18\x04 \x01(\x04R\x06FileID\"C\n" +
-	"\x11FileDeleteRequest\x12\x16\n" +
-	"\x06FileID\x18\x01 \x01(\x04R\x06FileID\x12\x16\n" +
-	"\x06UserID\x18\x02 \x01(\x04R\x06UserID\"Y\n" +
-	"\x0fFileListRequest\x12\x16\n" +
-	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x12\n" +
-	"\x04Page\x18\x02 \x01(\x05R\x04Page\x12\x1a\n" +
-	"\bPageSize\x18\x03 \x01(\x05R\bPageSize\"p\n" +
-	"\x10FileListResponse\x12\x12\n" +
+func (x *FolderModel) Reset() {
+	*x = FolderModel{}
+	mi := &file_files_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FolderModel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FolderModel) ProtoMessage() {}
+
+func (x *FolderModel) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FolderModel.ProtoReflect.Descriptor instead.
+func (*FolderModel) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *FolderModel) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
+func (x *FolderModel) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *FolderModel) GetParentID() uint64 {
+	if x != nil {
+		return x.ParentID
+	}
+	return 0
+}
+
+func (x *FolderModel) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FolderModel) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type FolderCreateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"parent_id" form:"parent_id"
+	ParentID uint64 `protobuf:"varint,2,opt,name=ParentID,proto3" json:"parent_id" form:"parent_id"` // 父文件夹，0 表示根目录
+	// @inject_tag: json:"name" form:"name"
+	Name          string `protobuf:"bytes,3,opt,name=Name,proto3" json:"name" form:"name"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FolderCreateRequest) Reset() {
+	*x = FolderCreateRequest{}
+	mi := &file_files_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FolderCreateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FolderCreateRequest) ProtoMessage() {}
+
+func (x *FolderCreateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FolderCreateRequest.ProtoReflect.Descriptor instead.
+func (*FolderCreateRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *FolderCreateRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *FolderCreateRequest) GetParentID() uint64 {
+	if x != nil {
+		return x.ParentID
+	}
+	return 0
+}
+
+func (x *FolderCreateRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type FolderCreateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"code"
+	Code int64 `protobuf:"varint,1,opt,name=Code,proto3" json:"code"`
+	// @inject_tag: json:"msg"
+	Msg string `protobuf:"bytes,2,opt,name=Msg,proto3" json:"msg"`
+	// @inject_tag: json:"folder_id"
+	FolderID      uint64 `protobuf:"varint,3,opt,name=FolderID,proto3" json:"folder_id"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FolderCreateResponse) Reset() {
+	*x = FolderCreateResponse{}
+	mi := &file_files_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FolderCreateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FolderCreateResponse) ProtoMessage() {}
+
+func (x *FolderCreateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FolderCreateResponse.ProtoReflect.Descriptor instead.
+func (*FolderCreateResponse) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *FolderCreateResponse) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *FolderCreateResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *FolderCreateResponse) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
+// 文件重命名
+type FileRenameRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"file_id" form:"file_id"
+	FileID uint64 `protobuf:"varint,2,opt,name=FileID,proto3" json:"file_id" form:"file_id"`
+	// @inject_tag: json:"new_name" form:"new_name"
+	NewName       string `protobuf:"bytes,3,opt,name=NewName,proto3" json:"new_name" form:"new_name"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileRenameRequest) Reset() {
+	*x = FileRenameRequest{}
+	mi := &file_files_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileRenameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileRenameRequest) ProtoMessage() {}
+
+func (x *FileRenameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileRenameRequest.ProtoReflect.Descriptor instead.
+func (*FileRenameRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *FileRenameRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *FileRenameRequest) GetFileID() uint64 {
+	if x != nil {
+		return x.FileID
+	}
+	return 0
+}
+
+func (x *FileRenameRequest) GetNewName() string {
+	if x != nil {
+		return x.NewName
+	}
+	return ""
+}
+
+// 文件夹内容（子文件夹 + 文件）
+type FolderListRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"folder_id" form:"folder_id"
+	FolderID      uint64 `protobuf:"varint,2,opt,name=FolderID,proto3" json:"folder_id" form:"folder_id"` // 0 表示用户自己的根目录
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FolderListRequest) Reset() {
+	*x = FolderListRequest{}
+	mi := &file_files_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FolderListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FolderListRequest) ProtoMessage() {}
+
+func (x *FolderListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FolderListRequest.ProtoReflect.Descriptor instead.
+func (*FolderListRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *FolderListRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *FolderListRequest) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
+type FolderListResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"code"
+	Code int64 `protobuf:"varint,1,opt,name=Code,proto3" json:"code"`
+	// @inject_tag: json:"msg"
+	Msg string `protobuf:"bytes,2,opt,name=Msg,proto3" json:"msg"`
+	// @inject_tag: json:"folders"
+	Folders []*FolderModel `protobuf:"bytes,3,rep,name=Folders,proto3" json:"folders"`
+	// @inject_tag: json:"files"
+	Files         []*FileModel `protobuf:"bytes,4,rep,name=Files,proto3" json:"files"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FolderListResponse) Reset() {
+	*x = FolderListResponse{}
+	mi := &file_files_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FolderListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FolderListResponse) ProtoMessage() {}
+
+func (x *FolderListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FolderListResponse.ProtoReflect.Descriptor instead.
+func (*FolderListResponse) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *FolderListResponse) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *FolderListResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *FolderListResponse) GetFolders() []*FolderModel {
+	if x != nil {
+		return x.Folders
+	}
+	return nil
+}
+
+func (x *FolderListResponse) GetFiles() []*FileModel {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+// 移动文件（可同时重命名）
+type FileMoveRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"file_id" form:"file_id"
+	FileID uint64 `protobuf:"varint,2,opt,name=FileID,proto3" json:"file_id" form:"file_id"`
+	// @inject_tag: json:"folder_id" form:"folder_id"
+	FolderID uint64 `protobuf:"varint,3,opt,name=FolderID,proto3" json:"folder_id" form:"folder_id"` // 目标文件夹，0 表示根目录
+	// @inject_tag: json:"new_name" form:"new_name"
+	NewName       string `protobuf:"bytes,4,opt,name=NewName,proto3" json:"new_name" form:"new_name"` // 为空时保留原文件名
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileMoveRequest) Reset() {
+	*x = FileMoveRequest{}
+	mi := &file_files_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileMoveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileMoveRequest) ProtoMessage() {}
+
+func (x *FileMoveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileMoveRequest.ProtoReflect.Descriptor instead.
+func (*FileMoveRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *FileMoveRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *FileMoveRequest) GetFileID() uint64 {
+	if x != nil {
+		return x.FileID
+	}
+	return 0
+}
+
+func (x *FileMoveRequest) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
+func (x *FileMoveRequest) GetNewName() string {
+	if x != nil {
+		return x.NewName
+	}
+	return ""
+}
+
+// 移动/重命名文件夹
+type FolderMoveRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"folder_id" form:"folder_id"
+	FolderID uint64 `protobuf:"varint,2,opt,name=FolderID,proto3" json:"folder_id" form:"folder_id"`
+	// @inject_tag: json:"parent_id" form:"parent_id"
+	ParentID uint64 `protobuf:"varint,3,opt,name=ParentID,proto3" json:"parent_id" form:"parent_id"` // 目标父文件夹，0 表示根目录
+	// @inject_tag: json:"new_name" form:"new_name"
+	NewName       string `protobuf:"bytes,4,opt,name=NewName,proto3" json:"new_name" form:"new_name"` // 为空时保留原名称
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FolderMoveRequest) Reset() {
+	*x = FolderMoveRequest{}
+	mi := &file_files_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FolderMoveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FolderMoveRequest) ProtoMessage() {}
+
+func (x *FolderMoveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FolderMoveRequest.ProtoReflect.Descriptor instead.
+func (*FolderMoveRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *FolderMoveRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *FolderMoveRequest) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
+func (x *FolderMoveRequest) GetParentID() uint64 {
+	if x != nil {
+		return x.ParentID
+	}
+	return 0
+}
+
+func (x *FolderMoveRequest) GetNewName() string {
+	if x != nil {
+		return x.NewName
+	}
+	return ""
+}
+
+// 删除文件夹（递归删除其中的文件和子文件夹）
+type FolderDeleteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"folder_id" form:"folder_id"
+	FolderID      uint64 `protobuf:"varint,2,opt,name=FolderID,proto3" json:"folder_id" form:"folder_id"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FolderDeleteRequest) Reset() {
+	*x = FolderDeleteRequest{}
+	mi := &file_files_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FolderDeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FolderDeleteRequest) ProtoMessage() {}
+
+func (x *FolderDeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FolderDeleteRequest.ProtoReflect.Descriptor instead.
+func (*FolderDeleteRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *FolderDeleteRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *FolderDeleteRequest) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
+// 共享授权
+type ShareModel struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"share_id"
+	ShareID uint64 `protobuf:"varint,1,opt,name=ShareID,proto3" json:"share_id"`
+	// @inject_tag: json:"owner_id"
+	OwnerID uint64 `protobuf:"varint,2,opt,name=OwnerID,proto3" json:"owner_id"`
+	// @inject_tag: json:"resource_type"
+	ResourceType string `protobuf:"bytes,3,opt,name=ResourceType,proto3" json:"resource_type"` // file / folder
+	// @inject_tag: json:"resource_id"
+	ResourceID uint64 `protobuf:"varint,4,opt,name=ResourceID,proto3" json:"resource_id"`
+	// @inject_tag: json:"resource_name"
+	ResourceName string `protobuf:"bytes,5,opt,name=ResourceName,proto3" json:"resource_name"`
+	// @inject_tag: json:"grantee_type"
+	GranteeType string `protobuf:"bytes,6,opt,name=GranteeType,proto3" json:"grantee_type"` // user / group
+	// @inject_tag: json:"grantee_id"
+	GranteeID uint64 `protobuf:"varint,7,opt,name=GranteeID,proto3" json:"grantee_id"`
+	// @inject_tag: json:"role"
+	Role string `protobuf:"bytes,8,opt,name=Role,proto3" json:"role"` // viewer / editor / owner
+	// @inject_tag: json:"created_at"
+	CreatedAt     string `protobuf:"bytes,9,opt,name=CreatedAt,proto3" json:"created_at"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareModel) Reset() {
+	*x = ShareModel{}
+	mi := &file_files_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareModel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareModel) ProtoMessage() {}
+
+func (x *ShareModel) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareModel.ProtoReflect.Descriptor instead.
+func (*ShareModel) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ShareModel) GetShareID() uint64 {
+	if x != nil {
+		return x.ShareID
+	}
+	return 0
+}
+
+func (x *ShareModel) GetOwnerID() uint64 {
+	if x != nil {
+		return x.OwnerID
+	}
+	return 0
+}
+
+func (x *ShareModel) GetResourceType() string {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *ShareModel) GetResourceID() uint64 {
+	if x != nil {
+		return x.ResourceID
+	}
+	return 0
+}
+
+func (x *ShareModel) GetResourceName() string {
+	if x != nil {
+		return x.ResourceName
+	}
+	return ""
+}
+
+func (x *ShareModel) GetGranteeType() string {
+	if x != nil {
+		return x.GranteeType
+	}
+	return ""
+}
+
+func (x *ShareModel) GetGranteeID() uint64 {
+	if x != nil {
+		return x.GranteeID
+	}
+	return 0
+}
+
+func (x *ShareModel) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *ShareModel) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type ShareGrantRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"resource_type" form:"resource_type"
+	ResourceType string `protobuf:"bytes,2,opt,name=ResourceType,proto3" json:"resource_type" form:"resource_type"`
+	// @inject_tag: json:"resource_id" form:"resource_id"
+	ResourceID uint64 `protobuf:"varint,3,opt,name=ResourceID,proto3" json:"resource_id" form:"resource_id"`
+	// @inject_tag: json:"grantee_type" form:"grantee_type"
+	GranteeType string `protobuf:"bytes,4,opt,name=GranteeType,proto3" json:"grantee_type" form:"grantee_type"`
+	// @inject_tag: json:"grantee_id" form:"grantee_id"
+	GranteeID uint64 `protobuf:"varint,5,opt,name=GranteeID,proto3" json:"grantee_id" form:"grantee_id"`
+	// @inject_tag: json:"role" form:"role"
+	Role          string `protobuf:"bytes,6,opt,name=Role,proto3" json:"role" form:"role"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareGrantRequest) Reset() {
+	*x = ShareGrantRequest{}
+	mi := &file_files_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareGrantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareGrantRequest) ProtoMessage() {}
+
+func (x *ShareGrantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareGrantRequest.ProtoReflect.Descriptor instead.
+func (*ShareGrantRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ShareGrantRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *ShareGrantRequest) GetResourceType() string {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *ShareGrantRequest) GetResourceID() uint64 {
+	if x != nil {
+		return x.ResourceID
+	}
+	return 0
+}
+
+func (x *ShareGrantRequest) GetGranteeType() string {
+	if x != nil {
+		return x.GranteeType
+	}
+	return ""
+}
+
+func (x *ShareGrantRequest) GetGranteeID() uint64 {
+	if x != nil {
+		return x.GranteeID
+	}
+	return 0
+}
+
+func (x *ShareGrantRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type ShareGrantResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"code"
+	Code int64 `protobuf:"varint,1,opt,name=Code,proto3" json:"code"`
+	// @inject_tag: json:"msg"
+	Msg string `protobuf:"bytes,2,opt,name=Msg,proto3" json:"msg"`
+	// @inject_tag: json:"share_id"
+	ShareID       uint64 `protobuf:"varint,3,opt,name=ShareID,proto3" json:"share_id"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareGrantResponse) Reset() {
+	*x = ShareGrantResponse{}
+	mi := &file_files_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareGrantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareGrantResponse) ProtoMessage() {}
+
+func (x *ShareGrantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareGrantResponse.ProtoReflect.Descriptor instead.
+func (*ShareGrantResponse) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ShareGrantResponse) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ShareGrantResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *ShareGrantResponse) GetShareID() uint64 {
+	if x != nil {
+		return x.ShareID
+	}
+	return 0
+}
+
+type ShareRevokeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"share_id" form:"share_id"
+	ShareID       uint64 `protobuf:"varint,2,opt,name=ShareID,proto3" json:"share_id" form:"share_id"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareRevokeRequest) Reset() {
+	*x = ShareRevokeRequest{}
+	mi := &file_files_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareRevokeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareRevokeRequest) ProtoMessage() {}
+
+func (x *ShareRevokeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareRevokeRequest.ProtoReflect.Descriptor instead.
+func (*ShareRevokeRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ShareRevokeRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *ShareRevokeRequest) GetShareID() uint64 {
+	if x != nil {
+		return x.ShareID
+	}
+	return 0
+}
+
+type ShareListRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"resource_type" form:"resource_type"
+	ResourceType string `protobuf:"bytes,2,opt,name=ResourceType,proto3" json:"resource_type" form:"resource_type"` // ShareList 必填：查看某个资源的授权列表
+	// @inject_tag: json:"resource_id" form:"resource_id"
+	ResourceID uint64 `protobuf:"varint,3,opt,name=ResourceID,proto3" json:"resource_id" form:"resource_id"`
+	// @inject_tag: json:"page" form:"page"
+	Page int32 `protobuf:"varint,4,opt,name=Page,proto3" json:"page" form:"page"`
+	// @inject_tag: json:"page_size" form:"page_size"
+	PageSize      int32 `protobuf:"varint,5,opt,name=PageSize,proto3" json:"page_size" form:"page_size"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareListRequest) Reset() {
+	*x = ShareListRequest{}
+	mi := &file_files_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareListRequest) ProtoMessage() {}
+
+func (x *ShareListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareListRequest.ProtoReflect.Descriptor instead.
+func (*ShareListRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ShareListRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *ShareListRequest) GetResourceType() string {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *ShareListRequest) GetResourceID() uint64 {
+	if x != nil {
+		return x.ResourceID
+	}
+	return 0
+}
+
+func (x *ShareListRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ShareListRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ShareListResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"code"
+	Code int64 `protobuf:"varint,1,opt,name=Code,proto3" json:"code"`
+	// @inject_tag: json:"msg"
+	Msg string `protobuf:"bytes,2,opt,name=Msg,proto3" json:"msg"`
+	// @inject_tag: json:"shares"
+	Shares []*ShareModel `protobuf:"bytes,3,rep,name=Shares,proto3" json:"shares"`
+	// @inject_tag: json:"total"
+	Total         int64 `protobuf:"varint,4,opt,name=Total,proto3" json:"total"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareListResponse) Reset() {
+	*x = ShareListResponse{}
+	mi := &file_files_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareListResponse) ProtoMessage() {}
+
+func (x *ShareListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareListResponse.ProtoReflect.Descriptor instead.
+func (*ShareListResponse) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ShareListResponse) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ShareListResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *ShareListResponse) GetShares() []*ShareModel {
+	if x != nil {
+		return x.Shares
+	}
+	return nil
+}
+
+func (x *ShareListResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// 用户组
+type GroupCreateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"name" form:"name"
+	Name          string `protobuf:"bytes,2,opt,name=Name,proto3" json:"name" form:"name"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupCreateRequest) Reset() {
+	*x = GroupCreateRequest{}
+	mi := &file_files_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupCreateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupCreateRequest) ProtoMessage() {}
+
+func (x *GroupCreateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupCreateRequest.ProtoReflect.Descriptor instead.
+func (*GroupCreateRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GroupCreateRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *GroupCreateRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type GroupCreateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"code"
+	Code int64 `protobuf:"varint,1,opt,name=Code,proto3" json:"code"`
+	// @inject_tag: json:"msg"
+	Msg string `protobuf:"bytes,2,opt,name=Msg,proto3" json:"msg"`
+	// @inject_tag: json:"group_id"
+	GroupID       uint64 `protobuf:"varint,3,opt,name=GroupID,proto3" json:"group_id"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupCreateResponse) Reset() {
+	*x = GroupCreateResponse{}
+	mi := &file_files_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupCreateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupCreateResponse) ProtoMessage() {}
+
+func (x *GroupCreateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupCreateResponse.ProtoReflect.Descriptor instead.
+func (*GroupCreateResponse) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GroupCreateResponse) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *GroupCreateResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *GroupCreateResponse) GetGroupID() uint64 {
+	if x != nil {
+		return x.GroupID
+	}
+	return 0
+}
+
+type GroupMemberRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"group_id" form:"group_id"
+	GroupID uint64 `protobuf:"varint,2,opt,name=GroupID,proto3" json:"group_id" form:"group_id"`
+	// @inject_tag: json:"member_id" form:"member_id"
+	MemberID      uint64 `protobuf:"varint,3,opt,name=MemberID,proto3" json:"member_id" form:"member_id"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupMemberRequest) Reset() {
+	*x = GroupMemberRequest{}
+	mi := &file_files_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupMemberRequest) ProtoMessage() {}
+
+func (x *GroupMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupMemberRequest.ProtoReflect.Descriptor instead.
+func (*GroupMemberRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *GroupMemberRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *GroupMemberRequest) GetGroupID() uint64 {
+	if x != nil {
+		return x.GroupID
+	}
+	return 0
+}
+
+func (x *GroupMemberRequest) GetMemberID() uint64 {
+	if x != nil {
+		return x.MemberID
+	}
+	return 0
+}
+
+// 变更日志（同步客户端使用）
+type ChangeModel struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"seq"
+	Seq uint64 `protobuf:"varint,1,opt,name=Seq,proto3" json:"seq"` // 用户内严格递增的序号
+	// @inject_tag: json:"action"
+	Action string `protobuf:"bytes,2,opt,name=Action,proto3" json:"action"` // create / update / rename / move / delete
+	// @inject_tag: json:"resource_type"
+	ResourceType string `protobuf:"bytes,3,opt,name=ResourceType,proto3" json:"resource_type"` // file / folder
+	// @inject_tag: json:"resource_id"
+	ResourceID uint64 `protobuf:"varint,4,opt,name=ResourceID,proto3" json:"resource_id"`
+	// @inject_tag: json:"parent_id"
+	ParentID uint64 `protobuf:"varint,5,opt,name=ParentID,proto3" json:"parent_id"` // 变更后所在的文件夹
+	// @inject_tag: json:"name"
+	Name string `protobuf:"bytes,6,opt,name=Name,proto3" json:"name"`
+	// @inject_tag: json:"size"
+	Size int64 `protobuf:"varint,7,opt,name=Size,proto3" json:"size"`
+	// @inject_tag: json:"created_at"
+	CreatedAt     int64 `protobuf:"varint,8,opt,name=CreatedAt,proto3" json:"created_at"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeModel) Reset() {
+	*x = ChangeModel{}
+	mi := &file_files_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeModel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeModel) ProtoMessage() {}
+
+func (x *ChangeModel) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeModel.ProtoReflect.Descriptor instead.
+func (*ChangeModel) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ChangeModel) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *ChangeModel) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ChangeModel) GetResourceType() string {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *ChangeModel) GetResourceID() uint64 {
+	if x != nil {
+		return x.ResourceID
+	}
+	return 0
+}
+
+func (x *ChangeModel) GetParentID() uint64 {
+	if x != nil {
+		return x.ParentID
+	}
+	return 0
+}
+
+func (x *ChangeModel) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ChangeModel) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ChangeModel) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type ChangeListRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"cursor" form:"cursor"
+	Cursor uint64 `protobuf:"varint,2,opt,name=Cursor,proto3" json:"cursor" form:"cursor"` // 上次同步到的序号，0 表示从头开始
+	// @inject_tag: json:"limit" form:"limit"
+	Limit         int32 `protobuf:"varint,3,opt,name=Limit,proto3" json:"limit" form:"limit"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeListRequest) Reset() {
+	*x = ChangeListRequest{}
+	mi := &file_files_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeListRequest) ProtoMessage() {}
+
+func (x *ChangeListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeListRequest.ProtoReflect.Descriptor instead.
+func (*ChangeListRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ChangeListRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *ChangeListRequest) GetCursor() uint64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+func (x *ChangeListRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ChangeListResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"code"
+	Code int64 `protobuf:"varint,1,opt,name=Code,proto3" json:"code"`
+	// @inject_tag: json:"msg"
+	Msg string `protobuf:"bytes,2,opt,name=Msg,proto3" json:"msg"`
+	// @inject_tag: json:"changes"
+	Changes []*ChangeModel `protobuf:"bytes,3,rep,name=Changes,proto3" json:"changes"`
+	// @inject_tag: json:"cursor"
+	Cursor uint64 `protobuf:"varint,4,opt,name=Cursor,proto3" json:"cursor"` // 下次请求使用的游标
+	// @inject_tag: json:"has_more"
+	HasMore       bool `protobuf:"varint,5,opt,name=HasMore,proto3" json:"has_more"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeListResponse) Reset() {
+	*x = ChangeListResponse{}
+	mi := &file_files_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeListResponse) ProtoMessage() {}
+
+func (x *ChangeListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeListResponse.ProtoReflect.Descriptor instead.
+func (*ChangeListResponse) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ChangeListResponse) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ChangeListResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *ChangeListResponse) GetChanges() []*ChangeModel {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *ChangeListResponse) GetCursor() uint64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+func (x *ChangeListResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+// 增量同步：基础版本的块签名
+type BlockSignature struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"index"
+	Index int64 `protobuf:"varint,1,opt,name=Index,proto3" json:"index"`
+	// @inject_tag: json:"weak"
+	Weak uint32 `protobuf:"varint,2,opt,name=Weak,proto3" json:"weak"` // 滚动校验和
+	// @inject_tag: json:"strong"
+	Strong []byte `protobuf:"bytes,3,opt,name=Strong,proto3" json:"strong"` // SHA-256 前 16 字节
+	// @inject_tag: json:"length"
+	Length        int32 `protobuf:"varint,4,opt,name=Length,proto3" json:"length"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlockSignature) Reset() {
+	*x = BlockSignature{}
+	mi := &file_files_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockSignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockSignature) ProtoMessage() {}
+
+func (x *BlockSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockSignature.ProtoReflect.Descriptor instead.
+func (*BlockSignature) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *BlockSignature) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BlockSignature) GetWeak() uint32 {
+	if x != nil {
+		return x.Weak
+	}
+	return 0
+}
+
+func (x *BlockSignature) GetStrong() []byte {
+	if x != nil {
+		return x.Strong
+	}
+	return nil
+}
+
+func (x *BlockSignature) GetLength() int32 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+type DeltaSignatureRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"file_id" form:"file_id"
+	FileID uint64 `protobuf:"varint,2,opt,name=FileID,proto3" json:"file_id" form:"file_id"`
+	// @inject_tag: json:"block_size" form:"block_size"
+	BlockSize     int32 `protobuf:"varint,3,opt,name=BlockSize,proto3" json:"block_size" form:"block_size"` // 0 使用默认块大小
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeltaSignatureRequest) Reset() {
+	*x = DeltaSignatureRequest{}
+	mi := &file_files_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeltaSignatureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeltaSignatureRequest) ProtoMessage() {}
+
+func (x *DeltaSignatureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeltaSignatureRequest.ProtoReflect.Descriptor instead.
+func (*DeltaSignatureRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *DeltaSignatureRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *DeltaSignatureRequest) GetFileID() uint64 {
+	if x != nil {
+		return x.FileID
+	}
+	return 0
+}
+
+func (x *DeltaSignatureRequest) GetBlockSize() int32 {
+	if x != nil {
+		return x.BlockSize
+	}
+	return 0
+}
+
+// 签名分批返回，FileHash 只在最后一批中给出
+type DeltaSignatureResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"code"
+	Code int64 `protobuf:"varint,1,opt,name=Code,proto3" json:"code"`
+	// @inject_tag: json:"msg"
+	Msg string `protobuf:"bytes,2,opt,name=Msg,proto3" json:"msg"`
+	// @inject_tag: json:"file_id"
+	FileID uint64 `protobuf:"varint,3,opt,name=FileID,proto3" json:"file_id"`
+	// @inject_tag: json:"version"
+	Version uint64 `protobuf:"varint,4,opt,name=Version,proto3" json:"version"`
+	// @inject_tag: json:"file_size"
+	FileSize int64 `protobuf:"varint,5,opt,name=FileSize,proto3" json:"file_size"`
+	// @inject_tag: json:"file_hash"
+	FileHash string `protobuf:"bytes,6,opt,name=FileHash,proto3" json:"file_hash"`
+	// @inject_tag: json:"block_size"
+	BlockSize int32 `protobuf:"varint,7,opt,name=BlockSize,proto3" json:"block_size"`
+	// @inject_tag: json:"blocks"
+	Blocks        []*BlockSignature `protobuf:"bytes,8,rep,name=Blocks,proto3" json:"blocks"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeltaSignatureResponse) Reset() {
+	*x = DeltaSignatureResponse{}
+	mi := &file_files_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeltaSignatureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeltaSignatureResponse) ProtoMessage() {}
+
+func (x *DeltaSignatureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeltaSignatureResponse.ProtoReflect.Descriptor instead.
+func (*DeltaSignatureResponse) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *DeltaSignatureResponse) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *DeltaSignatureResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *DeltaSignatureResponse) GetFileID() uint64 {
+	if x != nil {
+		return x.FileID
+	}
+	return 0
+}
+
+func (x *DeltaSignatureResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *DeltaSignatureResponse) GetFileSize() int64 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *DeltaSignatureResponse) GetFileHash() string {
+	if x != nil {
+		return x.FileHash
+	}
+	return ""
+}
+
+func (x *DeltaSignatureResponse) GetBlockSize() int32 {
+	if x != nil {
+		return x.BlockSize
+	}
+	return 0
+}
+
+func (x *DeltaSignatureResponse) GetBlocks() []*BlockSignature {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+// 增量操作：BlockCount > 0 时复制基础版本从 BlockIndex 开始的连续块，否则写入字面数据 Data
+type DeltaOp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"block_index"
+	BlockIndex int64 `protobuf:"varint,1,opt,name=BlockIndex,proto3" json:"block_index"`
+	// @inject_tag: json:"block_count"
+	BlockCount int64 `protobuf:"varint,2,opt,name=BlockCount,proto3" json:"block_count"`
+	// @inject_tag: json:"data"
+	Data          []byte `protobuf:"bytes,3,opt,name=Data,proto3" json:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeltaOp) Reset() {
+	*x = DeltaOp{}
+	mi := &file_files_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeltaOp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeltaOp) ProtoMessage() {}
+
+func (x *DeltaOp) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeltaOp.ProtoReflect.Descriptor instead.
+func (*DeltaOp) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *DeltaOp) GetBlockIndex() int64 {
+	if x != nil {
+		return x.BlockIndex
+	}
+	return 0
+}
+
+func (x *DeltaOp) GetBlockCount() int64 {
+	if x != nil {
+		return x.BlockCount
+	}
+	return 0
+}
+
+func (x *DeltaOp) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// 增量上传，第一条消息需要携带文件信息
+type DeltaUploadRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"file_id" form:"file_id"
+	FileID uint64 `protobuf:"varint,2,opt,name=FileID,proto3" json:"file_id" form:"file_id"`
+	// @inject_tag: json:"base_version" form:"base_version"
+	BaseVersion uint64 `protobuf:"varint,3,opt,name=BaseVersion,proto3" json:"base_version" form:"base_version"` // 计算增量时使用的版本，与服务端不一致时拒绝
+	// @inject_tag: json:"block_size" form:"block_size"
+	BlockSize int32 `protobuf:"varint,4,opt,name=BlockSize,proto3" json:"block_size" form:"block_size"`
+	// @inject_tag: json:"expected_hash" form:"expected_hash"
+	ExpectedHash string `protobuf:"bytes,5,opt,name=ExpectedHash,proto3" json:"expected_hash" form:"expected_hash"` // 可选，新内容的 SHA-256
+	// @inject_tag: json:"ops" form:"ops"
+	Ops           []*DeltaOp `protobuf:"bytes,6,rep,name=Ops,proto3" json:"ops" form:"ops"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeltaUploadRequest) Reset() {
+	*x = DeltaUploadRequest{}
+	mi := &file_files_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeltaUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeltaUploadRequest) ProtoMessage() {}
+
+func (x *DeltaUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeltaUploadRequest.ProtoReflect.Descriptor instead.
+func (*DeltaUploadRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *DeltaUploadRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *DeltaUploadRequest) GetFileID() uint64 {
+	if x != nil {
+		return x.FileID
+	}
+	return 0
+}
+
+func (x *DeltaUploadRequest) GetBaseVersion() uint64 {
+	if x != nil {
+		return x.BaseVersion
+	}
+	return 0
+}
+
+func (x *DeltaUploadRequest) GetBlockSize() int32 {
+	if x != nil {
+		return x.BlockSize
+	}
+	return 0
+}
+
+func (x *DeltaUploadRequest) GetExpectedHash() string {
+	if x != nil {
+		return x.ExpectedHash
+	}
+	return ""
+}
+
+func (x *DeltaUploadRequest) GetOps() []*DeltaOp {
+	if x != nil {
+		return x.Ops
+	}
+	return nil
+}
+
+// 存储统计（仅管理员）
+type StorageStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID        uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorageStatsRequest) Reset() {
+	*x = StorageStatsRequest{}
+	mi := &file_files_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorageStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageStatsRequest) ProtoMessage() {}
+
+func (x *StorageStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageStatsRequest.ProtoReflect.Descriptor instead.
+func (*StorageStatsRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *StorageStatsRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+type StorageStatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"code"
+	Code int64 `protobuf:"varint,1,opt,name=Code,proto3" json:"code"`
+	// @inject_tag: json:"msg"
+	Msg string `protobuf:"bytes,2,opt,name=Msg,proto3" json:"msg"`
+	// @inject_tag: json:"logical_bytes"
+	LogicalBytes int64 `protobuf:"varint,3,opt,name=LogicalBytes,proto3" json:"logical_bytes"` // 所有文件和历史版本的大小之和
+	// @inject_tag: json:"physical_bytes"
+	PhysicalBytes int64 `protobuf:"varint,4,opt,name=PhysicalBytes,proto3" json:"physical_bytes"` // 实际占用的磁盘空间
+	// @inject_tag: json:"chunk_bytes"
+	ChunkBytes int64 `protobuf:"varint,5,opt,name=ChunkBytes,proto3" json:"chunk_bytes"`
+	// @inject_tag: json:"chunk_count"
+	ChunkCount int64 `protobuf:"varint,6,opt,name=ChunkCount,proto3" json:"chunk_count"`
+	// @inject_tag: json:"chunked_files"
+	ChunkedFiles int64 `protobuf:"varint,7,opt,name=ChunkedFiles,proto3" json:"chunked_files"`
+	// @inject_tag: json:"raw_bytes"
+	RawBytes int64 `protobuf:"varint,8,opt,name=RawBytes,proto3" json:"raw_bytes"`
+	// @inject_tag: json:"dedup_ratio"
+	DedupRatio    float64 `protobuf:"fixed64,9,opt,name=DedupRatio,proto3" json:"dedup_ratio"` // LogicalBytes / PhysicalBytes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorageStatsResponse) Reset() {
+	*x = StorageStatsResponse{}
+	mi := &file_files_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorageStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageStatsResponse) ProtoMessage() {}
+
+func (x *StorageStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageStatsResponse.ProtoReflect.Descriptor instead.
+func (*StorageStatsResponse) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *StorageStatsResponse) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *StorageStatsResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *StorageStatsResponse) GetLogicalBytes() int64 {
+	if x != nil {
+		return x.LogicalBytes
+	}
+	return 0
+}
+
+func (x *StorageStatsResponse) GetPhysicalBytes() int64 {
+	if x != nil {
+		return x.PhysicalBytes
+	}
+	return 0
+}
+
+func (x *StorageStatsResponse) GetChunkBytes() int64 {
+	if x != nil {
+		return x.ChunkBytes
+	}
+	return 0
+}
+
+func (x *StorageStatsResponse) GetChunkCount() int64 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+func (x *StorageStatsResponse) GetChunkedFiles() int64 {
+	if x != nil {
+		return x.ChunkedFiles
+	}
+	return 0
+}
+
+func (x *StorageStatsResponse) GetRawBytes() int64 {
+	if x != nil {
+		return x.RawBytes
+	}
+	return 0
+}
+
+func (x *StorageStatsResponse) GetDedupRatio() float64 {
+	if x != nil {
+		return x.DedupRatio
+	}
+	return 0
+}
+
+// 签名下载链接
+type PresignDownloadRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"file_id" form:"file_id"
+	FileID uint64 `protobuf:"varint,2,opt,name=FileID,proto3" json:"file_id" form:"file_id"`
+	// @inject_tag: json:"expires" form:"expires"
+	Expires int64 `protobuf:"varint,3,opt,name=Expires,proto3" json:"expires" form:"expires"` // 有效期（秒），0 使用默认值，超过上限时按上限
+	// @inject_tag: json:"bind_ip" form:"bind_ip"
+	BindIP string `protobuf:"bytes,4,opt,name=BindIP,proto3" json:"bind_ip" form:"bind_ip"` // 非空时只有该 IP 可以使用链接
+	// @inject_tag: json:"attachment" form:"attachment"
+	Attachment    bool `protobuf:"varint,5,opt,name=Attachment,proto3" json:"attachment" form:"attachment"` // true 时浏览器下载，否则内联展示（可用于 <img> 等）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PresignDownloadRequest) Reset() {
+	*x = PresignDownloadRequest{}
+	mi := &file_files_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresignDownloadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresignDownloadRequest) ProtoMessage() {}
+
+func (x *PresignDownloadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresignDownloadRequest.ProtoReflect.Descriptor instead.
+func (*PresignDownloadRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *PresignDownloadRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *PresignDownloadRequest) GetFileID() uint64 {
+	if x != nil {
+		return x.FileID
+	}
+	return 0
+}
+
+func (x *PresignDownloadRequest) GetExpires() int64 {
+	if x != nil {
+		return x.Expires
+	}
+	return 0
+}
+
+func (x *PresignDownloadRequest) GetBindIP() string {
+	if x != nil {
+		return x.BindIP
+	}
+	return ""
+}
+
+func (x *PresignDownloadRequest) GetAttachment() bool {
+	if x != nil {
+		return x.Attachment
+	}
+	return false
+}
+
+type PresignDownloadResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"code"
+	Code int64 `protobuf:"varint,1,opt,name=Code,proto3" json:"code"`
+	// @inject_tag: json:"msg"
+	Msg string `protobuf:"bytes,2,opt,name=Msg,proto3" json:"msg"`
+	// @inject_tag: json:"url"
+	Url string `protobuf:"bytes,3,opt,name=Url,proto3" json:"url"`
+	// @inject_tag: json:"expires_at"
+	ExpiresAt int64 `protobuf:"varint,4,opt,name=ExpiresAt,proto3" json:"expires_at"` // 过期时间（Unix 秒）
+	// @inject_tag: json:"file_name"
+	Filename      string `protobuf:"bytes,5,opt,name=Filename,proto3" json:"file_name"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PresignDownloadResponse) Reset() {
+	*x = PresignDownloadResponse{}
+	mi := &file_files_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresignDownloadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresignDownloadResponse) ProtoMessage() {}
+
+func (x *PresignDownloadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresignDownloadResponse.ProtoReflect.Descriptor instead.
+func (*PresignDownloadResponse) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *PresignDownloadResponse) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PresignDownloadResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *PresignDownloadResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *PresignDownloadResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *PresignDownloadResponse) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+// 七牛云客户端直传凭证
+type QiniuUploadTokenRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"file_name" form:"file_name"
+	Filename string `protobuf:"bytes,2,opt,name=Filename,proto3" json:"file_name" form:"file_name"`
+	// @inject_tag: json:"file_size" form:"file_size"
+	FileSize int64 `protobuf:"varint,3,opt,name=FileSize,proto3" json:"file_size" form:"file_size"` // 预计大小，用于策略检查，实际大小在回调时再次检查
+	// @inject_tag: json:"folder_id" form:"folder_id"
+	FolderID      uint64 `protobuf:"varint,4,opt,name=FolderID,proto3" json:"folder_id" form:"folder_id"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QiniuUploadTokenRequest) Reset() {
+	*x = QiniuUploadTokenRequest{}
+	mi := &file_files_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QiniuUploadTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QiniuUploadTokenRequest) ProtoMessage() {}
+
+func (x *QiniuUploadTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QiniuUploadTokenRequest.ProtoReflect.Descriptor instead.
+func (*QiniuUploadTokenRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *QiniuUploadTokenRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *QiniuUploadTokenRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *QiniuUploadTokenRequest) GetFileSize() int64 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *QiniuUploadTokenRequest) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
+type QiniuUploadTokenResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"code"
+	Code int64 `protobuf:"varint,1,opt,name=Code,proto3" json:"code"`
+	// @inject_tag: json:"msg"
+	Msg string `protobuf:"bytes,2,opt,name=Msg,proto3" json:"msg"`
+	// @inject_tag: json:"token"
+	Token string `protobuf:"bytes,3,opt,name=Token,proto3" json:"token"`
+	// @inject_tag: json:"key"
+	Key string `protobuf:"bytes,4,opt,name=Key,proto3" json:"key"`
+	// @inject_tag: json:"upload_url"
+	UploadUrl string `protobuf:"bytes,5,opt,name=UploadUrl,proto3" json:"upload_url"` // 表单上传地址
+	// @inject_tag: json:"expires_at"
+	ExpiresAt int64 `protobuf:"varint,6,opt,name=ExpiresAt,proto3" json:"expires_at"` // 凭证过期时间（Unix 秒）
+	// @inject_tag: json:"rejection,omitempty"
+	Rejection     *PolicyRejection `protobuf:"bytes,7,opt,name=Rejection,proto3" json:"rejection,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QiniuUploadTokenResponse) Reset() {
+	*x = QiniuUploadTokenResponse{}
+	mi := &file_files_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QiniuUploadTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QiniuUploadTokenResponse) ProtoMessage() {}
+
+func (x *QiniuUploadTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QiniuUploadTokenResponse.ProtoReflect.Descriptor instead.
+func (*QiniuUploadTokenResponse) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *QiniuUploadTokenResponse) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *QiniuUploadTokenResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *QiniuUploadTokenResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *QiniuUploadTokenResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *QiniuUploadTokenResponse) GetUploadUrl() string {
+	if x != nil {
+		return x.UploadUrl
+	}
+	return ""
+}
+
+func (x *QiniuUploadTokenResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *QiniuUploadTokenResponse) GetRejection() *PolicyRejection {
+	if x != nil {
+		return x.Rejection
+	}
+	return nil
+}
+
+// 七牛云直传完成后的回调内容，UserID、FolderID、Filename 在签发凭证时写入
+type QiniuUploadCallbackRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"key" form:"key"
+	Key string `protobuf:"bytes,1,opt,name=Key,proto3" json:"key" form:"key"`
+	// @inject_tag: json:"hash" form:"hash"
+	Hash string `protobuf:"bytes,2,opt,name=Hash,proto3" json:"hash" form:"hash"` // 七牛云 etag
+	// @inject_tag: json:"fsize" form:"fsize"
+	FileSize int64 `protobuf:"varint,3,opt,name=FileSize,proto3" json:"fsize" form:"fsize"`
+	// @inject_tag: json:"uid" form:"uid"
+	UserID uint64 `protobuf:"varint,4,opt,name=UserID,proto3" json:"uid" form:"uid"`
+	// @inject_tag: json:"folder" form:"folder"
+	FolderID uint64 `protobuf:"varint,5,opt,name=FolderID,proto3" json:"folder" form:"folder"`
+	// @inject_tag: json:"fname" form:"fname"
+	Filename      string `protobuf:"bytes,6,opt,name=Filename,proto3" json:"fname" form:"fname"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QiniuUploadCallbackRequest) Reset() {
+	*x = QiniuUploadCallbackRequest{}
+	mi := &file_files_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QiniuUploadCallbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QiniuUploadCallbackRequest) ProtoMessage() {}
+
+func (x *QiniuUploadCallbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QiniuUploadCallbackRequest.ProtoReflect.Descriptor instead.
+func (*QiniuUploadCallbackRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *QiniuUploadCallbackRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *QiniuUploadCallbackRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *QiniuUploadCallbackRequest) GetFileSize() int64 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *QiniuUploadCallbackRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *QiniuUploadCallbackRequest) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
+func (x *QiniuUploadCallbackRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+// 预签名直传：签发上传地址，客户端上传后调用 ConfirmUpload 入库
+type PresignUploadRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"file_name" form:"file_name"
+	Filename string `protobuf:"bytes,2,opt,name=Filename,proto3" json:"file_name" form:"file_name"`
+	// @inject_tag: json:"file_size" form:"file_size"
+	FileSize int64 `protobuf:"varint,3,opt,name=FileSize,proto3" json:"file_size" form:"file_size"` // 文件大小，确认时必须一致
+	// @inject_tag: json:"file_hash" form:"file_hash"
+	FileHash string `protobuf:"bytes,4,opt,name=FileHash,proto3" json:"file_hash" form:"file_hash"` // 本地存储为 SHA256（十六进制），七牛云为 etag，确认时必须一致
+	// @inject_tag: json:"folder_id" form:"folder_id"
+	FolderID uint64 `protobuf:"varint,5,opt,name=FolderID,proto3" json:"folder_id" form:"folder_id"`
+	// @inject_tag: json:"bucket" form:"bucket"
+	Bucket        string `protobuf:"bytes,6,opt,name=Bucket,proto3" json:"bucket" form:"bucket"` // local（默认）或 qiniu
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PresignUploadRequest) Reset() {
+	*x = PresignUploadRequest{}
+	mi := &file_files_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresignUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresignUploadRequest) ProtoMessage() {}
+
+func (x *PresignUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresignUploadRequest.ProtoReflect.Descriptor instead.
+func (*PresignUploadRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *PresignUploadRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *PresignUploadRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *PresignUploadRequest) GetFileSize() int64 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *PresignUploadRequest) GetFileHash() string {
+	if x != nil {
+		return x.FileHash
+	}
+	return ""
+}
+
+func (x *PresignUploadRequest) GetFolderID() uint64 {
+	if x != nil {
+		return x.FolderID
+	}
+	return 0
+}
+
+func (x *PresignUploadRequest) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+type PresignUploadResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"code"
+	Code int64 `protobuf:"varint,1,opt,name=Code,proto3" json:"code"`
+	// @inject_tag: json:"msg"
+	Msg string `protobuf:"bytes,2,opt,name=Msg,proto3" json:"msg"`
+	// @inject_tag: json:"upload_id"
+	UploadID string `protobuf:"bytes,3,opt,name=UploadID,proto3" json:"upload_id"` // 确认上传时使用
+	// @inject_tag: json:"method"
+	Method string `protobuf:"bytes,4,opt,name=Method,proto3" json:"method"` // local 为 PUT 请求体，qiniu 为 POST 表单
+	// @inject_tag: json:"url"
+	Url string `protobuf:"bytes,5,opt,name=Url,proto3" json:"url"`
+	// @inject_tag: json:"token,omitempty"
+	Token string `protobuf:"bytes,6,opt,name=Token,proto3" json:"token,omitempty"` // 七牛云上传凭证，作为表单的 token 字段
+	// @inject_tag: json:"key,omitempty"
+	Key string `protobuf:"bytes,7,opt,name=Key,proto3" json:"key,omitempty"` // 七牛云对象 key，作为表单的 key 字段
+	// @inject_tag: json:"expires_at"
+	ExpiresAt int64 `protobuf:"varint,8,opt,name=ExpiresAt,proto3" json:"expires_at"`
+	// @inject_tag: json:"rejection,omitempty"
+	Rejection     *PolicyRejection `protobuf:"bytes,9,opt,name=Rejection,proto3" json:"rejection,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PresignUploadResponse) Reset() {
+	*x = PresignUploadResponse{}
+	mi := &file_files_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresignUploadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresignUploadResponse) ProtoMessage() {}
+
+func (x *PresignUploadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresignUploadResponse.ProtoReflect.Descriptor instead.
+func (*PresignUploadResponse) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *PresignUploadResponse) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PresignUploadResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *PresignUploadResponse) GetUploadID() string {
+	if x != nil {
+		return x.UploadID
+	}
+	return ""
+}
+
+func (x *PresignUploadResponse) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *PresignUploadResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *PresignUploadResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *PresignUploadResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *PresignUploadResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *PresignUploadResponse) GetRejection() *PolicyRejection {
+	if x != nil {
+		return x.Rejection
+	}
+	return nil
+}
+
+type ConfirmUploadRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"upload_id" form:"upload_id"
+	UploadID      string `protobuf:"bytes,2,opt,name=UploadID,proto3" json:"upload_id" form:"upload_id"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmUploadRequest) Reset() {
+	*x = ConfirmUploadRequest{}
+	mi := &file_files_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmUploadRequest) ProtoMessage() {}
+
+func (x *ConfirmUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_files_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmUploadRequest.ProtoReflect.Descriptor instead.
+func (*ConfirmUploadRequest) Descriptor() ([]byte, []int) {
+	return file_files_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *ConfirmUploadRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *ConfirmUploadRequest) GetUploadID() string {
+	if x != nil {
+		return x.UploadID
+	}
+	return ""
+}
+
+var File_files_proto protoreflect.FileDescriptor
+
+const file_files_proto_rawDesc = "" +
+	"\n" +
+	"\vfiles.proto\"\x85\x02\n" +
+	"\tFileModel\x12\x16\n" +
+	"\x06FileID\x18\x01 \x01(\x04R\x06FileID\x12\x16\n" +
+	"\x06UserID\x18\x02 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bFileName\x18\x03 \x01(\tR\bFileName\x12\x1a\n" +
+	"\bFileSize\x18\x04 \x01(\x03R\bFileSize\x12\x16\n" +
+	"\x06Bucket\x18\x05 \x01(\tR\x06Bucket\x12\x1e\n" +
+	"\n" +
+	"ObjectName\x18\x06 \x01(\tR\n" +
+	"ObjectName\x12\x1a\n" +
+	"\bFolderID\x18\a \x01(\x04R\bFolderID\x12\x1c\n" +
+	"\tUpdatedAt\x18\b \x01(\x03R\tUpdatedAt\x12\x1e\n" +
+	"\n" +
+	"ScanStatus\x18\t \x01(\tR\n" +
+	"ScanStatus\"\xbb\x01\n" +
+	"\x11FileUploadRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bFilename\x18\x02 \x01(\tR\bFilename\x12\x1a\n" +
+	"\bFileSize\x18\x03 \x01(\x03R\bFileSize\x12\x1e\n" +
+	"\n" +
+	"ObjectName\x18\x04 \x01(\tR\n" +
+	"ObjectName\x12\x1a\n" +
+	"\bFileHash\x18\x05 \x01(\tR\bFileHash\x12\x1a\n" +
+	"\bFolderID\x18\x06 \x01(\x04R\bFolderID\"\xa0\x01\n" +
+	"\x12FileUploadResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12\x1c\n" +
+	"\tObjectUrl\x18\x03 \x01(\tR\tObjectUrl\x12\x16\n" +
+	"\x06FileID\x18\x04 \x01(\x04R\x06FileID\x12.\n" +
+	"\tRejection\x18\x05 \x01(\v2\x10.PolicyRejectionR\tRejection\"k\n" +
+	"\x0fPolicyRejection\x12\x12\n" +
+	"\x04Rule\x18\x01 \x01(\tR\x04Rule\x12\x16\n" +
+	"\x06Reason\x18\x02 \x01(\tR\x06Reason\x12\x14\n" +
+	"\x05Limit\x18\x03 \x01(\x03R\x05Limit\x12\x16\n" +
+	"\x06Actual\x18\x04 \x01(\x03R\x06Actual\"\xf0\x01\n" +
+	"\x14BigFileUploadRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bFilename\x18\x02 \x01(\tR\bFilename\x12\x1a\n" +
+	"\bFileSize\x18\x03 \x01(\x03R\bFileSize\x12\x1e\n" +
+	"\n" +
+	"ObjectName\x18\x04 \x01(\tR\n" +
+	"ObjectName\x12\x18\n" +
+	"\aContent\x18\x05 \x01(\fR\aContent\x12\x16\n" +
+	"\x06IsLast\x18\x06 \x01(\bR\x06IsLast\x12\x1a\n" +
+	"\bFileHash\x18\a \x01(\tR\bFileHash\x12\x1a\n" +
+	"\bFolderID\x18\b \x01(\x04R\bFolderID\"\xa3\x01\n" +
+	"\x15BigFileUploadResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12\x1c\n" +
+	"\tObjectUrl\x18\x03 \x01(\tR\tObjectUrl\x12\x16\n" +
+	"\x06FileID\x18\x04 \x01(\x04R\x06FileID\x12.\n" +
+	"\tRejection\x18\x05 \x01(\v2\x10.PolicyRejectionR\tRejection\"C\n" +
+	"\x11FileDeleteRequest\x12\x16\n" +
+	"\x06FileID\x18\x01 \x01(\x04R\x06FileID\x12\x16\n" +
+	"\x06UserID\x18\x02 \x01(\x04R\x06UserID\"u\n" +
+	"\x0fFileListRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x12\n" +
+	"\x04Page\x18\x02 \x01(\x05R\x04Page\x12\x1a\n" +
+	"\bPageSize\x18\x03 \x01(\x05R\bPageSize\x12\x1a\n" +
+	"\bFolderID\x18\x04 \x01(\x04R\bFolderID\"p\n" +
+	"\x10FileListResponse\x12\x12\n" +
 	"\x04Code\x18\x01 \x01(\x05R\x04Code\x12\x10\n" +
 	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12 \n" +
 	"\x05Files\x18\x03 \x03(\v2\n" +
@@ -1274,22 +4260,29 @@ const file_files_proto_rawDesc = "" +
 	"\x13FileDownloadRequest\x12\x16\n" +
 	"\x06FileID\x18\x01 \x01(\x04R\x06FileID\x12\x1a\n" +
 	"\bFilename\x18\x02 \x01(\tR\bFilename\x12\x16\n" +
-	"\x06UserID\x18\x03 \x01(\x04R\x06UserID\"z\n" +
+	"\x06UserID\x18\x03 \x01(\x04R\x06UserID\"\xc0\x01\n" +
 	"\x14FileDownloadResponse\x12\x12\n" +
 	"\x04Code\x18\x01 \x01(\x05R\x04Code\x12\x10\n" +
 	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12 \n" +
 	"\vDownloadUrl\x18\x03 \x01(\tR\vDownloadUrl\x12\x1a\n" +
-	"\bFilename\x18\x04 \x01(\tR\bFilename\":\n" +
+	"\bFilename\x18\x04 \x01(\tR\bFilename\x12\x16\n" +
+	"\x06Layout\x18\x05 \x01(\tR\x06Layout\x12\x14\n" +
+	"\x05Codec\x18\x06 \x01(\tR\x05Codec\x12\x16\n" +
+	"\x06Bucket\x18\a \x01(\tR\x06Bucket\":\n" +
 	"\x12FileCommonResponse\x12\x12\n" +
 	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
-	"\x03Msg\x18\x02 \x01(\tR\x03Msg\"F\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\"~\n" +
 	"\x10CheckFileRequest\x12\x1a\n" +
 	"\bFileHash\x18\x01 \x01(\tR\bFileHash\x12\x16\n" +
-	"\x06UserID\x18\x02 \x01(\x04R\x06UserID\"a\n" +
+	"\x06UserID\x18\x02 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bFolderID\x18\x03 \x01(\x04R\bFolderID\x12\x1a\n" +
+	"\bFilename\x18\x04 \x01(\tR\bFilename\"\x87\x01\n" +
 	"\x11CheckFileResponse\x12\x16\n" +
 	"\x06FileID\x18\x01 \x01(\x04R\x06FileID\x12\x1c\n" +
 	"\tObjectUrl\x18\x02 \x01(\tR\tObjectUrl\x12\x16\n" +
-	"\x06exists\x18\x03 \x01(\bR\x06exists\"}\n" +
+	"\x06exists\x18\x03 \x01(\bR\x06exists\x12\x12\n" +
+	"\x04Code\x18\x04 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x05 \x01(\tR\x03Msg\"}\n" +
 	"\x17GlobalFileSearchRequest\x12\x1a\n" +
 	"\bFileName\x18\x01 \x01(\tR\bFileName\x12\x12\n" +
 	"\x04Page\x18\x02 \x01(\rR\x04Page\x12\x1a\n" +
@@ -1313,7 +4306,226 @@ const file_files_proto_rawDesc = "" +
 	"\bFileHash\x18\x06 \x01(\tR\bFileHash\x12\x16\n" +
 	"\x06UserID\x18\a \x01(\x04R\x06UserID\x12\x1c\n" +
 	"\tCreatedAt\x18\b \x01(\tR\tCreatedAt\x12\x1c\n" +
-	"\tUpdatedAt\x18\t \x01(\tR\tUpdatedAt2\xb0\x05\n" +
+	"\tUpdatedAt\x18\t \x01(\tR\tUpdatedAt\"\x8f\x01\n" +
+	"\vFolderModel\x12\x1a\n" +
+	"\bFolderID\x18\x01 \x01(\x04R\bFolderID\x12\x16\n" +
+	"\x06UserID\x18\x02 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bParentID\x18\x03 \x01(\x04R\bParentID\x12\x12\n" +
+	"\x04Name\x18\x04 \x01(\tR\x04Name\x12\x1c\n" +
+	"\tUpdatedAt\x18\x05 \x01(\x03R\tUpdatedAt\"]\n" +
+	"\x13FolderCreateRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bParentID\x18\x02 \x01(\x04R\bParentID\x12\x12\n" +
+	"\x04Name\x18\x03 \x01(\tR\x04Name\"X\n" +
+	"\x14FolderCreateResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12\x1a\n" +
+	"\bFolderID\x18\x03 \x01(\x04R\bFolderID\"]\n" +
+	"\x11FileRenameRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x16\n" +
+	"\x06FileID\x18\x02 \x01(\x04R\x06FileID\x12\x18\n" +
+	"\aNewName\x18\x03 \x01(\tR\aNewName\"G\n" +
+	"\x11FolderListRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bFolderID\x18\x02 \x01(\x04R\bFolderID\"\x84\x01\n" +
+	"\x12FolderListResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12&\n" +
+	"\aFolders\x18\x03 \x03(\v2\f.FolderModelR\aFolders\x12 \n" +
+	"\x05Files\x18\x04 \x03(\v2\n" +
+	".FileModelR\x05Files\"w\n" +
+	"\x0fFileMoveRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x16\n" +
+	"\x06FileID\x18\x02 \x01(\x04R\x06FileID\x12\x1a\n" +
+	"\bFolderID\x18\x03 \x01(\x04R\bFolderID\x12\x18\n" +
+	"\aNewName\x18\x04 \x01(\tR\aNewName\"}\n" +
+	"\x11FolderMoveRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bFolderID\x18\x02 \x01(\x04R\bFolderID\x12\x1a\n" +
+	"\bParentID\x18\x03 \x01(\x04R\bParentID\x12\x18\n" +
+	"\aNewName\x18\x04 \x01(\tR\aNewName\"I\n" +
+	"\x13FolderDeleteRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bFolderID\x18\x02 \x01(\x04R\bFolderID\"\x9a\x02\n" +
+	"\n" +
+	"ShareModel\x12\x18\n" +
+	"\aShareID\x18\x01 \x01(\x04R\aShareID\x12\x18\n" +
+	"\aOwnerID\x18\x02 \x01(\x04R\aOwnerID\x12\"\n" +
+	"\fResourceType\x18\x03 \x01(\tR\fResourceType\x12\x1e\n" +
+	"\n" +
+	"ResourceID\x18\x04 \x01(\x04R\n" +
+	"ResourceID\x12\"\n" +
+	"\fResourceName\x18\x05 \x01(\tR\fResourceName\x12 \n" +
+	"\vGranteeType\x18\x06 \x01(\tR\vGranteeType\x12\x1c\n" +
+	"\tGranteeID\x18\a \x01(\x04R\tGranteeID\x12\x12\n" +
+	"\x04Role\x18\b \x01(\tR\x04Role\x12\x1c\n" +
+	"\tCreatedAt\x18\t \x01(\tR\tCreatedAt\"\xc3\x01\n" +
+	"\x11ShareGrantRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\"\n" +
+	"\fResourceType\x18\x02 \x01(\tR\fResourceType\x12\x1e\n" +
+	"\n" +
+	"ResourceID\x18\x03 \x01(\x04R\n" +
+	"ResourceID\x12 \n" +
+	"\vGranteeType\x18\x04 \x01(\tR\vGranteeType\x12\x1c\n" +
+	"\tGranteeID\x18\x05 \x01(\x04R\tGranteeID\x12\x12\n" +
+	"\x04Role\x18\x06 \x01(\tR\x04Role\"T\n" +
+	"\x12ShareGrantResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12\x18\n" +
+	"\aShareID\x18\x03 \x01(\x04R\aShareID\"F\n" +
+	"\x12ShareRevokeRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x18\n" +
+	"\aShareID\x18\x02 \x01(\x04R\aShareID\"\x9e\x01\n" +
+	"\x10ShareListRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\"\n" +
+	"\fResourceType\x18\x02 \x01(\tR\fResourceType\x12\x1e\n" +
+	"\n" +
+	"ResourceID\x18\x03 \x01(\x04R\n" +
+	"ResourceID\x12\x12\n" +
+	"\x04Page\x18\x04 \x01(\x05R\x04Page\x12\x1a\n" +
+	"\bPageSize\x18\x05 \x01(\x05R\bPageSize\"t\n" +
+	"\x11ShareListResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12#\n" +
+	"\x06Shares\x18\x03 \x03(\v2\v.ShareModelR\x06Shares\x12\x14\n" +
+	"\x05Total\x18\x04 \x01(\x03R\x05Total\"@\n" +
+	"\x12GroupCreateRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x12\n" +
+	"\x04Name\x18\x02 \x01(\tR\x04Name\"U\n" +
+	"\x13GroupCreateResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12\x18\n" +
+	"\aGroupID\x18\x03 \x01(\x04R\aGroupID\"b\n" +
+	"\x12GroupMemberRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x18\n" +
+	"\aGroupID\x18\x02 \x01(\x04R\aGroupID\x12\x1a\n" +
+	"\bMemberID\x18\x03 \x01(\x04R\bMemberID\"\xdd\x01\n" +
+	"\vChangeModel\x12\x10\n" +
+	"\x03Seq\x18\x01 \x01(\x04R\x03Seq\x12\x16\n" +
+	"\x06Action\x18\x02 \x01(\tR\x06Action\x12\"\n" +
+	"\fResourceType\x18\x03 \x01(\tR\fResourceType\x12\x1e\n" +
+	"\n" +
+	"ResourceID\x18\x04 \x01(\x04R\n" +
+	"ResourceID\x12\x1a\n" +
+	"\bParentID\x18\x05 \x01(\x04R\bParentID\x12\x12\n" +
+	"\x04Name\x18\x06 \x01(\tR\x04Name\x12\x12\n" +
+	"\x04Size\x18\a \x01(\x03R\x04Size\x12\x1c\n" +
+	"\tCreatedAt\x18\b \x01(\x03R\tCreatedAt\"Y\n" +
+	"\x11ChangeListRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x16\n" +
+	"\x06Cursor\x18\x02 \x01(\x04R\x06Cursor\x12\x14\n" +
+	"\x05Limit\x18\x03 \x01(\x05R\x05Limit\"\x94\x01\n" +
+	"\x12ChangeListResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12&\n" +
+	"\aChanges\x18\x03 \x03(\v2\f.ChangeModelR\aChanges\x12\x16\n" +
+	"\x06Cursor\x18\x04 \x01(\x04R\x06Cursor\x12\x18\n" +
+	"\aHasMore\x18\x05 \x01(\bR\aHasMore\"j\n" +
+	"\x0eBlockSignature\x12\x14\n" +
+	"\x05Index\x18\x01 \x01(\x03R\x05Index\x12\x12\n" +
+	"\x04Weak\x18\x02 \x01(\rR\x04Weak\x12\x16\n" +
+	"\x06Strong\x18\x03 \x01(\fR\x06Strong\x12\x16\n" +
+	"\x06Length\x18\x04 \x01(\x05R\x06Length\"e\n" +
+	"\x15DeltaSignatureRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x16\n" +
+	"\x06FileID\x18\x02 \x01(\x04R\x06FileID\x12\x1c\n" +
+	"\tBlockSize\x18\x03 \x01(\x05R\tBlockSize\"\xef\x01\n" +
+	"\x16DeltaSignatureResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12\x16\n" +
+	"\x06FileID\x18\x03 \x01(\x04R\x06FileID\x12\x18\n" +
+	"\aVersion\x18\x04 \x01(\x04R\aVersion\x12\x1a\n" +
+	"\bFileSize\x18\x05 \x01(\x03R\bFileSize\x12\x1a\n" +
+	"\bFileHash\x18\x06 \x01(\tR\bFileHash\x12\x1c\n" +
+	"\tBlockSize\x18\a \x01(\x05R\tBlockSize\x12'\n" +
+	"\x06Blocks\x18\b \x03(\v2\x0f.BlockSignatureR\x06Blocks\"]\n" +
+	"\aDeltaOp\x12\x1e\n" +
+	"\n" +
+	"BlockIndex\x18\x01 \x01(\x03R\n" +
+	"BlockIndex\x12\x1e\n" +
+	"\n" +
+	"BlockCount\x18\x02 \x01(\x03R\n" +
+	"BlockCount\x12\x12\n" +
+	"\x04Data\x18\x03 \x01(\fR\x04Data\"\xc4\x01\n" +
+	"\x12DeltaUploadRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x16\n" +
+	"\x06FileID\x18\x02 \x01(\x04R\x06FileID\x12 \n" +
+	"\vBaseVersion\x18\x03 \x01(\x04R\vBaseVersion\x12\x1c\n" +
+	"\tBlockSize\x18\x04 \x01(\x05R\tBlockSize\x12\"\n" +
+	"\fExpectedHash\x18\x05 \x01(\tR\fExpectedHash\x12\x1a\n" +
+	"\x03Ops\x18\x06 \x03(\v2\b.DeltaOpR\x03Ops\"-\n" +
+	"\x13StorageStatsRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\"\xa6\x02\n" +
+	"\x14StorageStatsResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12\"\n" +
+	"\fLogicalBytes\x18\x03 \x01(\x03R\fLogicalBytes\x12$\n" +
+	"\rPhysicalBytes\x18\x04 \x01(\x03R\rPhysicalBytes\x12\x1e\n" +
+	"\n" +
+	"ChunkBytes\x18\x05 \x01(\x03R\n" +
+	"ChunkBytes\x12\x1e\n" +
+	"\n" +
+	"ChunkCount\x18\x06 \x01(\x03R\n" +
+	"ChunkCount\x12\"\n" +
+	"\fChunkedFiles\x18\a \x01(\x03R\fChunkedFiles\x12\x1a\n" +
+	"\bRawBytes\x18\b \x01(\x03R\bRawBytes\x12\x1e\n" +
+	"\n" +
+	"DedupRatio\x18\t \x01(\x01R\n" +
+	"DedupRatio\"\x9a\x01\n" +
+	"\x16PresignDownloadRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x16\n" +
+	"\x06FileID\x18\x02 \x01(\x04R\x06FileID\x12\x18\n" +
+	"\aExpires\x18\x03 \x01(\x03R\aExpires\x12\x16\n" +
+	"\x06BindIP\x18\x04 \x01(\tR\x06BindIP\x12\x1e\n" +
+	"\n" +
+	"Attachment\x18\x05 \x01(\bR\n" +
+	"Attachment\"\x8b\x01\n" +
+	"\x17PresignDownloadResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12\x10\n" +
+	"\x03Url\x18\x03 \x01(\tR\x03Url\x12\x1c\n" +
+	"\tExpiresAt\x18\x04 \x01(\x03R\tExpiresAt\x12\x1a\n" +
+	"\bFilename\x18\x05 \x01(\tR\bFilename\"\x85\x01\n" +
+	"\x17QiniuUploadTokenRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bFilename\x18\x02 \x01(\tR\bFilename\x12\x1a\n" +
+	"\bFileSize\x18\x03 \x01(\x03R\bFileSize\x12\x1a\n" +
+	"\bFolderID\x18\x04 \x01(\x04R\bFolderID\"\xd4\x01\n" +
+	"\x18QiniuUploadTokenResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12\x14\n" +
+	"\x05Token\x18\x03 \x01(\tR\x05Token\x12\x10\n" +
+	"\x03Key\x18\x04 \x01(\tR\x03Key\x12\x1c\n" +
+	"\tUploadUrl\x18\x05 \x01(\tR\tUploadUrl\x12\x1c\n" +
+	"\tExpiresAt\x18\x06 \x01(\x03R\tExpiresAt\x12.\n" +
+	"\tRejection\x18\a \x01(\v2\x10.PolicyRejectionR\tRejection\"\xae\x01\n" +
+	"\x1aQiniuUploadCallbackRequest\x12\x10\n" +
+	"\x03Key\x18\x01 \x01(\tR\x03Key\x12\x12\n" +
+	"\x04Hash\x18\x02 \x01(\tR\x04Hash\x12\x1a\n" +
+	"\bFileSize\x18\x03 \x01(\x03R\bFileSize\x12\x16\n" +
+	"\x06UserID\x18\x04 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bFolderID\x18\x05 \x01(\x04R\bFolderID\x12\x1a\n" +
+	"\bFilename\x18\x06 \x01(\tR\bFilename\"\xb6\x01\n" +
+	"\x14PresignUploadRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bFilename\x18\x02 \x01(\tR\bFilename\x12\x1a\n" +
+	"\bFileSize\x18\x03 \x01(\x03R\bFileSize\x12\x1a\n" +
+	"\bFileHash\x18\x04 \x01(\tR\bFileHash\x12\x1a\n" +
+	"\bFolderID\x18\x05 \x01(\x04R\bFolderID\x12\x16\n" +
+	"\x06Bucket\x18\x06 \x01(\tR\x06Bucket\"\xf9\x01\n" +
+	"\x15PresignUploadResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12\x1a\n" +
+	"\bUploadID\x18\x03 \x01(\tR\bUploadID\x12\x16\n" +
+	"\x06Method\x18\x04 \x01(\tR\x06Method\x12\x10\n" +
+	"\x03Url\x18\x05 \x01(\tR\x03Url\x12\x14\n" +
+	"\x05Token\x18\x06 \x01(\tR\x05Token\x12\x10\n" +
+	"\x03Key\x18\a \x01(\tR\x03Key\x12\x1c\n" +
+	"\tExpiresAt\x18\b \x01(\x03R\tExpiresAt\x12.\n" +
+	"\tRejection\x18\t \x01(\v2\x10.PolicyRejectionR\tRejection\"J\n" +
+	"\x14ConfirmUploadRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bUploadID\x18\x02 \x01(\tR\bUploadID2\x98\x10\n" +
 	"\fFilesService\x125\n" +
 	"\n" +
 	"FileUpload\x12\x12.FileUploadRequest\x1a\x13.FileUploadResponse\x12@\n" +
@@ -1327,7 +4539,34 @@ const file_files_proto_rawDesc = "" +
 	"\x12QiniuBigFileUpload\x12\x15.BigFileUploadRequest\x1a\x16.BigFileUploadResponse(\x01\x12@\n" +
 	"\x11QiniuFileDownload\x12\x14.FileDownloadRequest\x1a\x15.FileDownloadResponse\x12G\n" +
 	"\x10GlobalFileSearch\x12\x18.GlobalFileSearchRequest\x1a\x19.GlobalFileSearchResponse\x12:\n" +
-	"\x0fQiniuFileDelete\x12\x12.FileDeleteRequest\x1a\x13.FileCommonResponseB\bZ\x06files/b\x06proto3"
+	"\x0fQiniuFileDelete\x12\x12.FileDeleteRequest\x1a\x13.FileCommonResponse\x12;\n" +
+	"\fFolderCreate\x12\x14.FolderCreateRequest\x1a\x15.FolderCreateResponse\x125\n" +
+	"\n" +
+	"FileRename\x12\x12.FileRenameRequest\x1a\x13.FileCommonResponse\x125\n" +
+	"\n" +
+	"FolderList\x12\x12.FolderListRequest\x1a\x13.FolderListResponse\x121\n" +
+	"\bFileMove\x12\x10.FileMoveRequest\x1a\x13.FileCommonResponse\x125\n" +
+	"\n" +
+	"FolderMove\x12\x12.FolderMoveRequest\x1a\x13.FileCommonResponse\x129\n" +
+	"\fFolderDelete\x12\x14.FolderDeleteRequest\x1a\x13.FileCommonResponse\x125\n" +
+	"\n" +
+	"ShareGrant\x12\x12.ShareGrantRequest\x1a\x13.ShareGrantResponse\x127\n" +
+	"\vShareRevoke\x12\x13.ShareRevokeRequest\x1a\x13.FileCommonResponse\x122\n" +
+	"\tShareList\x12\x11.ShareListRequest\x1a\x12.ShareListResponse\x125\n" +
+	"\fSharedWithMe\x12\x11.ShareListRequest\x1a\x12.ShareListResponse\x128\n" +
+	"\vGroupCreate\x12\x13.GroupCreateRequest\x1a\x14.GroupCreateResponse\x12:\n" +
+	"\x0eGroupAddMember\x12\x13.GroupMemberRequest\x1a\x13.FileCommonResponse\x12=\n" +
+	"\x11GroupRemoveMember\x12\x13.GroupMemberRequest\x1a\x13.FileCommonResponse\x126\n" +
+	"\vListChanges\x12\x12.ChangeListRequest\x1a\x13.ChangeListResponse\x129\n" +
+	"\fWatchChanges\x12\x12.ChangeListRequest\x1a\x13.ChangeListResponse0\x01\x12C\n" +
+	"\x0eDeltaSignature\x12\x16.DeltaSignatureRequest\x1a\x17.DeltaSignatureResponse0\x01\x12<\n" +
+	"\vDeltaUpload\x12\x13.DeltaUploadRequest\x1a\x16.BigFileUploadResponse(\x01\x12;\n" +
+	"\fStorageStats\x12\x14.StorageStatsRequest\x1a\x15.StorageStatsResponse\x12D\n" +
+	"\x0fPresignDownload\x12\x17.PresignDownloadRequest\x1a\x18.PresignDownloadResponse\x12G\n" +
+	"\x10QiniuUploadToken\x12\x18.QiniuUploadTokenRequest\x1a\x19.QiniuUploadTokenResponse\x12G\n" +
+	"\x13QiniuUploadCallback\x12\x1b.QiniuUploadCallbackRequest\x1a\x13.FileUploadResponse\x12>\n" +
+	"\rPresignUpload\x12\x15.PresignUploadRequest\x1a\x16.PresignUploadResponse\x12;\n" +
+	"\rConfirmUpload\x12\x15.ConfirmUploadRequest\x1a\x13.FileUploadResponseB\bZ\x06files/b\x06proto3"
 
 var (
 	file_files_proto_rawDescOnce sync.Once
@@ -1341,55 +4580,148 @@ func file_files_proto_rawDescGZIP() []byte {
 	return file_files_proto_rawDescData
 }
 
-var file_files_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_files_proto_msgTypes = make([]protoimpl.MessageInfo, 53)
 var file_files_proto_goTypes = []any{
-	(*FileModel)(nil),                // 0: FileModel
-	(*FileUploadRequest)(nil),        // 1: FileUploadRequest
-	(*FileUploadResponse)(nil),       // 2: FileUploadResponse
-	(*BigFileUploadRequest)(nil),     // 3: BigFileUploadRequest
-	(*BigFileUploadResponse)(nil),    // 4: BigFileUploadResponse
-	(*FileDeleteRequest)(nil),        // 5: FileDeleteRequest
-	(*FileListRequest)(nil),          // 6: FileListRequest
-	(*FileListResponse)(nil),         // 7: FileListResponse
-	(*FileDownloadRequest)(nil),      // 8: FileDownloadRequest
-	(*FileDownloadResponse)(nil),     // 9: FileDownloadResponse
-	(*FileCommonResponse)(nil),       // 10: FileCommonResponse
-	(*CheckFileRequest)(nil),         // 11: CheckFileRequest
-	(*CheckFileResponse)(nil),        // 12: CheckFileResponse
-	(*GlobalFileSearchRequest)(nil),  // 13: GlobalFileSearchRequest
-	(*GlobalFileSearchResponse)(nil), // 14: GlobalFileSearchResponse
-	(*GlobalFileInfo)(nil),           // 15: GlobalFileInfo
+	(*FileModel)(nil),                  // 0: FileModel
+	(*FileUploadRequest)(nil),          // 1: FileUploadRequest
+	(*FileUploadResponse)(nil),         // 2: FileUploadResponse
+	(*PolicyRejection)(nil),            // 3: PolicyRejection
+	(*BigFileUploadRequest)(nil),       // 4: BigFileUploadRequest
+	(*BigFileUploadResponse)(nil),      // 5: BigFileUploadResponse
+	(*FileDeleteRequest)(nil),          // 6: FileDeleteRequest
+	(*FileListRequest)(nil),            // 7: FileListRequest
+	(*FileListResponse)(nil),           // 8: FileListResponse
+	(*FileDownloadRequest)(nil),        // 9: FileDownloadRequest
+	(*FileDownloadResponse)(nil),       // 10: FileDownloadResponse
+	(*FileCommonResponse)(nil),         // 11: FileCommonResponse
+	(*CheckFileRequest)(nil),           // 12: CheckFileRequest
+	(*CheckFileResponse)(nil),          // 13: CheckFileResponse
+	(*GlobalFileSearchRequest)(nil),    // 14: GlobalFileSearchRequest
+	(*GlobalFileSearchResponse)(nil),   // 15: GlobalFileSearchResponse
+	(*GlobalFileInfo)(nil),             // 16: GlobalFileInfo
+	(*FolderModel)(nil),                // 17: FolderModel
+	(*FolderCreateRequest)(nil),        // 18: FolderCreateRequest
+	(*FolderCreateResponse)(nil),       // 19: FolderCreateResponse
+	(*FileRenameRequest)(nil),          // 20: FileRenameRequest
+	(*FolderListRequest)(nil),          // 21: FolderListRequest
+	(*FolderListResponse)(nil),         // 22: FolderListResponse
+	(*FileMoveRequest)(nil),            // 23: FileMoveRequest
+	(*FolderMoveRequest)(nil),          // 24: FolderMoveRequest
+	(*FolderDeleteRequest)(nil),        // 25: FolderDeleteRequest
+	(*ShareModel)(nil),                 // 26: ShareModel
+	(*ShareGrantRequest)(nil),          // 27: ShareGrantRequest
+	(*ShareGrantResponse)(nil),         // 28: ShareGrantResponse
+	(*ShareRevokeRequest)(nil),         // 29: ShareRevokeRequest
+	(*ShareListRequest)(nil),           // 30: ShareListRequest
+	(*ShareListResponse)(nil),          // 31: ShareListResponse
+	(*GroupCreateRequest)(nil),         // 32: GroupCreateRequest
+	(*GroupCreateResponse)(nil),        // 33: GroupCreateResponse
+	(*GroupMemberRequest)(nil),         // 34: GroupMemberRequest
+	(*ChangeModel)(nil),                // 35: ChangeModel
+	(*ChangeListRequest)(nil),          // 36: ChangeListRequest
+	(*ChangeListResponse)(nil),         // 37: ChangeListResponse
+	(*BlockSignature)(nil),             // 38: BlockSignature
+	(*DeltaSignatureRequest)(nil),      // 39: DeltaSignatureRequest
+	(*DeltaSignatureResponse)(nil),     // 40: DeltaSignatureResponse
+	(*DeltaOp)(nil),                    // 41: DeltaOp
+	(*DeltaUploadRequest)(nil),         // 42: DeltaUploadRequest
+	(*StorageStatsRequest)(nil),        // 43: StorageStatsRequest
+	(*StorageStatsResponse)(nil),       // 44: StorageStatsResponse
+	(*PresignDownloadRequest)(nil),     // 45: PresignDownloadRequest
+	(*PresignDownloadResponse)(nil),    // 46: PresignDownloadResponse
+	(*QiniuUploadTokenRequest)(nil),    // 47: QiniuUploadTokenRequest
+	(*QiniuUploadTokenResponse)(nil),   // 48: QiniuUploadTokenResponse
+	(*QiniuUploadCallbackRequest)(nil), // 49: QiniuUploadCallbackRequest
+	(*PresignUploadRequest)(nil),       // 50: PresignUploadRequest
+	(*PresignUploadResponse)(nil),      // 51: PresignUploadResponse
+	(*ConfirmUploadRequest)(nil),       // 52: ConfirmUploadRequest
 }
 var file_files_proto_depIdxs = []int32{
-	0,  // 0: FileListResponse.Files:type_name -> FileModel
-	15, // 1: GlobalFileSearchResponse.Files:type_name -> GlobalFileInfo
-	1,  // 2: FilesService.FileUpload:input_type -> FileUploadRequest
-	3,  // 3: FilesService.BigFileUpload:input_type -> BigFileUploadRequest
-	5,  // 4: FilesService.FileDelete:input_type -> FileDeleteRequest
-	6,  // 5: FilesService.FileList:input_type -> FileListRequest
-	8,  // 6: FilesService.FileDownload:input_type -> FileDownloadRequest
-	11, // 7: FilesService.CheckFileExists:input_type -> CheckFileRequest
-	1,  // 8: FilesService.QiniuFileUpload:input_type -> FileUploadRequest
-	3,  // 9: FilesService.QiniuBigFileUpload:input_type -> BigFileUploadRequest
-	8,  // 10: FilesService.QiniuFileDownload:input_type -> FileDownloadRequest
-	13, // 11: FilesService.GlobalFileSearch:input_type -> GlobalFileSearchRequest
-	5,  // 12: FilesService.QiniuFileDelete:input_type -> FileDeleteRequest
-	2,  // 13: FilesService.FileUpload:output_type -> FileUploadResponse
-	4,  // 14: FilesService.BigFileUpload:output_type -> BigFileUploadResponse
-	10, // 15: FilesService.FileDelete:output_type -> FileCommonResponse
-	7,  // 16: FilesService.FileList:output_type -> FileListResponse
-	9,  // 17: FilesService.FileDownload:output_type -> FileDownloadResponse
-	12, // 18: FilesService.CheckFileExists:output_type -> CheckFileResponse
-	2,  // 19: FilesService.QiniuFileUpload:output_type -> FileUploadResponse
-	4,  // 20: FilesService.QiniuBigFileUpload:output_type -> BigFileUploadResponse
-	9,  // 21: FilesService.QiniuFileDownload:output_type -> FileDownloadResponse
-	14, // 22: FilesService.GlobalFileSearch:output_type -> GlobalFileSearchResponse
-	10, // 23: FilesService.QiniuFileDelete:output_type -> FileCommonResponse
-	13, // [13:24] is the sub-list for method output_type
-	2,  // [2:13] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	3,  // 0: FileUploadResponse.Rejection:type_name -> PolicyRejection
+	3,  // 1: BigFileUploadResponse.Rejection:type_name -> PolicyRejection
+	0,  // 2: FileListResponse.Files:type_name -> FileModel
+	16, // 3: GlobalFileSearchResponse.Files:type_name -> GlobalFileInfo
+	17, // 4: FolderListResponse.Folders:type_name -> FolderModel
+	0,  // 5: FolderListResponse.Files:type_name -> FileModel
+	26, // 6: ShareListResponse.Shares:type_name -> ShareModel
+	35, // 7: ChangeListResponse.Changes:type_name -> ChangeModel
+	38, // 8: DeltaSignatureResponse.Blocks:type_name -> BlockSignature
+	41, // 9: DeltaUploadRequest.Ops:type_name -> DeltaOp
+	3,  // 10: QiniuUploadTokenResponse.Rejection:type_name -> PolicyRejection
+	3,  // 11: PresignUploadResponse.Rejection:type_name -> PolicyRejection
+	1,  // 12: FilesService.FileUpload:input_type -> FileUploadRequest
+	4,  // 13: FilesService.BigFileUpload:input_type -> BigFileUploadRequest
+	6,  // 14: FilesService.FileDelete:input_type -> FileDeleteRequest
+	7,  // 15: FilesService.FileList:input_type -> FileListRequest
+	9,  // 16: FilesService.FileDownload:input_type -> FileDownloadRequest
+	12, // 17: FilesService.CheckFileExists:input_type -> CheckFileRequest
+	1,  // 18: FilesService.QiniuFileUpload:input_type -> FileUploadRequest
+	4,  // 19: FilesService.QiniuBigFileUpload:input_type -> BigFileUploadRequest
+	9,  // 20: FilesService.QiniuFileDownload:input_type -> FileDownloadRequest
+	14, // 21: FilesService.GlobalFileSearch:input_type -> GlobalFileSearchRequest
+	6,  // 22: FilesService.QiniuFileDelete:input_type -> FileDeleteRequest
+	18, // 23: FilesService.FolderCreate:input_type -> FolderCreateRequest
+	20, // 24: FilesService.FileRename:input_type -> FileRenameRequest
+	21, // 25: FilesService.FolderList:input_type -> FolderListRequest
+	23, // 26: FilesService.FileMove:input_type -> FileMoveRequest
+	24, // 27: FilesService.FolderMove:input_type -> FolderMoveRequest
+	25, // 28: FilesService.FolderDelete:input_type -> FolderDeleteRequest
+	27, // 29: FilesService.ShareGrant:input_type -> ShareGrantRequest
+	29, // 30: FilesService.ShareRevoke:input_type -> ShareRevokeRequest
+	30, // 31: FilesService.ShareList:input_type -> ShareListRequest
+	30, // 32: FilesService.SharedWithMe:input_type -> ShareListRequest
+	32, // 33: FilesService.GroupCreate:input_type -> GroupCreateRequest
+	34, // 34: FilesService.GroupAddMember:input_type -> GroupMemberRequest
+	34, // 35: FilesService.GroupRemoveMember:input_type -> GroupMemberRequest
+	36, // 36: FilesService.ListChanges:input_type -> ChangeListRequest
+	36, // 37: FilesService.WatchChanges:input_type -> ChangeListRequest
+	39, // 38: FilesService.DeltaSignature:input_type -> DeltaSignatureRequest
+	42, // 39: FilesService.DeltaUpload:input_type -> DeltaUploadRequest
+	43, // 40: FilesService.StorageStats:input_type -> StorageStatsRequest
+	45, // 41: FilesService.PresignDownload:input_type -> PresignDownloadRequest
+	47, // 42: FilesService.QiniuUploadToken:input_type -> QiniuUploadTokenRequest
+	49, // 43: FilesService.QiniuUploadCallback:input_type -> QiniuUploadCallbackRequest
+	50, // 44: FilesService.PresignUpload:input_type -> PresignUploadRequest
+	52, // 45: FilesService.ConfirmUpload:input_type -> ConfirmUploadRequest
+	2,  // 46: FilesService.FileUpload:output_type -> FileUploadResponse
+	5,  // 47: FilesService.BigFileUpload:output_type -> BigFileUploadResponse
+	11, // 48: FilesService.FileDelete:output_type -> FileCommonResponse
+	8,  // 49: FilesService.FileList:output_type -> FileListResponse
+	10, // 50: FilesService.FileDownload:output_type -> FileDownloadResponse
+	13, // 51: FilesService.CheckFileExists:output_type -> CheckFileResponse
+	2,  // 52: FilesService.QiniuFileUpload:output_type -> FileUploadResponse
+	5,  // 53: FilesService.QiniuBigFileUpload:output_type -> BigFileUploadResponse
+	10, // 54: FilesService.QiniuFileDownload:output_type -> FileDownloadResponse
+	15, // 55: FilesService.GlobalFileSearch:output_type -> GlobalFileSearchResponse
+	11, // 56: FilesService.QiniuFileDelete:output_type -> FileCommonResponse
+	19, // 57: FilesService.FolderCreate:output_type -> FolderCreateResponse
+	11, // 58: FilesService.FileRename:output_type -> FileCommonResponse
+	22, // 59: FilesService.FolderList:output_type -> FolderListResponse
+	11, // 60: FilesService.FileMove:output_type -> FileCommonResponse
+	11, // 61: FilesService.FolderMove:output_type -> FileCommonResponse
+	11, // 62: FilesService.FolderDelete:output_type -> FileCommonResponse
+	28, // 63: FilesService.ShareGrant:output_type -> ShareGrantResponse
+	11, // 64: FilesService.ShareRevoke:output_type -> FileCommonResponse
+	31, // 65: FilesService.ShareList:output_type -> ShareListResponse
+	31, // 66: FilesService.SharedWithMe:output_type -> ShareListResponse
+	33, // 67: FilesService.GroupCreate:output_type -> GroupCreateResponse
+	11, // 68: FilesService.GroupAddMember:output_type -> FileCommonResponse
+	11, // 69: FilesService.GroupRemoveMember:output_type -> FileCommonResponse
+	37, // 70: FilesService.ListChanges:output_type -> ChangeListResponse
+	37, // 71: FilesService.WatchChanges:output_type -> ChangeListResponse
+	40, // 72: FilesService.DeltaSignature:output_type -> DeltaSignatureResponse
+	5,  // 73: FilesService.DeltaUpload:output_type -> BigFileUploadResponse
+	44, // 74: FilesService.StorageStats:output_type -> StorageStatsResponse
+	46, // 75: FilesService.PresignDownload:output_type -> PresignDownloadResponse
+	48, // 76: FilesService.QiniuUploadToken:output_type -> QiniuUploadTokenResponse
+	2,  // 77: FilesService.QiniuUploadCallback:output_type -> FileUploadResponse
+	51, // 78: FilesService.PresignUpload:output_type -> PresignUploadResponse
+	2,  // 79: FilesService.ConfirmUpload:output_type -> FileUploadResponse
+	46, // [46:80] is the sub-list for method output_type
+	12, // [12:46] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_files_proto_init() }
@@ -1403,7 +4735,7 @@ func file_files_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_files_proto_rawDesc), len(file_files_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   16,
+			NumMessages:   53,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,17 +19,40 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	FilesService_FileUpload_FullMethodName         = "/FilesService/FileUpload"
-	FilesService_BigFileUpload_FullMethodName      = "/FilesService/BigFileUpload"
-	FilesService_FileDelete_FullMethodName         = "/FilesService/FileDelete"
-	FilesService_FileList_FullMethodName           = "/FilesService/FileList"
-	FilesService_FileDownload_FullMethodName       = "/FilesService/FileDownload"
-	FilesService_CheckFileExists_FullMethodName    = "/FilesService/CheckFileExists"
-	FilesService_QiniuFileUpload_FullMethodName    = "/FilesService/QiniuFileUpload"
-	FilesService_QiniuBigFileUpload_FullMethodName = "/FilesService/QiniuBigFileUpload"
-	FilesService_QiniuFileDownload_FullMethodName  = "/FilesService/QiniuFileDownload"
-	FilesService_GlobalFileSearch_FullMethodName   = "/FilesService/GlobalFileSearch"
-	FilesService_QiniuFileDelete_FullMethodName    = "/FilesService/QiniuFileDelete"
+	FilesService_FileUpload_FullMethodName          = "/FilesService/FileUpload"
+	FilesService_BigFileUpload_FullMethodName       = "/FilesService/BigFileUpload"
+	FilesService_FileDelete_FullMethodName          = "/FilesService/FileDelete"
+	FilesService_FileList_FullMethodName            = "/FilesService/FileList"
+	FilesService_FileDownload_FullMethodName        = "/FilesService/FileDownload"
+	FilesService_CheckFileExists_FullMethodName     = "/FilesService/CheckFileExists"
+	FilesService_QiniuFileUpload_FullMethodName     = "/FilesService/QiniuFileUpload"
+	FilesService_QiniuBigFileUpload_FullMethodName  = "/FilesService/QiniuBigFileUpload"
+	FilesService_QiniuFileDownload_FullMethodName   = "/FilesService/QiniuFileDownload"
+	FilesService_GlobalFileSearch_FullMethodName    = "/FilesService/GlobalFileSearch"
+	FilesService_QiniuFileDelete_FullMethodName     = "/FilesService/QiniuFileDelete"
+	FilesService_FolderCreate_FullMethodName        = "/FilesService/FolderCreate"
+	FilesService_FileRename_FullMethodName          = "/FilesService/FileRename"
+	FilesService_FolderList_FullMethodName          = "/FilesService/FolderList"
+	FilesService_FileMove_FullMethodName            = "/FilesService/FileMove"
+	FilesService_FolderMove_FullMethodName          = "/FilesService/FolderMove"
+	FilesService_FolderDelete_FullMethodName        = "/FilesService/FolderDelete"
+	FilesService_ShareGrant_FullMethodName          = "/FilesService/ShareGrant"
+	FilesService_ShareRevoke_FullMethodName         = "/FilesService/ShareRevoke"
+	FilesService_ShareList_FullMethodName           = "/FilesService/ShareList"
+	FilesService_SharedWithMe_FullMethodName        = "/FilesService/SharedWithMe"
+	FilesService_GroupCreate_FullMethodName         = "/FilesService/GroupCreate"
+	FilesService_GroupAddMember_FullMethodName      = "/FilesService/GroupAddMember"
+	FilesService_GroupRemoveMember_FullMethodName   = "/FilesService/GroupRemoveMember"
+	FilesService_ListChanges_FullMethodName         = "/FilesService/ListChanges"
+	FilesService_WatchChanges_FullMethodName        = "/FilesService/WatchChanges"
+	FilesService_DeltaSignature_FullMethodName      = "/FilesService/DeltaSignature"
+	FilesService_DeltaUpload_FullMethodName         = "/FilesService/DeltaUpload"
+	FilesService_StorageStats_FullMethodName        = "/FilesService/StorageStats"
+	FilesService_PresignDownload_FullMethodName     = "/FilesService/PresignDownload"
+	FilesService_QiniuUploadToken_FullMethodName    = "/FilesService/QiniuUploadToken"
+	FilesService_QiniuUploadCallback_FullMethodName = "/FilesService/QiniuUploadCallback"
+	FilesService_PresignUpload_FullMethodName       = "/FilesService/PresignUpload"
+	FilesService_ConfirmUpload_FullMethodName       = "/FilesService/ConfirmUpload"
 )
 
 // FilesServiceClient is the client API for FilesService service.
@@ -51,6 +74,38 @@ type FilesServiceClient interface {
 	GlobalFileSearch(ctx context.Context, in *GlobalFileSearchRequest, opts ...grpc.CallOption) (*GlobalFileSearchResponse, error)
 	// 七牛云文件删除接口
 	QiniuFileDelete(ctx context.Context, in *FileDeleteRequest, opts ...grpc.CallOption) (*FileCommonResponse, error)
+	// 文件夹与重命名
+	FolderCreate(ctx context.Context, in *FolderCreateRequest, opts ...grpc.CallOption) (*FolderCreateResponse, error)
+	FileRename(ctx context.Context, in *FileRenameRequest, opts ...grpc.CallOption) (*FileCommonResponse, error)
+	FolderList(ctx context.Context, in *FolderListRequest, opts ...grpc.CallOption) (*FolderListResponse, error)
+	FileMove(ctx context.Context, in *FileMoveRequest, opts ...grpc.CallOption) (*FileCommonResponse, error)
+	FolderMove(ctx context.Context, in *FolderMoveRequest, opts ...grpc.CallOption) (*FileCommonResponse, error)
+	FolderDelete(ctx context.Context, in *FolderDeleteRequest, opts ...grpc.CallOption) (*FileCommonResponse, error)
+	// 共享与权限
+	ShareGrant(ctx context.Context, in *ShareGrantRequest, opts ...grpc.CallOption) (*ShareGrantResponse, error)
+	ShareRevoke(ctx context.Context, in *ShareRevokeRequest, opts ...grpc.CallOption) (*FileCommonResponse, error)
+	ShareList(ctx context.Context, in *ShareListRequest, opts ...grpc.CallOption) (*ShareListResponse, error)
+	SharedWithMe(ctx context.Context, in *ShareListRequest, opts ...grpc.CallOption) (*ShareListResponse, error)
+	// 用户组
+	GroupCreate(ctx context.Context, in *GroupCreateRequest, opts ...grpc.CallOption) (*GroupCreateResponse, error)
+	GroupAddMember(ctx context.Context, in *GroupMemberRequest, opts ...grpc.CallOption) (*FileCommonResponse, error)
+	GroupRemoveMember(ctx context.Context, in *GroupMemberRequest, opts ...grpc.CallOption) (*FileCommonResponse, error)
+	// 变更日志
+	ListChanges(ctx context.Context, in *ChangeListRequest, opts ...grpc.CallOption) (*ChangeListResponse, error)
+	WatchChanges(ctx context.Context, in *ChangeListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChangeListResponse], error)
+	// 增量同步
+	DeltaSignature(ctx context.Context, in *DeltaSignatureRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DeltaSignatureResponse], error)
+	DeltaUpload(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[DeltaUploadRequest, BigFileUploadResponse], error)
+	// 存储统计
+	StorageStats(ctx context.Context, in *StorageStatsRequest, opts ...grpc.CallOption) (*StorageStatsResponse, error)
+	// 签名下载链接
+	PresignDownload(ctx context.Context, in *PresignDownloadRequest, opts ...grpc.CallOption) (*PresignDownloadResponse, error)
+	// 七牛云客户端直传
+	QiniuUploadToken(ctx context.Context, in *QiniuUploadTokenRequest, opts ...grpc.CallOption) (*QiniuUploadTokenResponse, error)
+	QiniuUploadCallback(ctx context.Context, in *QiniuUploadCallbackRequest, opts ...grpc.CallOption) (*FileUploadResponse, error)
+	// 预签名直传
+	PresignUpload(ctx context.Context, in *PresignUploadRequest, opts ...grpc.CallOption) (*PresignUploadResponse, error)
+	ConfirmUpload(ctx context.Context, in *ConfirmUploadRequest, opts ...grpc.CallOption) (*FileUploadResponse, error)
 }
 
 type filesServiceClient struct {
@@ -177,6 +232,257 @@ func (c *filesServiceClient) QiniuFileDelete(ctx context.Context, in *FileDelete
 	return out, nil
 }
 
+func (c *filesServiceClient) FolderCreate(ctx context.Context, in *FolderCreateRequest, opts ...grpc.CallOption) (*FolderCreateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FolderCreateResponse)
+	err := c.cc.Invoke(ctx, FilesService_FolderCreate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) FileRename(ctx context.Context, in *FileRenameRequest, opts ...grpc.CallOption) (*FileCommonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileCommonResponse)
+	err := c.cc.Invoke(ctx, FilesService_FileRename_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) FolderList(ctx context.Context, in *FolderListRequest, opts ...grpc.CallOption) (*FolderListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FolderListResponse)
+	err := c.cc.Invoke(ctx, FilesService_FolderList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) FileMove(ctx context.Context, in *FileMoveRequest, opts ...grpc.CallOption) (*FileCommonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileCommonResponse)
+	err := c.cc.Invoke(ctx, FilesService_FileMove_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) FolderMove(ctx context.Context, in *FolderMoveRequest, opts ...grpc.CallOption) (*FileCommonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileCommonResponse)
+	err := c.cc.Invoke(ctx, FilesService_FolderMove_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) FolderDelete(ctx context.Context, in *FolderDeleteRequest, opts ...grpc.CallOption) (*FileCommonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileCommonResponse)
+	err := c.cc.Invoke(ctx, FilesService_FolderDelete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) ShareGrant(ctx context.Context, in *ShareGrantRequest, opts ...grpc.CallOption) (*ShareGrantResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShareGrantResponse)
+	err := c.cc.Invoke(ctx, FilesService_ShareGrant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) ShareRevoke(ctx context.Context, in *ShareRevokeRequest, opts ...grpc.CallOption) (*FileCommonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileCommonResponse)
+	err := c.cc.Invoke(ctx, FilesService_ShareRevoke_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) ShareList(ctx context.Context, in *ShareListRequest, opts ...grpc.CallOption) (*ShareListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShareListResponse)
+	err := c.cc.Invoke(ctx, FilesService_ShareList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) SharedWithMe(ctx context.Context, in *ShareListRequest, opts ...grpc.CallOption) (*ShareListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShareListResponse)
+	err := c.cc.Invoke(ctx, FilesService_SharedWithMe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) GroupCreate(ctx context.Context, in *GroupCreateRequest, opts ...grpc.CallOption) (*GroupCreateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GroupCreateResponse)
+	err := c.cc.Invoke(ctx, FilesService_GroupCreate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) GroupAddMember(ctx context.Context, in *GroupMemberRequest, opts ...grpc.CallOption) (*FileCommonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileCommonResponse)
+	err := c.cc.Invoke(ctx, FilesService_GroupAddMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) GroupRemoveMember(ctx context.Context, in *GroupMemberRequest, opts ...grpc.CallOption) (*FileCommonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileCommonResponse)
+	err := c.cc.Invoke(ctx, FilesService_GroupRemoveMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) ListChanges(ctx context.Context, in *ChangeListRequest, opts ...grpc.CallOption) (*ChangeListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangeListResponse)
+	err := c.cc.Invoke(ctx, FilesService_ListChanges_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) WatchChanges(ctx context.Context, in *ChangeListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChangeListResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FilesService_ServiceDesc.Streams[2], FilesService_WatchChanges_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ChangeListRequest, ChangeListResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FilesService_WatchChangesClient = grpc.ServerStreamingClient[ChangeListResponse]
+
+func (c *filesServiceClient) DeltaSignature(ctx context.Context, in *DeltaSignatureRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DeltaSignatureResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FilesService_ServiceDesc.Streams[3], FilesService_DeltaSignature_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DeltaSignatureRequest, DeltaSignatureResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FilesService_DeltaSignatureClient = grpc.ServerStreamingClient[DeltaSignatureResponse]
+
+func (c *filesServiceClient) DeltaUpload(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[DeltaUploadRequest, BigFileUploadResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FilesService_ServiceDesc.Streams[4], FilesService_DeltaUpload_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DeltaUploadRequest, BigFileUploadResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FilesService_DeltaUploadClient = grpc.ClientStreamingClient[DeltaUploadRequest, BigFileUploadResponse]
+
+func (c *filesServiceClient) StorageStats(ctx context.Context, in *StorageStatsRequest, opts ...grpc.CallOption) (*StorageStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StorageStatsResponse)
+	err := c.cc.Invoke(ctx, FilesService_StorageStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) PresignDownload(ctx context.Context, in *PresignDownloadRequest, opts ...grpc.CallOption) (*PresignDownloadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PresignDownloadResponse)
+	err := c.cc.Invoke(ctx, FilesService_PresignDownload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) QiniuUploadToken(ctx context.Context, in *QiniuUploadTokenRequest, opts ...grpc.CallOption) (*QiniuUploadTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QiniuUploadTokenResponse)
+	err := c.cc.Invoke(ctx, FilesService_QiniuUploadToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) QiniuUploadCallback(ctx context.Context, in *QiniuUploadCallbackRequest, opts ...grpc.CallOption) (*FileUploadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileUploadResponse)
+	err := c.cc.Invoke(ctx, FilesService_QiniuUploadCallback_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) PresignUpload(ctx context.Context, in *PresignUploadRequest, opts ...grpc.CallOption) (*PresignUploadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PresignUploadResponse)
+	err := c.cc.Invoke(ctx, FilesService_PresignUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filesServiceClient) ConfirmUpload(ctx context.Context, in *ConfirmUploadRequest, opts ...grpc.CallOption) (*FileUploadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileUploadResponse)
+	err := c.cc.Invoke(ctx, FilesService_ConfirmUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // FilesServiceServer is the server API for FilesService service.
 // All implementations must embed UnimplementedFilesServiceServer
 // for forward compatibility.
@@ -196,6 +502,38 @@ type FilesServiceServer interface {
 	GlobalFileSearch(context.Context, *GlobalFileSearchRequest) (*GlobalFileSearchResponse, error)
 	// 七牛云文件删除接口
 	QiniuFileDelete(context.Context, *FileDeleteRequest) (*FileCommonResponse, error)
+	// 文件夹与重命名
+	FolderCreate(context.Context, *FolderCreateRequest) (*FolderCreateResponse, error)
+	FileRename(context.Context, *FileRenameRequest) (*FileCommonResponse, error)
+	FolderList(context.Context, *FolderListRequest) (*FolderListResponse, error)
+	FileMove(context.Context, *FileMoveRequest) (*FileCommonResponse, error)
+	FolderMove(context.Context, *FolderMoveRequest) (*FileCommonResponse, error)
+	FolderDelete(context.Context, *FolderDeleteRequest) (*FileCommonResponse, error)
+	// 共享与权限
+	ShareGrant(context.Context, *ShareGrantRequest) (*ShareGrantResponse, error)
+	ShareRevoke(context.Context, *ShareRevokeRequest) (*FileCommonResponse, error)
+	ShareList(context.Context, *ShareListRequest) (*ShareListResponse, error)
+	SharedWithMe(context.Context, *ShareListRequest) (*ShareListResponse, error)
+	// 用户组
+	GroupCreate(context.Context, *GroupCreateRequest) (*GroupCreateResponse, error)
+	GroupAddMember(context.Context, *GroupMemberRequest) (*FileCommonResponse, error)
+	GroupRemoveMember(context.Context, *GroupMemberRequest) (*FileCommonResponse, error)
+	// 变更日志
+	ListChanges(context.Context, *ChangeListRequest) (*ChangeListResponse, error)
+	WatchChanges(*ChangeListRequest, grpc.ServerStreamingServer[ChangeListResponse]) error
+	// 增量同步
+	DeltaSignature(*DeltaSignatureRequest, grpc.ServerStreamingServer[DeltaSignatureResponse]) error
+	DeltaUpload(grpc.ClientStreamingServer[DeltaUploadRequest, BigFileUploadResponse]) error
+	// 存储统计
+	StorageStats(context.Context, *StorageStatsRequest) (*StorageStatsResponse, error)
+	// 签名下载链接
+	PresignDownload(context.Context, *PresignDownloadRequest) (*PresignDownloadResponse, error)
+	// 七牛云客户端直传
+	QiniuUploadToken(context.Context, *QiniuUploadTokenRequest) (*QiniuUploadTokenResponse, error)
+	QiniuUploadCallback(context.Context, *QiniuUploadCallbackRequest) (*FileUploadResponse, error)
+	// 预签名直传
+	PresignUpload(context.Context, *PresignUploadRequest) (*PresignUploadResponse, error)
+	ConfirmUpload(context.Context, *ConfirmUploadRequest) (*FileUploadResponse, error)
 	mustEmbedUnimplementedFilesServiceServer()
 }
 
@@ -239,6 +577,75 @@ func (UnimplementedFilesServiceServer) GlobalFileSearch(context.Context, *Global
 func (UnimplementedFilesServiceServer) QiniuFileDelete(context.Context, *FileDeleteRequest) (*FileCommonResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QiniuFileDelete not implemented")
 }
+func (UnimplementedFilesServiceServer) FolderCreate(context.Context, *FolderCreateRequest) (*FolderCreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FolderCreate not implemented")
+}
+func (UnimplementedFilesServiceServer) FileRename(context.Context, *FileRenameRequest) (*FileCommonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FileRename not implemented")
+}
+func (UnimplementedFilesServiceServer) FolderList(context.Context, *FolderListRequest) (*FolderListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FolderList not implemented")
+}
+func (UnimplementedFilesServiceServer) FileMove(context.Context, *FileMoveRequest) (*FileCommonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FileMove not implemented")
+}
+func (UnimplementedFilesServiceServer) FolderMove(context.Context, *FolderMoveRequest) (*FileCommonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FolderMove not implemented")
+}
+func (UnimplementedFilesServiceServer) FolderDelete(context.Context, *FolderDeleteRequest) (*FileCommonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FolderDelete not implemented")
+}
+func (UnimplementedFilesServiceServer) ShareGrant(context.Context, *ShareGrantRequest) (*ShareGrantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShareGrant not implemented")
+}
+func (UnimplementedFilesServiceServer) ShareRevoke(context.Context, *ShareRevokeRequest) (*FileCommonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShareRevoke not implemented")
+}
+func (UnimplementedFilesServiceServer) ShareList(context.Context, *ShareListRequest) (*ShareListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShareList not implemented")
+}
+func (UnimplementedFilesServiceServer) SharedWithMe(context.Context, *ShareListRequest) (*ShareListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SharedWithMe not implemented")
+}
+func (UnimplementedFilesServiceServer) GroupCreate(context.Context, *GroupCreateRequest) (*GroupCreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GroupCreate not implemented")
+}
+func (UnimplementedFilesServiceServer) GroupAddMember(context.Context, *GroupMemberRequest) (*FileCommonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GroupAddMember not implemented")
+}
+func (UnimplementedFilesServiceServer) GroupRemoveMember(context.Context, *GroupMemberRequest) (*FileCommonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GroupRemoveMember not implemented")
+}
+func (UnimplementedFilesServiceServer) ListChanges(context.Context, *ChangeListRequest) (*ChangeListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListChanges not implemented")
+}
+func (UnimplementedFilesServiceServer) WatchChanges(*ChangeListRequest, grpc.ServerStreamingServer[ChangeListResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchChanges not implemented")
+}
+func (UnimplementedFilesServiceServer) DeltaSignature(*DeltaSignatureRequest, grpc.ServerStreamingServer[DeltaSignatureResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method DeltaSignature not implemented")
+}
+func (UnimplementedFilesServiceServer) DeltaUpload(grpc.ClientStreamingServer[DeltaUploadRequest, BigFileUploadResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method DeltaUpload not implemented")
+}
+func (UnimplementedFilesServiceServer) StorageStats(context.Context, *StorageStatsRequest) (*StorageStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StorageStats not implemented")
+}
+func (UnimplementedFilesServiceServer) PresignDownload(context.Context, *PresignDownloadRequest) (*PresignDownloadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PresignDownload not implemented")
+}
+func (UnimplementedFilesServiceServer) QiniuUploadToken(context.Context, *QiniuUploadTokenRequest) (*QiniuUploadTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QiniuUploadToken not implemented")
+}
+func (UnimplementedFilesServiceServer) QiniuUploadCallback(context.Context, *QiniuUploadCallbackRequest) (*FileUploadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QiniuUploadCallback not implemented")
+}
+func (UnimplementedFilesServiceServer) PresignUpload(context.Context, *PresignUploadRequest) (*PresignUploadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PresignUpload not implemented")
+}
+func (UnimplementedFilesServiceServer) ConfirmUpload(context.Context, *ConfirmUploadRequest) (*FileUploadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmUpload not implemented")
+}
 func (UnimplementedFilesServiceServer) mustEmbedUnimplementedFilesServiceServer() {}
 func (UnimplementedFilesServiceServer) testEmbeddedByValue()                      {}
 
@@ -436,61 +843,545 @@ func _FilesService_QiniuFileDelete_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
-// FilesService_ServiceDesc is the grpc.ServiceDesc for FilesService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var FilesService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "FilesService",
-	HandlerType: (*FilesServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "FileUpload",
-			Handler:    _FilesService_FileUpload_Handler,
-		},
-		{
-			MethodName: "FileDelete",
-			Handler:    _FilesService_FileDelete_Handler,
-		},
-		{
-			MethodName: "FileList",
-			Handler:    _FilesService_FileList_Handler,
-		},
-		{
-			MethodName: "FileDownload",
-			Handler:    _FilesService_FileDownload_Handler,
-		},
-		{
-			MethodName: "CheckFileExists",
-			Handler:    _FilesService_CheckFileExists_Handler,
-		},
-		{
-			MethodName: "QiniuFileUpload",
-			Handler:    _FilesService_QiniuFileUpload_Handler,
-		},
-		{
-			MethodName: "QiniuFileDownload",
-			Handler:    _FilesService_QiniuFileDownload_Handler,
-		},
-		{
-			MethodName: "GlobalFileSearch",
-			Handler:    _FilesService_GlobalFileSearch_Handler,
-		},
-		{
-			MethodName: "QiniuFileDelete",
-			Handler:    _FilesService_QiniuFileDelete_Handler,
-		},
-	},
-	Streams: []grpc.StreamDesc{
-		{
-			StreamName:    "BigFileUpload",
-			Handler:       _FilesService_BigFileUpload_Handler,
-			ClientStreams: true,
+func _FilesService_FolderCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FolderCreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).FolderCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_FolderCreate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).FolderCreate(ctx, req.(*FolderCreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_FileRename_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileRenameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).FileRename(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_FileRename_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).FileRename(ctx, req.(*FileRenameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_FolderList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FolderListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).FolderList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_FolderList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).FolderList(ctx, req.(*FolderListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_FileMove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileMoveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).FileMove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_FileMove_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).FileMove(ctx, req.(*FileMoveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_FolderMove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FolderMoveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).FolderMove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_FolderMove_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).FolderMove(ctx, req.(*FolderMoveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_FolderDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FolderDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).FolderDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_FolderDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).FolderDelete(ctx, req.(*FolderDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_ShareGrant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareGrantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).ShareGrant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_ShareGrant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).ShareGrant(ctx, req.(*ShareGrantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_ShareRevoke_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareRevokeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).ShareRevoke(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_ShareRevoke_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).ShareRevoke(ctx, req.(*ShareRevokeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_ShareList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).ShareList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_ShareList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).ShareList(ctx, req.(*ShareListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_SharedWithMe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).SharedWithMe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_SharedWithMe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).SharedWithMe(ctx, req.(*ShareListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_GroupCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupCreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).GroupCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_GroupCreate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).GroupCreate(ctx, req.(*GroupCreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_GroupAddMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).GroupAddMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_GroupAddMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).GroupAddMember(ctx, req.(*GroupMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_GroupRemoveMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).GroupRemoveMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_GroupRemoveMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).GroupRemoveMember(ctx, req.(*GroupMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_ListChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).ListChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_ListChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).ListChanges(ctx, req.(*ChangeListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_WatchChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ChangeListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FilesServiceServer).WatchChanges(m, &grpc.GenericServerStream[ChangeListRequest, ChangeListResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FilesService_WatchChangesServer = grpc.ServerStreamingServer[ChangeListResponse]
+
+func _FilesService_DeltaSignature_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DeltaSignatureRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FilesServiceServer).DeltaSignature(m, &grpc.GenericServerStream[DeltaSignatureRequest, DeltaSignatureResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FilesService_DeltaSignatureServer = grpc.ServerStreamingServer[DeltaSignatureResponse]
+
+func _FilesService_DeltaUpload_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(FilesServiceServer).DeltaUpload(&grpc.GenericServerStream[DeltaUploadRequest, BigFileUploadResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FilesService_DeltaUploadServer = grpc.ClientStreamingServer[DeltaUploadRequest, BigFileUploadResponse]
+
+func _FilesService_StorageStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).StorageStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_StorageStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).StorageStats(ctx, req.(*StorageStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_PresignDownload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PresignDownloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).PresignDownload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_PresignDownload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).PresignDownload(ctx, req.(*PresignDownloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_QiniuUploadToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QiniuUploadTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).QiniuUploadToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_QiniuUploadToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).QiniuUploadToken(ctx, req.(*QiniuUploadTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_QiniuUploadCallback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QiniuUploadCallbackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).QiniuUploadCallback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_QiniuUploadCallback_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).QiniuUploadCallback(ctx, req.(*QiniuUploadCallbackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_PresignUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PresignUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).PresignUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_PresignUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).PresignUpload(ctx, req.(*PresignUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilesService_ConfirmUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilesServiceServer).ConfirmUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilesService_ConfirmUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilesServiceServer).ConfirmUpload(ctx, req.(*ConfirmUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FilesService_ServiceDesc is the grpc.ServiceDesc for FilesService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FilesService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "FilesService",
+	HandlerType: (*FilesServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "FileUpload",
+			Handler:    _FilesService_FileUpload_Handler,
+		},
+		{
+			MethodName: "FileDelete",
+			Handler:    _FilesService_FileDelete_Handler,
+		},
+		{
+			MethodName: "FileList",
+			Handler:    _FilesService_FileList_Handler,
+		},
+		{
+			MethodName: "FileDownload",
+			Handler:    _FilesService_FileDownload_Handler,
+		},
+		{
+			MethodName: "CheckFileExists",
+			Handler:    _FilesService_CheckFileExists_Handler,
+		},
+		{
+			MethodName: "QiniuFileUpload",
+			Handler:    _FilesService_QiniuFileUpload_Handler,
+		},
+		{
+			MethodName: "QiniuFileDownload",
+			Handler:    _FilesService_QiniuFileDownload_Handler,
+		},
+		{
+			MethodName: "GlobalFileSearch",
+			Handler:    _FilesService_GlobalFileSearch_Handler,
+		},
+		{
+			MethodName: "QiniuFileDelete",
+			Handler:    _FilesService_QiniuFileDelete_Handler,
+		},
+		{
+			MethodName: "FolderCreate",
+			Handler:    _FilesService_FolderCreate_Handler,
+		},
+		{
+			MethodName: "FileRename",
+			Handler:    _FilesService_FileRename_Handler,
+		},
+		{
+			MethodName: "FolderList",
+			Handler:    _FilesService_FolderList_Handler,
+		},
+		{
+			MethodName: "FileMove",
+			Handler:    _FilesService_FileMove_Handler,
+		},
+		{
+			MethodName: "FolderMove",
+			Handler:    _FilesService_FolderMove_Handler,
+		},
+		{
+			MethodName: "FolderDelete",
+			Handler:    _FilesService_FolderDelete_Handler,
+		},
+		{
+			MethodName: "ShareGrant",
+			Handler:    _FilesService_ShareGrant_Handler,
+		},
+		{
+			MethodName: "ShareRevoke",
+			Handler:    _FilesService_ShareRevoke_Handler,
+		},
+		{
+			MethodName: "ShareList",
+			Handler:    _FilesService_ShareList_Handler,
+		},
+		{
+			MethodName: "SharedWithMe",
+			Handler:    _FilesService_SharedWithMe_Handler,
+		},
+		{
+			MethodName: "GroupCreate",
+			Handler:    _FilesService_GroupCreate_Handler,
+		},
+		{
+			MethodName: "GroupAddMember",
+			Handler:    _FilesService_GroupAddMember_Handler,
+		},
+		{
+			MethodName: "GroupRemoveMember",
+			Handler:    _FilesService_GroupRemoveMember_Handler,
+		},
+		{
+			MethodName: "ListChanges",
+			Handler:    _FilesService_ListChanges_Handler,
+		},
+		{
+			MethodName: "StorageStats",
+			Handler:    _FilesService_StorageStats_Handler,
+		},
+		{
+			MethodName: "PresignDownload",
+			Handler:    _FilesService_PresignDownload_Handler,
+		},
+		{
+			MethodName: "QiniuUploadToken",
+			Handler:    _FilesService_QiniuUploadToken_Handler,
+		},
+		{
+			MethodName: "QiniuUploadCallback",
+			Handler:    _FilesService_QiniuUploadCallback_Handler,
+		},
+		{
+			MethodName: "PresignUpload",
+			Handler:    _FilesService_PresignUpload_Handler,
+		},
+		{
+			MethodName: "ConfirmUpload",
+			Handler:    _FilesService_ConfirmUpload_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BigFileUpload",
+			Handler:       _FilesService_BigFileUpload_Handler,
+			ClientStreams: true,
 		},
 		{
 			StreamName:    "QiniuBigFileUpload",
 			Handler:       _FilesService_QiniuBigFileUpload_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "WatchChanges",
+			Handler:       _FilesService_WatchChanges_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "DeltaSignature",
+			Handler:       _FilesService_DeltaSignature_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "DeltaUpload",
+			Handler:       _FilesService_DeltaUpload_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "files.proto",
 }
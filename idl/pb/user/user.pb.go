@@ -291,6 +291,160 @@ func (x *UserCommonResponse) GetData() string {
 	return ""
 }
 
+// 应用专用密码（用于 WebDAV 等无法使用 JWT 的客户端）
+type AppPasswordRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"user_id" form:"user_id"
+	UserID uint64 `protobuf:"varint,1,opt,name=UserID,proto3" json:"user_id" form:"user_id"`
+	// @inject_tag: json:"user_name" form:"user_name"
+	Username string `protobuf:"bytes,2,opt,name=Username,proto3" json:"user_name" form:"user_name"`
+	// @inject_tag: json:"name" form:"name"
+	Name string `protobuf:"bytes,3,opt,name=Name,proto3" json:"name" form:"name"`
+	// @inject_tag: json:"password" form:"password"
+	Password string `protobuf:"bytes,4,opt,name=Password,proto3" json:"password" form:"password"`
+	// @inject_tag: json:"app_password_id" form:"app_password_id"
+	AppPasswordID uint64 `protobuf:"varint,5,opt,name=AppPasswordID,proto3" json:"app_password_id" form:"app_password_id"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppPasswordRequest) Reset() {
+	*x = AppPasswordRequest{}
+	mi := &file_user_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppPasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppPasswordRequest) ProtoMessage() {}
+
+func (x *AppPasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppPasswordRequest.ProtoReflect.Descriptor instead.
+func (*AppPasswordRequest) Descriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AppPasswordRequest) GetUserID() uint64 {
+	if x != nil {
+		return x.UserID
+	}
+	return 0
+}
+
+func (x *AppPasswordRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *AppPasswordRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AppPasswordRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *AppPasswordRequest) GetAppPasswordID() uint64 {
+	if x != nil {
+		return x.AppPasswordID
+	}
+	return 0
+}
+
+type AppPasswordResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @inject_tag: json:"code"
+	Code int64 `protobuf:"varint,1,opt,name=Code,proto3" json:"code"`
+	// @inject_tag: json:"msg"
+	Msg string `protobuf:"bytes,2,opt,name=Msg,proto3" json:"msg"`
+	// @inject_tag: json:"app_password_id"
+	AppPasswordID uint64 `protobuf:"varint,3,opt,name=AppPasswordID,proto3" json:"app_password_id"`
+	// @inject_tag: json:"password"
+	Password      string `protobuf:"bytes,4,opt,name=Password,proto3" json:"password"` // 仅创建时返回一次
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppPasswordResponse) Reset() {
+	*x = AppPasswordResponse{}
+	mi := &file_user_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppPasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppPasswordResponse) ProtoMessage() {}
+
+func (x *AppPasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppPasswordResponse.ProtoReflect.Descriptor instead.
+func (*AppPasswordResponse) Descriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AppPasswordResponse) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *AppPasswordResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *AppPasswordResponse) GetAppPasswordID() uint64 {
+	if x != nil {
+		return x.AppPasswordID
+	}
+	return 0
+}
+
+func (x *AppPasswordResponse) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
 var File_user_proto protoreflect.FileDescriptor
 
 const file_user_proto_rawDesc = "" +
@@ -316,7 +470,18 @@ const file_user_proto_rawDesc = "" +
 	"\x12UserCommonResponse\x12\x12\n" +
 	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
 	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12\x12\n" +
-	"\x04Data\x18\x03 \x01(\tR\x04Data2\x8b\x02\n" +
+	"\x04Data\x18\x03 \x01(\tR\x04Data\"\x9e\x01\n" +
+	"\x12AppPasswordRequest\x12\x16\n" +
+	"\x06UserID\x18\x01 \x01(\x04R\x06UserID\x12\x1a\n" +
+	"\bUsername\x18\x02 \x01(\tR\bUsername\x12\x12\n" +
+	"\x04Name\x18\x03 \x01(\tR\x04Name\x12\x1a\n" +
+	"\bPassword\x18\x04 \x01(\tR\bPassword\x12$\n" +
+	"\rAppPasswordID\x18\x05 \x01(\x04R\rAppPasswordID\"}\n" +
+	"\x13AppPasswordResponse\x12\x12\n" +
+	"\x04Code\x18\x01 \x01(\x03R\x04Code\x12\x10\n" +
+	"\x03Msg\x18\x02 \x01(\tR\x03Msg\x12$\n" +
+	"\rAppPasswordID\x18\x03 \x01(\x04R\rAppPasswordID\x12\x1a\n" +
+	"\bPassword\x18\x04 \x01(\tR\bPassword2\xd4\x03\n" +
 	"\vUserService\x12.\n" +
 	"\tUserLogin\x12\f.UserRequest\x1a\x13.UserDetailResponse\x121\n" +
 	"\fUserRegister\x12\f.UserRequest\x1a\x13.UserCommonResponse\x12/\n" +
@@ -324,7 +489,10 @@ const file_user_proto_rawDesc = "" +
 	"UserLogout\x12\f.UserRequest\x1a\x13.UserCommonResponse\x127\n" +
 	"\x12UserChangePassword\x12\f.UserRequest\x1a\x13.UserCommonResponse\x12/\n" +
 	"\n" +
-	"UserDelete\x12\f.UserRequest\x1a\x13.UserCommonResponseB\aZ\x05user/b\x06proto3"
+	"UserDelete\x12\f.UserRequest\x1a\x13.UserCommonResponse\x12B\n" +
+	"\x15UserAppPasswordCreate\x12\x13.AppPasswordRequest\x1a\x14.AppPasswordResponse\x12A\n" +
+	"\x15UserAppPasswordDelete\x12\x13.AppPasswordRequest\x1a\x13.UserCommonResponse\x12@\n" +
+	"\x14UserAppPasswordCheck\x12\x13.AppPasswordRequest\x1a\x13.UserDetailResponseB\aZ\x05user/b\x06proto3"
 
 var (
 	file_user_proto_rawDescOnce sync.Once
@@ -338,12 +506,14 @@ func file_user_proto_rawDescGZIP() []byte {
 	return file_user_proto_rawDescData
 }
 
-var file_user_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_user_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_user_proto_goTypes = []any{
-	(*UserRequest)(nil),        // 0: UserRequest
-	(*UserResponse)(nil),       // 1: UserResponse
-	(*UserDetailResponse)(nil), // 2: UserDetailResponse
-	(*UserCommonResponse)(nil), // 3: UserCommonResponse
+	(*UserRequest)(nil),         // 0: UserRequest
+	(*UserResponse)(nil),        // 1: UserResponse
+	(*UserDetailResponse)(nil),  // 2: UserDetailResponse
+	(*UserCommonResponse)(nil),  // 3: UserCommonResponse
+	(*AppPasswordRequest)(nil),  // 4: AppPasswordRequest
+	(*AppPasswordResponse)(nil), // 5: AppPasswordResponse
 }
 var file_user_proto_depIdxs = []int32{
 	1, // 0: UserDetailResponse.UserDetail:type_name -> UserResponse
@@ -352,13 +522,19 @@ var file_user_proto_depIdxs = []int32{
 	0, // 3: UserService.UserLogout:input_type -> UserRequest
 	0, // 4: UserService.UserChangePassword:input_type -> UserRequest
 	0, // 5: UserService.UserDelete:input_type -> UserRequest
-	2, // 6: UserService.UserLogin:output_type -> UserDetailResponse
-	3, // 7: UserService.UserRegister:output_type -> UserCommonResponse
-	3, // 8: UserService.UserLogout:output_type -> UserCommonResponse
-	3, // 9: UserService.UserChangePassword:output_type -> UserCommonResponse
-	3, // 10: UserService.UserDelete:output_type -> UserCommonResponse
-	6, // [6:11] is the sub-list for method output_type
-	1, // [1:6] is the sub-list for method input_type
+	4, // 6: UserService.UserAppPasswordCreate:input_type -> AppPasswordRequest
+	4, // 7: UserService.UserAppPasswordDelete:input_type -> AppPasswordRequest
+	4, // 8: UserService.UserAppPasswordCheck:input_type -> AppPasswordRequest
+	2, // 9: UserService.UserLogin:output_type -> UserDetailResponse
+	3, // 10: UserService.UserRegister:output_type -> UserCommonResponse
+	3, // 11: UserService.UserLogout:output_type -> UserCommonResponse
+	3, // 12: UserService.UserChangePassword:output_type -> UserCommonResponse
+	3, // 13: UserService.UserDelete:output_type -> UserCommonResponse
+	5, // 14: UserService.UserAppPasswordCreate:output_type -> AppPasswordResponse
+	3, // 15: UserService.UserAppPasswordDelete:output_type -> UserCommonResponse
+	2, // 16: UserService.UserAppPasswordCheck:output_type -> UserDetailResponse
+	9, // [9:17] is the sub-list for method output_type
+	1, // [1:9] is the sub-list for method input_type
 	1, // [1:1] is the sub-list for extension type_name
 	1, // [1:1] is the sub-list for extension extendee
 	0, // [0:1] is the sub-list for field type_name
@@ -375,7 +551,7 @@ func file_user_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_user_proto_rawDesc), len(file_user_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
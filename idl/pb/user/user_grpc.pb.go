@@ -19,11 +19,14 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	UserService_UserLogin_FullMethodName          = "/UserService/UserLogin"
-	UserService_UserRegister_FullMethodName       = "/UserService/UserRegister"
-	UserService_UserLogout_FullMethodName         = "/UserService/UserLogout"
-	UserService_UserChangePassword_FullMethodName = "/UserService/UserChangePassword"
-	UserService_UserDelete_FullMethodName         = "/UserService/UserDelete"
+	UserService_UserLogin_FullMethodName             = "/UserService/UserLogin"
+	UserService_UserRegister_FullMethodName          = "/UserService/UserRegister"
+	UserService_UserLogout_FullMethodName            = "/UserService/UserLogout"
+	UserService_UserChangePassword_FullMethodName    = "/UserService/UserChangePassword"
+	UserService_UserDelete_FullMethodName            = "/UserService/UserDelete"
+	UserService_UserAppPasswordCreate_FullMethodName = "/UserService/UserAppPasswordCreate"
+	UserService_UserAppPasswordDelete_FullMethodName = "/UserService/UserAppPasswordDelete"
+	UserService_UserAppPasswordCheck_FullMethodName  = "/UserService/UserAppPasswordCheck"
 )
 
 // UserServiceClient is the client API for UserService service.
@@ -35,6 +38,9 @@ type UserServiceClient interface {
 	UserLogout(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*UserCommonResponse, error)
 	UserChangePassword(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*UserCommonResponse, error)
 	UserDelete(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*UserCommonResponse, error)
+	UserAppPasswordCreate(ctx context.Context, in *AppPasswordRequest, opts ...grpc.CallOption) (*AppPasswordResponse, error)
+	UserAppPasswordDelete(ctx context.Context, in *AppPasswordRequest, opts ...grpc.CallOption) (*UserCommonResponse, error)
+	UserAppPasswordCheck(ctx context.Context, in *AppPasswordRequest, opts ...grpc.CallOption) (*UserDetailResponse, error)
 }
 
 type userServiceClient struct {
@@ -95,6 +101,36 @@ func (c *userServiceClient) UserDelete(ctx context.Context, in *UserRequest, opt
 	return out, nil
 }
 
+func (c *userServiceClient) UserAppPasswordCreate(ctx context.Context, in *AppPasswordRequest, opts ...grpc.CallOption) (*AppPasswordResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AppPasswordResponse)
+	err := c.cc.Invoke(ctx, UserService_UserAppPasswordCreate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) UserAppPasswordDelete(ctx context.Context, in *AppPasswordRequest, opts ...grpc.CallOption) (*UserCommonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserCommonResponse)
+	err := c.cc.Invoke(ctx, UserService_UserAppPasswordDelete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) UserAppPasswordCheck(ctx context.Context, in *AppPasswordRequest, opts ...grpc.CallOption) (*UserDetailResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserDetailResponse)
+	err := c.cc.Invoke(ctx, UserService_UserAppPasswordCheck_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserServiceServer is the server API for UserService service.
 // All implementations must embed UnimplementedUserServiceServer
 // for forward compatibility.
@@ -104,6 +140,9 @@ type UserServiceServer interface {
 	UserLogout(context.Context, *UserRequest) (*UserCommonResponse, error)
 	UserChangePassword(context.Context, *UserRequest) (*UserCommonResponse, error)
 	UserDelete(context.Context, *UserRequest) (*UserCommonResponse, error)
+	UserAppPasswordCreate(context.Context, *AppPasswordRequest) (*AppPasswordResponse, error)
+	UserAppPasswordDelete(context.Context, *AppPasswordRequest) (*UserCommonResponse, error)
+	UserAppPasswordCheck(context.Context, *AppPasswordRequest) (*UserDetailResponse, error)
 	mustEmbedUnimplementedUserServiceServer()
 }
 
@@ -129,6 +168,15 @@ func (UnimplementedUserServiceServer) UserChangePassword(context.Context, *UserR
 func (UnimplementedUserServiceServer) UserDelete(context.Context, *UserRequest) (*UserCommonResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UserDelete not implemented")
 }
+func (UnimplementedUserServiceServer) UserAppPasswordCreate(context.Context, *AppPasswordRequest) (*AppPasswordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserAppPasswordCreate not implemented")
+}
+func (UnimplementedUserServiceServer) UserAppPasswordDelete(context.Context, *AppPasswordRequest) (*UserCommonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserAppPasswordDelete not implemented")
+}
+func (UnimplementedUserServiceServer) UserAppPasswordCheck(context.Context, *AppPasswordRequest) (*UserDetailResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UserAppPasswordCheck not implemented")
+}
 func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
 func (UnimplementedUserServiceServer) testEmbeddedByValue()                     {}
 
@@ -240,6 +288,60 @@ func _UserService_UserDelete_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_UserAppPasswordCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).UserAppPasswordCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_UserAppPasswordCreate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).UserAppPasswordCreate(ctx, req.(*AppPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_UserAppPasswordDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).UserAppPasswordDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_UserAppPasswordDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).UserAppPasswordDelete(ctx, req.(*AppPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_UserAppPasswordCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).UserAppPasswordCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_UserAppPasswordCheck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).UserAppPasswordCheck(ctx, req.(*AppPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UserService_ServiceDesc is the grpc.ServiceDesc for UserService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -267,6 +369,18 @@ var UserService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UserDelete",
 			Handler:    _UserService_UserDelete_Handler,
 		},
+		{
+			MethodName: "UserAppPasswordCreate",
+			Handler:    _UserService_UserAppPasswordCreate_Handler,
+		},
+		{
+			MethodName: "UserAppPasswordDelete",
+			Handler:    _UserService_UserAppPasswordDelete_Handler,
+		},
+		{
+			MethodName: "UserAppPasswordCheck",
+			Handler:    _UserService_UserAppPasswordCheck_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "user.proto",
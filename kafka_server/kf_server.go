@@ -1,34 +1,31 @@
 package main
 
 import (
+	"context"
 	"grpc-todolist-disk/conf"
 	"grpc-todolist-disk/kafka_server/service"
 	"log"
-	"sync"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
 	conf.InitConfig()
+	// kf_server dlq ...：查看和重放死信
+	if len(os.Args) > 1 && os.Args[1] == "dlq" {
+		if err := service.DeadLetterCommand(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	service.Init()
 
-	var wg sync.WaitGroup
-	wg.Add(3)
-
-	go func() {
-		defer wg.Done()
-		service.MsgConsumer()
-	}()
-
-	go func() {
-		defer wg.Done()
-		service.FileMsgConsumer()
-	}()
-
-	go func() {
-		defer wg.Done()
-		service.TaskWorker()
-	}()
-
+	// 收到退出信号后停止读取消息，等待执行中的任务完成
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 	log.Println("kafka consumer running")
-	wg.Wait()
+	service.Run(ctx)
+	service.Close()
+	log.Println("kafka_server exiting")
 }
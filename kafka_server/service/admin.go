@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"grpc-todolist-disk/conf"
+	"grpc-todolist-disk/utils/delayqueue"
+	"grpc-todolist-disk/utils/kafka_mq"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultAdminLimit = 50
+
+// StartAdmin 配置了 jobs.admin.addr 时启动管理接口，ctx 结束时关闭
+func StartAdmin(ctx context.Context) error {
+	if conf.Conf.Jobs == nil || conf.Conf.Jobs.Admin == nil || conf.Conf.Jobs.Admin.Addr == "" {
+		return nil
+	}
+	c := conf.Conf.Jobs.Admin
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/jobs", adminJobs)
+	mux.HandleFunc("GET /admin/jobs/{name}/tasks", adminTasks)
+	mux.HandleFunc("DELETE /admin/jobs/{name}/tasks/{id}", adminCancelTask)
+	mux.HandleFunc("POST /admin/jobs/{name}/pause", adminPause)
+	mux.HandleFunc("POST /admin/jobs/{name}/resume", adminResume)
+	mux.HandleFunc("GET /admin/consumers", adminConsumers)
+	mux.HandleFunc("GET /admin/failures", adminFailures)
+	mux.HandleFunc("POST /admin/deadletters/replay", adminReplay)
+	mux.HandleFunc("GET /admin/cron", adminCron)
+	server := &http.Server{
+		Addr:         c.Addr,
+		Handler:      adminAuth(c.Token, mux),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: time.Minute, // 重放死信需要读取整个死信主题
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("admin server exited: %v\n", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	log.Printf("admin listen on: %s\n", c.Addr)
+	return nil
+}
+
+// adminAuth 校验 Authorization: Bearer <token>，未配置 token 时拒绝所有请求
+func adminAuth(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			writeAdmin(w, http.StatusUnauthorized, "unauthorized", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeAdmin(w http.ResponseWriter, status int, msg string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": status, "msg": msg, "data": data})
+}
+
+func writeAdminError(w http.ResponseWriter, err error) {
+	writeAdmin(w, http.StatusInternalServerError, err.Error(), nil)
+}
+
+func adminLimit(r *http.Request) int64 {
+	n, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
+	if err != nil || n <= 0 {
+		return defaultAdminLimit
+	}
+	return min(n, 1000)
+}
+
+// jobStatus 任务类型的配置和队列状态
+type jobStatus struct {
+	Name        string `json:"name"`
+	Version     int    `json:"version"`
+	Concurrency int    `json:"concurrency"`
+	Timeout     string `json:"timeout"`
+	MaxAttempts int    `json:"max_attempts"`
+	Paused      bool   `json:"paused"`
+	*delayqueue.Stats
+}
+
+// adminJobs GET /admin/jobs 各任务类型的待执行、延迟、执行中和等待中的任务数
+func adminJobs(w http.ResponseWriter, r *http.Request) {
+	registryLocker.RLock()
+	types := make([]*jobType, 0, len(registry))
+	for _, jt := range registry {
+		types = append(types, jt)
+	}
+	registryLocker.RUnlock()
+	sort.Slice(types, func(i, j int) bool { return types[i].name < types[j].name })
+
+	list := make([]*jobStatus, 0, len(types))
+	for _, jt := range types {
+		stats, err := jt.queue.Stats(r.Context())
+		if err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		list = append(list, &jobStatus{
+			Name:        jt.name,
+			Version:     jt.version,
+			Concurrency: jt.concurrency,
+			Timeout:     jt.timeout.String(),
+			MaxAttempts: jt.retry.maxAttempts,
+			Paused:      paused(r.Context(), jt.name),
+			Stats:       stats,
+		})
+	}
+	writeAdmin(w, http.StatusOK, "ok", list)
+}
+
+func adminJobType(w http.ResponseWriter, r *http.Request) *jobType {
+	jt := lookup(r.PathValue("name"))
+	if jt == nil {
+		writeAdmin(w, http.StatusNotFound, ErrUnknownJob.Error(), nil)
+	}
+	return jt
+}
+
+// adminTasks GET /admin/jobs/{name}/tasks?state=pending|inflight&limit=50 列出待执行（按执行时间）或执行中（按租约到期时间）的任务
+func adminTasks(w http.ResponseWriter, r *http.Request) {
+	jt := adminJobType(w, r)
+	if jt == nil {
+		return
+	}
+	var entries []*delayqueue.Entry
+	var err error
+	switch r.URL.Query().Get("state") {
+	case "", "pending":
+		entries, err = jt.queue.Pending(r.Context(), adminLimit(r))
+	case "inflight":
+		entries, err = jt.queue.InFlight(r.Context(), adminLimit(r))
+	default:
+		writeAdmin(w, http.StatusBadRequest, "state 只能是 pending 或 inflight", nil)
+		return
+	}
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeAdmin(w, http.StatusOK, "ok", entries)
+}
+
+// adminCancelTask DELETE /admin/jobs/{name}/tasks/{id} 取消尚未执行的任务
+func adminCancelTask(w http.ResponseWriter, r *http.Request) {
+	jt := adminJobType(w, r)
+	if jt == nil {
+		return
+	}
+	ok, err := jt.queue.Cancel(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	if !ok {
+		writeAdmin(w, http.StatusConflict, "任务不存在或正在执行", nil)
+		return
+	}
+	log.Printf("Task %s (%s) cancelled\n", r.PathValue("id"), jt.name)
+	writeAdmin(w, http.StatusOK, "ok", nil)
+}
+
+// adminPause POST /admin/jobs/{name}/pause
+func adminPause(w http.ResponseWriter, r *http.Request) {
+	if jt := adminJobType(w, r); jt == nil {
+		return
+	}
+	if err := Pause(r.Context(), r.PathValue("name")); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	log.Printf("job %s paused\n", r.PathValue("name"))
+	writeAdmin(w, http.StatusOK, "ok", nil)
+}
+
+// adminResume POST /admin/jobs/{name}/resume
+func adminResume(w http.ResponseWriter, r *http.Request) {
+	if jt := adminJobType(w, r); jt == nil {
+		return
+	}
+	if err := Resume(r.Context(), r.PathValue("name")); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	log.Printf("job %s resumed\n", r.PathValue("name"))
+	writeAdmin(w, http.StatusOK, "ok", nil)
+}
+
+// consumerLag 消费的主题在各分区上的积压
+type consumerLag struct {
+	Topic      string                  `json:"topic"`
+	GroupID    string                  `json:"group_id"`
+	Lag        int64                   `json:"lag"`
+	Partitions []kafka_mq.PartitionLag `json:"partitions"`
+	Error      string                  `json:"error,omitempty"`
+}
+
+// adminConsumers GET /admin/consumers
+func adminConsumers(w http.ResponseWriter, r *http.Request) {
+	var list []*consumerLag
+	for _, c := range consumers() {
+		item := &consumerLag{Topic: c.Topic, GroupID: c.GroupID}
+		partitions, err := kafka_mq.Lag(r.Context(), c.Topic, c.GroupID)
+		if err != nil {
+			item.Error = err.Error()
+		}
+		for _, p := range partitions {
+			item.Lag += p.Lag
+		}
+		item.Partitions = partitions
+		list = append(list, item)
+	}
+	writeAdmin(w, http.StatusOK, "ok", list)
+}
+
+// adminFailures GET /admin/failures?limit=50 最近的失败记录，包括之后重试成功的
+func adminFailures(w http.ResponseWriter, r *http.Request) {
+	failures, err := Failures(r.Context(), adminLimit(r))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeAdmin(w, http.StatusOK, "ok", failures)
+}
+
+// adminReplay POST /admin/deadletters/replay?task=<任务 ID>[,<任务 ID>...] 把死信中的任务重新发送到原主题
+func adminReplay(w http.ResponseWriter, r *http.Request) {
+	wanted := map[string]bool{}
+	for _, id := range splitComma(r.URL.Query().Get("task")) {
+		wanted[id] = true
+	}
+	if len(wanted) == 0 {
+		writeAdmin(w, http.StatusBadRequest, "需要指定 task", nil)
+		return
+	}
+	var replayed []string
+	err := readDeadLetters(r.Context(), func(dl *kafka_mq.DeadLetter, pos string) error {
+		if !wanted[dl.TaskID] {
+			return nil
+		}
+		if err := replay(r.Context(), dl); err != nil {
+			return err
+		}
+		replayed = append(replayed, dl.TaskID)
+		return nil
+	})
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	log.Printf("Replayed dead letters: %v\n", replayed)
+	writeAdmin(w, http.StatusOK, "ok", replayed)
+}
+
+// adminCron GET /admin/cron 定时任务最近一次的触发和执行结果
+func adminCron(w http.ResponseWriter, r *http.Request) {
+	runs, err := CronRuns(r.Context())
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeAdmin(w, http.StatusOK, "ok", runs)
+}
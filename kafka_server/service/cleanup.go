@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"grpc-todolist-disk/utils/uploadjob"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// JobCleanupTemp 清理临时目录中过期的文件，由定时任务触发
+const JobCleanupTemp = "cleanup_temp"
+
+// CleanupTempMsg 清理任务的参数
+type CleanupTempMsg struct {
+	Dir    string `json:"dir"`     // 为空时清理异步上传的暂存目录，只清理这一层的文件
+	MaxAge int64  `json:"max_age"` // 修改时间早于多少秒前的文件会被删除，默认 1 天
+}
+
+func cleanupTemp(ctx context.Context, job *Job) error {
+	var m CleanupTempMsg
+	if err := json.Unmarshal(job.Payload, &m); err != nil {
+		return Permanent(fmt.Errorf("解析清理任务失败: %w", err))
+	}
+	if m.Dir == "" {
+		m.Dir = uploadjob.StagingDir
+	}
+	maxAge := time.Duration(m.MaxAge) * time.Second
+	if maxAge <= 0 {
+		maxAge = 24 * time.Hour
+	}
+
+	entries, err := os.ReadDir(m.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(-maxAge)
+	removed := 0
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(deadline) {
+			continue
+		}
+		if err = os.Remove(filepath.Join(m.Dir, entry.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("删除临时文件失败 %s: %v", entry.Name(), err)
+			continue
+		}
+		removed++
+	}
+	log.Printf("清理临时目录 %s：删除 %d 个文件", m.Dir, removed)
+	return nil
+}
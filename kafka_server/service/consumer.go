@@ -1,160 +1,188 @@
 package service
 
 import (
-	"container/heap"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/segmentio/kafka-go"
 	"grpc-todolist-disk/conf"
+	"grpc-todolist-disk/utils/delayqueue"
+	"grpc-todolist-disk/utils/kafka_mq"
+	"hash/fnv"
 	"log"
+	"sync"
 	"time"
 )
 
-var MsgSignal chan int
-
-// MsgConsumer Kafka 消费协程，从 Kafka 中消费消息 → 解析 → 转为延时任务 → 推入堆中 → 通知执行器
-func MsgConsumer() {
-	for {
-		// 读取下一条消息
-		ctx := context.Background()
-		msg, err := KfReader.ReadMessage(ctx)
-		if err != nil {
-			log.Printf("Error reading message: %s\n", err)
-			break
-		}
+// consumers 读取配置 jobs.consumers，未配置时沿用 kafka.topic 与 kafka.groupID 的前两项
+func consumers() []*conf.JobConsumer {
+	if conf.Conf.Jobs != nil && len(conf.Conf.Jobs.Consumers) > 0 {
+		return conf.Conf.Jobs.Consumers
+	}
+	k := conf.Conf.Kafka
+	defaults := []string{kafka_mq.JobClearCache, kafka_mq.JobFileUpload}
+	var list []*conf.JobConsumer
+	for i := 0; i < len(defaults) && i < len(k.Topic) && i < len(k.GroupId); i++ {
+		list = append(list, &conf.JobConsumer{Topic: k.Topic[i], GroupID: k.GroupId[i], DefaultJob: defaults[i]})
+	}
+	return list
+}
 
-		// 解析消息
-		var m Message
-		if err := json.Unmarshal(msg.Value, &m); err != nil {
-			log.Printf("Error unmarshalling message: %s\n", err)
-			continue
-		}
+// Run 启动所有任务类型的执行协程、定时任务和 kafka 消费协程，ctx 结束后停止读取消息和领取任务，
+// 等待执行中的任务和已读取的消息处理完（最长 jobs.shutdownTimeout），超时的任务放回队列，提交已完成的 offset 并关闭消费者后返回
+func Run(ctx context.Context) {
+	// runCtx 在等待超时后才取消，执行中的任务和已读取的消息在此之前可以正常完成
+	runCtx, cancelRun := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRun()
+
+	var wg sync.WaitGroup
+	startWorkers(ctx, runCtx, &wg)
+	startCron(ctx)
+	if err := StartAdmin(ctx); err != nil {
+		panic(err)
+	}
+	for _, c := range consumers() {
+		wg.Add(1)
+		go func(c *conf.JobConsumer) {
+			defer wg.Done()
+			reader := kafka_mq.NewConsumer(c.Topic, c.GroupID)
+			defer reader.Close()
+			consume(ctx, runCtx, reader, c)
+		}(c)
+		log.Printf("consuming topic %s (group %s)\n", c.Topic, c.GroupID)
+	}
 
-		// 创建一个延时任务，并放入堆中
-		task := &DelayedTask{
-			Type:      TaskTypeClearCache,
-			Name:      m.Name,
-			Timestamp: m.Timestamp,
-			Msg:       &msg,
-		}
-		heap.Push(&TaskHeap, task)
-		MsgSignal <- 1
+	<-ctx.Done()
+	timeout := shutdownTimeout()
+	log.Printf("kafka_server shutting down, waiting up to %s for running tasks\n", timeout)
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return
+	case <-time.After(timeout):
+	}
+	// 取消执行中的任务，处理函数返回后任务放回队列
+	log.Println("shutdown timeout, cancelling running tasks")
+	cancelRun()
+	select {
+	case <-done:
+	case <-time.After(releaseGrace):
+		log.Println("some tasks did not stop in time, their leases will expire")
+	}
+}
 
-		// 打印时间戳
-		log.Printf("Message timestamp: %d\n", m.Timestamp)
+func shutdownTimeout() time.Duration {
+	if conf.Conf.Jobs != nil && conf.Conf.Jobs.ShutdownTimeout > 0 {
+		return time.Duration(conf.Conf.Jobs.ShutdownTimeout) * time.Second
 	}
+	return defaultShutdownTimeout
 }
 
-// FileMsgConsumer 文件消费协程（表单）
-func FileMsgConsumer() {
+// consume Kafka 消费协程：读取消息 → 按消息键分配给调度协程 → 解析任务信封写入任务类型的延时队列 → 提交 offset。
+// 键相同的消息由同一个调度协程按顺序写入队列，队列再按键依次执行；每个分区只提交连续处理完成的 offset。
+// 实例在写入队列和提交之间崩溃时消息会被重新消费，按相同的任务 ID 覆盖，不会重复调度。
+// ctx 结束后停止读取，已读取的消息用 runCtx 写入队列并提交
+func consume(ctx, runCtx context.Context, reader kafka_mq.Subscriber, c *conf.JobConsumer) {
+	workers := c.Workers
+	if workers <= 0 {
+		workers = defaultConsumerWorkers
+	}
+	tracker := newOffsetTracker()
+	var commitLocker sync.Mutex
+	lanes := make([]chan kafka.Message, workers)
+	var wg sync.WaitGroup
+	for i := range lanes {
+		lanes[i] = make(chan kafka.Message, laneBuffer)
+		wg.Add(1)
+		go func(lane chan kafka.Message) {
+			defer wg.Done()
+			for msg := range lane {
+				if !scheduleMessage(runCtx, &msg, c.DefaultJob) {
+					continue
+				}
+				// 按顺序提交，避免较小的 offset 覆盖已提交的较大 offset
+				commitLocker.Lock()
+				if commit, ok := tracker.done(msg); ok {
+					if err := reader.CommitMessages(runCtx, commit); err != nil {
+						log.Printf("Failed to commit msg: %s\n", err)
+					}
+				}
+				commitLocker.Unlock()
+			}
+		}(lanes[i])
+	}
+
 	for {
 		// 读取下一条消息
-		ctx := context.Background()
-		msg, err := KfFileReader.ReadMessage(ctx)
+		msg, err := reader.FetchMessage(ctx)
 		if err != nil {
-			log.Printf("Error reading message: %s\n", err)
+			if ctx.Err() == nil {
+				log.Printf("Error reading message: %s\n", err)
+			}
 			break
 		}
-
-		// 解析消息
-		var m Message
-		if err := json.Unmarshal(msg.Value, &m); err != nil {
-			log.Printf("Error unmarshalling message: %s\n", err)
-			continue
-		}
-
-		// 创建一个延时任务，并放入堆中
-		task := &DelayedTask{
-			Type:      TaskTypeFileUpload,
-			Name:      m.Name,
-			Timestamp: time.Now().Unix(), // 或指定延迟
-			Msg:       &msg,
-		}
-		heap.Push(&TaskHeap, task)
-		MsgSignal <- 1
-
-		// 打印时间戳
-		log.Printf("Message timestamp: %d\n", m.Timestamp)
+		tracker.fetched(msg)
+		lanes[laneOf(&msg, workers)] <- msg
 	}
+	for _, lane := range lanes {
+		close(lane)
+	}
+	wg.Wait()
 }
 
-// TaskWorker 任务调度器，定时 + 被动监听 MsgSignal，执行堆中任务（即调用 task()）
-func TaskWorker() {
-	ticker := time.NewTicker(time.Second * 5) // 5秒一次
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ticker.C:
-			task()
-		case <-MsgSignal:
-			task()
-		}
+// laneOf 带键的消息按键分配调度协程，不带键的按 offset 轮流分配
+func laneOf(msg *kafka.Message, n int) int {
+	if len(msg.Key) == 0 {
+		return int(msg.Offset % int64(n))
 	}
+	h := fnv.New32a()
+	h.Write(msg.Key)
+	return int(h.Sum32() % uint32(n))
 }
 
-/*
-1、从堆顶取出任务（最早时间戳）
-
-2、当前时间尚未到：放回堆中，等待下次执行
-
-3、时间已到：执行 ClearNameRedisCache，成功则提交 Kafka offset，失败则时间延迟 1 秒后重新入堆（重试）
-*/
-func task() {
-	// 处理堆中的任务
-	for TaskHeap.Len() > 0 {
-		task := heap.Pop(&TaskHeap).(*DelayedTask)
-
-		if time.Now().Unix() < task.Timestamp {
-			heap.Push(&TaskHeap, task)
-			MsgSignal <- 1
-			log.Println("Not To Time")
-			continue
+// scheduleMessage 把消息写入任务队列，无法处理的消息写入死信。返回 false 表示 ctx 已结束、消息未处理
+func scheduleMessage(ctx context.Context, msg *kafka.Message, defaultJob string) bool {
+	env, ok := kafka_mq.ParseEnvelope(msg.Value)
+	if !ok {
+		// 旧格式的消息整体作为任务内容
+		env = &kafka_mq.Envelope{Job: defaultJob, Version: 1, Payload: msg.Value}
+	}
+	id := fmt.Sprintf("%s-%d-%d", msg.Topic, msg.Partition, msg.Offset)
+	var err error
+	for {
+		err = Schedule(ctx, id, msg.Topic, string(msg.Key), env)
+		if err == nil || errors.Is(err, ErrUnknownJob) || errors.Is(err, ErrJobVersion) {
+			break
 		}
-
-		ctx := context.Background()
-
-		switch task.Type {
-		case TaskTypeClearCache:
-			// 执行清除缓存操作
-			ok := ClearNameRedisCache(task.Name)
-			if !ok {
-				// 删除失败，延迟一秒重新推入队列
-				task.Timestamp = time.Now().Add(1 * time.Second).UnixNano()
-				heap.Push(&TaskHeap, task)
-				log.Printf("Failed to clear cache for %s, retrying in 1 second\n", task.Name)
-				continue
-			}
-		case TaskTypeFileUpload:
-			if err := HandleAsyncFileUpload(task.Msg); err != nil {
-				task.Timestamp = time.Now().Add(1 * time.Second).UnixNano()
-				heap.Push(&TaskHeap, task)
-				log.Printf("Failed to upload file %s, retrying: %v", task.Name, err)
-				continue
-			}
-			log.Printf("Uploaded file: %s", task.Name)
-		default:
-			panic("unhandled default case")
-
+		if ctx.Err() != nil {
+			return false
 		}
-
-		if task.Msg != nil {
-			if err := commitMsg(ctx, task.Msg); err != nil {
-				log.Printf("Failed to commit msg: %s\n", err)
-				continue
+		log.Printf("Failed to schedule task %s: %v, retrying\n", id, err)
+		time.Sleep(time.Second)
+	}
+	if err != nil {
+		// 未注册或版本过高的任务无法处理，写入死信，注册处理函数后可以重放。写入成功后才提交 offset
+		log.Printf("Skip message %s: %v\n", id, err)
+		task := &delayqueue.Task{ID: id, Type: env.Job, Version: env.Version, Payload: env.Payload, Source: msg.Topic, Key: string(msg.Key),
+			IdempotencyKey: env.IdempotencyKey}
+		task.Errors = appendError(nil, err)
+		for {
+			dlErr := deadLetter(ctx, task)
+			if dlErr == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return false
 			}
+			log.Printf("Failed to dead-letter message %s: %v, retrying\n", id, dlErr)
+			time.Sleep(time.Second)
 		}
-		log.Printf("Cleared cache for %s at timestamp: %d\n", task.Name, task.Timestamp)
-	}
-}
-
-func commitMsg(ctx context.Context, msg *kafka.Message) error {
-	switch msg.Topic {
-	case conf.Conf.Kafka.Topic[0]:
-		return KfReader.CommitMessages(ctx, *msg)
-	case conf.Conf.Kafka.Topic[1]:
-		return KfFileReader.CommitMessages(ctx, *msg)
-	default:
-		return fmt.Errorf("unknown topic: %s", msg.Topic)
+	} else {
+		log.Printf("Scheduled task %s (%s)\n", id, env.Job)
 	}
+	return true
 }
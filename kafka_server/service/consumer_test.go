@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"grpc-todolist-disk/app/gateway/utils/mq"
+	"grpc-todolist-disk/conf"
+	"grpc-todolist-disk/utils/kafka_mq"
+)
+
+// useMemory 使用进程内的消息队列，网关的生产者和 kafka_server 的消费者在同一进程中通信。
+// 每个测试使用不同的主题，任务 ID（主题-分区-offset）不会与之前的运行重复
+func useMemory(t *testing.T) *conf.JobConsumer {
+	t.Helper()
+	topic := fmt.Sprintf("file_cache_%d", time.Now().UnixNano())
+	c := &conf.JobConsumer{Topic: topic, GroupID: "file_group", DefaultJob: kafka_mq.JobFileUpload, Workers: 2}
+	old := conf.Conf
+	conf.Conf = &conf.Config{
+		Kafka: &conf.Kafka{Driver: kafka_mq.DriverMemory, Topic: []string{"user_cache", topic}},
+		Jobs:  &conf.Jobs{Consumers: []*conf.JobConsumer{c}, DeadLetterTopic: topic + "_dlq"},
+	}
+	t.Cleanup(func() { conf.Conf = old })
+	mq.Init()
+	t.Cleanup(func() { mq.KfWriter.Close() })
+	return c
+}
+
+// testRedis 连接 JOBS_TEST_REDIS 指定的 redis（地址），未设置时跳过
+func testRedis(t *testing.T) {
+	t.Helper()
+	addr := os.Getenv("JOBS_TEST_REDIS")
+	if addr == "" {
+		t.Skip("未设置 JOBS_TEST_REDIS，跳过需要 redis 的测试")
+	}
+	old := RDB
+	RDB = redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() {
+		RDB.Close()
+		RDB = old
+	})
+}
+
+// runConsumer 启动消费协程，测试结束时停止并等待已读取的消息处理完
+func runConsumer(t *testing.T, c *conf.JobConsumer) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reader := kafka_mq.NewConsumer(c.Topic, c.GroupID)
+		defer reader.Close()
+		consume(ctx, ctx, reader, c)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func uploadMsg() *mq.AsyncFileUploadMsg {
+	return &mq.AsyncFileUploadMsg{
+		JobID: "job-1", UserID: 7, Filename: "a.txt", FileSize: 5, FileHash: "hash-a",
+		ObjectName: "7/a.txt", TempPath: "stores/uploaded_temp/async/a.txt", FolderID: 3,
+	}
+}
+
+// checkUploadMsg 网关发送的消息能被 kafka_server 的消息结构完整解析
+func checkUploadMsg(t *testing.T, payload []byte) {
+	t.Helper()
+	var m AsyncFileUploadMsg
+	if err := json.Unmarshal(payload, &m); err != nil {
+		t.Fatal(err)
+	}
+	want := uploadMsg()
+	if m.JobID != want.JobID || m.UserID != want.UserID || m.Filename != want.Filename || m.FileSize != want.FileSize ||
+		m.FileHash != want.FileHash || m.ObjectName != want.ObjectName || m.TempPath != want.TempPath || m.FolderID != want.FolderID {
+		t.Fatalf("payload %+v, want %+v", m, want)
+	}
+}
+
+// waitFor 等待 cond 成立，最长 5 秒
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestGatewayUnknownJobDeadLetter 网关发送的任务在 kafka_server 未注册时写入死信主题并提交 offset，不会阻塞后续消息
+func TestGatewayUnknownJobDeadLetter(t *testing.T) {
+	c := useMemory(t)
+	runConsumer(t, c)
+
+	if err := mq.SendFileUploadTask(uploadMsg()); err != nil {
+		t.Fatal(err)
+	}
+	var dl *kafka_mq.DeadLetter
+	waitFor(t, "dead letter", func() bool {
+		readDeadLetters(context.Background(), func(d *kafka_mq.DeadLetter, pos string) error {
+			dl = d
+			return nil
+		})
+		return dl != nil
+	})
+	if dl.Job != kafka_mq.JobFileUpload || dl.Topic != c.Topic || dl.Key != "hash-a" || len(dl.Errors) != 1 {
+		t.Fatalf("dead letter %+v", dl)
+	}
+	checkUploadMsg(t, dl.Payload)
+	waitFor(t, "commit", func() bool {
+		lags, err := kafka_mq.Lag(context.Background(), c.Topic, c.GroupID)
+		return err == nil && lags[0].Lag == 0
+	})
+}
+
+// TestGatewayRoundTrip 网关发送异步上传任务 → kafka_server 消费并写入延时队列 → 执行协程调用处理函数。
+// 延时队列需要 redis
+func TestGatewayRoundTrip(t *testing.T) {
+	testRedis(t)
+	c := useMemory(t)
+
+	// 第一次执行失败，重试后成功
+	conf.Conf.Jobs.Types = map[string]*conf.JobType{kafka_mq.JobFileUpload: {BackoffBase: 1, BackoffMax: 1}}
+	var (
+		mu       sync.Mutex
+		attempts int
+		payload  []byte
+	)
+	if err := Register(kafka_mq.JobFileUpload, 1, func(ctx context.Context, job *Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			return errors.New("temporary failure")
+		}
+		payload = job.Payload
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		registryLocker.Lock()
+		delete(registry, kafka_mq.JobFileUpload)
+		registryLocker.Unlock()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	startWorkers(ctx, ctx, &wg)
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	runConsumer(t, c)
+
+	if err := mq.SendFileUploadTask(uploadMsg()); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "job", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return payload != nil
+	})
+	checkUploadMsg(t, payload)
+	lags, err := kafka_mq.Lag(context.Background(), c.Topic, c.GroupID)
+	if err != nil || lags[0].Lag != 0 {
+		t.Fatalf("lag %+v err %v", lags, err)
+	}
+}
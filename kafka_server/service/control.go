@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+const (
+	// pausedKey 已暂停的任务类型集合，所有实例共享
+	pausedKey = "kafka_server:paused"
+	// failuresKey 最近失败的任务记录
+	failuresKey = "kafka_server:failures"
+	// maxFailures 保留的失败记录条数
+	maxFailures = 200
+)
+
+// Pause 暂停任务类型，所有实例不再领取该类型的任务，正在执行的任务不受影响
+func Pause(ctx context.Context, name string) error {
+	if lookup(name) == nil {
+		return ErrUnknownJob
+	}
+	return RDB.SAdd(ctx, pausedKey, name).Err()
+}
+
+// Resume 恢复任务类型
+func Resume(ctx context.Context, name string) error {
+	if lookup(name) == nil {
+		return ErrUnknownJob
+	}
+	if err := RDB.SRem(ctx, pausedKey, name).Err(); err != nil {
+		return err
+	}
+	lookup(name).notify()
+	return nil
+}
+
+// paused 查询失败时按未暂停处理
+func paused(ctx context.Context, name string) bool {
+	ok, err := RDB.SIsMember(ctx, pausedKey, name).Result()
+	return err == nil && ok
+}
+
+// Failure 任务的一次失败
+type Failure struct {
+	TaskID   string `json:"task_id"`
+	Job      string `json:"job"`
+	Attempts int    `json:"attempts"` // 包括本次的失败次数
+	Error    string `json:"error"`
+	Dead     bool   `json:"dead"` // 不再重试，已写入死信
+	At       int64  `json:"at"`
+}
+
+// recordFailure 记录失败，只保留最近 maxFailures 条
+func recordFailure(ctx context.Context, task *Failure) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return
+	}
+	pipe := RDB.Pipeline()
+	pipe.LPush(ctx, failuresKey, data)
+	pipe.LTrim(ctx, failuresKey, 0, maxFailures-1)
+	if _, err = pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to record failure of task %s: %v\n", task.TaskID, err)
+	}
+}
+
+// Failures 最近的 limit 条失败记录，最新的在前
+func Failures(ctx context.Context, limit int64) ([]*Failure, error) {
+	list, err := RDB.LRange(ctx, failuresKey, 0, limit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	failures := make([]*Failure, 0, len(list))
+	for _, data := range list {
+		var f Failure
+		if json.Unmarshal([]byte(data), &f) == nil {
+			failures = append(failures, &f)
+		}
+	}
+	return failures, nil
+}
+
+func newFailure(taskID, job string, attempts int, err error, dead bool) *Failure {
+	return &Failure{TaskID: taskID, Job: job, Attempts: attempts, Error: err.Error(), Dead: dead, At: time.Now().Unix()}
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/go-redsync/redsync/v4"
+	"grpc-todolist-disk/conf"
+	"grpc-todolist-disk/utils/cron"
+	"grpc-todolist-disk/utils/kafka_mq"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// cronRunsKey 定时任务最近一次执行记录的哈希，字段为定时任务名
+	cronRunsKey = "kafka_server:cron:runs"
+	// cronLockPrefix 每次触发的锁，后接定时任务名和计划时间，只有拿到锁的实例写入任务
+	cronLockPrefix = "kafka_server:cron:lock:"
+	// cronLockExpiry 写入任务后锁不主动释放，过期时间需要大于实例之间的时钟误差
+	cronLockExpiry = 10 * time.Minute
+	// cronTaskPrefix 定时任务产生的任务 ID 前缀，后接定时任务名和计划时间
+	cronTaskPrefix = "cron-"
+	// cronScheduleTries 触发时写入任务队列的最多尝试次数
+	cronScheduleTries = 3
+)
+
+// 定时任务的执行状态
+const (
+	CronScheduled = "scheduled" // 已写入任务队列
+	CronRetrying  = "retrying"  // 执行失败，等待重试
+	CronSucceeded = "succeeded"
+	CronFailed    = "failed" // 写入任务队列失败，或重试耗尽、不可重试，已写入死信
+)
+
+var RedSync *redsync.Redsync
+
+// CronRun 定时任务最近一次触发和执行的结果
+type CronRun struct {
+	Name       string `json:"name"`
+	Spec       string `json:"spec"`
+	Job        string `json:"job"`
+	TaskID     string `json:"task_id"`
+	FiredAt    int64  `json:"fired_at"` // 计划触发时间
+	State      string `json:"state"`
+	Attempts   int    `json:"attempts,omitempty"`
+	Error      string `json:"error,omitempty"`
+	FinishedAt int64  `json:"finished_at,omitempty"`
+}
+
+// startCron 为 jobs.schedules 中的每个定时任务启动触发协程，配置错误时 panic
+func startCron(ctx context.Context) {
+	if conf.Conf.Jobs == nil {
+		return
+	}
+	for _, s := range conf.Conf.Jobs.Schedules {
+		sched, err := cron.Parse(s.Spec)
+		if err != nil {
+			panic(fmt.Errorf("定时任务 %s: %w", s.Name, err))
+		}
+		if lookup(s.Job) == nil {
+			panic(fmt.Errorf("定时任务 %s: %w: %s", s.Name, ErrUnknownJob, s.Job))
+		}
+		if strings.Contains(s.Name, "-") {
+			panic(fmt.Errorf("定时任务名 %s 不能包含 -", s.Name))
+		}
+		if s.Payload != "" && !json.Valid([]byte(s.Payload)) {
+			panic(fmt.Errorf("定时任务 %s: payload 不是有效的 JSON: %s", s.Name, s.Payload))
+		}
+		go runSchedule(ctx, s, sched)
+		log.Printf("cron %s: %s -> %s\n", s.Name, s.Spec, s.Job)
+	}
+}
+
+// runSchedule 等到下一次触发时间写入任务，实例停止期间错过的触发不会补上
+func runSchedule(ctx context.Context, s *conf.JobSchedule, sched cron.Schedule) {
+	for {
+		next := sched.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("cron %s: no next run for %q\n", s.Name, s.Spec)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := fire(ctx, s, next); err != nil {
+			log.Printf("cron %s: %v\n", s.Name, err)
+		}
+	}
+}
+
+// fire 拿到本次触发的锁后写入任务队列。任务 ID 和幂等键由定时任务名和计划时间组成，即使多个实例都写入也只执行一次。
+// 写入失败时重试 cronScheduleTries 次，仍然失败则释放锁并记录失败，本次触发不再补上
+func fire(ctx context.Context, s *conf.JobSchedule, at time.Time) error {
+	lock := RedSync.NewMutex(fmt.Sprintf("%s%s:%d", cronLockPrefix, s.Name, at.Unix()),
+		redsync.WithExpiry(cronLockExpiry),
+		redsync.WithTries(1))
+	if err := lock.LockContext(ctx); err != nil {
+		var taken *redsync.ErrTaken
+		if errors.Is(err, redsync.ErrFailed) || errors.As(err, &taken) {
+			// 其他实例已经触发
+			return nil
+		}
+		return fmt.Errorf("获取锁失败: %w", err)
+	}
+
+	payload := s.Payload
+	if payload == "" {
+		payload = "{}"
+	}
+	id := fmt.Sprintf("%s%s-%d", cronTaskPrefix, s.Name, at.Unix())
+	env := &kafka_mq.Envelope{Job: s.Job, Version: 1, IdempotencyKey: id, Payload: json.RawMessage(payload)}
+	run := &CronRun{Name: s.Name, Spec: s.Spec, Job: s.Job, TaskID: id, FiredAt: at.Unix(), State: CronScheduled}
+	err := Schedule(ctx, id, "", "", env)
+	for i := 1; i < cronScheduleTries && err != nil && !errors.Is(err, ErrUnknownJob); i++ {
+		log.Printf("cron %s: failed to schedule %s (attempt %d/%d): %v\n", s.Name, id, i, cronScheduleTries, err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Duration(i) * time.Second):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		err = Schedule(ctx, id, "", "", env)
+	}
+	if err != nil {
+		// 实例退出时 ctx 已结束，释放锁和记录结果都不受影响
+		ctx = context.WithoutCancel(ctx)
+		if _, unlockErr := lock.UnlockContext(ctx); unlockErr != nil {
+			log.Printf("cron %s: failed to release lock: %v\n", s.Name, unlockErr)
+		}
+		run.State, run.Error, run.FinishedAt = CronFailed, err.Error(), time.Now().Unix()
+		if saveErr := saveCronRun(ctx, run); saveErr != nil {
+			log.Printf("Failed to record cron run %s: %v\n", s.Name, saveErr)
+		}
+		return fmt.Errorf("写入任务 %s 失败: %w", id, err)
+	}
+	log.Printf("cron %s fired (%s)\n", s.Name, id)
+	return saveCronRun(ctx, run)
+}
+
+// cronName 从任务 ID 中取出定时任务名，不是定时任务时返回 false
+func cronName(taskID string) (string, bool) {
+	rest, ok := strings.CutPrefix(taskID, cronTaskPrefix)
+	if !ok {
+		return "", false
+	}
+	i := strings.LastIndex(rest, "-")
+	if i <= 0 {
+		return "", false
+	}
+	return rest[:i], true
+}
+
+// recordCronResult 记录定时任务产生的任务的执行结果，只更新最近一次触发的记录
+func recordCronResult(ctx context.Context, taskID string, attempts int, err error, dead bool) {
+	name, ok := cronName(taskID)
+	if !ok {
+		return
+	}
+	run, getErr := getCronRun(ctx, name)
+	if getErr != nil || run == nil || run.TaskID != taskID {
+		return
+	}
+	run.Attempts, run.Error = attempts, ""
+	switch {
+	case err == nil:
+		run.State, run.FinishedAt = CronSucceeded, time.Now().Unix()
+	case dead:
+		run.State, run.Error, run.FinishedAt = CronFailed, err.Error(), time.Now().Unix()
+	default:
+		run.State, run.Error = CronRetrying, err.Error()
+	}
+	if err = saveCronRun(ctx, run); err != nil {
+		log.Printf("Failed to record cron run %s: %v\n", name, err)
+	}
+}
+
+func saveCronRun(ctx context.Context, run *CronRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	return RDB.HSet(ctx, cronRunsKey, run.Name, data).Err()
+}
+
+func getCronRun(ctx context.Context, name string) (*CronRun, error) {
+	data, err := RDB.HGet(ctx, cronRunsKey, name).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var run CronRun
+	if err = json.Unmarshal(data, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// CronRuns 所有定时任务最近一次的执行记录，按名称排序
+func CronRuns(ctx context.Context) ([]*CronRun, error) {
+	all, err := RDB.HGetAll(ctx, cronRunsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]*CronRun, 0, len(all))
+	for _, data := range all {
+		var run CronRun
+		if json.Unmarshal([]byte(data), &run) == nil {
+			runs = append(runs, &run)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Name < runs[j].Name })
+	return runs, nil
+}
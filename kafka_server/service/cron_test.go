@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"grpc-todolist-disk/conf"
+	"grpc-todolist-disk/utils/redis_cache"
+)
+
+// TestFireScheduleFailure 写入任务队列失败时释放本次触发的锁并记录失败
+func TestFireScheduleFailure(t *testing.T) {
+	testRedis(t)
+	old := RedSync
+	RedSync = redis_cache.NewSync(RDB)
+	t.Cleanup(func() { RedSync = old })
+
+	ctx := context.Background()
+	name := fmt.Sprintf("test_fire_%d", time.Now().UnixNano())
+	t.Cleanup(func() { RDB.HDel(ctx, cronRunsKey, name) })
+	s := &conf.JobSchedule{Name: name, Spec: "@every 1m", Job: "not_registered"}
+	at := time.Now().Truncate(time.Second)
+
+	if err := fire(ctx, s, at); err == nil {
+		t.Fatal("fire should fail for an unregistered job")
+	}
+	lockKey := fmt.Sprintf("%s%s:%d", cronLockPrefix, name, at.Unix())
+	if n, err := RDB.Exists(ctx, lockKey).Result(); err != nil || n != 0 {
+		t.Fatalf("lock not released: exists=%d err=%v", n, err)
+	}
+	run, err := getCronRun(ctx, name)
+	if err != nil || run == nil {
+		t.Fatalf("cron run %v err %v", run, err)
+	}
+	if run.State != CronFailed || run.Error == "" || run.FinishedAt == 0 || run.FiredAt != at.Unix() {
+		t.Fatalf("cron run %+v", run)
+	}
+}
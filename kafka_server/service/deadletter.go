@@ -0,0 +1,193 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"grpc-todolist-disk/conf"
+	"grpc-todolist-disk/utils/delayqueue"
+	"grpc-todolist-disk/utils/kafka_mq"
+	"grpc-todolist-disk/utils/redis_cache"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	dlWriter     kafka_mq.Publisher
+	dlWriterOnce sync.Once
+)
+
+func deadLetterTopic() string {
+	if conf.Conf.Jobs == nil {
+		return ""
+	}
+	return conf.Conf.Jobs.DeadLetterTopic
+}
+
+// writer 写入任意主题的生产者，消息中指定主题
+func writer() kafka_mq.Publisher {
+	dlWriterOnce.Do(func() {
+		dlWriter = kafka_mq.NewPublisher("")
+	})
+	return dlWriter
+}
+
+// deadLettersKey 未配置死信主题时保存死信的 redis 哈希，字段为任务 ID
+const deadLettersKey = "kafka_server:dead_letters"
+
+// deadLetter 把任务连同失败记录写入死信主题，未配置死信主题时保存在 redis 中，同样可以查看和重放。
+// 返回错误时任务没有保存，调用方需要保留任务
+func deadLetter(ctx context.Context, task *delayqueue.Task) error {
+	value, err := json.Marshal(&kafka_mq.DeadLetter{
+		Envelope: kafka_mq.Envelope{Job: task.Type, Version: task.Version, IdempotencyKey: task.IdempotencyKey, Payload: task.Payload},
+		TaskID:   task.ID,
+		Topic:    task.Source,
+		Key:      task.Key,
+		Attempts: task.Attempts,
+		Errors:   task.Errors,
+		FailedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	topic := deadLetterTopic()
+	if topic == "" {
+		return RDB.HSet(ctx, deadLettersKey, task.ID, value).Err()
+	}
+	return writer().WriteMessages(ctx, kafka.Message{Topic: topic, Key: []byte(task.ID), Value: value})
+}
+
+// readDeadLetters 从头读取死信主题所有分区的消息，未配置死信主题时按失败时间读取 redis 中的死信。
+// pos 为死信的位置（分区:offset 或 redis）
+func readDeadLetters(ctx context.Context, fn func(dl *kafka_mq.DeadLetter, pos string) error) error {
+	topic := deadLetterTopic()
+	if topic == "" {
+		values, err := RDB.HVals(ctx, deadLettersKey).Result()
+		if err != nil {
+			return err
+		}
+		list := make([]*kafka_mq.DeadLetter, 0, len(values))
+		for _, value := range values {
+			var dl kafka_mq.DeadLetter
+			if json.Unmarshal([]byte(value), &dl) == nil {
+				list = append(list, &dl)
+			}
+		}
+		slices.SortFunc(list, func(a, b *kafka_mq.DeadLetter) int {
+			return cmp.Or(cmp.Compare(a.FailedAt, b.FailedAt), strings.Compare(a.TaskID, b.TaskID))
+		})
+		for _, dl := range list {
+			if err = fn(dl, "redis"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return kafka_mq.ReadTopic(ctx, topic, func(msg *kafka.Message) error {
+		var dl kafka_mq.DeadLetter
+		if json.Unmarshal(msg.Value, &dl) != nil {
+			return nil
+		}
+		return fn(&dl, fmt.Sprintf("%d:%d", msg.Partition, msg.Offset))
+	})
+}
+
+// replay 把死信中的任务重新发送到原主题，重放的任务从第一次执行开始计数
+func replay(ctx context.Context, dl *kafka_mq.DeadLetter) error {
+	topic := dl.Topic
+	if topic == "" {
+		for _, c := range consumers() {
+			topic = c.Topic
+			break
+		}
+	}
+	// 保留幂等键，Once 中已完成的步骤不会重复执行
+	value, err := json.Marshal(&kafka_mq.Envelope{Job: dl.Job, Version: dl.Version, IdempotencyKey: dl.IdempotencyKey, Payload: dl.Payload})
+	if err != nil {
+		return err
+	}
+	if err = writer().WriteMessages(ctx, kafka.Message{Topic: topic, Key: []byte(dl.Key), Value: value}); err != nil {
+		return err
+	}
+	// 死信主题中的消息无法删除；redis 中的死信重放后删除，再次失败时重新写入
+	if deadLetterTopic() == "" {
+		return RDB.HDel(ctx, deadLettersKey, dl.TaskID).Err()
+	}
+	return nil
+}
+
+// DeadLetterCommand 死信管理命令：
+//
+//	dlq list                      列出死信中的任务和失败记录
+//	dlq replay -task <任务 ID>     重放指定任务（可以用逗号分隔多个）
+//	dlq replay -all               重放所有任务，死信主题中的任务重复执行会再次重放
+func DeadLetterCommand(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("用法: dlq list | dlq replay (-task <任务 ID> | -all)")
+	}
+	ctx := context.Background()
+	if deadLetterTopic() == "" && RDB == nil {
+		RDB = redis_cache.ConnectRedis()
+	}
+	switch args[0] {
+	case "list":
+		return readDeadLetters(ctx, func(dl *kafka_mq.DeadLetter, pos string) error {
+			fmt.Fprintf(out, "[%s] task=%s job=%s v%d topic=%s attempts=%d failed_at=%s\n",
+				pos, dl.TaskID, dl.Job, dl.Version, dl.Topic, dl.Attempts,
+				time.Unix(dl.FailedAt, 0).Format(time.RFC3339))
+			fmt.Fprintf(out, "    payload: %s\n", dl.Payload)
+			for _, e := range dl.Errors {
+				fmt.Fprintf(out, "    %s\n", e)
+			}
+			return nil
+		})
+	case "replay":
+		fs := flag.NewFlagSet("dlq replay", flag.ContinueOnError)
+		fs.SetOutput(out)
+		taskIDs := fs.String("task", "", "要重放的任务 ID，逗号分隔")
+		all := fs.Bool("all", false, "重放所有任务")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		wanted := map[string]bool{}
+		for _, id := range splitComma(*taskIDs) {
+			wanted[id] = true
+		}
+		if !*all && len(wanted) == 0 {
+			return errors.New("需要指定 -task 或 -all")
+		}
+		replayed := 0
+		err := readDeadLetters(ctx, func(dl *kafka_mq.DeadLetter, pos string) error {
+			if !*all && !wanted[dl.TaskID] {
+				return nil
+			}
+			if err := replay(ctx, dl); err != nil {
+				return fmt.Errorf("重放 %s 失败: %w", dl.TaskID, err)
+			}
+			replayed++
+			fmt.Fprintf(out, "replayed task=%s job=%s\n", dl.TaskID, dl.Job)
+			return nil
+		})
+		fmt.Fprintf(out, "%d task(s) replayed\n", replayed)
+		return err
+	default:
+		return fmt.Errorf("未知的命令: %s", args[0])
+	}
+}
+
+func splitComma(s string) []string {
+	var list []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
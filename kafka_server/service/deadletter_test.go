@@ -0,0 +1,62 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"grpc-todolist-disk/conf"
+	"grpc-todolist-disk/utils/delayqueue"
+	"grpc-todolist-disk/utils/kafka_mq"
+)
+
+// TestDeadLetterRedis 未配置死信主题时死信保存在 redis 中，可以列出和重放，重放后删除
+func TestDeadLetterRedis(t *testing.T) {
+	testRedis(t)
+	c := useMemory(t)
+	conf.Conf.Jobs.DeadLetterTopic = ""
+
+	ctx := context.Background()
+	id := fmt.Sprintf("test-dead-%d", time.Now().UnixNano())
+	t.Cleanup(func() { RDB.HDel(ctx, deadLettersKey, id) })
+	task := &delayqueue.Task{ID: id, Type: kafka_mq.JobFileUpload, Version: 1, Payload: []byte(`{"job_id":"j"}`),
+		Source: c.Topic, Key: "k", Attempts: 3, Errors: []string{"boom"}}
+	if err := deadLetter(ctx, task); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := DeadLetterCommand([]string{"list"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "[redis] task="+id) || !strings.Contains(out.String(), "boom") {
+		t.Fatalf("list output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := DeadLetterCommand([]string{"replay", "-task", id}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "1 task(s) replayed") {
+		t.Fatalf("replay output:\n%s", out.String())
+	}
+	if exists, _ := RDB.HExists(ctx, deadLettersKey, id).Result(); exists {
+		t.Fatal("replayed dead letter not removed")
+	}
+	var replayed []kafka.Message
+	kafka_mq.ReadTopic(ctx, c.Topic, func(msg *kafka.Message) error {
+		replayed = append(replayed, *msg)
+		return nil
+	})
+	if len(replayed) != 1 || string(replayed[0].Key) != "k" {
+		t.Fatalf("replayed messages %v", replayed)
+	}
+	env, ok := kafka_mq.ParseEnvelope(replayed[0].Value)
+	if !ok || env.Job != kafka_mq.JobFileUpload || string(env.Payload) != `{"job_id":"j"}` {
+		t.Fatalf("replayed envelope %+v", env)
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"grpc-todolist-disk/conf"
+	"time"
+)
+
+const (
+	// processedPrefix 已处理任务的幂等键，后接任务名和幂等键
+	processedPrefix = "kafka_server:processed:"
+	// stepPrefix Once 已完成的步骤，后接任务名、幂等键和步骤名
+	stepPrefix = "kafka_server:step:"
+
+	defaultIdempotencyTTL = 7 * 24 * time.Hour
+)
+
+func idempotencyTTL() time.Duration {
+	if conf.Conf.Jobs != nil && conf.Conf.Jobs.IdempotencyTTL > 0 {
+		return time.Duration(conf.Conf.Jobs.IdempotencyTTL) * time.Second
+	}
+	return defaultIdempotencyTTL
+}
+
+func processedKey(job *Job) string {
+	return processedPrefix + job.Name + ":" + job.IdempotencyKey
+}
+
+// processed 任务是否已经成功执行过，查询失败时按未执行处理
+func processed(ctx context.Context, job *Job) bool {
+	n, err := RDB.Exists(ctx, processedKey(job)).Result()
+	return err == nil && n > 0
+}
+
+// markProcessed 记录任务已成功执行
+func markProcessed(ctx context.Context, job *Job) error {
+	return RDB.Set(ctx, processedKey(job), time.Now().Unix(), idempotencyTTL()).Err()
+}
+
+// Once 处理函数中有副作用的步骤（写文件、发通知、调用外部服务等）用 Once 包装：
+// 同一任务的 step 成功后记录下来，任务因后续步骤失败重试或重复投递时跳过。
+// fn 成功后、记录前崩溃时 fn 仍会再执行一次，fn 本身应尽量可重复执行（如先查询再写入、覆盖写）
+func Once(ctx context.Context, job *Job, step string, fn func() error) error {
+	key := stepPrefix + job.Name + ":" + job.IdempotencyKey + ":" + step
+	if n, err := RDB.Exists(ctx, key).Result(); err == nil && n > 0 {
+		return nil
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return RDB.Set(ctx, key, time.Now().Unix(), idempotencyTTL()).Err()
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"grpc-todolist-disk/conf"
+	"grpc-todolist-disk/utils/delayqueue"
+	"grpc-todolist-disk/utils/kafka_mq"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job 从延时队列领取的任务
+type Job struct {
+	ID       string
+	Name     string
+	Version  int
+	Payload  json.RawMessage
+	Attempts int  // 之前已失败的次数
+	Final    bool // 本次失败后不再重试，处理函数可以据此记录最终状态
+	// IdempotencyKey 幂等键，同一任务重复投递时相同。成功执行过的任务在 jobs.idempotencyTTL 内不再执行
+	IdempotencyKey string
+}
+
+// Handler 任务处理函数，返回错误时按重试策略重新执行，返回 Permanent 包装的错误时不再重试。
+// 任务至少执行一次（成功后、记录幂等键前崩溃时会再次执行），处理函数需要能够重复执行，
+// 有副作用的步骤可以用 Once 包装，并在 ctx 超时后尽快返回
+type Handler func(ctx context.Context, job *Job) error
+
+const (
+	defaultConcurrency = 1
+	defaultTimeout     = time.Minute
+	// leaseMargin 租约比超时多出的时间，超时的任务在租约到期前有机会重新调度
+	leaseMargin = 30 * time.Second
+)
+
+var (
+	ErrUnknownJob  = errors.New("未注册的任务")
+	ErrJobVersion  = errors.New("不支持的任务版本")
+	ErrRegistered  = errors.New("任务已注册")
+	registry       = map[string]*jobType{}
+	registryLocker sync.RWMutex
+)
+
+// jobType 已注册的任务类型，每个类型使用单独的延时队列，由 concurrency 个协程执行
+type jobType struct {
+	name        string
+	version     int // 支持的最高版本
+	handler     Handler
+	concurrency int
+	timeout     time.Duration
+	retry       retryPolicy
+	queue       *delayqueue.Queue
+	wake        chan struct{}
+}
+
+// Register 注册任务处理函数，version 为支持的最高版本，更高版本的消息不会被执行。
+// 并发数、超时和重试策略读取配置 jobs.types.<name>，需要在 Init 之后、Run 之前调用
+func Register(name string, version int, handler Handler) error {
+	concurrency, timeout := defaultConcurrency, defaultTimeout
+	var t *conf.JobType
+	if conf.Conf.Jobs != nil {
+		t = conf.Conf.Jobs.Types[name]
+	}
+	if t != nil {
+		if t.Concurrency > 0 {
+			concurrency = t.Concurrency
+		}
+		if t.Timeout > 0 {
+			timeout = time.Duration(t.Timeout) * time.Second
+		}
+	}
+
+	registryLocker.Lock()
+	defer registryLocker.Unlock()
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("%w: %s", ErrRegistered, name)
+	}
+	registry[name] = &jobType{
+		name:        name,
+		version:     version,
+		handler:     handler,
+		concurrency: concurrency,
+		timeout:     timeout,
+		retry:       newRetryPolicy(t),
+		queue:       delayqueue.New(RDB, queuePrefix+name, timeout+leaseMargin),
+		wake:        make(chan struct{}, 1),
+	}
+	return nil
+}
+
+func lookup(name string) *jobType {
+	registryLocker.RLock()
+	defer registryLocker.RUnlock()
+	return registry[name]
+}
+
+// Schedule 把任务写入对应类型的延时队列，id 相同的任务覆盖之前的调度；source 为消息所在的主题，死信重放时发送到这里；
+// key 不为空时，同一类型中键相同的任务按调度顺序依次执行，前一个任务完成或进入死信后才执行下一个
+func Schedule(ctx context.Context, id, source, key string, env *kafka_mq.Envelope) error {
+	jt := lookup(env.Job)
+	if jt == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, env.Job)
+	}
+	if env.Version > jt.version {
+		return fmt.Errorf("%w: %s v%d", ErrJobVersion, env.Job, env.Version)
+	}
+	runAt := time.Now()
+	if env.RunAt > 0 {
+		runAt = time.UnixMilli(env.RunAt)
+	}
+	task := &delayqueue.Task{ID: id, Type: env.Job, Version: env.Version, Payload: env.Payload, Source: source, Key: key,
+		IdempotencyKey: env.IdempotencyKey}
+	if task.IdempotencyKey == "" {
+		// 旧格式的消息没有幂等键，同一条 kafka 消息重复消费时任务 ID 相同
+		task.IdempotencyKey = id
+	}
+	if err := jt.queue.Schedule(ctx, task, runAt); err != nil {
+		return err
+	}
+	jt.notify()
+	return nil
+}
+
+func (jt *jobType) notify() {
+	select {
+	case jt.wake <- struct{}{}:
+	default:
+	}
+}
+
+// work 单个执行协程：领取到期的任务执行，没有任务时等到最早的任务到期，最长 pollInterval（同时检查其他实例留下的过期租约）。
+// 任务类型暂停期间不领取任务；ctx 结束后不再领取，任务用 runCtx 执行
+func (jt *jobType) work(ctx, runCtx context.Context) {
+	for ctx.Err() == nil {
+		if paused(runCtx, jt.name) {
+			jt.sleep(ctx, pollInterval)
+			continue
+		}
+		tasks, err := jt.queue.Claim(runCtx, time.Now(), 1)
+		if err != nil {
+			log.Printf("Failed to claim %s tasks: %v\n", jt.name, err)
+		}
+		if len(tasks) > 0 {
+			jt.run(runCtx, tasks[0])
+			continue
+		}
+
+		wait := pollInterval
+		if next, ok, err := jt.queue.Next(runCtx); err == nil && ok {
+			wait = min(wait, max(time.Until(next), 0))
+		}
+		jt.sleep(ctx, wait)
+	}
+}
+
+// sleep 等待 d、被 notify 唤醒或 ctx 结束
+func (jt *jobType) sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-jt.wake:
+	case <-ctx.Done():
+	}
+}
+
+/*
+1、幂等键已处理过的任务直接从队列删除
+
+2、按任务类型的超时执行任务，成功则记录幂等键并从队列删除
+
+3、失败则按重试策略延迟后重新调度；不可重试或次数耗尽时写入死信主题后删除
+*/
+func (jt *jobType) run(ctx context.Context, task *delayqueue.Task) {
+	job := &Job{
+		ID:             task.ID,
+		Name:           task.Type,
+		Version:        task.Version,
+		Payload:        task.Payload,
+		Attempts:       task.Attempts,
+		Final:          task.Attempts+1 >= jt.retry.maxAttempts,
+		IdempotencyKey: task.IdempotencyKey,
+	}
+	if job.IdempotencyKey == "" {
+		job.IdempotencyKey = task.ID
+	}
+	if processed(ctx, job) {
+		log.Printf("Skip task %s (%s): already processed\n", task.ID, task.Type)
+		if err := jt.queue.Ack(ctx, task.ID); err != nil {
+			log.Printf("Failed to ack task %s: %v\n", task.ID, err)
+		}
+		return
+	}
+
+	err := jt.call(ctx, job)
+	if err != nil && ctx.Err() != nil {
+		// 实例退出时被取消，不计入失败次数，放回队列由其他实例执行
+		releaseCtx, cancel := context.WithTimeout(context.Background(), releaseGrace)
+		defer cancel()
+		if err = jt.queue.Release(releaseCtx, task.ID); err != nil {
+			log.Printf("Failed to release task %s: %v\n", task.ID, err)
+		}
+		log.Printf("Task %s (%s) released on shutdown\n", task.ID, task.Type)
+		return
+	}
+	// 任务已经执行完，记录结果不受退出影响
+	ctx = context.WithoutCancel(ctx)
+	if err == nil {
+		recordCronResult(ctx, task.ID, task.Attempts+1, nil, false)
+		if err = markProcessed(ctx, job); err != nil {
+			log.Printf("Failed to record task %s as processed: %v\n", task.ID, err)
+		}
+		if err = jt.queue.Ack(ctx, task.ID); err != nil {
+			log.Printf("Failed to ack task %s: %v\n", task.ID, err)
+		}
+		log.Printf("Finished task %s (%s)\n", task.ID, task.Type)
+		return
+	}
+
+	task.Attempts++
+	task.Errors = appendError(task.Errors, err)
+	dead := IsPermanent(err) || task.Attempts >= jt.retry.maxAttempts
+	recordCronResult(ctx, task.ID, task.Attempts, err, dead)
+	recordFailure(ctx, newFailure(task.ID, task.Type, task.Attempts, err, dead))
+	if dead {
+		if dlErr := deadLetter(ctx, task); dlErr != nil {
+			// 写入死信失败时保留任务，稍后再试
+			log.Printf("Failed to dead-letter task %s: %v\n", task.ID, dlErr)
+			jt.reschedule(ctx, task, jt.retry.max)
+			return
+		}
+		if err = jt.queue.Ack(ctx, task.ID); err != nil {
+			log.Printf("Failed to ack task %s: %v\n", task.ID, err)
+		}
+		log.Printf("Task %s (%s) dead-lettered after %d attempts: %v\n", task.ID, task.Type, task.Attempts, task.Errors[len(task.Errors)-1])
+		return
+	}
+	delay := jt.retry.backoff(task.Attempts)
+	log.Printf("Task %s (%s) failed (attempt %d/%d), retrying in %s: %v\n",
+		task.ID, task.Type, task.Attempts, jt.retry.maxAttempts, delay, err)
+	jt.reschedule(ctx, task, delay)
+}
+
+func (jt *jobType) reschedule(ctx context.Context, task *delayqueue.Task, delay time.Duration) {
+	if err := jt.queue.Schedule(ctx, task, time.Now().Add(delay)); err != nil {
+		// 租约到期后任务会被重新领取
+		log.Printf("Failed to reschedule task %s: %v\n", task.ID, err)
+	}
+}
+
+// call 执行处理函数，panic 按失败处理
+func (jt *jobType) call(ctx context.Context, job *Job) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, jt.timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return jt.handler(ctx, job)
+}
+
+// startWorkers 为每个已注册的任务类型启动执行协程，协程退出时调用 wg.Done
+func startWorkers(ctx, runCtx context.Context, wg *sync.WaitGroup) {
+	registryLocker.RLock()
+	defer registryLocker.RUnlock()
+	for _, jt := range registry {
+		for i := 0; i < jt.concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				jt.work(ctx, runCtx)
+			}()
+		}
+		log.Printf("job %s: %d workers, timeout %s\n", jt.name, jt.concurrency, jt.timeout)
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// offsetTracker 记录每个分区已领取和已处理的消息，只提交连续处理完成的 offset，
+// 之前还有未处理的消息时不提交，实例崩溃后从第一条未处理的消息重新消费
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[int]*partitionOffsets
+}
+
+type partitionOffsets struct {
+	pending []int64        // 已领取、按 offset 排列，尚未提交
+	done    map[int64]bool // 已处理
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: map[int]*partitionOffsets{}}
+}
+
+// fetched 记录领取的消息。offset 回退说明分区重新分配后从已提交的 offset 重新消费，之前的记录作废
+func (t *offsetTracker) fetched(msg kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.partitions[msg.Partition]
+	if !ok || (len(p.pending) > 0 && msg.Offset <= p.pending[len(p.pending)-1]) {
+		p = &partitionOffsets{done: map[int64]bool{}}
+		t.partitions[msg.Partition] = p
+	}
+	p.pending = append(p.pending, msg.Offset)
+}
+
+// done 标记消息已处理，返回可以提交的最后一条消息
+func (t *offsetTracker) done(msg kafka.Message) (kafka.Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.partitions[msg.Partition]
+	if !ok {
+		return kafka.Message{}, false
+	}
+	if _, found := slices.BinarySearch(p.pending, msg.Offset); !found {
+		// 重新分配前领取的消息
+		return kafka.Message{}, false
+	}
+	p.done[msg.Offset] = true
+	n := 0
+	for n < len(p.pending) && p.done[p.pending[n]] {
+		delete(p.done, p.pending[n])
+		n++
+	}
+	if n == 0 {
+		return kafka.Message{}, false
+	}
+	commit := kafka.Message{Topic: msg.Topic, Partition: msg.Partition, Offset: p.pending[n-1]}
+	p.pending = p.pending[n:]
+	return commit, true
+}
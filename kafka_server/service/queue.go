@@ -0,0 +1,20 @@
+package service
+
+import (
+	"time"
+)
+
+const (
+	// queuePrefix 延时队列在 redis 中的键前缀，后接任务名
+	queuePrefix = "kafka_server:jobs:"
+	// pollInterval 没有被唤醒时检查到期任务和过期租约的间隔
+	pollInterval = time.Second
+	// defaultConsumerWorkers 每个主题默认的调度协程数
+	defaultConsumerWorkers = 8
+	// laneBuffer 每个调度协程最多缓存的消息数，缓存满时暂停读取
+	laneBuffer = 64
+	// defaultShutdownTimeout 退出时等待执行中任务的默认时间
+	defaultShutdownTimeout = 30 * time.Second
+	// releaseGrace 取消执行中的任务后，等待处理函数返回并把任务放回队列的时间
+	releaseGrace = 5 * time.Second
+)
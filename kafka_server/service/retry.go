@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"grpc-todolist-disk/conf"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultBackoffBase = time.Second
+	defaultBackoffMax  = 5 * time.Minute
+	// maxErrorHistory 任务保留的失败记录条数
+	maxErrorHistory = 20
+)
+
+// retryPolicy 任务类型的重试策略
+type retryPolicy struct {
+	maxAttempts int
+	base        time.Duration
+	max         time.Duration
+}
+
+func newRetryPolicy(t *conf.JobType) retryPolicy {
+	p := retryPolicy{maxAttempts: defaultMaxAttempts, base: defaultBackoffBase, max: defaultBackoffMax}
+	if t == nil {
+		return p
+	}
+	if t.MaxAttempts > 0 {
+		p.maxAttempts = t.MaxAttempts
+	}
+	if t.BackoffBase > 0 {
+		p.base = time.Duration(t.BackoffBase) * time.Second
+	}
+	if t.BackoffMax > 0 {
+		p.max = time.Duration(t.BackoffMax) * time.Second
+	}
+	return p
+}
+
+// backoff 第 attempts 次失败后的重试延迟：base * 2^(attempts-1)，不超过 max，在 [d/2, d] 之间随机，避免大量任务同时重试
+func (p retryPolicy) backoff(attempts int) time.Duration {
+	d := p.base
+	for i := 1; i < attempts && d < p.max; i++ {
+		d *= 2
+	}
+	d = min(d, p.max)
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// permanentError 重试也不会成功的错误（如消息格式错误），任务直接进入死信主题
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 把错误标记为不可重试
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent 判断错误是否不可重试，其他错误（超时、依赖服务暂时不可用等）按策略重试
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// appendError 记录失败原因，只保留最近 maxErrorHistory 条
+func appendError(history []string, err error) []string {
+	history = append(history, fmt.Sprintf("%s %v", time.Now().Format(time.RFC3339), err))
+	if len(history) > maxErrorHistory {
+		history = history[len(history)-maxErrorHistory:]
+	}
+	return history
+}
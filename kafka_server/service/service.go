@@ -1,19 +1,23 @@
 package service
 
 import (
-	"container/heap"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-redis/redis/v8"
-	"github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
 	"grpc-todolist-disk/app/files/dao"
 	pb "grpc-todolist-disk/idl/pb/files"
 	"grpc-todolist-disk/utils/kafka_mq"
 	"grpc-todolist-disk/utils/redis_cache"
+	"grpc-todolist-disk/utils/storage"
+	"grpc-todolist-disk/utils/uploadjob"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Message 解析Kafka消息中的JSON
@@ -23,16 +27,14 @@ type Message struct {
 }
 
 var RDB *redis.Client
-var KfReader *kafka.Reader
-var TaskHeap DelayedTaskHeap
-var KfFileReader *kafka.Reader
 
 func ClearNameRedisCache(name string) bool {
 	// 连接redis
 	rdb := RDB
 	ctx := context.Background()
 	key := fmt.Sprintf("user:%s", name)
-	_, err := rdb.Get(ctx, key).Result()
+	// 键不存在也算清除成功
+	_, err := rdb.Del(ctx, key).Result()
 	if err != nil {
 		log.Printf("Failed to clear Redis cache for user ID %s: %v", name, err)
 		return false
@@ -41,55 +43,178 @@ func ClearNameRedisCache(name string) bool {
 }
 
 type AsyncFileUploadMsg struct {
+	JobID      string `json:"job_id"`
 	UserID     uint64 `json:"user_id"`
 	Filename   string `json:"filename"`
 	FileSize   int64  `json:"file_size"`
 	FileHash   string `json:"file_hash"`
 	ObjectName string `json:"object_name"`
-	Content    []byte `json:"content"` // 小文件内容
-	TempPath   string `json:"temp_path"`
+	Content    []byte `json:"content"`   // 旧版本网关直接携带文件内容
+	TempPath   string `json:"temp_path"` // 网关写入的暂存路径
+	FolderID   uint64 `json:"folder_id"` // 目标文件夹，网关已校验写入权限，写入前再次校验
 }
 
-// HandleAsyncFileUpload 异步启动上传文件的消费者（表单）
-func HandleAsyncFileUpload(msg *kafka.Message) error {
+// HandleAsyncFileUpload 异步启动上传文件的消费者（表单），把暂存的文件移入正式存储并写入数据库。
+// final 为 true 时本次是最后一次重试，失败后把上传任务标记为失败。ctx 取消（任务超时或进程退出）时中止数据库和 redis 操作
+func HandleAsyncFileUpload(ctx context.Context, value []byte, final bool) error {
 	var m AsyncFileUploadMsg
-	if err := json.Unmarshal(msg.Value, &m); err != nil {
-		return fmt.Errorf("解析文件上传消息失败: %w", err)
+	if err := json.Unmarshal(value, &m); err != nil {
+		return Permanent(fmt.Errorf("解析文件上传消息失败: %w", err))
 	}
 
 	log.Println("开始异步处理文件：", m.Filename)
 
-	savePath := filepath.Join("stores/uploaded_files", m.ObjectName)
-	if err := os.MkdirAll(filepath.Dir(savePath), os.ModePerm); err != nil {
-		return fmt.Errorf("创建目录失败: %w", err)
+	if err := updateJob(ctx, m.JobID, func(job *uploadjob.Job) {
+		job.State = uploadjob.StateRunning
+		job.Attempts++
+	}); err != nil {
+		log.Printf("更新上传任务状态失败 %s: %v", m.JobID, err)
 	}
-	if err := os.WriteFile(savePath, m.Content, 0644); err != nil {
-		return fmt.Errorf("写入文件失败: %w", err)
+	fileID, err := storeAsyncUpload(ctx, &m)
+	if errors.Is(err, errStagingMissing) {
+		// 暂存文件已不存在，重试也无法完成
+		err = Permanent(err)
+	}
+	if err != nil {
+		state := uploadjob.StatePending
+		if final || IsPermanent(err) {
+			log.Printf("异步上传无法完成 %s: %v", m.Filename, err)
+			state = uploadjob.StateFailed
+		}
+		// ctx 可能已经超时，失败状态仍要写入
+		updateJob(context.WithoutCancel(ctx), m.JobID, func(job *uploadjob.Job) {
+			job.State, job.Error = state, err.Error()
+		})
+		return err
+	}
+	if err = updateJob(ctx, m.JobID, func(job *uploadjob.Job) {
+		job.State, job.FileID, job.Error = uploadjob.StateDone, fileID, ""
+	}); err != nil {
+		log.Printf("更新上传任务状态失败 %s: %v", m.JobID, err)
 	}
 
-	// 写入数据库
+	log.Println("文件处理成功: ", m.Filename)
+	return nil
+}
+
+var errStagingMissing = errors.New("暂存文件不存在")
+
+func storeAsyncUpload(ctx context.Context, m *AsyncFileUploadMsg) (uint64, error) {
 	if dao.DB == nil {
 		log.Fatal("dao.DB 未初始化")
 	}
-	if _, err := dao.NewFilesDao().CreateFile(&pb.FileUploadRequest{
+	db := dao.NewDBClient().WithContext(ctx)
+	// 写入数据库后、提交前崩溃时任务会重新执行，记录已存在则直接返回，不再移动或写入文件
+	filesDao := &dao.FilesDao{DB: db}
+	if file, err := filesDao.FindByObjectName(m.ObjectName); err != nil {
+		return 0, fmt.Errorf("查询文件记录失败: %w", err)
+	} else if file != nil {
+		if uint64(file.UserID) != m.UserID {
+			return 0, Permanent(fmt.Errorf("对象名 %s 已被其他用户的文件占用", m.ObjectName))
+		}
+		return uint64(file.ID), nil
+	}
+
+	// 排队期间共享可能已被撤销
+	if err := (&dao.AclDao{DB: db}).CheckFolderWritable(uint(m.UserID), uint(m.FolderID)); err != nil {
+		if errors.Is(err, dao.ErrPermissionDenied) || errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, Permanent(fmt.Errorf("没有目标文件夹 %d 的写入权限: %w", m.FolderID, err))
+		}
+		return 0, fmt.Errorf("校验目标文件夹失败: %w", err)
+	}
+
+	// 任务已超时或被取消时不再移动文件
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	savePath := filepath.Join("stores/uploaded_files", m.ObjectName)
+	if err := os.MkdirAll(filepath.Dir(savePath), os.ModePerm); err != nil {
+		return 0, fmt.Errorf("创建目录失败: %w", err)
+	}
+	switch {
+	case m.TempPath != "":
+		// 只接受暂存目录下的路径
+		if rel, err := filepath.Rel(uploadjob.StagingDir, filepath.Clean(m.TempPath)); err != nil || strings.HasPrefix(rel, "..") {
+			return 0, fmt.Errorf("%w: 非法的暂存路径 %s", errStagingMissing, m.TempPath)
+		}
+		if err := os.Rename(m.TempPath, savePath); err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return 0, fmt.Errorf("移动文件失败: %w", err)
+			}
+			// 上次已经移动成功、写数据库失败后重试
+			if _, err = os.Stat(savePath); err != nil {
+				return 0, errStagingMissing
+			}
+		}
+	default:
+		if err := os.WriteFile(savePath, m.Content, 0644); err != nil {
+			return 0, fmt.Errorf("写入文件失败: %w", err)
+		}
+	}
+
+	// 写入数据库
+	file, err := filesDao.CreateFile(&pb.FileUploadRequest{
 		UserID:     m.UserID,
 		Filename:   m.Filename,
 		FileSize:   m.FileSize,
 		ObjectName: m.ObjectName,
 		FileHash:   m.FileHash,
-	}); err != nil {
-		return fmt.Errorf("数据库写入失败: %w", err)
+		FolderID:   m.FolderID,
+	}, storage.Info{})
+	if err != nil {
+		return 0, fmt.Errorf("数据库写入失败: %w", err)
 	}
+	return uint64(file.ID), nil
+}
 
-	log.Println("文件处理成功: ", m.Filename)
+func updateJob(ctx context.Context, id string, fn func(job *uploadjob.Job)) error {
+	return uploadjob.Update(ctx, RDB, id, fn)
+}
+
+// clearCache 延时双删：清除用户缓存
+func clearCache(ctx context.Context, job *Job) error {
+	var m Message
+	if err := json.Unmarshal(job.Payload, &m); err != nil {
+		return Permanent(fmt.Errorf("解析清除缓存消息失败: %w", err))
+	}
+	if !ClearNameRedisCache(m.Name) {
+		return fmt.Errorf("failed to clear cache for %s", m.Name)
+	}
 	return nil
 }
 
+func asyncFileUpload(ctx context.Context, job *Job) error {
+	return HandleAsyncFileUpload(ctx, job.Payload, job.Final)
+}
+
 func Init() {
 	dao.InitDB()
 	RDB = redis_cache.ConnectRedis()
-	KfReader = kafka_mq.NewKafkaConsumer()
-	KfFileReader = kafka_mq.NewFileKafkaConsumer()
-	heap.Init(&TaskHeap)
-	MsgSignal = make(chan int)
+	RedSync = redis_cache.NewSync(RDB)
+
+	// 新的后台任务在这里注册，消息发送到 jobs.consumers 中任意主题即可
+	for name, handler := range map[string]Handler{
+		kafka_mq.JobClearCache: clearCache,
+		kafka_mq.JobFileUpload: asyncFileUpload,
+		JobCleanupTemp:         cleanupTemp,
+	} {
+		if err := Register(name, 1, handler); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Close 关闭死信生产者、redis 和数据库连接，在 Run 返回后调用
+func Close() {
+	if dlWriter != nil {
+		if err := dlWriter.Close(); err != nil {
+			log.Printf("Failed to close dead-letter writer: %v", err)
+		}
+	}
+	if err := RDB.Close(); err != nil {
+		log.Printf("Failed to close redis: %v", err)
+	}
+	if sqlDB, err := dao.DB.DB(); err == nil {
+		sqlDB.Close()
+	}
 }
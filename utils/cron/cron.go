@@ -0,0 +1,194 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 定时规则
+type Schedule interface {
+	// Next 返回 t 之后的下一次执行时间，没有时返回零值
+	Next(t time.Time) time.Time
+}
+
+// field 每个字段允许的取值范围和名称
+type field struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minutes = field{0, 59, nil}
+	hours   = field{0, 23, nil}
+	doms    = field{1, 31, nil}
+	months  = field{1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dows = field{0, 6, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse 解析标准的 5 段 cron 表达式（分 时 日 月 周），支持 *、数字、a-b、*/n、a-b/n、逗号分隔的列表，
+// 月份和星期可以用英文缩写，星期 7 等同于 0；日和周都不是 * 时满足其一即可。
+// 也支持 @hourly、@daily、@weekly、@monthly、@yearly 和 @every <时长>（如 @every 5m）
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("cron: 无效的间隔 %q", rest)
+		}
+		return every(d), nil
+	}
+	if m, ok := macros[strings.ToLower(spec)]; ok {
+		spec = m
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron: 表达式 %q 需要 5 段（分 时 日 月 周）", spec)
+	}
+	s := &specSchedule{}
+	var err error
+	if s.minute, err = parseField(parts[0], minutes); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(parts[1], hours); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(parts[2], doms); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(parts[3], months); err != nil {
+		return nil, err
+	}
+	// 星期允许 7 表示周日
+	if s.dow, err = parseField(parts[4], field{0, 7, dows.names}); err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domStar, s.dowStar = parts[2] == "*" || parts[2] == "?", parts[4] == "*" || parts[4] == "?"
+	return s, nil
+}
+
+// parseField 把字段解析为位图，第 i 位表示取值 i
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(expr, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("cron: 无效的步长 %q", item)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*" || rng == "?":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(a); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(b); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := f.value(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if !hasStep {
+				hi = v
+			}
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("cron: 无效的范围 %q", item)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("cron: %q 超出范围 %d-%d", s, f.min, f.max)
+	}
+	return v, nil
+}
+
+type specSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+// Next 逐级向后查找满足条件的时间，精度为分钟，使用 t 的时区
+func (s *specSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// 最多查找 5 年，规则无法满足（如 2 月 30 日）时返回零值
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *specSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// every 固定间隔，从整点间隔开始对齐（如 @every 5m 在 00、05、10 分执行）
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	d := time.Duration(e)
+	return t.Truncate(d).Add(d)
+}
@@ -0,0 +1,87 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// 2024-01-01 是周一
+	from := time.Date(2024, 1, 1, 10, 30, 15, 0, time.UTC)
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2024, month, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", from, at(1, 1, 10, 31)},
+		{"*/15 * * * *", from, at(1, 1, 10, 45)},
+		{"1,2,58 * * * *", from, at(1, 1, 10, 58)},
+		{"0 9-17/4 * * *", from, at(1, 1, 13, 0)},
+		{"30 10 * * *", from, at(1, 2, 10, 30)}, // 当前分钟不算
+		{" 5 4 * * * ", from, at(1, 2, 4, 5)},
+		{"0 0 1 jan-mar *", from, at(2, 1, 0, 0)},
+		{"0 0 1 FEB *", from, at(2, 1, 0, 0)},
+		{"0 12 * * MON", from, at(1, 1, 12, 0)},
+		{"0 0 * * sat,sun", from, at(1, 6, 0, 0)},
+		{"0 0 * * 7", from, at(1, 7, 0, 0)}, // 7 等同于周日
+		{"0 0 * * 1-5/2", at(1, 1, 12, 0), at(1, 3, 0, 0)},
+		{"0 0 13 * *", from, at(1, 13, 0, 0)},
+		// 日和周都有限制时满足其一即可：先到周五，再到 13 日（周六）
+		{"0 0 13 * fri", from, at(1, 5, 0, 0)},
+		{"0 0 13 * fri", at(1, 12, 1, 0), at(1, 13, 0, 0)},
+		// ? 等同于 *，只按星期匹配
+		{"0 0 ? * fri", at(1, 5, 0, 0), at(1, 12, 0, 0)},
+		{"0 0 30 2 *", from, time.Time{}}, // 无法满足
+		{"@hourly", from, at(1, 1, 11, 0)},
+		{"@daily", from, at(1, 2, 0, 0)},
+		{"@midnight", from, at(1, 2, 0, 0)},
+		{"@weekly", from, at(1, 7, 0, 0)},
+		{"@monthly", from, at(2, 1, 0, 0)},
+		{"@yearly", from, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 5m", from, at(1, 1, 10, 35)},
+		{"@every 1h", from, at(1, 1, 11, 0)},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := s.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).Next(%s) = %s, want %s", tt.spec, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"* * * foo *",
+		"* * * * funday",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"@reboot",
+		"@every",
+		"@every x",
+		"@every 500ms",
+		"@every -5m",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) should fail", spec)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package delayqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Task 延时任务，ID 相同的任务重复调度时覆盖之前的内容
+type Task struct {
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	Version        int             `json:"version,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	Source         string          `json:"source,omitempty"`          // 来源，如消息所在的 kafka 主题
+	Key            string          `json:"key,omitempty"`             // 键相同的任务按调度顺序依次执行
+	IdempotencyKey string          `json:"idempotency_key,omitempty"` // 幂等键，相同的任务只成功执行一次
+	Attempts       int             `json:"attempts"`                  // 已失败的次数
+	Errors         []string        `json:"errors,omitempty"`          // 每次失败的时间和原因
+	RunAt          int64           `json:"run_at"`                    // 计划执行时间（毫秒）
+}
+
+// Queue 基于 redis 的延时队列，多个实例可以同时领取任务：
+// {<name>}:tasks 哈希保存任务内容，{<name>}:ready 有序集合按执行时间排列待执行的任务，
+// {<name>}:leases 有序集合记录已领取任务的租约到期时间。领取者崩溃后租约到期，任务重新进入待执行队列，
+// 因此任务至少执行一次，处理函数需要能够重复执行。
+// 带键的任务按调度顺序排在 {<name>}:key:<键> 列表中，只有列表头部的任务进入待执行队列，
+// 前一个任务完成（包括重试后成功或被放弃）后下一个任务才能执行。
+// 队列的键都带有哈希标签 {<name>}，在 redis 集群中位于同一个槽，脚本访问的键都通过 KEYS 传入
+type Queue struct {
+	rdb    *redis.Client
+	tasks  string
+	ready  string
+	leases string
+	keyed  string // 键列表的前缀
+	lease  time.Duration
+}
+
+// New 创建队列，lease 为领取任务后的租约时长，处理时间不能超过租约
+func New(rdb *redis.Client, name string, lease time.Duration) *Queue {
+	tag := "{" + name + "}"
+	return &Queue{
+		rdb:    rdb,
+		tasks:  tag + ":tasks",
+		ready:  tag + ":ready",
+		leases: tag + ":leases",
+		keyed:  tag + ":key:",
+		lease:  lease,
+	}
+}
+
+// scheduleScript 保存任务并放入待执行队列，同时移除可能存在的租约。
+// 带键的新任务排到键列表 KEYS[4] 末尾，只有位于列表头部时才进入待执行队列，不带键时没有 KEYS[4]。
+// ARGV: 任务 ID、任务内容、执行时间
+var scheduleScript = redis.NewScript(`
+local existed = redis.call('HEXISTS', KEYS[1], ARGV[1])
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('ZREM', KEYS[3], ARGV[1])
+if KEYS[4] then
+	if existed == 0 then
+		redis.call('RPUSH', KEYS[4], ARGV[1])
+	end
+	if redis.call('LINDEX', KEYS[4], 0) ~= ARGV[1] then
+		return 1
+	end
+end
+redis.call('ZADD', KEYS[2], ARGV[3], ARGV[1])
+return 1
+`)
+
+// claimScript 先把租约到期的任务放回待执行队列，再领取到期的任务。ARGV: 当前时间、租约到期时间、数量
+var claimScript = redis.NewScript(`
+local expired = redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', ARGV[1])
+for _, id in ipairs(expired) do
+	redis.call('ZREM', KEYS[3], id)
+	redis.call('ZADD', KEYS[2], ARGV[1], id)
+end
+local ids = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[3]))
+local out = {}
+for _, id in ipairs(ids) do
+	redis.call('ZREM', KEYS[2], id)
+	local task = redis.call('HGET', KEYS[1], id)
+	if task then
+		redis.call('ZADD', KEYS[3], ARGV[2], id)
+		table.insert(out, task)
+	end
+end
+return out
+`)
+
+// ackBody 删除任务和租约，带键的任务位于键列表 KEYS[4] 头部时把下一个任务放入待执行队列（不早于当前时间），
+// 不带键时没有 KEYS[4]。ARGV: 任务 ID、当前时间
+const ackBody = `
+local data = redis.call('HGET', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[3], ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[1], ARGV[1])
+if not data or not KEYS[4] then
+	return 1
+end
+local list = KEYS[4]
+local head = redis.call('LINDEX', list, 0)
+redis.call('LREM', list, 1, ARGV[1])
+if head ~= ARGV[1] then
+	return 1
+end
+while true do
+	local id = redis.call('LINDEX', list, 0)
+	if not id then
+		return 1
+	end
+	local next = redis.call('HGET', KEYS[1], id)
+	if next then
+		local runAt = cjson.decode(next)['run_at']
+		if runAt < tonumber(ARGV[2]) then
+			runAt = ARGV[2]
+		end
+		redis.call('ZADD', KEYS[2], runAt, id)
+		return 1
+	end
+	redis.call('LPOP', list)
+end
+`
+
+var ackScript = redis.NewScript(ackBody)
+
+// cancelScript 取消未在执行的任务，已被领取时返回 0。KEYS、ARGV 同 ackBody
+var cancelScript = redis.NewScript(`
+if redis.call('ZSCORE', KEYS[3], ARGV[1]) or redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+` + ackBody)
+
+func (q *Queue) keys() []string {
+	return []string{q.tasks, q.ready, q.leases}
+}
+
+// taskKeys 脚本访问的键，带键的任务加上键列表。任务的键在 redis 集群中需要预先声明，
+// 因此先读取任务内容；任务已不存在时只需要清理租约和待执行队列
+func (q *Queue) taskKeys(ctx context.Context, id string) ([]string, error) {
+	keys := q.keys()
+	data, err := q.rdb.HGet(ctx, q.tasks, id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return keys, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var task Task
+	if err = json.Unmarshal(data, &task); err != nil {
+		return nil, err
+	}
+	if task.Key != "" {
+		keys = append(keys, q.keyed+task.Key)
+	}
+	return keys, nil
+}
+
+// Schedule 在 runAt 执行任务
+func (q *Queue) Schedule(ctx context.Context, task *Task, runAt time.Time) error {
+	if task.ID == "" {
+		return errors.New("任务 ID 不能为空")
+	}
+	task.RunAt = runAt.UnixMilli()
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	keys := q.keys()
+	if task.Key != "" {
+		keys = append(keys, q.keyed+task.Key)
+	}
+	return scheduleScript.Run(ctx, q.rdb, keys, task.ID, data, task.RunAt).Err()
+}
+
+// Claim 领取最多 n 个已到期的任务，领取后需要调用 Ack 或 Schedule（重试）
+func (q *Queue) Claim(ctx context.Context, now time.Time, n int) ([]*Task, error) {
+	res, err := claimScript.Run(ctx, q.rdb, q.keys(),
+		now.UnixMilli(), now.Add(q.lease).UnixMilli(), n).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]*Task, 0, len(res))
+	for _, data := range res {
+		var task Task
+		if err = json.Unmarshal([]byte(data), &task); err != nil {
+			return tasks, err
+		}
+		tasks = append(tasks, &task)
+	}
+	return tasks, nil
+}
+
+// Ack 任务完成，从队列中删除
+func (q *Queue) Ack(ctx context.Context, id string) error {
+	keys, err := q.taskKeys(ctx, id)
+	if err != nil {
+		return err
+	}
+	return ackScript.Run(ctx, q.rdb, keys, id, time.Now().UnixMilli()).Err()
+}
+
+// Next 返回最早的待执行时间，队列为空时返回 false
+func (q *Queue) Next(ctx context.Context) (time.Time, bool, error) {
+	res, err := q.rdb.ZRangeWithScores(ctx, q.ready, 0, 0).Result()
+	if err != nil || len(res) == 0 {
+		return time.Time{}, false, err
+	}
+	return time.UnixMilli(int64(res[0].Score)), true, nil
+}
+
+// releaseScript 放弃已领取的任务，立即放回待执行队列。ARGV: 任务 ID、当前时间
+var releaseScript = redis.NewScript(`
+if not redis.call('ZSCORE', KEYS[3], ARGV[1]) then
+	return 0
+end
+redis.call('ZREM', KEYS[3], ARGV[1])
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[1])
+return 1
+`)
+
+// Release 放弃已领取、未完成的任务（如实例退出），其他领取者可以立即领取，不计入失败次数
+func (q *Queue) Release(ctx context.Context, id string) error {
+	return releaseScript.Run(ctx, q.rdb, q.keys(), id, time.Now().UnixMilli()).Err()
+}
+
+// Cancel 取消尚未执行的任务，任务不存在或正在执行时返回 false
+func (q *Queue) Cancel(ctx context.Context, id string) (bool, error) {
+	keys, err := q.taskKeys(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := cancelScript.Run(ctx, q.rdb, keys, id, time.Now().UnixMilli()).Int()
+	return n == 1, err
+}
+
+// Stats 队列中各状态的任务数
+type Stats struct {
+	Ready    int64 `json:"ready"`    // 已到期，等待领取
+	Delayed  int64 `json:"delayed"`  // 未到执行时间（包括等待重试）
+	InFlight int64 `json:"inflight"` // 已领取，正在执行
+	Waiting  int64 `json:"waiting"`  // 带键的任务，等待同一个键之前的任务完成
+}
+
+func (q *Queue) Stats(ctx context.Context) (*Stats, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	pipe := q.rdb.Pipeline()
+	ready := pipe.ZCount(ctx, q.ready, "-inf", now)
+	queued := pipe.ZCard(ctx, q.ready)
+	inflight := pipe.ZCard(ctx, q.leases)
+	total := pipe.HLen(ctx, q.tasks)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return &Stats{
+		Ready:    ready.Val(),
+		Delayed:  queued.Val() - ready.Val(),
+		InFlight: inflight.Val(),
+		Waiting:  max(total.Val()-queued.Val()-inflight.Val(), 0),
+	}, nil
+}
+
+// Entry 列出的任务，Until 为待执行任务的执行时间或执行中任务的租约到期时间（毫秒）
+type Entry struct {
+	*Task
+	Until int64 `json:"until"`
+}
+
+// Pending 按执行时间列出最多 limit 个待执行的任务
+func (q *Queue) Pending(ctx context.Context, limit int64) ([]*Entry, error) {
+	return q.list(ctx, q.ready, limit)
+}
+
+// InFlight 按租约到期时间列出最多 limit 个正在执行的任务
+func (q *Queue) InFlight(ctx context.Context, limit int64) ([]*Entry, error) {
+	return q.list(ctx, q.leases, limit)
+}
+
+func (q *Queue) list(ctx context.Context, set string, limit int64) ([]*Entry, error) {
+	members, err := q.rdb.ZRangeWithScores(ctx, set, 0, limit-1).Result()
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+	ids := make([]string, len(members))
+	for i, m := range members {
+		ids[i] = m.Member.(string)
+	}
+	values, err := q.rdb.HMGet(ctx, q.tasks, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*Entry, 0, len(values))
+	for i, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var task Task
+		if json.Unmarshal([]byte(data), &task) != nil {
+			continue
+		}
+		entries = append(entries, &Entry{Task: &task, Until: int64(members[i].Score)})
+	}
+	return entries, nil
+}
@@ -0,0 +1,212 @@
+package delayqueue
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// testQueue 连接 JOBS_TEST_REDIS 指定的 redis（地址），未设置时跳过。每个测试使用不同的队列名，结束时删除队列的键
+func testQueue(t *testing.T, lease time.Duration) *Queue {
+	t.Helper()
+	addr := os.Getenv("JOBS_TEST_REDIS")
+	if addr == "" {
+		t.Skip("未设置 JOBS_TEST_REDIS，跳过需要 redis 的测试")
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: addr})
+	q := New(rdb, fmt.Sprintf("delayqueue_test:%s:%d", t.Name(), time.Now().UnixNano()), lease)
+	t.Cleanup(func() {
+		ctx := context.Background()
+		keys, _ := rdb.Keys(ctx, q.keyed+"*").Result()
+		rdb.Del(ctx, append(keys, q.keys()...)...)
+		rdb.Close()
+	})
+	return q
+}
+
+func schedule(t *testing.T, q *Queue, id, key string, runAt time.Time) {
+	t.Helper()
+	if err := q.Schedule(context.Background(), &Task{ID: id, Type: "test", Key: key}, runAt); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// claim 在 now 领取任务，返回领取到的任务 ID
+func claim(t *testing.T, q *Queue, now time.Time) []string {
+	t.Helper()
+	tasks, err := q.Claim(context.Background(), now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func sameIDs(got []string, want ...string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestScheduleClaimAck(t *testing.T) {
+	q := testQueue(t, time.Minute)
+	ctx := context.Background()
+	now := time.Now()
+	schedule(t, q, "later", "", now.Add(time.Hour))
+	schedule(t, q, "a", "", now)
+
+	if ids := claim(t, q, now); !sameIDs(ids, "a") {
+		t.Fatalf("claimed %v, want [a]", ids)
+	}
+	stats, err := q.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Ready != 0 || stats.Delayed != 1 || stats.InFlight != 1 {
+		t.Fatalf("stats after claim %+v", stats)
+	}
+	// 已领取的任务不会被再次领取
+	if ids := claim(t, q, now); len(ids) != 0 {
+		t.Fatalf("claimed again %v", ids)
+	}
+
+	if err = q.Ack(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if stats, err = q.Stats(ctx); err != nil || stats.InFlight != 0 || stats.Delayed != 1 {
+		t.Fatalf("stats after ack %+v err %v", stats, err)
+	}
+	if n, _ := q.rdb.HLen(ctx, q.tasks).Result(); n != 1 {
+		t.Fatalf("%d tasks stored after ack, want 1", n)
+	}
+	if ids := claim(t, q, now.Add(time.Hour)); !sameIDs(ids, "later") {
+		t.Fatalf("claimed %v, want [later]", ids)
+	}
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	lease := time.Second
+	q := testQueue(t, lease)
+	now := time.Now()
+	schedule(t, q, "a", "", now)
+
+	if ids := claim(t, q, now); !sameIDs(ids, "a") {
+		t.Fatalf("claimed %v", ids)
+	}
+	if ids := claim(t, q, now.Add(lease/2)); len(ids) != 0 {
+		t.Fatalf("claimed %v before the lease expired", ids)
+	}
+	// 领取者没有 Ack，租约到期后重新投递
+	if ids := claim(t, q, now.Add(lease+time.Millisecond)); !sameIDs(ids, "a") {
+		t.Fatalf("redelivered %v, want [a]", ids)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	q := testQueue(t, time.Minute)
+	ctx := context.Background()
+	now := time.Now()
+	schedule(t, q, "a", "", now)
+	claim(t, q, now)
+
+	if err := q.Release(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	// 不必等待租约到期
+	if ids := claim(t, q, time.Now()); !sameIDs(ids, "a") {
+		t.Fatalf("claimed %v after release, want [a]", ids)
+	}
+	// 未领取的任务不受影响
+	schedule(t, q, "b", "", now.Add(time.Hour))
+	if err := q.Release(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if ids := claim(t, q, time.Now()); len(ids) != 0 {
+		t.Fatalf("release moved a pending task forward: %v", ids)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	q := testQueue(t, time.Minute)
+	ctx := context.Background()
+	now := time.Now()
+	schedule(t, q, "running", "", now)
+	schedule(t, q, "pending", "", now.Add(time.Hour))
+	claim(t, q, now)
+
+	if ok, err := q.Cancel(ctx, "running"); err != nil || ok {
+		t.Fatalf("cancel claimed task: ok %v err %v", ok, err)
+	}
+	if ok, err := q.Cancel(ctx, "pending"); err != nil || !ok {
+		t.Fatalf("cancel pending task: ok %v err %v", ok, err)
+	}
+	if ok, err := q.Cancel(ctx, "missing"); err != nil || ok {
+		t.Fatalf("cancel missing task: ok %v err %v", ok, err)
+	}
+	if ids := claim(t, q, now.Add(time.Hour)); len(ids) != 0 {
+		t.Fatalf("cancelled task claimed: %v", ids)
+	}
+	if stats, err := q.Stats(ctx); err != nil || stats.InFlight != 1 || stats.Delayed != 0 || stats.Ready != 0 {
+		t.Fatalf("stats %+v err %v", stats, err)
+	}
+}
+
+func TestKeyedOrder(t *testing.T) {
+	q := testQueue(t, time.Minute)
+	ctx := context.Background()
+	now := time.Now()
+	schedule(t, q, "first", "k", now)
+	schedule(t, q, "second", "k", now)
+	schedule(t, q, "other", "j", now)
+
+	// 同一个键的第二个任务等待第一个完成，其他键不受影响
+	if ids := claim(t, q, now); !sameIDs(ids, "first", "other") && !sameIDs(ids, "other", "first") {
+		t.Fatalf("claimed %v, want first and other", ids)
+	}
+	stats, err := q.Stats(ctx)
+	if err != nil || stats.Waiting != 1 {
+		t.Fatalf("stats %+v err %v", stats, err)
+	}
+	// 重试（重新调度）不会让出键列表的头部
+	schedule(t, q, "first", "k", now)
+	if ids := claim(t, q, now); !sameIDs(ids, "first") {
+		t.Fatalf("claimed %v after retry, want [first]", ids)
+	}
+
+	if err = q.Ack(ctx, "first"); err != nil {
+		t.Fatal(err)
+	}
+	if ids := claim(t, q, time.Now()); !sameIDs(ids, "second") {
+		t.Fatalf("claimed %v after ack, want [second]", ids)
+	}
+	if err = q.Ack(ctx, "second"); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := q.rdb.Exists(ctx, q.keyed+"k").Result(); n != 0 {
+		t.Fatal("key list not removed after the last task")
+	}
+}
+
+// TestKeysShareSlot 队列的键带有相同的哈希标签，在 redis 集群中位于同一个槽
+func TestKeysShareSlot(t *testing.T) {
+	q := New(nil, "jobs:file_upload", time.Minute)
+	for _, key := range append(q.keys(), q.keyed+"k") {
+		if !strings.HasPrefix(key, "{jobs:file_upload}:") {
+			t.Errorf("key %q does not start with the hash tag", key)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	DefaultBlockSize = 64 << 10 // 64KB
+	MinBlockSize     = 1 << 10
+	MaxBlockSize     = 4 << 20
+	maxLiteral       = 1 << 20 // 单个字面数据操作的最大长度
+)
+
+// Signature 基础版本中一个块的签名：弱校验和用于快速查找，强哈希用于确认
+type Signature struct {
+	Index  int64
+	Weak   uint32
+	Strong []byte
+	Length int // 最后一块可能小于块大小
+}
+
+// Op 增量操作：BlockCount > 0 时复制基础版本从 BlockIndex 开始的连续块，否则写入字面数据 Data
+type Op struct {
+	BlockIndex int64
+	BlockCount int64
+	Data       []byte
+}
+
+// ValidBlockSize 校验块大小，0 使用默认值
+func ValidBlockSize(blockSize int) (int, error) {
+	if blockSize == 0 {
+		return DefaultBlockSize, nil
+	}
+	if blockSize < MinBlockSize || blockSize > MaxBlockSize {
+		return 0, fmt.Errorf("块大小必须在 %d 到 %d 之间", MinBlockSize, MaxBlockSize)
+	}
+	return blockSize, nil
+}
+
+// rolling rsync 的滚动校验和：a 为字节和，b 为按位置加权的和
+type rolling struct {
+	a, b uint32
+	n    uint32
+}
+
+func (r *rolling) init(p []byte) {
+	r.a, r.b, r.n = 0, 0, uint32(len(p))
+	for i, c := range p {
+		r.a += uint32(c)
+		r.b += uint32(len(p)-i) * uint32(c)
+	}
+}
+
+// rollOut 移出窗口最前面的字节
+func (r *rolling) rollOut(c byte) {
+	r.a -= uint32(c)
+	r.b -= r.n * uint32(c)
+	r.n--
+}
+
+// rollIn 在窗口末尾加入一个字节
+func (r *rolling) rollIn(c byte) {
+	r.a += uint32(c)
+	r.b += r.a
+	r.n++
+}
+
+func (r *rolling) sum() uint32 {
+	return r.a&0xffff | r.b<<16
+}
+
+// WeakSum 计算一个块的弱校验和
+func WeakSum(p []byte) uint32 {
+	var r rolling
+	r.init(p)
+	return r.sum()
+}
+
+// StrongSum 计算一个块的强哈希（SHA-256 前 16 字节，最终内容还会整体校验 SHA-256）
+func StrongSum(p []byte) []byte {
+	sum := sha256.Sum256(p)
+	return sum[:16]
+}
+
+// Sign 按块计算基础版本的签名，每计算出一块回调一次
+func Sign(r io.Reader, blockSize int, emit func(Signature) error) error {
+	buf := make([]byte, blockSize)
+	for index := int64(0); ; index++ {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if err := emit(Signature{Index: index, Weak: WeakSum(buf[:n]), Strong: StrongSum(buf[:n]), Length: n}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Diff 根据基础版本的签名计算新内容的增量操作（客户端使用），相邻的块复制会被合并
+func Diff(sigs []Signature, blockSize int, r io.Reader, emit func(Op) error) error {
+	table := make(map[uint32][]*Signature, len(sigs))
+	for i := range sigs {
+		table[sigs[i].Weak] = append(table[sigs[i].Weak], &sigs[i])
+	}
+
+	var (
+		pending *Op    // 尚未发出的块复制，用于合并相邻块
+		literal []byte // 尚未发出的字面数据
+	)
+	flushLiteral := func() error {
+		if len(literal) == 0 {
+			return nil
+		}
+		data := literal
+		literal = nil
+		return emit(Op{Data: data})
+	}
+	flushCopy := func() error {
+		if pending == nil {
+			return nil
+		}
+		op := *pending
+		pending = nil
+		return emit(op)
+	}
+	addCopy := func(index int64) error {
+		if pending != nil && pending.BlockIndex+pending.BlockCount == index {
+			pending.BlockCount++
+			return nil
+		}
+		if err := flushCopy(); err != nil {
+			return err
+		}
+		pending = &Op{BlockIndex: index, BlockCount: 1}
+		return nil
+	}
+	match := func(window []byte, weak uint32) *Signature {
+		candidates := table[weak]
+		if len(candidates) == 0 {
+			return nil
+		}
+		strong := StrongSum(window)
+		for _, sig := range candidates {
+			if sig.Length == len(window) && bytes.Equal(sig.Strong, strong) {
+				return sig
+			}
+		}
+		return nil
+	}
+
+	br := bufio.NewReaderSize(r, blockSize)
+	// buf[start:end] 为当前窗口，空间用完时整体搬到开头
+	buf := make([]byte, 4*blockSize)
+	start, end := 0, 0
+	eof := false
+	fill := func() error {
+		if end-start > 0 {
+			return nil
+		}
+		start, end = 0, 0
+		n, err := io.ReadFull(br, buf[:blockSize])
+		end = n
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			eof = true
+			return nil
+		}
+		return err
+	}
+
+	if err := fill(); err != nil {
+		return err
+	}
+	var rc rolling
+	rc.init(buf[start:end])
+	for end > start {
+		window := buf[start:end]
+		if sig := match(window, rc.sum()); sig != nil {
+			if err := flushLiteral(); err != nil {
+				return err
+			}
+			if err := addCopy(sig.Index); err != nil {
+				return err
+			}
+			start = end
+			if !eof {
+				if err := fill(); err != nil {
+					return err
+				}
+			}
+			rc.init(buf[start:end])
+			continue
+		}
+
+		// 未命中：窗口前移一个字节，移出的字节成为字面数据
+		if err := flushCopy(); err != nil {
+			return err
+		}
+		out := buf[start]
+		literal = append(literal, out)
+		if len(literal) >= maxLiteral {
+			if err := flushLiteral(); err != nil {
+				return err
+			}
+		}
+		start++
+		rc.rollOut(out)
+		if !eof {
+			c, err := br.ReadByte()
+			if err == io.EOF {
+				eof = true
+			} else if err != nil {
+				return err
+			} else {
+				if end == len(buf) {
+					end = copy(buf, buf[start:end])
+					start = 0
+				}
+				buf[end] = c
+				end++
+				rc.rollIn(c)
+			}
+		}
+	}
+	if err := flushCopy(); err != nil {
+		return err
+	}
+	return flushLiteral()
+}
+
+// Apply 将一个增量操作作用到基础版本上，结果写入 w
+func Apply(base io.ReaderAt, baseSize int64, blockSize int, op Op, w io.Writer) error {
+	if op.BlockCount <= 0 {
+		_, err := w.Write(op.Data)
+		return err
+	}
+	blocks := (baseSize + int64(blockSize) - 1) / int64(blockSize)
+	if op.BlockIndex < 0 || op.BlockIndex+op.BlockCount > blocks {
+		return fmt.Errorf("块索引越界: %d+%d > %d", op.BlockIndex, op.BlockCount, blocks)
+	}
+	offset := op.BlockIndex * int64(blockSize)
+	length := op.BlockCount * int64(blockSize)
+	if offset+length > baseSize {
+		length = baseSize - offset
+	}
+	_, err := io.Copy(w, io.NewSectionReader(base, offset, length))
+	return err
+}
@@ -0,0 +1,106 @@
+package delta
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+const testBlockSize = MinBlockSize
+
+func randomBytes(seed int64, n int) []byte {
+	p := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(p)
+	return p
+}
+
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// roundTrip 对 base 签名、计算 target 的增量并应用到 base，返回重建的内容和字面数据的总长度
+func roundTrip(t *testing.T, base, target []byte) ([]byte, int) {
+	t.Helper()
+	var sigs []Signature
+	if err := Sign(bytes.NewReader(base), testBlockSize, func(s Signature) error {
+		sigs = append(sigs, s)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := (len(base) + testBlockSize - 1) / testBlockSize; len(sigs) != want {
+		t.Fatalf("%d signatures, want %d", len(sigs), want)
+	}
+
+	var out bytes.Buffer
+	literal := 0
+	if err := Diff(sigs, testBlockSize, bytes.NewReader(target), func(op Op) error {
+		if op.BlockCount == 0 {
+			literal += len(op.Data)
+		}
+		return Apply(bytes.NewReader(base), int64(len(base)), testBlockSize, op, &out)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes(), literal
+}
+
+func TestRoundTrip(t *testing.T) {
+	base := randomBytes(1, 8*testBlockSize+300) // 最后一块不满
+	insert := randomBytes(2, 100)
+	tests := []struct {
+		name       string
+		base       []byte
+		target     []byte
+		maxLiteral int // 字面数据的最大长度，未变化的块应该被复制
+	}{
+		{"identical", base, base, 0},
+		{"insert", base, concat(base[:3*testBlockSize+17], insert, base[3*testBlockSize+17:]), len(insert) + testBlockSize},
+		{"delete", base, concat(base[:2*testBlockSize+5], base[4*testBlockSize+9:]), testBlockSize},
+		{"change at block boundary", base, concat(base[:testBlockSize-1], []byte{^base[testBlockSize-1], ^base[testBlockSize]}, base[testBlockSize+1:]), 2 * testBlockSize},
+		{"empty base", nil, base, len(base)},
+		{"empty target", base, nil, 0},
+		{"append after partial block", base, concat(base, insert), 300 + len(insert)},
+		{"change in partial block", base, concat(base[:len(base)-10], insert[:10]), 300},
+		{"base shorter than a block", base[:100], concat(base[:100], insert), 100 + len(insert)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, literal := roundTrip(t, tt.base, tt.target)
+			if !bytes.Equal(got, tt.target) {
+				t.Fatalf("rebuilt %d bytes, want %d", len(got), len(tt.target))
+			}
+			if literal > tt.maxLiteral {
+				t.Errorf("literal %d bytes, want at most %d", literal, tt.maxLiteral)
+			}
+		})
+	}
+}
+
+func TestApplyOutOfRange(t *testing.T) {
+	base := randomBytes(3, 2*testBlockSize+1) // 3 块
+	for _, op := range []Op{
+		{BlockIndex: -1, BlockCount: 1},
+		{BlockIndex: 2, BlockCount: 2},
+		{BlockIndex: 3, BlockCount: 1},
+	} {
+		if err := Apply(bytes.NewReader(base), int64(len(base)), testBlockSize, op, &bytes.Buffer{}); err == nil {
+			t.Errorf("Apply(%+v) should fail", op)
+		}
+	}
+	var out bytes.Buffer
+	if err := Apply(bytes.NewReader(base), int64(len(base)), testBlockSize, Op{BlockIndex: 2, BlockCount: 1}, &out); err != nil || !bytes.Equal(out.Bytes(), base[2*testBlockSize:]) {
+		t.Fatalf("last partial block: %d bytes err %v", out.Len(), err)
+	}
+}
+
+func TestValidBlockSize(t *testing.T) {
+	if n, err := ValidBlockSize(0); err != nil || n != DefaultBlockSize {
+		t.Fatalf("default: %d %v", n, err)
+	}
+	for _, n := range []int{MinBlockSize - 1, MaxBlockSize + 1} {
+		if _, err := ValidBlockSize(n); err == nil {
+			t.Errorf("block size %d should be rejected", n)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,6 +18,7 @@ type Register struct {
 	DialTimeout int
 
 	closeCh     chan struct{}
+	stopOnce    sync.Once
 	leasesID    clientv3.LeaseID
 	KeepAliveCh <-chan *clientv3.LeaseKeepAliveResponse
 
@@ -92,6 +94,15 @@ func (r *Register) register() error {
 }
 
 func (r *Register) Stop() {
+	if r.cli == nil {
+		return
+	}
+	// 先停止续约协程，避免租约撤销后又重新注册
+	r.stopOnce.Do(func() {
+		if r.closeCh != nil {
+			close(r.closeCh)
+		}
+	})
 	if err := r.unregister(); err != nil {
 		r.logger.Error("unregister failed, err:", zap.Error(err))
 	}
@@ -99,6 +110,9 @@ func (r *Register) Stop() {
 	if _, err := r.cli.Revoke(context.Background(), r.leasesID); err != nil {
 		r.logger.Error("revoke failed, err:", zap.Error(err))
 	}
+	if err := r.cli.Close(); err != nil {
+		r.logger.Error("close etcd client failed, err:", zap.Error(err))
+	}
 }
 
 // unregister 删除节点
@@ -109,9 +123,12 @@ func (r *Register) unregister() error {
 
 func (r *Register) keepAliveCh() {
 	ticker := time.NewTicker(time.Duration(r.srvTTL) * time.Second)
+	defer ticker.Stop()
 
 	for {
 		select {
+		case <-r.closeCh:
+			return
 		case resp := <-r.KeepAliveCh:
 			if resp == nil {
 				if err := r.register(); err != nil {
@@ -0,0 +1,36 @@
+package discovery
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// ShutdownTimeout 等待处理中的 gRPC 请求完成的最长时间
+const ShutdownTimeout = 10 * time.Second
+
+// WaitSignal 阻塞直到收到 SIGINT 或 SIGTERM
+func WaitSignal() os.Signal {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+	return <-quit
+}
+
+// Shutdown 先从 etcd 注销，网关不再把新请求发到本实例，再等待处理中的请求完成，超过 timeout 后强制关闭
+func Shutdown(register *Register, server *grpc.Server, timeout time.Duration) {
+	register.Stop()
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		server.Stop()
+	}
+}
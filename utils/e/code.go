@@ -22,8 +22,19 @@ const (
 	ErrorAuthToken             = 30003
 	ErrorAuth                  = 30004
 	ErrorAuthNotFound          = 30005
+	ErrorNotAdmin              = 30006
 	ErrorDatabase              = 40001
 
 	ErrorServiceUnavailable = 50003
 	ErrorDeadline           = 50004
+
+	// 文件错误
+	ErrorFilePermission   = 60001
+	ErrorUploadPolicy     = 60002
+	ErrorFileNotScanned   = 60003
+	ErrorFileInfected     = 60004
+	ErrorUploadMismatch   = 60005
+	ErrorTooManyTransfers = 60006
+	ErrorFileNotExist     = 60007
+	ErrorFolderNotExist   = 60008
 )
@@ -14,6 +14,7 @@ var MsgFlags = map[int]string{
 	ErrorNotCompare:            "不匹配",
 	ErrorDatabase:              "数据库操作出错,请重试",
 	ErrorAuthNotFound:          "Token不能为空",
+	ErrorNotAdmin:              "需要管理员权限",
 
 	ErrorServiceUnavailable: "过载保护，服务暂时不可用",
 	ErrorDeadline:           "服务调用超时",
@@ -24,6 +25,15 @@ var MsgFlags = map[int]string{
 	ErrorUserLock:           "用户被锁定",
 	ErrorUserPassword:       "用户密码错误",
 	ErrorUserChangePassword: "用户修改密码错误",
+
+	ErrorFilePermission:   "无权限操作该文件",
+	ErrorUploadPolicy:     "上传被策略拒绝",
+	ErrorFileNotScanned:   "文件尚未通过安全扫描，请稍后再试",
+	ErrorFileInfected:     "文件包含恶意内容，已被隔离",
+	ErrorUploadMismatch:   "上传内容与声明的大小或哈希不一致",
+	ErrorTooManyTransfers: "同时进行的传输过多，请稍后再试",
+	ErrorFileNotExist:     "文件不存在",
+	ErrorFolderNotExist:   "文件夹不存在",
 }
 
 // GetMsg 获取状态码对应信息
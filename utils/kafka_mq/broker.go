@@ -0,0 +1,212 @@
+package kafka_mq
+
+import (
+	"context"
+	"github.com/segmentio/kafka-go"
+	"grpc-todolist-disk/conf"
+	"slices"
+)
+
+const (
+	DriverKafka  = "kafka"
+	DriverMemory = "memory" // 进程内的消息队列，用于单元测试和单机开发
+)
+
+// Publisher 消息生产者，*kafka.Writer 满足该接口。
+// 创建时指定了主题的生产者不能再在消息中指定主题，反之消息中必须指定主题
+type Publisher interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Subscriber 消费组中的消费者，*kafka.Reader 满足该接口。
+// 提交一条消息表示同一分区中它之前的消息都已处理；消费者关闭或分区重新分配后，未提交的消息会被重新投递
+type Subscriber interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// driver 读取配置 kafka.driver，默认 kafka
+func driver() string {
+	if conf.Conf.Kafka == nil || conf.Conf.Kafka.Driver == "" {
+		return DriverKafka
+	}
+	return conf.Conf.Kafka.Driver
+}
+
+// InProcess 是否使用进程内的消息队列，此时只有同一进程中的消费者能收到消息
+func InProcess() bool {
+	return driver() == DriverMemory
+}
+
+// NewPublisher 创建生产者，topic 为空时在消息中指定主题。带键的消息按键选择分区，不带键的轮流写入各分区
+func NewPublisher(topic string) Publisher {
+	if driver() == DriverMemory {
+		return memory.publisher(topic)
+	}
+	return &kafka.Writer{
+		Addr:                   kafka.TCP(conf.Conf.Kafka.Broker...),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{}, // 键相同的消息写入同一分区，保证按键有序
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: topic == "",
+	}
+}
+
+// NewSubscriber 创建指定主题和消费组的消费者
+func NewSubscriber(topic, groupID string) Subscriber {
+	if driver() == DriverMemory {
+		return memory.subscriber(topic, groupID)
+	}
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  conf.Conf.Kafka.Broker,
+		Topic:    topic,
+		GroupID:  groupID,
+		MinBytes: 10e3,
+		MaxBytes: 10e6,
+	})
+}
+
+// ReadTopic 按分区从头读取主题中当前的所有消息，不影响消费组的 offset
+func ReadTopic(ctx context.Context, topic string, fn func(msg *kafka.Message) error) error {
+	if driver() == DriverMemory {
+		for _, msg := range memory.snapshot(topic) {
+			if err := fn(&msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	broker := conf.Conf.Kafka.Broker[0]
+	conn, err := kafka.DialContext(ctx, "tcp", broker)
+	if err != nil {
+		return err
+	}
+	partitions, err := conn.ReadPartitions(topic)
+	conn.Close()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range partitions {
+		leader, err := kafka.DialLeader(ctx, "tcp", broker, topic, p.ID)
+		if err != nil {
+			return err
+		}
+		first, last, err := leader.ReadOffsets()
+		leader.Close()
+		if err != nil {
+			return err
+		}
+		if first >= last {
+			continue
+		}
+		if err = readPartition(ctx, topic, p.ID, first, last, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readPartition(ctx context.Context, topic string, partition int, first, last int64, fn func(msg *kafka.Message) error) error {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   conf.Conf.Kafka.Broker,
+		Topic:     topic,
+		Partition: partition,
+		MaxBytes:  10e6,
+	})
+	defer reader.Close()
+	if err := reader.SetOffset(first); err != nil {
+		return err
+	}
+	for offset := first; offset < last; {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return err
+		}
+		offset = msg.Offset + 1
+		if err = fn(&msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PartitionLag 消费组在一个分区上的进度
+type PartitionLag struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Committed int64  `json:"committed"` // 已提交的 offset，-1 表示还没有提交过
+	End       int64  `json:"end"`       // 下一条消息的 offset
+	Lag       int64  `json:"lag"`       // 尚未提交的消息数
+}
+
+// Lag 查询消费组在主题各分区上的积压
+func Lag(ctx context.Context, topic, groupID string) ([]PartitionLag, error) {
+	if driver() == DriverMemory {
+		return memory.lag(topic, groupID), nil
+	}
+
+	client := &kafka.Client{Addr: kafka.TCP(conf.Conf.Kafka.Broker...)}
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, err
+	}
+	var partitions []int
+	for _, t := range meta.Topics {
+		if t.Name != topic {
+			continue
+		}
+		if t.Error != nil {
+			return nil, t.Error
+		}
+		for _, p := range t.Partitions {
+			partitions = append(partitions, p.ID)
+		}
+	}
+	if len(partitions) == 0 {
+		return nil, nil
+	}
+
+	requests := make([]kafka.OffsetRequest, 0, 2*len(partitions))
+	for _, p := range partitions {
+		requests = append(requests, kafka.FirstOffsetOf(p), kafka.LastOffsetOf(p))
+	}
+	offsets, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{topic: requests}})
+	if err != nil {
+		return nil, err
+	}
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: groupID, Topics: map[string][]int{topic: partitions}})
+	if err != nil {
+		return nil, err
+	}
+	if committed.Error != nil {
+		return nil, committed.Error
+	}
+	commits := map[int]int64{}
+	for _, p := range committed.Topics[topic] {
+		commits[p.Partition] = p.CommittedOffset
+	}
+
+	lags := make([]PartitionLag, 0, len(partitions))
+	for _, p := range offsets.Topics[topic] {
+		if p.Error != nil {
+			return nil, p.Error
+		}
+		c, ok := commits[p.Partition]
+		if !ok {
+			c = -1
+		}
+		lags = append(lags, PartitionLag{
+			Topic:     topic,
+			Partition: p.Partition,
+			Committed: c,
+			End:       p.LastOffset,
+			Lag:       p.LastOffset - max(c, p.FirstOffset),
+		})
+	}
+	slices.SortFunc(lags, func(a, b PartitionLag) int { return a.Partition - b.Partition })
+	return lags, nil
+}
@@ -0,0 +1,69 @@
+package kafka_mq
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// 任务名，kafka_server 按任务名找到处理函数
+const (
+	JobClearCache = "clear_cache"
+	JobFileUpload = "file_upload"
+)
+
+// Envelope 后台任务消息的统一格式
+type Envelope struct {
+	Job            string          `json:"job"`
+	Version        int             `json:"version"`
+	RunAt          int64           `json:"run_at,omitempty"`          // 计划执行时间（毫秒），0 表示立即执行
+	IdempotencyKey string          `json:"idempotency_key,omitempty"` // 幂等键，同一任务重复投递时相同，已处理过的任务不再执行
+	Payload        json.RawMessage `json:"payload"`
+}
+
+// NewEnvelope 生成任务消息，runAt 为零值时立即执行。每次调用生成新的幂等键，
+// 消息重复发送（如 outbox 重发）或重复消费时幂等键不变
+func NewEnvelope(job string, version int, payload interface{}, runAt time.Time) ([]byte, error) {
+	return NewKeyedEnvelope(job, version, newKey(), payload, runAt)
+}
+
+// NewKeyedEnvelope 与 NewEnvelope 相同，但使用调用方指定的幂等键（如上传任务 ID），
+// 调用方重试发送同一任务时不会被重复执行
+func NewKeyedEnvelope(job string, version int, key string, payload interface{}, runAt time.Time) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	env := Envelope{Job: job, Version: version, IdempotencyKey: key, Payload: data}
+	if !runAt.IsZero() {
+		env.RunAt = runAt.UnixMilli()
+	}
+	return json.Marshal(env)
+}
+
+func newKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ParseEnvelope 解析任务消息，不是任务信封（旧格式）时返回 false
+func ParseEnvelope(value []byte) (*Envelope, bool) {
+	var env Envelope
+	if err := json.Unmarshal(value, &env); err != nil || env.Job == "" || env.Payload == nil {
+		return nil, false
+	}
+	return &env, true
+}
+
+// DeadLetter 重试耗尽或不可重试的任务，写入死信主题
+type DeadLetter struct {
+	Envelope
+	TaskID   string   `json:"task_id"`
+	Topic    string   `json:"topic"`         // 原消息所在的主题，重放时发送到这里
+	Key      string   `json:"key,omitempty"` // 原消息的键
+	Attempts int      `json:"attempts"`
+	Errors   []string `json:"errors"`
+	FailedAt int64    `json:"failed_at"`
+}
@@ -0,0 +1,46 @@
+package kafka_mq
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func decodeEnvelope(t *testing.T, value []byte) Envelope {
+	t.Helper()
+	var env Envelope
+	if err := json.Unmarshal(value, &env); err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+// TestEnvelopeKey NewKeyedEnvelope 使用指定的幂等键，NewEnvelope 每次生成新的幂等键
+func TestEnvelopeKey(t *testing.T) {
+	payload := map[string]string{"job_id": "job-1"}
+	for i := 0; i < 2; i++ {
+		value, err := NewKeyedEnvelope(JobFileUpload, 1, "job-1", payload, time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if env := decodeEnvelope(t, value); env.IdempotencyKey != "job-1" || env.Job != JobFileUpload || env.RunAt != 0 {
+			t.Fatalf("keyed envelope %+v", env)
+		}
+	}
+
+	a, err := NewEnvelope(JobFileUpload, 1, payload, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewEnvelope(JobFileUpload, 1, payload, time.UnixMilli(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	envA, envB := decodeEnvelope(t, a), decodeEnvelope(t, b)
+	if envA.IdempotencyKey == "" || envA.IdempotencyKey == envB.IdempotencyKey {
+		t.Fatalf("keys %q and %q", envA.IdempotencyKey, envB.IdempotencyKey)
+	}
+	if envB.RunAt != 1000 {
+		t.Fatalf("run_at %d, want 1000", envB.RunAt)
+	}
+}
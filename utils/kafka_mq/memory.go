@@ -0,0 +1,217 @@
+package kafka_mq
+
+import (
+	"context"
+	"errors"
+	"github.com/segmentio/kafka-go"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// memory 进程内的消息队列（kafka.driver: memory），同一进程中的生产者和消费者共享。
+// 每个主题只有一个分区，消息一直保存在内存中，只适合单元测试和单机开发（cmd/dev）
+var memory = newMemoryBroker()
+
+func newMemoryBroker() *memoryBroker {
+	return &memoryBroker{topics: map[string]*memoryTopic{}, notify: make(chan struct{})}
+}
+
+type memoryBroker struct {
+	mu     sync.Mutex
+	topics map[string]*memoryTopic
+	notify chan struct{} // 状态变化时关闭并替换，唤醒等待消息的消费者
+}
+
+type memoryTopic struct {
+	msgs   []kafka.Message
+	groups map[string]*memoryGroup
+}
+
+// memoryGroup 消费组。与 kafka 相同，唯一的分区同一时间只分配给组内一个消费者，
+// 其他消费者在它关闭后接替，从已提交的 offset 开始消费，未提交的消息因此被重新投递
+type memoryGroup struct {
+	committed int64 // 已提交的 offset，之前的消息都已处理
+	next      int64 // 当前消费者下一条领取的消息
+	owner     *memorySubscriber
+}
+
+var (
+	errMemoryTopic   = errors.New("kafka_mq: 主题不能同时在生产者和消息中指定")
+	errMemoryNoTopic = errors.New("kafka_mq: 未指定主题")
+)
+
+// topic 调用方持有锁
+func (b *memoryBroker) topic(name string) *memoryTopic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &memoryTopic{groups: map[string]*memoryGroup{}}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// broadcast 调用方持有锁
+func (b *memoryBroker) broadcast() {
+	close(b.notify)
+	b.notify = make(chan struct{})
+}
+
+func (b *memoryBroker) snapshot(topic string) []kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.topic(topic).msgs)
+}
+
+func (b *memoryBroker) lag(topic, groupID string) []PartitionLag {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.topic(topic)
+	end, committed := int64(len(t.msgs)), int64(-1)
+	lag := end
+	if g, ok := t.groups[groupID]; ok {
+		committed, lag = g.committed, end-g.committed
+	}
+	return []PartitionLag{{Topic: topic, Committed: committed, End: end, Lag: lag}}
+}
+
+func (b *memoryBroker) publisher(topic string) Publisher {
+	return &memoryPublisher{broker: b, topic: topic}
+}
+
+// subscriber 创建消费者，groupID 为空时单独消费，从第一条消息开始
+func (b *memoryBroker) subscriber(topic, groupID string) Subscriber {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.topic(topic)
+	g, ok := t.groups[groupID]
+	if !ok {
+		g = &memoryGroup{}
+		if groupID != "" {
+			t.groups[groupID] = g
+		}
+	}
+	return &memorySubscriber{broker: b, topic: topic, group: g}
+}
+
+type memoryPublisher struct {
+	broker *memoryBroker
+	topic  string
+	closed bool
+}
+
+func (p *memoryPublisher) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b := p.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if p.closed {
+		return io.ErrClosedPipe
+	}
+	for _, msg := range msgs {
+		if p.topic != "" && msg.Topic != "" {
+			return errMemoryTopic
+		}
+		if p.topic == "" && msg.Topic == "" {
+			return errMemoryNoTopic
+		}
+	}
+	now := time.Now()
+	for _, msg := range msgs {
+		if msg.Topic == "" {
+			msg.Topic = p.topic
+		}
+		if msg.Time.IsZero() {
+			msg.Time = now
+		}
+		t := b.topic(msg.Topic)
+		msg.Partition, msg.Offset = 0, int64(len(t.msgs))
+		t.msgs = append(t.msgs, msg)
+	}
+	b.broadcast()
+	return nil
+}
+
+func (p *memoryPublisher) Close() error {
+	p.broker.mu.Lock()
+	defer p.broker.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+type memorySubscriber struct {
+	broker *memoryBroker
+	topic  string
+	group  *memoryGroup
+	closed bool
+}
+
+// FetchMessage 领取下一条消息，没有消息或分区分配给了同组的其他消费者时等待
+func (s *memorySubscriber) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	b := s.broker
+	for {
+		b.mu.Lock()
+		if s.closed {
+			b.mu.Unlock()
+			return kafka.Message{}, io.EOF
+		}
+		t, g := b.topic(s.topic), s.group
+		if g.owner == nil {
+			g.owner, g.next = s, g.committed
+		}
+		if g.owner == s && g.next < int64(len(t.msgs)) {
+			msg := t.msgs[g.next]
+			g.next++
+			b.mu.Unlock()
+			return msg, nil
+		}
+		notify := b.notify
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		case <-notify:
+		}
+	}
+}
+
+// CommitMessages 提交消息，与 kafka 相同，提交一条消息表示它和之前的消息都已处理
+func (s *memorySubscriber) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	b := s.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s.closed {
+		return io.ErrClosedPipe
+	}
+	g := s.group
+	if g.owner != s {
+		// 分区已经分配给其他消费者，消息会被重新投递
+		return nil
+	}
+	for _, msg := range msgs {
+		if msg.Topic == s.topic && msg.Offset+1 > g.committed {
+			g.committed = msg.Offset + 1
+		}
+	}
+	return nil
+}
+
+// Close 关闭消费者，分区交给同组的其他消费者
+func (s *memorySubscriber) Close() error {
+	b := s.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.group.owner == s {
+		s.group.owner = nil
+	}
+	b.broadcast()
+	return nil
+}
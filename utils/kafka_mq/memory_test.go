@@ -0,0 +1,180 @@
+package kafka_mq
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"grpc-todolist-disk/conf"
+)
+
+// useMemory 测试期间使用新的进程内消息队列
+func useMemory(t *testing.T) {
+	t.Helper()
+	oldConf, oldBroker := conf.Conf, memory
+	conf.Conf = &conf.Config{Kafka: &conf.Kafka{Driver: DriverMemory}}
+	memory = newMemoryBroker()
+	t.Cleanup(func() { conf.Conf, memory = oldConf, oldBroker })
+}
+
+func publish(t *testing.T, topic string, values ...string) {
+	t.Helper()
+	p := NewPublisher(topic)
+	defer p.Close()
+	msgs := make([]kafka.Message, 0, len(values))
+	for _, v := range values {
+		msgs = append(msgs, kafka.Message{Value: []byte(v)})
+	}
+	if err := p.WriteMessages(context.Background(), msgs...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func fetch(t *testing.T, s Subscriber) kafka.Message {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, err := s.FetchMessage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// fetchNone 断言短时间内没有可领取的消息
+func fetchNone(t *testing.T, s Subscriber) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if msg, err := s.FetchMessage(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected message %q err %v", msg.Value, err)
+	}
+}
+
+func TestMemoryRedeliverUncommitted(t *testing.T) {
+	useMemory(t)
+	publish(t, "jobs", "a", "b", "c")
+
+	first := NewSubscriber("jobs", "g")
+	a, b := fetch(t, first), fetch(t, first)
+	if string(a.Value) != "a" || string(b.Value) != "b" || b.Offset != 1 {
+		t.Fatalf("fetched %q@%d %q@%d", a.Value, a.Offset, b.Value, b.Offset)
+	}
+	// 分区分配给了 first，同组的 second 等待
+	second := NewSubscriber("jobs", "g")
+	defer second.Close()
+	fetchNone(t, second)
+
+	if err := first.CommitMessages(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+	first.Close()
+	if _, err := first.FetchMessage(context.Background()); !errors.Is(err, io.EOF) {
+		t.Fatalf("fetch after close: %v", err)
+	}
+	// second 接替后从已提交的 offset 开始，已领取未提交的 b 被重新投递
+	if msg := fetch(t, second); string(msg.Value) != "b" {
+		t.Fatalf("redelivered %q, want b", msg.Value)
+	}
+	if msg := fetch(t, second); string(msg.Value) != "c" {
+		t.Fatalf("next %q, want c", msg.Value)
+	}
+}
+
+func TestMemoryCommit(t *testing.T) {
+	useMemory(t)
+	publish(t, "jobs", "a", "b", "c")
+
+	s := NewSubscriber("jobs", "g")
+	a, b, c := fetch(t, s), fetch(t, s), fetch(t, s)
+	// 提交 b 表示 a 也已处理，之后提交较小的 offset 不会回退
+	if err := s.CommitMessages(context.Background(), b, a); err != nil {
+		t.Fatal(err)
+	}
+	lags, err := Lag(context.Background(), "jobs", "g")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lags) != 1 || lags[0].Committed != 2 || lags[0].End != 3 || lags[0].Lag != 1 {
+		t.Fatalf("lag %+v", lags)
+	}
+	// 其他主题的消息不影响该主题的进度
+	if err = s.CommitMessages(context.Background(), kafka.Message{Topic: "other", Offset: 10}); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	if err = s.CommitMessages(context.Background(), c); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("commit after close: %v", err)
+	}
+
+	next := NewSubscriber("jobs", "g")
+	defer next.Close()
+	if msg := fetch(t, next); string(msg.Value) != "c" {
+		t.Fatalf("after commit got %q, want c", msg.Value)
+	}
+	// 分区交给 next 后，旧消费者的提交被忽略
+	stale := &memorySubscriber{broker: memory, topic: "jobs", group: next.(*memorySubscriber).group}
+	if err = stale.CommitMessages(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if lags, _ = Lag(context.Background(), "jobs", "g"); lags[0].Committed != 2 {
+		t.Fatalf("stale commit advanced offset: %+v", lags)
+	}
+}
+
+func TestMemoryGroupsAndTopics(t *testing.T) {
+	useMemory(t)
+	publish(t, "jobs", "a")
+
+	// 不同消费组各自从头消费
+	g1, g2 := NewSubscriber("jobs", "g1"), NewSubscriber("jobs", "g2")
+	defer g1.Close()
+	defer g2.Close()
+	if string(fetch(t, g1).Value) != "a" || string(fetch(t, g2).Value) != "a" {
+		t.Fatal("each group should receive the message")
+	}
+
+	// 等待中的消费者在新消息写入后被唤醒
+	got := make(chan kafka.Message, 1)
+	go func() {
+		msg, _ := g1.FetchMessage(context.Background())
+		got <- msg
+	}()
+	time.Sleep(20 * time.Millisecond)
+	publish(t, "jobs", "b")
+	select {
+	case msg := <-got:
+		if string(msg.Value) != "b" {
+			t.Fatalf("woke with %q", msg.Value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiting consumer not woken")
+	}
+
+	// 生产者和消息只能有一处指定主题
+	p := NewPublisher("jobs")
+	if err := p.WriteMessages(context.Background(), kafka.Message{Topic: "x"}); !errors.Is(err, errMemoryTopic) {
+		t.Fatalf("topic twice: %v", err)
+	}
+	anyTopic := NewPublisher("")
+	if err := anyTopic.WriteMessages(context.Background(), kafka.Message{}); !errors.Is(err, errMemoryNoTopic) {
+		t.Fatalf("no topic: %v", err)
+	}
+	if err := anyTopic.WriteMessages(context.Background(), kafka.Message{Topic: "dlq", Value: []byte("x")}); err != nil {
+		t.Fatal(err)
+	}
+	var read []string
+	if err := ReadTopic(context.Background(), "dlq", func(msg *kafka.Message) error {
+		read = append(read, string(msg.Value))
+		return nil
+	}); err != nil || len(read) != 1 || read[0] != "x" {
+		t.Fatalf("read topic %v err %v", read, err)
+	}
+	p.Close()
+	if err := p.WriteMessages(context.Background(), kafka.Message{}); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("write after close: %v", err)
+	}
+}
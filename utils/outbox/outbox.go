@@ -0,0 +1,228 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"grpc-todolist-disk/utils/kafka_mq"
+)
+
+const (
+	// batchSize 每次发送的最多条数
+	batchSize = 100
+	// pollInterval 没有被 Notify 唤醒时检查未发送消息的间隔
+	pollInterval = time.Second
+	// retention 已发送消息的保留时间，便于排查
+	retention = 7 * 24 * time.Hour
+	// cleanInterval 清理已发送消息的间隔
+	cleanInterval = time.Hour
+	// maxAttempts 发送失败达到该次数的消息不再发送，留在表中等待人工处理
+	maxAttempts = 20
+	// retryDelayMax 发送失败后的重试间隔从 1 秒开始翻倍，最长 5 分钟
+	retryDelayMax = 5 * time.Minute
+)
+
+// Message 待发送的消息，与业务数据在同一事务中写入，由 Relay 发送到 kafka
+type Message struct {
+	ID            uint64     `gorm:"primarykey"`
+	Topic         string     `gorm:"type:varchar(255)"`
+	Key           string     `gorm:"type:varchar(255)"`
+	Value         []byte     `gorm:"type:mediumblob"`
+	Attempts      int        // 发送失败的次数，达到 maxAttempts 后不再发送
+	LastError     string     `gorm:"type:varchar(1024)"`
+	NextAttemptAt *time.Time // 发送失败后下次重试的时间
+	SentAt        *time.Time `gorm:"index"` // 为空表示尚未发送
+	CreatedAt     time.Time
+}
+
+func (Message) TableName() string {
+	return "outbox"
+}
+
+var wake = make(chan struct{}, 1)
+
+// Add 在事务 tx 中写入待发送的消息，事务提交后调用 Notify 可以让本进程的 Relay 立即发送
+func Add(tx *gorm.DB, topic, key string, value []byte) error {
+	return tx.Create(&Message{Topic: topic, Key: key, Value: value}).Error
+}
+
+// AddEnvelope 在事务 tx 中写入 kafka_server 的任务，runAt 为零值时立即执行
+func AddEnvelope(tx *gorm.DB, topic, key, job string, version int, payload any, runAt time.Time) error {
+	value, err := kafka_mq.NewEnvelope(job, version, payload, runAt)
+	if err != nil {
+		return err
+	}
+	return Add(tx, topic, key, value)
+}
+
+// Notify 唤醒本进程的 Relay
+func Notify() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// Relay 把 outbox 中未发送的消息按写入顺序发送到 kafka，发送成功后标记为已发送。
+// 发送成功、标记失败时消息会被再次发送（至少一次），消费者需要能够处理重复消息；
+// 发送失败的消息退避后重试，期间后写入的消息照常发送，失败 maxAttempts 次后不再发送；
+// 多个实例通过 SKIP LOCKED 领取不同的消息，可以同时运行
+type Relay struct {
+	db        *gorm.DB
+	publisher kafka_mq.Publisher // 不指定主题的生产者
+}
+
+func NewRelay(db *gorm.DB, publisher kafka_mq.Publisher) *Relay {
+	return &Relay{db: db, publisher: publisher}
+}
+
+// Run 持续发送未发送的消息，直到 ctx 结束。正在发送的一批消息被中断时事务回滚，之后重新发送
+func (r *Relay) Run(ctx context.Context) {
+	lastClean := time.Time{}
+	for ctx.Err() == nil {
+		n, err := r.relay(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("outbox 发送失败: %v", err)
+		}
+		if time.Since(lastClean) > cleanInterval {
+			r.clean()
+			lastClean = time.Now()
+		}
+		if n == batchSize {
+			// 可能还有未发送的消息
+			continue
+		}
+
+		timer := time.NewTimer(pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// relay 领取一批未发送的消息发送，返回发送的条数
+func (r *Relay) relay(ctx context.Context) (int, error) {
+	sent := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var list []*Message
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("sent_at IS NULL AND attempts < ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)", maxAttempts, now).
+			Order("id").Limit(batchSize).Find(&list).Error
+		if err != nil || len(list) == 0 {
+			return err
+		}
+
+		msgs := make([]kafka.Message, len(list))
+		for i, m := range list {
+			msgs[i] = kafka.Message{Topic: m.Topic, Key: []byte(m.Key), Value: m.Value}
+		}
+		errs := r.send(ctx, msgs)
+		if ctx.Err() != nil {
+			// 被中断时回滚，之后重新发送，不计入失败次数
+			return ctx.Err()
+		}
+		var ids []uint64
+		for i, m := range list {
+			switch {
+			case errs[i] == nil:
+				ids = append(ids, m.ID)
+			case errors.Is(errs[i], errNotSent):
+			default:
+				if err = r.fail(tx, m, errs[i]); err != nil {
+					return err
+				}
+			}
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err = tx.Model(&Message{}).Where("id IN ?", ids).Update("sent_at", &now).Error; err != nil {
+			return err
+		}
+		sent = len(ids)
+		return nil
+	})
+	return sent, err
+}
+
+// errNotSent 逐条发送时前面的消息失败，该消息没有发送
+var errNotSent = errors.New("outbox: not sent")
+
+// send 发送一批消息，返回每条消息的结果。整批失败且无法区分是哪条消息导致时逐条发送，
+// 遇到失败的消息停止，之后的消息不计入失败次数，下一轮再发送。
+// 这样一条无法发送的消息（如超过大小限制）不会拖住同一批的其他消息
+func (r *Relay) send(ctx context.Context, msgs []kafka.Message) []error {
+	errs := make([]error, len(msgs))
+	err := r.publisher.WriteMessages(ctx, msgs...)
+	if err == nil {
+		return errs
+	}
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) && len(writeErrs) == len(msgs) {
+		copy(errs, writeErrs)
+		return errs
+	}
+	if len(msgs) == 1 {
+		errs[0] = err
+		return errs
+	}
+	for i := range msgs {
+		if err = r.publisher.WriteMessages(ctx, msgs[i]); err != nil {
+			errs[i] = err
+			for j := i + 1; j < len(msgs); j++ {
+				errs[j] = errNotSent
+			}
+			break
+		}
+	}
+	return errs
+}
+
+// fail 记录发送失败，退避后重试，达到 maxAttempts 次后不再发送
+func (r *Relay) fail(tx *gorm.DB, m *Message, sendErr error) error {
+	m.Attempts++
+	next := time.Now().Add(retryDelay(m.Attempts))
+	if m.Attempts >= maxAttempts {
+		log.Printf("outbox 消息 %d（主题 %s）发送失败 %d 次，不再发送: %v", m.ID, m.Topic, m.Attempts, sendErr)
+	}
+	return tx.Model(&Message{}).Where("id = ?", m.ID).Updates(map[string]any{
+		"attempts":        m.Attempts,
+		"last_error":      truncate(sendErr.Error(), 1024),
+		"next_attempt_at": &next,
+	}).Error
+}
+
+// retryDelay 第 attempts 次失败后的重试间隔
+func retryDelay(attempts int) time.Duration {
+	if attempts > 16 {
+		return retryDelayMax
+	}
+	return min(time.Second<<(attempts-1), retryDelayMax)
+}
+
+// clean 删除超过保留时间的已发送消息
+func (r *Relay) clean() {
+	err := r.db.Where("sent_at < ?", time.Now().Add(-retention)).Delete(&Message{}).Error
+	if err != nil {
+		log.Printf("outbox 清理失败: %v", err)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return strings.ToValidUTF8(s[:n], "")
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// fakePublisher 值为 "bad" 的消息无法发送；一批中有这样的消息时按 batchErr 整批失败
+type fakePublisher struct {
+	batchErr error
+	written  []string
+}
+
+var errTooLarge = errors.New("message too large")
+
+func (p *fakePublisher) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	for _, m := range msgs {
+		if string(m.Value) == "bad" {
+			if p.batchErr != nil {
+				return p.batchErr
+			}
+			return errTooLarge
+		}
+	}
+	for _, m := range msgs {
+		p.written = append(p.written, string(m.Value))
+	}
+	return nil
+}
+
+func (p *fakePublisher) Close() error { return nil }
+
+func values(vs ...string) []kafka.Message {
+	msgs := make([]kafka.Message, len(vs))
+	for i, v := range vs {
+		msgs[i] = kafka.Message{Value: []byte(v)}
+	}
+	return msgs
+}
+
+func TestSendIsolatesPoisonMessage(t *testing.T) {
+	p := &fakePublisher{}
+	r := NewRelay(nil, p)
+
+	errs := r.send(context.Background(), values("a", "b"))
+	if errs[0] != nil || errs[1] != nil || len(p.written) != 2 {
+		t.Fatalf("batch: errs %v written %v", errs, p.written)
+	}
+
+	// 整批失败后逐条发送，失败的消息之前的照常发送，之后的留到下一轮
+	p.written = nil
+	errs = r.send(context.Background(), values("a", "bad", "c"))
+	if errs[0] != nil || !errors.Is(errs[1], errTooLarge) || !errors.Is(errs[2], errNotSent) {
+		t.Fatalf("errs %v", errs)
+	}
+	if len(p.written) != 1 || p.written[0] != "a" {
+		t.Fatalf("written %v", p.written)
+	}
+
+	// 能区分每条消息的错误时直接使用
+	p.batchErr = kafka.WriteErrors{nil, errTooLarge}
+	p.written = nil
+	errs = r.send(context.Background(), values("a", "bad"))
+	if errs[0] != nil || !errors.Is(errs[1], errTooLarge) || len(p.written) != 0 {
+		t.Fatalf("write errors: errs %v written %v", errs, p.written)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		1:           time.Second,
+		2:           2 * time.Second,
+		9:           256 * time.Second,
+		10:          retryDelayMax,
+		maxAttempts: retryDelayMax,
+		100:         retryDelayMax,
+	} {
+		if got := retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"grpc-todolist-disk/conf"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// 上传接口，网关路由和 files 服务的 gRPC 方法共用同一组名称
+const (
+	RouteFileUpload         = "file_upload"
+	RouteAsyncFileUpload    = "async_file_upload"
+	RouteBigFileUpload      = "big_file_upload"
+	RouteQiniuFileUpload    = "qiniu_file_upload"
+	RouteQiniuBigFileUpload = "qiniu_big_file_upload"
+	RouteQiniuDirectUpload  = "qiniu_direct_upload" // 客户端持凭证直传七牛云
+	RouteDirectUpload       = "direct_upload"       // 客户端持预签名地址直传，确认后入库
+	RouteDeltaUpload        = "delta_upload"
+)
+
+// 拒绝规则
+const (
+	RuleRouteSize   = "route_size"   // 超过接口的单文件上限
+	RuleTierSize    = "tier_size"    // 超过用户等级的单文件上限
+	RuleDailyVolume = "daily_volume" // 超过用户等级的每日上传总量
+	RuleExtension   = "extension"    // 扩展名不允许
+	RuleMimeType    = "mime_type"    // 按内容识别的类型不允许
+	RuleFilename    = "filename"     // 文件名不合法
+)
+
+// SniffLen 识别内容类型需要的字节数
+const SniffLen = 512
+
+// defaultPolicy 未配置策略时沿用原来的 10MB 表单上传限制
+var defaultPolicy = &conf.Policy{
+	RouteMaxSize: map[string]int64{
+		RouteFileUpload:      10 << 20,
+		RouteAsyncFileUpload: 10 << 20,
+		RouteQiniuFileUpload: 10 << 20,
+	},
+	MaxFilenameLength: 255,
+}
+
+// Rejection 结构化的拒绝原因
+type Rejection struct {
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+	Limit  int64  `json:"limit,omitempty"`
+	Actual int64  `json:"actual,omitempty"`
+}
+
+func (r *Rejection) Error() string {
+	return r.Reason
+}
+
+type Engine struct {
+	p *conf.Policy
+}
+
+// New 使用指定的策略，p 为 nil 时使用默认策略
+func New(p *conf.Policy) *Engine {
+	if p == nil {
+		p = defaultPolicy
+	}
+	return &Engine{p: p}
+}
+
+// Default 使用配置文件中的策略
+func Default() *Engine {
+	if conf.Conf == nil {
+		return New(nil)
+	}
+	return New(conf.Conf.Policy)
+}
+
+// MaxSize 返回接口和用户等级两者中更严格的单文件上限，0 表示不限制
+func (e *Engine) MaxSize(route string, userID uint64) (int64, string) {
+	limit, rule := e.p.RouteMaxSize[route], RuleRouteSize
+	if _, tier := conf.TierOf(uint(userID)); tier != nil && tier.MaxFileSize > 0 {
+		if limit <= 0 || tier.MaxFileSize < limit {
+			limit, rule = tier.MaxFileSize, RuleTierSize
+		}
+	}
+	return limit, rule
+}
+
+// CheckFile 校验文件名、扩展名和文件大小，size < 0 表示大小未知（流式上传时再用 CheckSize 校验）
+func (e *Engine) CheckFile(route string, userID uint64, filename string, size int64) *Rejection {
+	if rej := e.checkFilename(filename); rej != nil {
+		return rej
+	}
+	if rej := e.checkExtension(filename); rej != nil {
+		return rej
+	}
+	if size >= 0 {
+		return e.CheckSize(route, userID, size)
+	}
+	return nil
+}
+
+// CheckSize 校验已知或已接收的大小
+func (e *Engine) CheckSize(route string, userID uint64, size int64) *Rejection {
+	limit, rule := e.MaxSize(route, userID)
+	if limit > 0 && size > limit {
+		return &Rejection{
+			Rule:   rule,
+			Reason: fmt.Sprintf("文件大小超过限制（%s）", formatSize(limit)),
+			Limit:  limit,
+			Actual: size,
+		}
+	}
+	return nil
+}
+
+// CheckContent 按文件开头的内容识别类型并校验，head 取前 SniffLen 字节即可
+func (e *Engine) CheckContent(head []byte) *Rejection {
+	typ := Sniff(head)
+	for _, denied := range e.p.DeniedMimeTypes {
+		if strings.HasPrefix(typ, strings.ToLower(denied)) {
+			return &Rejection{Rule: RuleMimeType, Reason: "不允许上传该类型的文件: " + typ}
+		}
+	}
+	if len(e.p.AllowedMimeTypes) == 0 {
+		return nil
+	}
+	for _, allowed := range e.p.AllowedMimeTypes {
+		if strings.HasPrefix(typ, strings.ToLower(allowed)) {
+			return nil
+		}
+	}
+	return &Rejection{Rule: RuleMimeType, Reason: "不允许上传该类型的文件: " + typ}
+}
+
+// DailyVolume 返回用户每日上传总量上限，0 表示不限制
+func (e *Engine) DailyVolume(userID uint64) int64 {
+	if _, tier := conf.TierOf(uint(userID)); tier != nil {
+		return tier.DailyVolume
+	}
+	return 0
+}
+
+// CheckDailyVolume used 为当日已上传的总量
+func (e *Engine) CheckDailyVolume(userID uint64, used, size int64) *Rejection {
+	limit := e.DailyVolume(userID)
+	if limit > 0 && used+size > limit {
+		return &Rejection{
+			Rule:   RuleDailyVolume,
+			Reason: fmt.Sprintf("超过每日上传总量限制（%s），今日已上传 %s", formatSize(limit), formatSize(used)),
+			Limit:  limit,
+			Actual: used + size,
+		}
+	}
+	return nil
+}
+
+func (e *Engine) checkFilename(filename string) *Rejection {
+	reject := func(reason string) *Rejection {
+		return &Rejection{Rule: RuleFilename, Reason: reason}
+	}
+	if strings.TrimSpace(filename) == "" {
+		return reject("文件名不能为空")
+	}
+	if e.p.MaxFilenameLength > 0 && len(filename) > e.p.MaxFilenameLength {
+		return &Rejection{
+			Rule:   RuleFilename,
+			Reason: fmt.Sprintf("文件名过长（最多 %d 字节）", e.p.MaxFilenameLength),
+			Limit:  int64(e.p.MaxFilenameLength),
+			Actual: int64(len(filename)),
+		}
+	}
+	if filename == "." || filename == ".." || strings.ContainsAny(filename, `/\`) {
+		return reject("文件名不能包含路径")
+	}
+	if strings.IndexFunc(filename, unicode.IsControl) >= 0 {
+		return reject("文件名不能包含控制字符")
+	}
+	lower := strings.ToLower(filename)
+	for _, pattern := range e.p.DeniedFilenames {
+		if ok, _ := path.Match(strings.ToLower(pattern), lower); ok {
+			return reject("不允许上传该文件名: " + filename)
+		}
+	}
+	return nil
+}
+
+func (e *Engine) checkExtension(filename string) *Rejection {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, denied := range e.p.DeniedExtensions {
+		if ext == normalizeExt(denied) {
+			return &Rejection{Rule: RuleExtension, Reason: "不允许上传该扩展名的文件: " + ext}
+		}
+	}
+	if len(e.p.AllowedExtensions) == 0 {
+		return nil
+	}
+	for _, allowed := range e.p.AllowedExtensions {
+		if ext == normalizeExt(allowed) {
+			return nil
+		}
+	}
+	return &Rejection{Rule: RuleExtension, Reason: "不允许上传该扩展名的文件: " + ext}
+}
+
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// Sniff 按内容识别 MIME 类型（不含参数），在 http.DetectContentType 的基础上补充可执行文件
+func Sniff(head []byte) string {
+	if len(head) > SniffLen {
+		head = head[:SniffLen]
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte("MZ")):
+		return "application/x-msdownload"
+	case bytes.HasPrefix(head, []byte("\x7fELF")):
+		return "application/x-executable"
+	}
+	typ, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return typ
+}
+
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<30 && n%(1<<30) == 0:
+		return fmt.Sprintf("%dGB", n>>30)
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", n>>10)
+	default:
+		return fmt.Sprintf("%d 字节", n)
+	}
+}
@@ -0,0 +1,193 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"grpc-todolist-disk/conf"
+)
+
+const (
+	freeUser = 1 // 默认等级
+	vipUser  = 2
+)
+
+// useTiers 测试期间使用的用户等级：free 单文件 1MB、每日 10MB，vip 单文件 1GB、每日不限
+func useTiers(t *testing.T) {
+	t.Helper()
+	old := conf.Conf
+	conf.Conf = &conf.Config{Tiers: &conf.Tiers{
+		Default: "free",
+		Levels: map[string]*conf.Tier{
+			"free": {MaxFileSize: 1 << 20, DailyVolume: 10 << 20},
+			"vip":  {UserIDs: []uint{vipUser}, MaxFileSize: 1 << 30},
+		},
+	}}
+	t.Cleanup(func() { conf.Conf = old })
+}
+
+func rule(rej *Rejection) string {
+	if rej == nil {
+		return ""
+	}
+	return rej.Rule
+}
+
+func TestCheckSize(t *testing.T) {
+	useTiers(t)
+	e := New(&conf.Policy{RouteMaxSize: map[string]int64{
+		RouteFileUpload:    10 << 20,
+		RouteBigFileUpload: 2 << 30,
+	}})
+	tests := []struct {
+		route  string
+		userID uint64
+		size   int64
+		want   string
+	}{
+		// 等级上限比接口上限更严格
+		{RouteFileUpload, freeUser, 1 << 20, ""},
+		{RouteFileUpload, freeUser, 1<<20 + 1, RuleTierSize},
+		// 接口上限比等级上限更严格
+		{RouteFileUpload, vipUser, 10 << 20, ""},
+		{RouteFileUpload, vipUser, 10<<20 + 1, RuleRouteSize},
+		{RouteBigFileUpload, vipUser, 1 << 30, ""},
+		{RouteBigFileUpload, vipUser, 1<<30 + 1, RuleTierSize},
+		// 接口未配置上限时只按等级限制
+		{RouteDeltaUpload, vipUser, 1<<30 + 1, RuleTierSize},
+		{RouteDeltaUpload, freeUser, 1 << 20, ""},
+	}
+	for _, tt := range tests {
+		rej := e.CheckSize(tt.route, tt.userID, tt.size)
+		if rule(rej) != tt.want {
+			t.Errorf("CheckSize(%s, user %d, %d) = %v, want %q", tt.route, tt.userID, tt.size, rej, tt.want)
+			continue
+		}
+		if rej != nil && (rej.Actual != tt.size || rej.Limit <= 0 || rej.Limit >= tt.size) {
+			t.Errorf("CheckSize(%s, user %d, %d) limit %d actual %d", tt.route, tt.userID, tt.size, rej.Limit, rej.Actual)
+		}
+	}
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	old := conf.Conf
+	conf.Conf = nil
+	t.Cleanup(func() { conf.Conf = old })
+
+	e := Default()
+	if rej := e.CheckFile(RouteFileUpload, freeUser, "a.txt", 10<<20+1); rule(rej) != RuleRouteSize || !strings.Contains(rej.Reason, "10MB") {
+		t.Fatalf("form upload over 10MB: %v", rej)
+	}
+	// 未配置等级时流式上传不限制大小
+	if rej := e.CheckFile(RouteBigFileUpload, freeUser, "a.txt", 1<<40); rej != nil {
+		t.Fatalf("big file upload: %v", rej)
+	}
+	if rej := e.CheckFile(RouteFileUpload, freeUser, strings.Repeat("a", 256), 1); rule(rej) != RuleFilename {
+		t.Fatalf("long filename: %v", rej)
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	useTiers(t)
+	e := New(&conf.Policy{
+		DeniedExtensions:  []string{"exe", ".BAT"},
+		MaxFilenameLength: 20,
+		DeniedFilenames:   []string{".htaccess", "thumbs.*"},
+	})
+	tests := []struct {
+		filename string
+		size     int64
+		want     string
+	}{
+		{"report.pdf", 100, ""},
+		{"README", 100, ""},
+		{"setup.exe", 100, RuleExtension},
+		{"SETUP.EXE", 100, RuleExtension},
+		{"run.bat", 100, RuleExtension},
+		{"", 100, RuleFilename},
+		{"  ", 100, RuleFilename},
+		{"..", 100, RuleFilename},
+		{"a/b.txt", 100, RuleFilename},
+		{`a\b.txt`, 100, RuleFilename},
+		{"a\nb.txt", 100, RuleFilename},
+		{strings.Repeat("a", 21), 100, RuleFilename},
+		{".HTACCESS", 100, RuleFilename},
+		{"Thumbs.db", 100, RuleFilename},
+		// 文件名先于大小校验
+		{"setup.exe", 2 << 20, RuleExtension},
+		{"big.pdf", 2 << 20, RuleTierSize},
+		// 大小未知时不校验大小
+		{"big.pdf", -1, ""},
+	}
+	for _, tt := range tests {
+		if rej := e.CheckFile(RouteFileUpload, freeUser, tt.filename, tt.size); rule(rej) != tt.want {
+			t.Errorf("CheckFile(%q, %d) = %v, want %q", tt.filename, tt.size, rej, tt.want)
+		}
+	}
+
+	allow := New(&conf.Policy{AllowedExtensions: []string{".jpg", "png"}})
+	for filename, want := range map[string]string{"a.jpg": "", "a.PNG": "", "a.gif": RuleExtension, "noext": RuleExtension} {
+		if rej := allow.CheckFile(RouteFileUpload, vipUser, filename, 1); rule(rej) != want {
+			t.Errorf("allow list CheckFile(%q) = %v, want %q", filename, rej, want)
+		}
+	}
+}
+
+func TestCheckContent(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+	text := []byte("hello world\n")
+	exe := []byte("MZ\x90\x00\x03\x00\x00\x00")
+	elf := []byte("\x7fELF\x02\x01\x01\x00")
+
+	deny := New(&conf.Policy{DeniedMimeTypes: []string{"application/x-msdownload", "application/x-executable"}})
+	allow := New(&conf.Policy{AllowedMimeTypes: []string{"image/", "TEXT/"}})
+	tests := []struct {
+		name  string
+		e     *Engine
+		head  []byte
+		allow bool
+	}{
+		{"deny exe", deny, exe, false},
+		{"deny elf", deny, elf, false},
+		{"deny passes text", deny, text, true},
+		{"allow image", allow, png, true},
+		{"allow text case insensitive", allow, text, true},
+		{"allow rejects exe", allow, exe, false},
+		{"no rules", New(&conf.Policy{}), exe, true},
+	}
+	for _, tt := range tests {
+		rej := tt.e.CheckContent(tt.head)
+		if (rej == nil) != tt.allow || (rej != nil && rej.Rule != RuleMimeType) {
+			t.Errorf("%s: %v", tt.name, rej)
+		}
+	}
+}
+
+func TestCheckDailyVolume(t *testing.T) {
+	useTiers(t)
+	e := New(nil)
+	if rej := e.CheckDailyVolume(freeUser, 9<<20, 1<<20); rej != nil {
+		t.Fatalf("exactly at the limit: %v", rej)
+	}
+	rej := e.CheckDailyVolume(freeUser, 9<<20, 1<<20+1)
+	if rule(rej) != RuleDailyVolume || rej.Limit != 10<<20 || rej.Actual != 10<<20+1 {
+		t.Fatalf("over the limit: %+v", rej)
+	}
+	if rej = e.CheckDailyVolume(vipUser, 1<<40, 1<<30); rej != nil {
+		t.Fatalf("vip has no daily limit: %v", rej)
+	}
+}
+
+func TestSniff(t *testing.T) {
+	for head, want := range map[string]string{
+		"MZ\x90\x00":           "application/x-msdownload",
+		"\x7fELF\x02":          "application/x-executable",
+		"%PDF-1.7\n":           "application/pdf",
+		"<html><body></body>":  "text/html",
+		"\x00\x01\x02\x03\x04": "application/octet-stream",
+	} {
+		if got := Sniff([]byte(head)); got != want {
+			t.Errorf("Sniff(%q) = %q, want %q", head, got, want)
+		}
+	}
+}
@@ -3,24 +3,43 @@ package qiniu
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/qiniu/go-sdk/v7/auth"
 	"github.com/qiniu/go-sdk/v7/auth/qbox"
 	"github.com/qiniu/go-sdk/v7/storage"
 	"grpc-todolist-disk/conf"
 )
 
+const (
+	defaultURLExpire   = 3600
+	defaultTokenExpire = 3600
+)
+
+// ErrNotFound 七牛云上不存在该对象
+var ErrNotFound = errors.New("七牛云文件不存在")
+
 type QiniuClient struct {
-	mac        *qbox.Mac                 // 鉴权用的 MAC 实例（AK/SK）
-	cfg        *storage.Config           // 存储配置，包括区域、是否使用 HTTPS
-	bucket     string                    // 存储空间名
-	domain     string                    // 对外访问域名（用于拼接 URL）
-	uploader   *storage.FormUploader     // 表单上传工具
-	resumeUpV2 *storage.ResumeUploaderV2 // 分片上传工具（适用于流式上传）
+	mac         *qbox.Mac                 // 鉴权用的 MAC 实例（AK/SK）
+	cfg         *storage.Config           // 存储配置，包括区域、是否使用 HTTPS
+	bucket      string                    // 存储空间名
+	domain      string                    // 对外访问域名（用于拼接 URL）
+	private     bool                      // 私有空间，下载地址需要签名
+	urlExpire   time.Duration             // 私有空间下载地址的有效期
+	tokenExpire time.Duration             // 上传凭证的有效期
+	upHost      string                    // 自定义上传地址，为空时按区域选择
+	rsHost      string                    // 管理接口地址
+	callbackURL string                    // 客户端直传完成后七牛云回调的地址
+	uploader    *storage.FormUploader     // 表单上传工具
+	resumeUpV2  *storage.ResumeUploaderV2 // 分片上传工具（适用于流式上传）
 }
 
 // NewQiniuClient 创建七牛云客户端
@@ -29,7 +48,7 @@ func NewQiniuClient() *QiniuClient {
 	mac := qbox.NewMac(qiniuConf.AccessKey, qiniuConf.SecretKey)
 
 	cfg := &storage.Config{
-		UseHTTPS:      false,
+		UseHTTPS:      qiniuConf.UseHTTPS,
 		UseCdnDomains: false,
 	}
 
@@ -48,14 +67,34 @@ func NewQiniuClient() *QiniuClient {
 	default:
 		cfg.Zone = &storage.ZoneHuadong // 默认华东
 	}
+	// 自定义地址用于私有化部署或本地模拟服务
+	cfg.UpHost = qiniuConf.UpHost
+
+	rsHost := qiniuConf.RsHost
+	if rsHost == "" {
+		rsHost = cfg.Zone.RsHost
+	}
+	urlExpire, tokenExpire := qiniuConf.URLExpire, qiniuConf.TokenExpire
+	if urlExpire <= 0 {
+		urlExpire = defaultURLExpire
+	}
+	if tokenExpire <= 0 {
+		tokenExpire = defaultTokenExpire
+	}
 
 	return &QiniuClient{
-		mac:        mac,
-		cfg:        cfg,
-		bucket:     qiniuConf.Bucket,
-		domain:     qiniuConf.Domain,
-		uploader:   storage.NewFormUploader(cfg),
-		resumeUpV2: storage.NewResumeUploaderV2(cfg),
+		mac:         mac,
+		cfg:         cfg,
+		bucket:      qiniuConf.Bucket,
+		domain:      qiniuConf.Domain,
+		private:     qiniuConf.Private,
+		urlExpire:   time.Duration(urlExpire) * time.Second,
+		tokenExpire: time.Duration(tokenExpire) * time.Second,
+		upHost:      qiniuConf.UpHost,
+		rsHost:      rsHost,
+		callbackURL: qiniuConf.CallbackURL,
+		uploader:    storage.NewFormUploader(cfg),
+		resumeUpV2:  storage.NewResumeUploaderV2(cfg),
 	}
 }
 
@@ -67,7 +106,7 @@ func (q *QiniuClient) getUploadToken(key string) string {
 	return putPolicy.UploadToken(q.mac)
 }
 
-// UploadFile 表单上传文件
+// UploadFile 表单上传文件，返回对象的 key
 func (q *QiniuClient) UploadFile(key string, data []byte) (string, error) {
 	upToken := q.getUploadToken(key)
 	ret := storage.PutRet{}
@@ -76,68 +115,135 @@ func (q *QiniuClient) UploadFile(key string, data []byte) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("七牛云上传失败: %w", err)
 	}
-
-	// 返回文件访问URL
-	return q.getFileURL(ret.Key), nil
+	return ret.Key, nil
 }
 
-// UploadStream 流式上传文件
+// uploadPartSize 分片上传的分片大小，SDK 默认并发 4 个分片，单次上传占用的内存约为 4 个分片
+const uploadPartSize = 1 << 20
+
+// UploadStream 流式上传文件，返回对象的 key。reader 实现 io.ReaderAt 时按分片随机读取，否则顺序读取，不会把整个文件读入内存
 func (q *QiniuClient) UploadStream(key string, reader io.Reader, size int64) (string, error) {
+	// 分片上传不支持空文件
+	if size == 0 {
+		return q.UploadFile(key, nil)
+	}
 	upToken := q.getUploadToken(key)
 	ret := storage.PutRet{}
+	extra := &storage.RputV2Extra{PartSize: uploadPartSize, UpHost: q.upHost}
 
-	// 将 io.Reader 转换为 io.ReaderAt
-	// 先读取所有数据到内存中
-	data, err := io.ReadAll(reader)
-	if err != nil {
-		return "", fmt.Errorf("读取数据失败: %w", err)
+	var err error
+	if readerAt, ok := reader.(io.ReaderAt); ok && size > 0 {
+		err = q.resumeUpV2.Put(context.Background(), &ret, upToken, key, readerAt, size, extra)
+	} else {
+		err = q.resumeUpV2.PutWithoutSize(context.Background(), &ret, upToken, key, reader, extra)
 	}
-
-	// 使用 bytes.Reader 实现 io.ReaderAt 接口
-	readerAt := bytes.NewReader(data)
-
-	// 使用分片上传
-	err = q.resumeUpV2.Put(context.Background(), &ret, upToken, key, readerAt, int64(len(data)), nil)
 	if err != nil {
 		return "", fmt.Errorf("七牛云流式上传失败: %w", err)
 	}
+	return ret.Key, nil
+}
 
-	// 返回文件访问URL
-	return q.getFileURL(ret.Key), nil
+// Key 返回对象的 key，兼容早期记录中保存的完整 URL
+func Key(objectName string) string {
+	if !strings.HasPrefix(objectName, "http://") && !strings.HasPrefix(objectName, "https://") {
+		return objectName
+	}
+	u, err := url.Parse(objectName)
+	if err != nil {
+		return objectName
+	}
+	return strings.TrimPrefix(u.Path, "/")
 }
 
-// UploadStreamFromBytes 从字节数据流式上传文件（适用于已在内存中的数据）
-func (q *QiniuClient) UploadStreamFromBytes(key string, data []byte) (string, error) {
-	upToken := q.getUploadToken(key)
-	ret := storage.PutRet{}
+// DownloadURL 返回对象的下载地址：公开空间直接拼接域名，私有空间附带有效期内的签名
+func (q *QiniuClient) DownloadURL(objectName string) string {
+	return q.DownloadURLWithExpiry(objectName, time.Now().Add(q.urlExpire))
+}
 
-	// 使用 bytes.Reader 实现 io.ReaderAt 接口
-	readerAt := bytes.NewReader(data)
+// DownloadURLWithExpiry 指定私有空间下载地址的过期时间
+func (q *QiniuClient) DownloadURLWithExpiry(objectName string, deadline time.Time) string {
+	key := Key(objectName)
+	// 没有配置域名时无法生成地址，返回 key
+	if q.domain == "" {
+		return key
+	}
+	domain := q.withScheme(q.domain)
+	if q.private {
+		return storage.MakePrivateURLv2(q.mac, domain, key, deadline.Unix())
+	}
+	return storage.MakePublicURLv2(domain, key)
+}
 
-	// 使用分片上传
-	err := q.resumeUpV2.Put(context.Background(), &ret, upToken, key, readerAt, int64(len(data)), nil)
+// Open 通过下载地址读取对象内容（私有空间使用带签名的地址），用于服务端扫描等需要读取内容的场景
+func (q *QiniuClient) Open(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	if q.domain == "" {
+		return nil, errors.New("未配置七牛云访问域名")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, q.DownloadURL(objectName), nil)
 	if err != nil {
-		return "", fmt.Errorf("七牛云流式上传失败: %w", err)
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("七牛云返回 %d", resp.StatusCode)
 	}
+}
 
-	// 返回文件访问URL
-	return q.getFileURL(ret.Key), nil
+// UploadHost 客户端直传使用的上传地址
+func (q *QiniuClient) UploadHost() (string, error) {
+	if q.upHost != "" {
+		return q.withScheme(q.upHost), nil
+	}
+	return q.uploader.UpHost(q.mac.AccessKey, q.bucket)
 }
 
-// getFileURL 获取文件访问URL
-func (q *QiniuClient) getFileURL(key string) string {
-	// 如果key已经是完整的URL，直接返回
-	if strings.HasPrefix(key, "http://") || strings.HasPrefix(key, "https://") {
-		return key
+// CallbackEnabled 是否配置了直传回调
+func (q *QiniuClient) CallbackEnabled() bool {
+	return q.callbackURL != ""
+}
+
+// CallbackUploadToken 签发客户端直传的上传凭证，上传完成后七牛云以表单格式回调 callbackURL，vars 中的值在签发时写入凭证，客户端无法修改
+func (q *QiniuClient) CallbackUploadToken(key string, maxSize int64, vars url.Values) (token string, deadline time.Time) {
+	body := "key=$(key)&hash=$(etag)&fsize=$(fsize)"
+	if len(vars) > 0 {
+		// 值经过 URL 编码（$ 编码为 %24），不会被当作 $(...) 魔法变量
+		body += "&" + vars.Encode()
 	}
+	putPolicy := q.clientPutPolicy(key, maxSize)
+	putPolicy.CallbackURL = q.callbackURL
+	putPolicy.CallbackBody = body
+	return putPolicy.UploadToken(q.mac), time.Unix(int64(putPolicy.Expires), 0)
+}
 
-	// 如果没有配置域名，返回key
-	if q.domain == "" {
-		return key
+// ClientUploadToken 签发不带回调的客户端直传凭证，上传完成后由客户端确认
+func (q *QiniuClient) ClientUploadToken(key string, maxSize int64) (token string, deadline time.Time) {
+	putPolicy := q.clientPutPolicy(key, maxSize)
+	return putPolicy.UploadToken(q.mac), time.Unix(int64(putPolicy.Expires), 0)
+}
+
+// clientPutPolicy 客户端直传的上传策略：只能上传到指定 key、不能覆盖、大小不超过 maxSize（0 不限制）
+func (q *QiniuClient) clientPutPolicy(key string, maxSize int64) storage.PutPolicy {
+	return storage.PutPolicy{
+		Scope:      fmt.Sprintf("%s:%s", q.bucket, key),
+		Expires:    uint64(time.Now().Add(q.tokenExpire).Unix()),
+		InsertOnly: 1,
+		FsizeLimit: maxSize,
 	}
+}
 
-	// 构造完整的URL
-	return fmt.Sprintf("http://%s/%s", q.domain, key)
+// VerifyCallback 校验回调请求确实来自七牛云（签名覆盖路径和表单内容）
+func (q *QiniuClient) VerifyCallback(req *http.Request) (bool, error) {
+	return qbox.VerifyCallback(q.mac, req)
 }
 
 // GenerateObjectName 生成对象名称
@@ -147,12 +253,71 @@ func GenerateObjectName(userID uint64, filename string) string {
 	return fmt.Sprintf("uploads/%d/%d%s", userID, timestamp, ext)
 }
 
+// FileInfo 对象信息
+type FileInfo struct {
+	Size     int64  `json:"fsize"`
+	Hash     string `json:"hash"` // 七牛云的 etag
+	MimeType string `json:"mimeType"`
+	PutTime  int64  `json:"putTime"`
+}
+
+// Stat 查询对象信息，不存在时返回 ErrNotFound
+func (q *QiniuClient) Stat(key string) (*FileInfo, error) {
+	var info FileInfo
+	if err := q.rs(http.MethodGet, storage.URIStat(q.bucket, key), &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
 // DeleteFile 删除文件
 func (q *QiniuClient) DeleteFile(key string) error {
-	bucketManager := storage.NewBucketManager(q.mac, q.cfg)
-	err := bucketManager.Delete(q.bucket, key)
-	if err != nil {
+	if err := q.rs(http.MethodPost, storage.URIDelete(q.bucket, key), nil); err != nil {
 		return fmt.Errorf("删除七牛云文件失败: %w", err)
 	}
 	return nil
 }
+
+// rs 调用管理接口，直接请求配置的地址，不依赖区域查询
+func (q *QiniuClient) rs(method, path string, ret interface{}) error {
+	req, err := http.NewRequest(method, strings.TrimRight(q.withScheme(q.rsHost), "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if err = q.mac.AddToken(auth.TokenQBox, req); err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == 612:
+		return ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("七牛云返回 %d: %s", resp.StatusCode, e.Error)
+	case ret != nil:
+		return json.NewDecoder(resp.Body).Decode(ret)
+	}
+	return nil
+}
+
+// withScheme 没有写协议的地址按 UseHTTPS 补全
+func (q *QiniuClient) withScheme(host string) string {
+	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
+		return host
+	}
+	if q.cfg.UseHTTPS {
+		return "https://" + host
+	}
+	return "http://" + host
+}
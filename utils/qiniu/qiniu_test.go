@@ -0,0 +1,232 @@
+package qiniu_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"grpc-todolist-disk/conf"
+	"grpc-todolist-disk/utils/qiniu"
+	"grpc-todolist-disk/utils/qiniu/qiniutest"
+)
+
+const bucket = "test-bucket"
+
+// setup 启动模拟服务，并让全局配置中的七牛云地址都指向它
+func setup(t *testing.T, private bool, callbackURL string) (*qiniutest.Server, *qiniu.QiniuClient) {
+	t.Helper()
+	server := qiniutest.New("ak", "sk")
+	server.Private = private
+	t.Cleanup(server.Close)
+	old := conf.Conf
+	conf.Conf = &conf.Config{Qiniu: &conf.Qiniu{
+		AccessKey:   "ak",
+		SecretKey:   "sk",
+		Bucket:      bucket,
+		Domain:      server.URL,
+		Private:     private,
+		UpHost:      server.URL,
+		RsHost:      server.URL,
+		CallbackURL: callbackURL,
+	}}
+	t.Cleanup(func() { conf.Conf = old })
+	return server, qiniu.NewQiniuClient()
+}
+
+func get(t *testing.T, rawURL string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestPrivateDownloadURL(t *testing.T) {
+	server, client := setup(t, true, "")
+	server.Put(bucket, "uploads/1/a.txt", []byte("hello"))
+
+	status, body := get(t, client.DownloadURL("uploads/1/a.txt"))
+	if status != http.StatusOK || body != "hello" {
+		t.Fatalf("signed url: %d %q", status, body)
+	}
+	// 早期记录保存的是完整地址，按 key 重新签名
+	status, _ = get(t, client.DownloadURL(server.URL+"/uploads/1/a.txt"))
+	if status != http.StatusOK {
+		t.Fatalf("legacy object name: %d", status)
+	}
+
+	unsigned := server.URL + "/uploads/1/a.txt"
+	if status, _ = get(t, unsigned); status != http.StatusUnauthorized {
+		t.Fatalf("unsigned url: %d", status)
+	}
+	signed := client.DownloadURL("uploads/1/a.txt")
+	if status, _ = get(t, strings.Replace(signed, "a.txt", "b.txt", 1)); status != http.StatusUnauthorized {
+		t.Fatalf("tampered url: %d", status)
+	}
+	expired := client.DownloadURLWithExpiry("uploads/1/a.txt", time.Now().Add(-time.Minute))
+	if status, _ = get(t, expired); status != http.StatusForbidden {
+		t.Fatalf("expired url: %d", status)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	server, client := setup(t, true, "")
+	server.Put(bucket, "uploads/1/a.txt", []byte("hello"))
+
+	r, err := client.Open(context.Background(), "uploads/1/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "hello" {
+		t.Fatalf("content %q", data)
+	}
+	if _, err = client.Open(context.Background(), "uploads/1/missing"); !errors.Is(err, qiniu.ErrNotFound) {
+		t.Fatalf("missing object: %v", err)
+	}
+}
+
+func TestStatDelete(t *testing.T) {
+	server, client := setup(t, false, "")
+	server.Put(bucket, "uploads/1/a.txt", []byte("hello"))
+
+	info, err := client.Stat("uploads/1/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != 5 || info.Hash != qiniutest.Etag([]byte("hello")) {
+		t.Fatalf("stat %+v", info)
+	}
+	if err = client.DeleteFile("uploads/1/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Stat("uploads/1/a.txt"); !errors.Is(err, qiniu.ErrNotFound) {
+		t.Fatalf("stat after delete: %v", err)
+	}
+}
+
+// formUpload 模拟客户端用直传凭证上传
+func formUpload(t *testing.T, host, token, key string, data []byte) (int, map[string]interface{}) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	w.WriteField("token", token)
+	w.WriteField("key", key)
+	part, _ := w.CreateFormFile("file", "a.txt")
+	part.Write(data)
+	w.Close()
+	resp, err := http.Post(host, w.FormDataContentType(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	return resp.StatusCode, body
+}
+
+func TestCallbackUpload(t *testing.T) {
+	var (
+		verified bool
+		form     url.Values
+	)
+	var client *qiniu.QiniuClient
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, err := client.VerifyCallback(r)
+		if err != nil || !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		verified = true
+		r.ParseForm()
+		form = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"file_id":7}`))
+	}))
+	defer callback.Close()
+	server, c := setup(t, false, callback.URL+"/qiniu/callback")
+	client = c
+
+	if !client.CallbackEnabled() {
+		t.Fatal("callback should be enabled")
+	}
+	host, err := client.UploadHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("hello")
+	vars := url.Values{"user_id": {"42"}, "filename": {"$(fname)"}}
+	token, deadline := client.CallbackUploadToken("uploads/42/a.txt", 1024, vars)
+	if time.Until(deadline) <= 0 {
+		t.Fatalf("deadline %v", deadline)
+	}
+
+	status, body := formUpload(t, host, token, "uploads/42/a.txt", data)
+	if status != http.StatusOK || body["file_id"] != float64(7) {
+		t.Fatalf("upload: %d %v", status, body)
+	}
+	if !verified {
+		t.Fatal("callback signature not verified")
+	}
+	if form.Get("key") != "uploads/42/a.txt" || form.Get("fsize") != "5" || form.Get("hash") != qiniutest.Etag(data) {
+		t.Fatalf("callback form %v", form)
+	}
+	// 凭证中的变量经过编码，不会被当作魔法变量替换
+	if form.Get("user_id") != "42" || form.Get("filename") != "$(fname)" {
+		t.Fatalf("callback vars %v", form)
+	}
+	if server.Object(bucket, "uploads/42/a.txt") == nil {
+		t.Fatal("object not stored")
+	}
+
+	// 凭证只能上传到指定 key，且不能覆盖、不能超过大小限制
+	if status, _ = formUpload(t, host, token, "uploads/42/b.txt", data); status != http.StatusForbidden {
+		t.Fatalf("other key: %d", status)
+	}
+	if status, _ = formUpload(t, host, token, "uploads/42/a.txt", data); status != 614 {
+		t.Fatalf("overwrite: %d", status)
+	}
+	token, _ = client.CallbackUploadToken("uploads/42/big.txt", 4, nil)
+	if status, _ = formUpload(t, host, token, "uploads/42/big.txt", data); status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("too large: %d", status)
+	}
+}
+
+func TestVerifyCallbackRejectsForgery(t *testing.T) {
+	_, client := setup(t, false, "http://127.0.0.1/callback")
+	req := httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader("key=a&hash=b&fsize=1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if ok, _ := client.VerifyCallback(req); ok {
+		t.Fatal("request without signature accepted")
+	}
+	req.Header.Set("Authorization", "QBox ak:forged")
+	if ok, _ := client.VerifyCallback(req); ok {
+		t.Fatal("forged signature accepted")
+	}
+}
+
+func TestUploadStream(t *testing.T) {
+	server, client := setup(t, false, "")
+	data := bytes.Repeat([]byte("0123456789"), 300_000) // 3MB，多个分片
+	key, err := client.UploadStream("uploads/1/big.bin", bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := server.Object(bucket, key)
+	if obj == nil || !bytes.Equal(obj.Data, data) {
+		t.Fatal("uploaded content mismatch")
+	}
+}
@@ -0,0 +1,377 @@
+// Package qiniutest 提供七牛云 HTTP 接口的本地模拟服务，用于在没有七牛云账号的环境下联调：
+// 表单上传（含回调）、分片上传 v2、stat/delete 管理接口以及公开/私有空间下载。
+// 将 qiniu.upHost、qiniu.rsHost、qiniu.domain 都配置为 Server.URL 即可。
+package qiniutest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qiniu/go-sdk/v7/auth/qbox"
+	"github.com/qiniu/go-sdk/v7/storage"
+)
+
+// Object 已保存的对象
+type Object struct {
+	Data    []byte
+	Hash    string
+	PutTime int64
+}
+
+type multipart struct {
+	bucket, key string
+	policy      *storage.PutPolicy
+	parts       map[int64][]byte
+}
+
+type Server struct {
+	*httptest.Server
+	Private bool // 私有空间，下载需要签名
+
+	mac     *qbox.Mac
+	mu      sync.Mutex
+	objects map[string]*Object // bucket:key
+	uploads map[string]*multipart
+	nextID  int
+}
+
+// New 启动模拟服务，使用与客户端相同的 AK/SK 校验凭证和签名
+func New(accessKey, secretKey string) *Server {
+	s := &Server{
+		mac:     qbox.NewMac(accessKey, secretKey),
+		objects: make(map[string]*Object),
+		uploads: make(map[string]*multipart),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Object 返回对象内容，不存在时返回 nil
+func (s *Server) Object(bucket, key string) *Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.objects[bucket+":"+key]
+}
+
+// Put 直接写入对象，用于准备测试数据
+func (s *Server) Put(bucket, key string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[bucket+":"+key] = &Object{Data: data, Hash: Etag(data), PutTime: time.Now().UnixNano() / 100}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// SDK 把没有 X-Reqid 的响应当作被劫持
+	w.Header().Set("X-Reqid", strconv.FormatInt(time.Now().UnixNano(), 36))
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/":
+		s.formUpload(w, r)
+	case strings.HasPrefix(r.URL.Path, "/buckets/"):
+		s.resumeUpload(w, r)
+	case strings.HasPrefix(r.URL.Path, "/stat/"), strings.HasPrefix(r.URL.Path, "/delete/"):
+		s.manage(w, r)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		s.download(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "no such api")
+	}
+}
+
+// checkToken 校验上传凭证，返回其中的上传策略
+func (s *Server) checkToken(token, key string) (*storage.PutPolicy, int, string) {
+	parts := strings.Split(token, ":")
+	if len(parts) != 3 || s.mac.Sign([]byte(parts[2])) != parts[0]+":"+parts[1] {
+		return nil, http.StatusUnauthorized, "bad token"
+	}
+	raw, err := base64.URLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, http.StatusUnauthorized, "bad token"
+	}
+	var policy storage.PutPolicy
+	if err = json.Unmarshal(raw, &policy); err != nil {
+		return nil, http.StatusUnauthorized, "bad token"
+	}
+	if policy.Expires > 0 && uint64(time.Now().Unix()) > policy.Expires {
+		return nil, http.StatusUnauthorized, "expired token"
+	}
+	if bucket, scopeKey, ok := strings.Cut(policy.Scope, ":"); ok && scopeKey != key {
+		return nil, http.StatusForbidden, "key doesn't match with scope"
+	} else if bucket == "" {
+		return nil, http.StatusUnauthorized, "bad token"
+	}
+	return &policy, 0, ""
+}
+
+// store 按上传策略保存对象并回调，返回给上传客户端的响应
+func (s *Server) store(w http.ResponseWriter, policy *storage.PutPolicy, key, fname string, data []byte) {
+	bucket, _, _ := strings.Cut(policy.Scope, ":")
+	if policy.FsizeLimit > 0 && int64(len(data)) > policy.FsizeLimit {
+		writeError(w, 413, "exceed FsizeLimit")
+		return
+	}
+	s.mu.Lock()
+	if _, exists := s.objects[bucket+":"+key]; exists && policy.InsertOnly != 0 {
+		s.mu.Unlock()
+		writeError(w, 614, "file exists")
+		return
+	}
+	obj := &Object{Data: data, Hash: Etag(data), PutTime: time.Now().UnixNano() / 100}
+	s.objects[bucket+":"+key] = obj
+	s.mu.Unlock()
+
+	if policy.CallbackURL == "" {
+		writeJSON(w, http.StatusOK, map[string]string{"key": key, "hash": obj.Hash})
+		return
+	}
+	status, body, err := s.callback(policy, map[string]string{
+		"bucket": bucket,
+		"key":    key,
+		"etag":   obj.Hash,
+		"fsize":  strconv.Itoa(len(data)),
+		"fname":  fname,
+	})
+	if err != nil || status != http.StatusOK {
+		// 与七牛云一致：回调失败时对象已经保存，客户端收到 579
+		writeError(w, 579, fmt.Sprintf("callback failed: status %d %s %v", status, body, err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// callback 替换魔法变量后以表单格式请求回调地址，并按七牛云的方式签名
+func (s *Server) callback(policy *storage.PutPolicy, vars map[string]string) (int, []byte, error) {
+	body := policy.CallbackBody
+	for name, value := range vars {
+		body = strings.ReplaceAll(body, "$("+name+")", url.QueryEscape(value))
+	}
+	req, err := http.NewRequest(http.MethodPost, policy.CallbackURL, strings.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	token, err := s.mac.SignRequest(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "QBox "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, respBody, err
+}
+
+func (s *Server) formUpload(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	key := r.FormValue("key")
+	policy, status, msg := s.checkToken(r.FormValue("token"), key)
+	if policy == nil {
+		writeError(w, status, msg)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.store(w, policy, key, header.Filename, data)
+}
+
+// resumeUpload 分片上传 v2：/buckets/<bucket>/objects/<base64 key>/uploads[/<id>[/<part>]]
+func (s *Server) resumeUpload(w http.ResponseWriter, r *http.Request) {
+	segs := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(segs) < 5 || segs[2] != "objects" || segs[4] != "uploads" {
+		writeError(w, http.StatusNotFound, "no such api")
+		return
+	}
+	key := ""
+	if segs[3] != "~" {
+		raw, err := base64.URLEncoding.DecodeString(segs[3])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "bad key")
+			return
+		}
+		key = string(raw)
+	}
+	policy, status, msg := s.checkToken(strings.TrimPrefix(r.Header.Get("Authorization"), "UpToken "), key)
+	if policy == nil {
+		writeError(w, status, msg)
+		return
+	}
+
+	switch {
+	case len(segs) == 5 && r.Method == http.MethodPost:
+		s.mu.Lock()
+		s.nextID++
+		id := strconv.Itoa(s.nextID)
+		s.uploads[id] = &multipart{bucket: segs[1], key: key, policy: policy, parts: make(map[int64][]byte)}
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]interface{}{"uploadId": id, "expireAt": time.Now().Add(7 * 24 * time.Hour).Unix()})
+	case len(segs) == 7 && r.Method == http.MethodPut:
+		partNumber, _ := strconv.ParseInt(segs[6], 10, 64)
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.mu.Lock()
+		up := s.uploads[segs[5]]
+		if up != nil {
+			up.parts[partNumber] = data
+		}
+		s.mu.Unlock()
+		if up == nil {
+			writeError(w, 612, "no such uploadId")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"etag": Etag(data), "md5": ""})
+	case len(segs) == 6 && r.Method == http.MethodPost:
+		var req struct {
+			Parts []struct {
+				PartNumber int64  `json:"partNumber"`
+				Etag       string `json:"etag"`
+			} `json:"parts"`
+			FileName string `json:"fname"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.mu.Lock()
+		up := s.uploads[segs[5]]
+		delete(s.uploads, segs[5])
+		s.mu.Unlock()
+		if up == nil {
+			writeError(w, 612, "no such uploadId")
+			return
+		}
+		sort.Slice(req.Parts, func(i, j int) bool { return req.Parts[i].PartNumber < req.Parts[j].PartNumber })
+		var buf bytes.Buffer
+		for _, part := range req.Parts {
+			data, ok := up.parts[part.PartNumber]
+			if !ok || Etag(data) != part.Etag {
+				writeError(w, http.StatusBadRequest, "invalid part")
+				return
+			}
+			buf.Write(data)
+		}
+		s.store(w, up.policy, key, req.FileName, buf.Bytes())
+	default:
+		writeError(w, http.StatusNotFound, "no such api")
+	}
+}
+
+// manage stat/delete 管理接口，使用 QBox 管理凭证
+func (s *Server) manage(w http.ResponseWriter, r *http.Request) {
+	if ok, err := s.mac.VerifyCallback(r); err != nil || !ok {
+		writeError(w, http.StatusUnauthorized, "bad token")
+		return
+	}
+	op, encoded, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	entry, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad entry")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj := s.objects[string(entry)]
+	if obj == nil {
+		writeError(w, 612, "no such file or directory")
+		return
+	}
+	if op == "delete" {
+		delete(s.objects, string(entry))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"fsize":    len(obj.Data),
+		"hash":     obj.Hash,
+		"mimeType": http.DetectContentType(obj.Data),
+		"putTime":  obj.PutTime,
+	})
+}
+
+// download 下载任意空间中的对象（模拟服务只有一个域名），私有空间校验 e 和 token
+func (s *Server) download(w http.ResponseWriter, r *http.Request) {
+	if s.Private {
+		token := r.URL.Query().Get("token")
+		signed, _, _ := strings.Cut("http://"+r.Host+r.RequestURI, "&token=")
+		deadline, _ := strconv.ParseInt(r.URL.Query().Get("e"), 10, 64)
+		if token == "" || s.mac.Sign([]byte(signed)) != token {
+			writeError(w, http.StatusUnauthorized, "bad token")
+			return
+		}
+		if time.Now().Unix() > deadline {
+			writeError(w, http.StatusForbidden, "token out of date")
+			return
+		}
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	s.mu.Lock()
+	var obj *Object
+	for entry, o := range s.objects {
+		if _, k, _ := strings.Cut(entry, ":"); k == key {
+			obj = o
+			break
+		}
+	}
+	s.mu.Unlock()
+	if obj == nil {
+		writeError(w, http.StatusNotFound, "Document not found")
+		return
+	}
+	http.ServeContent(w, r, key, time.Time{}, bytes.NewReader(obj.Data))
+}
+
+// Etag 七牛云的文件哈希：按 4MB 分块计算 SHA1，单块时为 0x16+SHA1，多块时为 0x96+SHA1(各块 SHA1 拼接)
+func Etag(data []byte) string {
+	const blockSize = 4 << 20
+	if len(data) <= blockSize {
+		sum := sha1.Sum(data)
+		return base64.URLEncoding.EncodeToString(append([]byte{0x16}, sum[:]...))
+	}
+	var blocks []byte
+	for off := 0; off < len(data); off += blockSize {
+		sum := sha1.Sum(data[off:min(off+blockSize, len(data))])
+		blocks = append(blocks, sum[:]...)
+	}
+	sum := sha1.Sum(blocks)
+	return base64.URLEncoding.EncodeToString(append([]byte{0x96}, sum[:]...))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize 每次发送的数据块大小，需要小于 clamd 的 StreamMaxLength
+const clamdChunkSize = 64 << 10
+
+// Clamd 通过 clamd 的 INSTREAM 命令扫描，内容以流的方式发送，不需要 clamd 能访问本地文件
+type Clamd struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+func NewClamd(network, address string, timeout time.Duration) *Clamd {
+	if network == "" {
+		network = "tcp"
+	}
+	if address == "" {
+		address = "127.0.0.1:3310"
+	}
+	return &Clamd{network: network, address: address, timeout: timeout}
+}
+
+func (c *Clamd) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return nil, fmt.Errorf("连接 clamd 失败: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else if c.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+
+	// 协议：zINSTREAM\0，之后每块为 4 字节大端长度 + 数据，长度为 0 表示结束
+	w := bufio.NewWriterSize(conn, clamdChunkSize+4)
+	if _, err = w.WriteString("zINSTREAM\x00"); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, clamdChunkSize)
+	var size [4]byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size[:], uint32(n))
+			if _, werr := w.Write(size[:]); werr != nil {
+				return nil, werr
+			}
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return nil, werr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取文件失败: %w", err)
+		}
+	}
+	binary.BigEndian.PutUint32(size[:], 0)
+	if _, err = w.Write(size[:]); err != nil {
+		return nil, err
+	}
+	if err = w.Flush(); err != nil {
+		return nil, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("读取 clamd 响应失败: %w", err)
+	}
+	return parseClamdReply(reply)
+}
+
+// parseClamdReply 解析 "stream: OK"、"stream: Eicar-Signature FOUND" 或 "... ERROR"
+func parseClamdReply(reply string) (*Result, error) {
+	reply = strings.TrimRight(reply, "\x00\n")
+	if i := strings.Index(reply, ": "); i >= 0 {
+		reply = reply[i+2:]
+	}
+	switch {
+	case reply == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &Result{Infected: true, Signature: strings.TrimSuffix(reply, " FOUND")}, nil
+	case strings.Contains(reply, "size limit exceeded"):
+		return nil, errors.New("文件超过 clamd 的 StreamMaxLength 限制")
+	default:
+		return nil, errors.New("clamd 扫描失败: " + reply)
+	}
+}
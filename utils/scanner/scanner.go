@@ -0,0 +1,35 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"grpc-todolist-disk/conf"
+	"io"
+	"time"
+)
+
+// Result 扫描结果
+type Result struct {
+	Infected  bool
+	Signature string // 命中的病毒名
+}
+
+// Scanner 对文件内容做恶意文件扫描，扫描失败（而不是发现病毒）时返回 error
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+// New 按配置创建扫描器
+func New(c *conf.Scan) (Scanner, error) {
+	if c == nil {
+		return nil, errors.New("未配置扫描器")
+	}
+	switch c.Scanner {
+	case "", "clamd":
+		return NewClamd(c.Network, c.Address, time.Duration(c.Timeout)*time.Second), nil
+	case "fake":
+		return NewFake(c.Signatures...), nil
+	default:
+		return nil, errors.New("未知的扫描器: " + c.Scanner)
+	}
+}
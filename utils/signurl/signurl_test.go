@@ -0,0 +1,103 @@
+package signurl
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+var secret = []byte("test-secret")
+
+// parse 拆分签名链接，返回文件 ID 和查询参数
+func parse(t *testing.T, link string) (string, url.Values) {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(u.Path, Path) {
+		t.Fatalf("path %q does not start with %q", u.Path, Path)
+	}
+	return strings.TrimPrefix(u.Path, Path), u.Query()
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	p := Params{FileID: 42, UserID: 7, Expires: now.Add(time.Hour).Unix(), IP: "10.0.0.1", Attachment: true}
+	link := URL(secret, "https://disk.example.com", p)
+	if !strings.HasPrefix(link, "https://disk.example.com"+Path+"42?") {
+		t.Fatalf("link %q", link)
+	}
+	fileID, q := parse(t, link)
+
+	got, err := Verify(secret, fileID, q, "10.0.0.1", now)
+	if err != nil || *got != p {
+		t.Fatalf("Verify = %+v, %v, want %+v", got, err, p)
+	}
+
+	with := func(key, value string) url.Values {
+		c := url.Values{}
+		for k, v := range q {
+			c[k] = append([]string(nil), v...)
+		}
+		if value == "" {
+			c.Del(key)
+		} else {
+			c.Set(key, value)
+		}
+		return c
+	}
+	tests := []struct {
+		name     string
+		fileID   string
+		q        url.Values
+		clientIP string
+		now      time.Time
+		secret   []byte
+		want     error
+	}{
+		{"other file", "43", q, "10.0.0.1", now, secret, ErrSignature},
+		{"other user", fileID, with("uid", "8"), "10.0.0.1", now, secret, ErrSignature},
+		{"extended expiry", fileID, with("exp", "9999999999"), "10.0.0.1", now, secret, ErrSignature},
+		{"ip removed", fileID, with("ip", ""), "10.0.0.2", now, secret, ErrSignature},
+		{"ip changed", fileID, with("ip", "10.0.0.2"), "10.0.0.2", now, secret, ErrSignature},
+		{"inline instead of attachment", fileID, with("dl", ""), "10.0.0.1", now, secret, ErrSignature},
+		{"tampered signature", fileID, with("sig", q.Get("sig")[1:]+"A"), "10.0.0.1", now, secret, ErrSignature},
+		{"missing signature", fileID, with("sig", ""), "10.0.0.1", now, secret, ErrSignature},
+		{"other secret", fileID, q, "10.0.0.1", now, []byte("other"), ErrSignature},
+		{"empty secret", fileID, q, "10.0.0.1", now, nil, ErrSignature},
+		{"expired", fileID, q, "10.0.0.1", now.Add(time.Hour + time.Second), secret, ErrExpired},
+		{"wrong ip", fileID, q, "10.0.0.2", now, secret, ErrIP},
+		{"bad file id", "abc", q, "10.0.0.1", now, secret, ErrMalformed},
+		{"missing uid", fileID, with("uid", ""), "10.0.0.1", now, secret, ErrMalformed},
+		{"bad expiry", fileID, with("exp", "soon"), "10.0.0.1", now, secret, ErrMalformed},
+	}
+	for _, tt := range tests {
+		if _, err := Verify(tt.secret, tt.fileID, tt.q, tt.clientIP, tt.now); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// 到期的那一秒仍然有效
+	if _, err = Verify(secret, fileID, q, "10.0.0.1", time.Unix(p.Expires, 0)); err != nil {
+		t.Fatalf("at expiry: %v", err)
+	}
+}
+
+func TestVerifyWithoutIP(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	p := Params{FileID: 1, UserID: 2, Expires: now.Add(time.Minute).Unix()}
+	link := URL(secret, "", p)
+	if !strings.HasPrefix(link, Path) {
+		t.Fatalf("relative link %q", link)
+	}
+	fileID, q := parse(t, link)
+	// 未绑定 IP 时任何客户端都可以使用
+	for _, ip := range []string{"10.0.0.1", "2001:db8::1", ""} {
+		if got, err := Verify(secret, fileID, q, ip, now); err != nil || *got != p {
+			t.Fatalf("client %q: %+v %v", ip, got, err)
+		}
+	}
+}
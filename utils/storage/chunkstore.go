@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	LayoutRaw     = ""        // 普通文件，对象路径上就是文件内容
+	LayoutChunked = "chunked" // 对象路径上是分块清单，内容存放在分块目录
+
+	manifestMagic = "GDMANIFEST1"
+)
+
+// ChunkDir 分块存储目录，按哈希前两级分目录
+var ChunkDir = "stores/chunks"
+
+// ChunkRef 清单中的一个分块
+type ChunkRef struct {
+	Hash string
+	Size int64
+}
+
+// Manifest 分块清单，按顺序拼接各分块即为文件内容
+type Manifest struct {
+	Size   int64
+	Chunks []ChunkRef
+}
+
+// ChunkPath 分块的存储路径
+func ChunkPath(hash string) string {
+	return filepath.Join(ChunkDir, hash[:2], hash[2:4], hash)
+}
+
+// BuildManifest 第一遍扫描：只计算分块哈希，不写入任何分块
+func BuildManifest(path string, chunker *Chunker) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m := &Manifest{}
+	err = chunker.Split(f, func(data []byte) error {
+		sum := sha256.Sum256(data)
+		m.Chunks = append(m.Chunks, ChunkRef{Hash: hex.EncodeToString(sum[:]), Size: int64(len(data))})
+		m.Size += int64(len(data))
+		return nil
+	})
+	return m, err
+}
+
+// WriteChunks 第二遍扫描：写入尚不存在的分块。必须在分块引用计数增加之后调用，
+// 这样并发的释放方不会在写入之后删除这些分块
+func WriteChunks(path string, m *Manifest, chunker *Chunker) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	i := 0
+	return chunker.Split(f, func(data []byte) error {
+		if i >= len(m.Chunks) {
+			return errors.New("文件内容与清单不一致")
+		}
+		ref := m.Chunks[i]
+		i++
+		chunkPath := ChunkPath(ref.Hash)
+		if _, err := os.Stat(chunkPath); err == nil {
+			return nil
+		}
+		return writeFileAtomic(chunkPath, func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		})
+	})
+}
+
+// WriteManifest 原子地写入清单
+func WriteManifest(path string, m *Manifest) error {
+	return writeFileAtomic(path, func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		fmt.Fprintf(bw, "%s\n%d\n", manifestMagic, m.Size)
+		for _, ref := range m.Chunks {
+			fmt.Fprintf(bw, "%s %d\n", ref.Hash, ref.Size)
+		}
+		return bw.Flush()
+	})
+}
+
+// ReadManifest 读取清单
+func ReadManifest(path string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	if !sc.Scan() || sc.Text() != manifestMagic {
+		return nil, errors.New("不是分块清单: " + path)
+	}
+	m := &Manifest{}
+	if !sc.Scan() {
+		return nil, errors.New("清单格式错误: " + path)
+	}
+	if m.Size, err = strconv.ParseInt(sc.Text(), 10, 64); err != nil {
+		return nil, err
+	}
+	for sc.Scan() {
+		parts := strings.Fields(sc.Text())
+		if len(parts) != 2 || len(parts[0]) != sha256.Size*2 {
+			return nil, errors.New("清单格式错误: " + path)
+		}
+		size, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		m.Chunks = append(m.Chunks, ChunkRef{Hash: parts[0], Size: size})
+	}
+	return m, sc.Err()
+}
+
+// RemoveChunk 删除分块文件，不存在时忽略
+func RemoveChunk(hash string) error {
+	if err := os.Remove(ChunkPath(hash)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// writeFileAtomic 先写临时文件再重命名，避免读到写了一半的内容
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if err = write(tmp); err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useChunkDir 测试期间把分块写入临时目录
+func useChunkDir(t *testing.T) {
+	t.Helper()
+	old := ChunkDir
+	ChunkDir = filepath.Join(t.TempDir(), "chunks")
+	t.Cleanup(func() { ChunkDir = old })
+}
+
+func randomBytes(seed int64, n int) []byte {
+	p := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(p)
+	return p
+}
+
+func writeTemp(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// storeChunked 按上传流程分块保存文件，返回清单路径和清单
+func storeChunked(t *testing.T, name string, data []byte, chunker *Chunker) (string, *Manifest) {
+	t.Helper()
+	src := writeTemp(t, name, data)
+	m, err := BuildManifest(src, chunker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = WriteChunks(src, m, chunker); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), name+".manifest")
+	if err = WriteManifest(path, m); err != nil {
+		t.Fatal(err)
+	}
+	return path, m
+}
+
+func countChunkFiles(t *testing.T) int {
+	t.Helper()
+	n := 0
+	filepath.Walk(ChunkDir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			n++
+		}
+		return nil
+	})
+	return n
+}
+
+func TestSplitDeterministic(t *testing.T) {
+	chunker := NewChunker(1 << 10)
+	data := randomBytes(1, 64<<10)
+	split := func(data []byte) (sizes []int) {
+		if err := chunker.Split(bytes.NewReader(data), func(p []byte) error {
+			sizes = append(sizes, len(p))
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return sizes
+	}
+	sizes := split(data)
+	total := 0
+	for i, n := range sizes {
+		total += n
+		if n > chunker.max || (n < chunker.min && i != len(sizes)-1) {
+			t.Errorf("chunk %d has %d bytes, want %d-%d", i, n, chunker.min, chunker.max)
+		}
+	}
+	if total != len(data) {
+		t.Fatalf("chunks total %d bytes, want %d", total, len(data))
+	}
+	// 切分只取决于内容
+	if again := split(data); !equalInts(again, sizes) {
+		t.Fatalf("second split %v, want %v", again, sizes)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestChunkDedup 两个文件的公共内容只保存一份分块，读取时拼接出各自的原始内容
+func TestChunkDedup(t *testing.T) {
+	useChunkDir(t)
+	chunker := NewChunker(1 << 10)
+	shared := randomBytes(2, 32<<10)
+	a := append(append([]byte{}, shared...), randomBytes(3, 4<<10)...)
+	b := append(randomBytes(4, 4<<10), shared...)
+
+	pathA, ma := storeChunked(t, "a", a, chunker)
+	afterA := countChunkFiles(t)
+	if afterA != len(uniqueHashes(ma)) {
+		t.Fatalf("%d chunk files for %d unique chunks", afterA, len(uniqueHashes(ma)))
+	}
+	pathB, mb := storeChunked(t, "b", b, chunker)
+	common := 0
+	for hash := range uniqueHashes(mb) {
+		if uniqueHashes(ma)[hash] {
+			common++
+		}
+	}
+	if common == 0 {
+		t.Fatal("no chunks shared between files with common content")
+	}
+	if n := countChunkFiles(t); n != afterA+len(uniqueHashes(mb))-common {
+		t.Fatalf("%d chunk files after the second file, want %d", n, afterA+len(uniqueHashes(mb))-common)
+	}
+
+	for path, want := range map[string][]byte{pathA: a, pathB: b} {
+		obj, err := Open(path, LayoutChunked)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(obj)
+		obj.Close()
+		if err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("read %s: %d bytes err %v, want %d bytes", path, len(got), err, len(want))
+		}
+	}
+
+	// 删除 a 独有的分块后 b 仍然完整
+	for hash := range uniqueHashes(ma) {
+		if !uniqueHashes(mb)[hash] {
+			if err := RemoveChunk(hash); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := RemoveChunk(ma.Chunks[0].Hash); err != nil {
+		t.Fatalf("removing a missing chunk: %v", err)
+	}
+	obj, err := Open(pathB, LayoutChunked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer obj.Close()
+	if got, err := io.ReadAll(obj); err != nil || !bytes.Equal(got, b) {
+		t.Fatalf("read b after removing a's chunks: %d bytes err %v", len(got), err)
+	}
+}
+
+func uniqueHashes(m *Manifest) map[string]bool {
+	hashes := make(map[string]bool, len(m.Chunks))
+	for _, ref := range m.Chunks {
+		hashes[ref.Hash] = true
+	}
+	return hashes
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	m := &Manifest{Size: 7, Chunks: []ChunkRef{
+		{Hash: string(bytes.Repeat([]byte("a"), 64)), Size: 3},
+		{Hash: string(bytes.Repeat([]byte("b"), 64)), Size: 4},
+	}}
+	path := filepath.Join(t.TempDir(), "m")
+	if err := WriteManifest(path, m); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Size != m.Size || len(got.Chunks) != len(m.Chunks) || got.Chunks[0] != m.Chunks[0] || got.Chunks[1] != m.Chunks[1] {
+		t.Fatalf("read %+v, want %+v", got, m)
+	}
+
+	if _, err = ReadManifest(writeTemp(t, "raw", []byte("plain content"))); err == nil {
+		t.Fatal("a raw object should not parse as a manifest")
+	}
+}
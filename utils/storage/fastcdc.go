@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"math/bits"
+)
+
+const DefaultChunkAvgSize = 16 << 10 // 16KB
+
+// gear FastCDC 使用的随机表，用固定种子生成，保证不同进程切分结果一致
+var gear [256]uint64
+
+func init() {
+	seed := uint64(0x9E3779B97F4A7C15)
+	for i := range gear {
+		// splitmix64
+		seed += 0x9E3779B97F4A7C15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+		z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+		gear[i] = z ^ (z >> 31)
+	}
+}
+
+// Chunker FastCDC 内容定义分块：在平均大小之前使用更严格的掩码，之后使用更宽松的掩码，使块大小集中在平均值附近
+type Chunker struct {
+	min, avg, max int
+	maskS, maskL  uint64
+}
+
+// NewChunker avgSize 会被调整为 2 的幂，最小块为其 1/4，最大块为其 4 倍
+func NewChunker(avgSize int) *Chunker {
+	if avgSize <= 0 {
+		avgSize = DefaultChunkAvgSize
+	}
+	n := bits.Len(uint(avgSize)) - 1
+	if n < 8 {
+		n = 8
+	}
+	return &Chunker{
+		min:   (1 << n) / 4,
+		avg:   1 << n,
+		max:   (1 << n) * 4,
+		maskS: ^uint64(0) << (64 - (n + 2)),
+		maskL: ^uint64(0) << (64 - (n - 2)),
+	}
+}
+
+// cut 返回 data 中第一个块的长度
+func (c *Chunker) cut(data []byte) int {
+	n := len(data)
+	if n <= c.min {
+		return n
+	}
+	if n > c.max {
+		n = c.max
+	}
+	normal := c.avg
+	if normal > n {
+		normal = n
+	}
+	var fp uint64
+	i := c.min
+	for ; i < normal; i++ {
+		fp = (fp << 1) + gear[data[i]]
+		if fp&c.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		fp = (fp << 1) + gear[data[i]]
+		if fp&c.maskL == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
+
+// Split 将 r 切分为块，每个块回调一次；回调返回后 data 会被复用，需要保留时自行复制
+func (c *Chunker) Split(r io.Reader, emit func(data []byte) error) error {
+	buf := make([]byte, 2*c.max)
+	start, end := 0, 0
+	eof := false
+	for {
+		if !eof && end-start < c.max {
+			if start > 0 {
+				end = copy(buf, buf[start:end])
+				start = 0
+			}
+			n, err := io.ReadFull(r, buf[end:])
+			end += n
+			if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+				eof = true
+			} else if err != nil {
+				return err
+			}
+		}
+		if start == end {
+			return nil
+		}
+		n := c.cut(buf[start:end])
+		if err := emit(buf[start : start+n]); err != nil {
+			return err
+		}
+		start += n
+	}
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sort"
+)
+
+// Object 可随机读取的存储对象，http.ServeContent 等可以直接使用
+type Object interface {
+	io.ReadSeekCloser
+	io.ReaderAt
+	Size() int64
+}
+
+// Info 对象在本地磁盘上的保存方式
+type Info struct {
+	Layout     string // 存储布局
+	Codec      string // 压缩格式
+	StoredSize int64  // 压缩后的大小（布局之前），未压缩时等于原始大小
+}
+
+// OpenContent 按存储布局打开对象并解压，返回原始内容
+func OpenContent(path, layout, codec string) (Object, error) {
+	obj, err := Open(path, layout)
+	if err != nil {
+		return nil, err
+	}
+	content, err := Decode(obj, codec)
+	if err != nil {
+		obj.Close()
+		return nil, err
+	}
+	return content, nil
+}
+
+// Open 按存储布局打开对象，对调用方屏蔽分块等细节
+func Open(path, layout string) (Object, error) {
+	switch layout {
+	case LayoutRaw:
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &rawObject{File: f, size: info.Size()}, nil
+	case LayoutChunked:
+		m, err := ReadManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		return newChunkedObject(m), nil
+	default:
+		return nil, errors.New("未知的存储布局: " + layout)
+	}
+}
+
+type rawObject struct {
+	*os.File
+	size int64
+}
+
+func (o *rawObject) Size() int64 { return o.size }
+
+// cursor 基于 ReadAt 实现顺序读取和 Seek
+type cursor struct {
+	readAt func(p []byte, off int64) (int, error)
+	size   int64
+	pos    int64
+}
+
+func (c *cursor) Read(p []byte) (int, error) {
+	if c.pos >= c.size {
+		return 0, io.EOF
+	}
+	n, err := c.readAt(p, c.pos)
+	c.pos += int64(n)
+	if errors.Is(err, io.EOF) && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (c *cursor) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += c.pos
+	case io.SeekEnd:
+		offset += c.size
+	default:
+		return 0, errors.New("无效的 whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("负的偏移量")
+	}
+	c.pos = offset
+	return offset, nil
+}
+
+// chunkedObject 按清单拼接分块，读到哪个分块才打开哪个分块
+type chunkedObject struct {
+	cursor
+	m       *Manifest
+	offsets []int64 // 每个分块在文件中的起始偏移
+	cur     *os.File
+	curIdx  int
+}
+
+func newChunkedObject(m *Manifest) *chunkedObject {
+	offsets := make([]int64, len(m.Chunks))
+	var off int64
+	for i, ref := range m.Chunks {
+		offsets[i] = off
+		off += ref.Size
+	}
+	o := &chunkedObject{m: m, offsets: offsets, curIdx: -1}
+	o.cursor = cursor{readAt: o.ReadAt, size: m.Size}
+	return o
+}
+
+func (o *chunkedObject) Size() int64 { return o.m.Size }
+
+// chunkAt 返回包含偏移 off 的分块下标
+func (o *chunkedObject) chunkAt(off int64) int {
+	return sort.Search(len(o.offsets), func(i int) bool { return o.offsets[i] > off }) - 1
+}
+
+func (o *chunkedObject) open(idx int) error {
+	if o.curIdx == idx && o.cur != nil {
+		return nil
+	}
+	if o.cur != nil {
+		o.cur.Close()
+		o.cur = nil
+	}
+	f, err := os.Open(ChunkPath(o.m.Chunks[idx].Hash))
+	if err != nil {
+		return err
+	}
+	o.cur, o.curIdx = f, idx
+	return nil
+}
+
+func (o *chunkedObject) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("负的偏移量")
+	}
+	total := 0
+	for len(p) > 0 {
+		if off >= o.m.Size {
+			return total, io.EOF
+		}
+		idx := o.chunkAt(off)
+		if err := o.open(idx); err != nil {
+			return total, err
+		}
+		inChunk := off - o.offsets[idx]
+		limit := o.m.Chunks[idx].Size - inChunk
+		buf := p
+		if int64(len(buf)) > limit {
+			buf = buf[:limit]
+		}
+		n, err := o.cur.ReadAt(buf, inChunk)
+		total += n
+		off += int64(n)
+		p = p[n:]
+		if err != nil && !(errors.Is(err, io.EOF) && int64(n) == limit) {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (o *chunkedObject) Close() error {
+	if o.cur != nil {
+		err := o.cur.Close()
+		o.cur = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	CodecNone = ""     // 未压缩
+	CodecZstd = "zstd" // zstd 可随机访问格式
+
+	// DefaultFrameSize 压缩帧的平均原始大小，帧之间相互独立，随机读取时只需解压一帧
+	DefaultFrameSize = 64 << 10
+
+	skippableFrameMagic = 0x184D2A5E
+	seekableMagic       = 0x8F92EAB1
+	seekTableFooterSize = 9
+)
+
+// DefaultCompressTypes 默认压缩的 MIME 类型（前缀匹配）
+var DefaultCompressTypes = []string{
+	"text/",
+	"application/json",
+	"application/xml",
+	"application/javascript",
+	"application/yaml",
+	"application/x-yaml",
+	"image/svg+xml",
+}
+
+// extraTypes 系统 MIME 表中可能缺失的常见文本扩展名
+var extraTypes = map[string]string{
+	".md":   "text/markdown",
+	".log":  "text/plain",
+	".csv":  "text/csv",
+	".json": "application/json",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+}
+
+var (
+	encoder, _ = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	decoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+)
+
+// Compressible 根据文件扩展名对应的 MIME 类型判断是否值得压缩
+func Compressible(filename string, types []string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	typ := mime.TypeByExtension(ext)
+	if typ == "" {
+		typ = extraTypes[ext]
+	}
+	if typ == "" {
+		return false
+	}
+	if len(types) == 0 {
+		types = DefaultCompressTypes
+	}
+	for _, prefix := range types {
+		if strings.HasPrefix(typ, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompressFile 将 src 压缩为 zstd 可随机访问格式写入 dst，返回压缩后的大小。
+// 帧边界由内容决定，相同的内容片段总是得到相同的压缩帧，压缩后仍可以分块去重；
+// 帧索引放在末尾的可跳过帧中，整个文件同时是合法的 zstd 流，可以直接作为 Content-Encoding: zstd 返回
+func CompressFile(src, dst string, frameSize int) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	var written int64
+	err = writeFileAtomic(dst, func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		var table []byte
+		var frames uint32
+		var buf []byte
+		err := NewChunker(frameSize).Split(in, func(data []byte) error {
+			buf = encoder.EncodeAll(data, buf[:0])
+			if _, err := bw.Write(buf); err != nil {
+				return err
+			}
+			table = binary.LittleEndian.AppendUint32(table, uint32(len(buf)))
+			table = binary.LittleEndian.AppendUint32(table, uint32(len(data)))
+			written += int64(len(buf))
+			frames++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// 索引：可跳过帧头 + 每帧（压缩大小, 原始大小）+ 帧数、描述符、魔数
+		footer := binary.LittleEndian.AppendUint32(nil, skippableFrameMagic)
+		footer = binary.LittleEndian.AppendUint32(footer, uint32(len(table)+seekTableFooterSize))
+		footer = append(footer, table...)
+		footer = binary.LittleEndian.AppendUint32(footer, frames)
+		footer = append(footer, 0)
+		footer = binary.LittleEndian.AppendUint32(footer, seekableMagic)
+		if _, err = bw.Write(footer); err != nil {
+			return err
+		}
+		written += int64(len(footer))
+		return bw.Flush()
+	})
+	if err != nil {
+		return 0, err
+	}
+	return written, nil
+}
+
+// Decode 按压缩格式包装对象，返回原始内容
+func Decode(obj Object, codec string) (Object, error) {
+	switch codec {
+	case CodecNone:
+		return obj, nil
+	case CodecZstd:
+		return newSeekableObject(obj)
+	default:
+		return nil, errors.New("未知的压缩格式: " + codec)
+	}
+}
+
+// seekableObject 读取 zstd 可随机访问格式，只解压包含读取位置的帧
+type seekableObject struct {
+	cursor
+	src       Object
+	compOffs  []int64 // 每帧在压缩流中的起始偏移，最后一个元素为所有帧的总大小
+	rawOffs   []int64 // 每帧在原始内容中的起始偏移，最后一个元素为原始大小
+	cache     []byte
+	cacheIdx  int
+	cacheComp []byte
+}
+
+func newSeekableObject(src Object) (*seekableObject, error) {
+	size := src.Size()
+	if size < seekTableFooterSize {
+		return nil, errors.New("压缩对象已损坏")
+	}
+	footer := make([]byte, seekTableFooterSize)
+	if _, err := src.ReadAt(footer, size-seekTableFooterSize); err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(footer[5:]) != seekableMagic {
+		return nil, errors.New("压缩对象缺少帧索引")
+	}
+	frames := int64(binary.LittleEndian.Uint32(footer))
+	entrySize := int64(8)
+	if footer[4]&0x80 != 0 { // 带校验和的索引
+		entrySize = 12
+	}
+	tableSize := frames * entrySize
+	if size < tableSize+seekTableFooterSize+8 {
+		return nil, errors.New("压缩对象已损坏")
+	}
+	table := make([]byte, tableSize)
+	if _, err := src.ReadAt(table, size-seekTableFooterSize-tableSize); err != nil {
+		return nil, err
+	}
+
+	o := &seekableObject{
+		src:      src,
+		compOffs: make([]int64, frames+1),
+		rawOffs:  make([]int64, frames+1),
+		cacheIdx: -1,
+	}
+	var comp int64
+	for i := int64(0); i < frames; i++ {
+		entry := table[i*entrySize:]
+		o.compOffs[i] = comp
+		comp += int64(binary.LittleEndian.Uint32(entry))
+		o.rawOffs[i+1] = o.rawOffs[i] + int64(binary.LittleEndian.Uint32(entry[4:]))
+	}
+	o.compOffs[frames] = comp
+	if comp+8+tableSize+seekTableFooterSize != size {
+		return nil, errors.New("压缩对象帧索引与大小不一致")
+	}
+	o.cursor = cursor{readAt: o.ReadAt, size: o.rawOffs[frames]}
+	return o, nil
+}
+
+func (o *seekableObject) Size() int64 { return o.cursor.size }
+
+// frame 解压第 idx 帧，保留最近一帧以便顺序读取
+func (o *seekableObject) frame(idx int) ([]byte, error) {
+	if o.cacheIdx == idx {
+		return o.cache, nil
+	}
+	comp := o.cacheComp
+	if n := int(o.compOffs[idx+1] - o.compOffs[idx]); cap(comp) < n {
+		comp = make([]byte, n)
+	} else {
+		comp = comp[:n]
+	}
+	if _, err := o.src.ReadAt(comp, o.compOffs[idx]); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	o.cacheIdx = -1
+	raw, err := decoder.DecodeAll(comp, o.cache[:0])
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(raw)) != o.rawOffs[idx+1]-o.rawOffs[idx] {
+		return nil, errors.New("压缩帧大小与索引不一致")
+	}
+	o.cacheComp, o.cache, o.cacheIdx = comp, raw, idx
+	return raw, nil
+}
+
+func (o *seekableObject) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("负的偏移量")
+	}
+	total := 0
+	for len(p) > 0 {
+		if off >= o.cursor.size {
+			return total, io.EOF
+		}
+		idx := sort.Search(len(o.compOffs)-1, func(i int) bool { return o.rawOffs[i+1] > off })
+		raw, err := o.frame(idx)
+		if err != nil {
+			return total, err
+		}
+		n := copy(p, raw[off-o.rawOffs[idx]:])
+		total += n
+		off += int64(n)
+		p = p[n:]
+	}
+	return total, nil
+}
+
+func (o *seekableObject) Close() error {
+	return o.src.Close()
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+// textBytes 生成可压缩的文本内容
+func textBytes(seed int64, n int) []byte {
+	r := rand.New(rand.NewSource(seed))
+	var buf bytes.Buffer
+	for buf.Len() < n {
+		fmt.Fprintf(&buf, "line %d value %d\n", buf.Len(), r.Intn(1000))
+	}
+	return buf.Bytes()[:n]
+}
+
+// compressed 压缩 data 并按 layout 保存，返回解压后的对象
+func compressed(t *testing.T, data []byte, layout string) Object {
+	t.Helper()
+	dst := filepath.Join(t.TempDir(), "obj.zst")
+	size, err := CompressFile(writeTemp(t, "obj", data), dst, 4<<10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size >= int64(len(data)) {
+		t.Fatalf("compressed %d bytes to %d", len(data), size)
+	}
+	path := dst
+	if layout == LayoutChunked {
+		path, _ = storeChunked(t, "obj.zst", readFile(t, dst), NewChunker(1<<10))
+	}
+	obj, err := OpenContent(path, layout, CodecZstd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { obj.Close() })
+	return obj
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+	obj, err := Open(path, LayoutRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer obj.Close()
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// TestZstdSeek 在任意位置 Seek 后读取的内容与原始内容一致，分块保存的压缩对象同样适用
+func TestZstdSeek(t *testing.T) {
+	useChunkDir(t)
+	data := textBytes(1, 100<<10)
+	for _, layout := range []string{LayoutRaw, LayoutChunked} {
+		obj := compressed(t, data, layout)
+		if obj.Size() != int64(len(data)) {
+			t.Fatalf("%q: size %d, want %d", layout, obj.Size(), len(data))
+		}
+		all, err := io.ReadAll(obj)
+		if err != nil || !bytes.Equal(all, data) {
+			t.Fatalf("%q: sequential read %d bytes err %v", layout, len(all), err)
+		}
+
+		r := rand.New(rand.NewSource(2))
+		offsets := []int64{0, 1, 4 << 10, 4<<10 - 1, int64(len(data)) - 1, int64(len(data)) - 100}
+		for i := 0; i < 50; i++ {
+			offsets = append(offsets, r.Int63n(int64(len(data))))
+		}
+		for _, off := range offsets {
+			n := 1 + r.Intn(10<<10) // 可能跨越多个帧
+			if _, err = obj.Seek(off, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			got := make([]byte, n)
+			m, err := io.ReadFull(obj, got)
+			want := data[off:min(off+int64(n), int64(len(data)))]
+			if !bytes.Equal(got[:m], want) || (err != nil && m != len(want)) {
+				t.Fatalf("%q: read %d bytes at %d: got %d bytes err %v", layout, n, off, m, err)
+			}
+		}
+
+		// ReadAt 与 Seek 无关
+		got := make([]byte, 300)
+		if n, err := obj.ReadAt(got, 12345); err != nil || !bytes.Equal(got[:n], data[12345:12645]) {
+			t.Fatalf("%q: ReadAt: %d bytes err %v", layout, n, err)
+		}
+		if pos, err := obj.Seek(-10, io.SeekEnd); err != nil || pos != int64(len(data))-10 {
+			t.Fatalf("%q: seek from end %d err %v", layout, pos, err)
+		}
+		if tail, err := io.ReadAll(obj); err != nil || !bytes.Equal(tail, data[len(data)-10:]) {
+			t.Fatalf("%q: tail %q err %v", layout, tail, err)
+		}
+	}
+}
+
+func TestZstdEmpty(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "empty.zst")
+	if _, err := CompressFile(writeTemp(t, "empty", nil), dst, 4<<10); err != nil {
+		t.Fatal(err)
+	}
+	obj, err := OpenContent(dst, LayoutRaw, CodecZstd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer obj.Close()
+	if data, err := io.ReadAll(obj); err != nil || len(data) != 0 || obj.Size() != 0 {
+		t.Fatalf("read %q err %v size %d", data, err, obj.Size())
+	}
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	path := writeTemp(t, "plain", textBytes(3, 1000))
+	if _, err := OpenContent(path, LayoutRaw, CodecZstd); err == nil {
+		t.Fatal("an uncompressed object should be rejected")
+	}
+	if _, err := OpenContent(path, LayoutRaw, "gzip"); err == nil {
+		t.Fatal("unknown codec should be rejected")
+	}
+}
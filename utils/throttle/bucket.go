@@ -0,0 +1,161 @@
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"grpc-todolist-disk/conf"
+)
+
+// Direction 传输方向
+type Direction string
+
+const (
+	Upload   Direction = "upload"
+	Download Direction = "download"
+)
+
+// chunkSize 单次读写的最大字节数，限速时按这个粒度等待，避免一次读写过大造成突发
+const chunkSize = 32 << 10
+
+// Bucket 令牌桶，每秒补充 rate 个令牌（字节），最多积累 1 秒的量。允许透支，透支的部分由下一次等待补回
+type Bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewBucket 创建令牌桶，rate 不大于 0 时返回 nil（不限速）
+func NewBucket(rate int64) *Bucket {
+	if rate <= 0 {
+		return nil
+	}
+	burst := float64(max(rate, chunkSize))
+	return &Bucket{rate: float64(rate), burst: burst, tokens: burst, last: time.Now()}
+}
+
+// reserve 取出 n 个令牌，返回需要等待的时间
+func (b *Bucket) reserve(n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// Wait 等待 n 个字节的令牌，b 为 nil 时直接返回
+func (b *Bucket) Wait(ctx context.Context, n int) error {
+	if b == nil || n <= 0 {
+		return nil
+	}
+	d := b.reserve(n)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reader 按令牌桶限速读取，b 为 nil 时返回 r 本身
+func Reader(ctx context.Context, r io.Reader, b *Bucket) io.Reader {
+	if b == nil {
+		return r
+	}
+	return &reader{ctx: ctx, r: r, b: b}
+}
+
+type reader struct {
+	ctx context.Context
+	r   io.Reader
+	b   *Bucket
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if len(p) > chunkSize {
+		p = p[:chunkSize]
+	}
+	n, err := r.r.Read(p)
+	if waitErr := r.b.Wait(r.ctx, n); waitErr != nil && err == nil {
+		err = waitErr
+	}
+	return n, err
+}
+
+// Writer 按令牌桶限速写入，b 为 nil 时返回 w 本身
+func Writer(ctx context.Context, w io.Writer, b *Bucket) io.Writer {
+	if b == nil {
+		return w
+	}
+	return &writer{ctx: ctx, w: w, b: b}
+}
+
+type writer struct {
+	ctx context.Context
+	w   io.Writer
+	b   *Bucket
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), chunkSize)]
+		if err := w.b.Wait(w.ctx, len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := w.w.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// buckets 每个用户每个方向一个令牌桶，同一用户的并发传输共享带宽
+var buckets sync.Map
+
+// For 返回用户在该方向上的令牌桶，用户等级未限速时返回 nil。令牌桶在进程内共享，带宽按实例计算
+func For(userID uint, dir Direction) *Bucket {
+	rate, _ := limits(userID, dir)
+	if rate <= 0 {
+		return nil
+	}
+	// 速率写入 key，配置变化后使用新的令牌桶
+	key := fmt.Sprintf("%s:%d:%d", dir, userID, rate)
+	if b, ok := buckets.Load(key); ok {
+		return b.(*Bucket)
+	}
+	b, _ := buckets.LoadOrStore(key, NewBucket(rate))
+	return b.(*Bucket)
+}
+
+// limits 返回用户等级在该方向上的带宽和并发数上限
+func limits(userID uint, dir Direction) (rate int64, concurrent int) {
+	_, tier := conf.TierOf(userID)
+	if tier == nil {
+		return 0, 0
+	}
+	if dir == Upload {
+		return tier.UploadRate, tier.MaxUploads
+	}
+	return tier.DownloadRate, tier.MaxDownloads
+}
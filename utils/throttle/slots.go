@@ -0,0 +1,113 @@
+package throttle
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+const (
+	// leaseTTL 并发名额的租约时长，持有期间定期续约；实例崩溃时租约到期后自动释放
+	leaseTTL = 30 * time.Second
+	// RetryAfter 并发数已满时建议客户端等待的时间
+	RetryAfter = 5 * time.Second
+)
+
+// ErrTooManyTransfers 同时进行的传输数已达到用户等级的上限
+var ErrTooManyTransfers = errors.New("同时进行的传输过多")
+
+// acquireScript 清理过期租约后，名额未满时加入新租约。有序集合的成员为租约 ID，分数为到期时间（毫秒）
+// ARGV: 当前时间、到期时间、租约时长、并发上限、租约 ID
+var acquireScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[4]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[5])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return 1
+`)
+
+// renewScript 续约，租约已被清理时不再加入。ARGV: 到期时间、租约时长、租约 ID
+var renewScript = redis.NewScript(`
+if not redis.call('ZSCORE', KEYS[1], ARGV[3]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[3])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+// Slots 在 redis 中记录每个用户正在进行的传输，多个网关实例共享同一份计数
+type Slots struct {
+	rdb *redis.Client
+}
+
+func NewSlots(rdb *redis.Client) *Slots {
+	return &Slots{rdb: rdb}
+}
+
+func slotKey(userID uint, dir Direction) string {
+	return fmt.Sprintf("transfer:%s:%d", dir, userID)
+}
+
+// Acquire 占用一个并发名额，返回释放函数。名额已满时返回 ErrTooManyTransfers；
+// 用户等级不限制并发或 redis 不可用时不做限制，避免 redis 故障导致无法传输
+func (s *Slots) Acquire(ctx context.Context, userID uint, dir Direction) (func(), error) {
+	_, limit := limits(userID, dir)
+	if s == nil || s.rdb == nil || limit <= 0 {
+		return func() {}, nil
+	}
+	key := slotKey(userID, dir)
+	id := leaseID()
+	now := time.Now()
+	ok, err := acquireScript.Run(ctx, s.rdb, []string{key},
+		now.UnixMilli(), now.Add(leaseTTL).UnixMilli(), leaseTTL.Milliseconds(), limit, id).Int()
+	if err != nil {
+		zap.L().Warn("获取传输名额失败，不限制并发", zap.String("key", key), zap.Error(err))
+		return func() {}, nil
+	}
+	if ok == 0 {
+		return nil, ErrTooManyTransfers
+	}
+
+	// 传输期间定期续约
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(leaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := renewScript.Run(context.Background(), s.rdb, []string{key},
+					time.Now().Add(leaseTTL).UnixMilli(), leaseTTL.Milliseconds(), id).Err()
+				if err != nil {
+					zap.L().Warn("传输名额续约失败", zap.String("key", key), zap.Error(err))
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		// 请求的 ctx 可能已经取消，释放时使用新的 ctx
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := s.rdb.ZRem(ctx, key, id).Err(); err != nil {
+			zap.L().Warn("释放传输名额失败", zap.String("key", key), zap.Error(err))
+		}
+	}, nil
+}
+
+func leaseID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
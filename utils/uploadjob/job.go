@@ -0,0 +1,120 @@
+package uploadjob
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// StagingDir 异步上传的暂存目录，网关写入，kafka_server 移入正式存储
+const StagingDir = "stores/uploaded_temp/async"
+
+// ttl 任务记录的保留时间
+const ttl = 24 * time.Hour
+
+// 任务状态
+const (
+	StatePending = "pending" // 已提交，等待处理或处理失败重试中
+	StateRunning = "running" // kafka_server 正在处理
+	StateDone    = "done"    // 已写入存储，可以下载
+	StateFailed  = "failed"  // 无法完成（暂存文件丢失等），不再重试
+)
+
+var ErrNotFound = errors.New("上传任务不存在或已过期")
+
+// Job 异步上传任务
+type Job struct {
+	ID        string `json:"job_id"`
+	UserID    uint64 `json:"user_id"`
+	Filename  string `json:"filename"`
+	State     string `json:"state"`
+	Attempts  int    `json:"attempts"`          // 已处理的次数，包括失败重试
+	FileID    uint64 `json:"file_id,omitempty"` // 完成后的文件 ID
+	Error     string `json:"error,omitempty"`   // 最近一次失败的原因
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+func key(id string) string {
+	return "upload_job:" + id
+}
+
+// Channel 用户任务状态变化的发布频道
+func Channel(userID uint64) string {
+	return fmt.Sprintf("upload_job_events:%d", userID)
+}
+
+// NewID 生成任务 ID
+func NewID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Create 保存新提交的任务
+func Create(ctx context.Context, rdb *redis.Client, job *Job) error {
+	now := time.Now().Unix()
+	job.State, job.CreatedAt, job.UpdatedAt = StatePending, now, now
+	return save(ctx, rdb, job)
+}
+
+// Get 查询任务
+func Get(ctx context.Context, rdb *redis.Client, id string) (*Job, error) {
+	data, err := rdb.Get(ctx, key(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var job Job
+	if err = json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Delete 删除任务，任务没能提交到 kafka 时使用
+func Delete(ctx context.Context, rdb *redis.Client, id string) error {
+	return rdb.Del(ctx, key(id)).Err()
+}
+
+// Update 修改任务状态，任务不存在（已过期或旧版本网关提交）时忽略
+func Update(ctx context.Context, rdb *redis.Client, id string, fn func(job *Job)) error {
+	if id == "" {
+		return nil
+	}
+	job, err := Get(ctx, rdb, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fn(job)
+	job.UpdatedAt = time.Now().Unix()
+	return save(ctx, rdb, job)
+}
+
+// Subscribe 订阅用户所有任务的状态变化，消息内容为 Job 的 JSON
+func Subscribe(ctx context.Context, rdb *redis.Client, userID uint64) *redis.PubSub {
+	return rdb.Subscribe(ctx, Channel(userID))
+}
+
+// save 保存任务并通知订阅者
+func save(ctx context.Context, rdb *redis.Client, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if err = rdb.Set(ctx, key(job.ID), data, ttl).Err(); err != nil {
+		return err
+	}
+	return rdb.Publish(ctx, Channel(job.UserID), data).Err()
+}